@@ -0,0 +1,247 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "go.k6.io/k6/api/v1"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	prometheusContentType  = "text/plain; version=0.0.4; charset=utf-8"
+	openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	prometheusNamespace    = "k6_"
+)
+
+// prometheusQuantiles are the quantiles that are exposed for Trend metrics.
+// The 0 and 1 quantiles are the min and max values.
+var prometheusQuantiles = []float64{0, 0.5, 0.9, 0.95, 0.99, 1} //nolint:gochecknoglobals
+
+// handlePrometheusMetrics serves the metrics of the MetricsEngine in the
+// Prometheus text exposition format or, if the scraper asks for it, in the
+// OpenMetrics format.
+func handlePrometheusMetrics(cs *v1.ControlSurface) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		openMetrics := strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text")
+		if openMetrics {
+			rw.Header().Set("Content-Type", openMetricsContentType)
+		} else {
+			rw.Header().Set("Content-Type", prometheusContentType)
+		}
+
+		// The values are copied from the sinks while holding the lock, since
+		// they are concurrently modified by the metrics ingester.
+		cs.MetricsEngine.MetricsLock.Lock()
+		families := newPrometheusFamilies(cs.MetricsEngine.ObservedMetrics)
+		cs.MetricsEngine.MetricsLock.Unlock()
+
+		if err := writePrometheusFamilies(rw, families, openMetrics); err != nil {
+			cs.RunState.Logger.WithError(err).Error("Error while writing the Prometheus metrics")
+		}
+	})
+}
+
+// prometheusFamily is a single metric family, which contains the values of a
+// k6 metric and of all its submetrics, with the submetric tags as labels.
+type prometheusFamily struct {
+	name   string
+	typ    string
+	series []prometheusSeries
+}
+
+type prometheusSeries struct {
+	labels  [][2]string
+	samples []prometheusSample
+}
+
+// prometheusSample is a single value of a series, with the suffix of its name
+// and the extra labels it has, e.g. the quantile of a summary.
+type prometheusSample struct {
+	suffix string
+	extra  []string
+	value  float64
+}
+
+func newPrometheusFamilies(observed map[string]*metrics.Metric) []*prometheusFamily {
+	byName := make(map[string]*prometheusFamily)
+	for _, m := range observed {
+		parent, labels := m, [][2]string(nil)
+		if m.Sub != nil {
+			parent = m.Sub.Parent
+			for k, v := range m.Sub.Tags.Map() {
+				labels = append(labels, [2]string{sanitizePrometheusName(k), v})
+			}
+			sort.Slice(labels, func(i, j int) bool { return labels[i][0] < labels[j][0] })
+		}
+
+		f, ok := byName[parent.Name]
+		if !ok {
+			f = &prometheusFamily{name: prometheusFamilyName(parent), typ: prometheusType(parent.Type)}
+			byName[parent.Name] = f
+		}
+		f.series = append(f.series, prometheusSeries{labels: labels, samples: newPrometheusSamples(f.name, m.Sink)})
+	}
+
+	families := make([]*prometheusFamily, 0, len(byName))
+	for _, f := range byName {
+		sort.Slice(f.series, func(i, j int) bool {
+			return formatPrometheusLabels(f.series[i].labels) < formatPrometheusLabels(f.series[j].labels)
+		})
+		families = append(families, f)
+	}
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+	return families
+}
+
+func prometheusFamilyName(m *metrics.Metric) string {
+	name := prometheusNamespace + sanitizePrometheusName(m.Name)
+	switch m.Contains {
+	case metrics.Time:
+		name += "_seconds"
+	case metrics.Data:
+		name += "_bytes"
+	default:
+	}
+	if m.Type == metrics.Rate {
+		name += "_rate"
+	}
+	return name
+}
+
+func prometheusType(mt metrics.MetricType) string {
+	switch mt {
+	case metrics.Counter:
+		return "counter"
+	case metrics.Trend:
+		return "summary"
+	default:
+		return "gauge"
+	}
+}
+
+// sanitizePrometheusName replaces all characters that are not allowed in
+// Prometheus metric and label names with underscores.
+func sanitizePrometheusName(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune('_')
+		}
+	}
+	return sb.String()
+}
+
+func formatPrometheusLabels(labels [][2]string, extra ...string) string {
+	if len(labels) == 0 && len(extra) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(labels)+len(extra)/2)
+	for _, l := range labels {
+		parts = append(parts, l[0]+`="`+escapePrometheusLabelValue(l[1])+`"`)
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		parts = append(parts, extra[i]+`="`+escapePrometheusLabelValue(extra[i+1])+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func escapePrometheusLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func formatPrometheusValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+func writePrometheusFamilies(w io.Writer, families []*prometheusFamily, openMetrics bool) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		// In OpenMetrics, the _total suffix is only part of the sample names.
+		typeName := f.name
+		if f.typ == "counter" && !openMetrics {
+			typeName += "_total"
+		}
+		if _, err := fmt.Fprintf(bw, "# TYPE %s %s\n", typeName, f.typ); err != nil {
+			return err
+		}
+		for _, s := range f.series {
+			if err := writePrometheusSeries(bw, f.name, s); err != nil {
+				return err
+			}
+		}
+	}
+	if openMetrics {
+		if _, err := bw.WriteString("# EOF\n"); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func writePrometheusSeries(w io.Writer, name string, s prometheusSeries) error {
+	for _, sample := range s.samples {
+		_, err := fmt.Fprintf(w, "%s%s%s %s\n",
+			name, sample.suffix, formatPrometheusLabels(s.labels, sample.extra...), formatPrometheusValue(sample.value))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newPrometheusSamples returns the current values of the sink, so it has to be
+// called while the sink isn't modified.
+func newPrometheusSamples(name string, sink metrics.Sink) []prometheusSample {
+	// The k6 time metrics are in milliseconds, but Prometheus uses seconds.
+	scale := 1.0
+	if strings.HasSuffix(name, "_seconds") || strings.HasSuffix(name, "_seconds_rate") {
+		scale = 1.0 / 1000
+	}
+
+	switch sink := sink.(type) {
+	case *metrics.CounterSink:
+		return []prometheusSample{{suffix: "_total", value: sink.Value * scale}}
+	case *metrics.GaugeSink:
+		return []prometheusSample{{value: sink.Value * scale}}
+	case *metrics.RateSink:
+		var rate float64
+		if sink.Total > 0 {
+			rate = float64(sink.Trues) / float64(sink.Total)
+		}
+		return []prometheusSample{{value: rate}}
+	case *metrics.TrendSink:
+		var samples []prometheusSample
+		if !sink.IsEmpty() {
+			for _, q := range prometheusQuantiles {
+				samples = append(samples, prometheusSample{
+					extra: []string{"quantile", formatPrometheusValue(q)},
+					value: sink.P(q) * scale,
+				})
+			}
+		}
+		return append(samples,
+			prometheusSample{suffix: "_sum", value: sink.Total() * scale},
+			prometheusSample{suffix: "_count", value: float64(sink.Count())},
+		)
+	default:
+		return nil
+	}
+}
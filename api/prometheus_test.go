@@ -0,0 +1,86 @@
+package api
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/metrics"
+)
+
+func TestWritePrometheusMetrics(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	reqs := registry.MustNewMetric("http_reqs", metrics.Counter)
+	vus := registry.MustNewMetric("vus", metrics.Gauge)
+	checks := registry.MustNewMetric("checks", metrics.Rate)
+	duration := registry.MustNewMetric("http_req_duration", metrics.Trend, metrics.Time)
+	sub, err := duration.AddSubmetric(`expected_response:true,name:a"b`)
+	require.NoError(t, err)
+
+	add := func(m *metrics.Metric, values ...float64) {
+		for _, v := range values {
+			m.Sink.Add(metrics.Sample{Value: v})
+		}
+	}
+	add(reqs, 1, 1, 1)
+	add(vus, 5)
+	add(checks, 1, 0, 1, 1)
+	add(duration, 100, 200, 300)
+	add(sub.Metric, 100)
+
+	observed := map[string]*metrics.Metric{
+		reqs.Name: reqs, vus.Name: vus, checks.Name: checks, duration.Name: duration, sub.Metric.Name: sub.Metric,
+	}
+	families := newPrometheusFamilies(observed)
+	// the families keep the values the sinks had when they were built
+	add(reqs, 1)
+	add(duration, 1000)
+
+	t.Run("prometheus", func(t *testing.T) {
+		t.Parallel()
+		buf := &bytes.Buffer{}
+		require.NoError(t, writePrometheusFamilies(buf, families, false))
+		assert.Equal(t, `# TYPE k6_checks_rate gauge
+k6_checks_rate 0.75
+# TYPE k6_http_req_duration_seconds summary
+k6_http_req_duration_seconds{quantile="0"} 0.1
+k6_http_req_duration_seconds{quantile="0.5"} 0.2
+k6_http_req_duration_seconds{quantile="0.9"} 0.28
+k6_http_req_duration_seconds{quantile="0.95"} 0.29
+k6_http_req_duration_seconds{quantile="0.99"} 0.298
+k6_http_req_duration_seconds{quantile="1"} 0.3
+k6_http_req_duration_seconds_sum 0.6
+k6_http_req_duration_seconds_count 3
+k6_http_req_duration_seconds{expected_response="true",name="a\"b",quantile="0"} 0.1
+k6_http_req_duration_seconds{expected_response="true",name="a\"b",quantile="0.5"} 0.1
+k6_http_req_duration_seconds{expected_response="true",name="a\"b",quantile="0.9"} 0.1
+k6_http_req_duration_seconds{expected_response="true",name="a\"b",quantile="0.95"} 0.1
+k6_http_req_duration_seconds{expected_response="true",name="a\"b",quantile="0.99"} 0.1
+k6_http_req_duration_seconds{expected_response="true",name="a\"b",quantile="1"} 0.1
+k6_http_req_duration_seconds_sum{expected_response="true",name="a\"b"} 0.1
+k6_http_req_duration_seconds_count{expected_response="true",name="a\"b"} 1
+# TYPE k6_http_reqs_total counter
+k6_http_reqs_total 3
+# TYPE k6_vus gauge
+k6_vus 5
+`, buf.String())
+	})
+
+	t.Run("openmetrics", func(t *testing.T) {
+		t.Parallel()
+		buf := &bytes.Buffer{}
+		require.NoError(t, writePrometheusFamilies(buf, families, true))
+		assert.Contains(t, buf.String(), "# TYPE k6_http_reqs counter\nk6_http_reqs_total 3\n")
+		assert.True(t, bytes.HasSuffix(buf.Bytes(), []byte("k6_vus 5\n# EOF\n")))
+	})
+}
+
+func TestSanitizePrometheusName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "my_custom_metric", sanitizePrometheusName("my.custom-metric"))
+	assert.Equal(t, "_xk6", sanitizePrometheusName("1xk6"))
+}
@@ -23,6 +23,7 @@ func newHandler(cs *v1.ControlSurface, profilingEnabled bool) http.Handler {
 	mux := http.NewServeMux()
 	mux.Handle("/v1/", v1.NewHandler(cs))
 	mux.Handle("/ping", handlePing(cs.RunState.Logger))
+	mux.Handle("/metrics", handlePrometheusMetrics(cs))
 	mux.Handle("/", handlePing(cs.RunState.Logger))
 
 	injectProfilerHandler(mux, profilingEnabled)
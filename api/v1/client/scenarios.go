@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	v1 "go.k6.io/k6/api/v1"
+)
+
+// Scenarios returns the current state of all the scenarios with work.
+func (c *Client) Scenarios(ctx context.Context) (ret []v1.Scenario, err error) {
+	var resp v1.ScenariosJSONAPI
+
+	if err = c.CallAPI(ctx, http.MethodGet, &url.URL{Path: "/v1/scenarios"}, nil, &resp); err != nil {
+		return ret, err
+	}
+
+	return resp.Scenarios(), nil
+}
+
+// Scenario returns the current state of the scenario with the given name.
+func (c *Client) Scenario(ctx context.Context, name string) (ret v1.Scenario, err error) {
+	var resp v1.ScenarioJSONAPI
+
+	apiURL := scenarioURL(name)
+	if err = c.CallAPI(ctx, http.MethodGet, apiURL, nil, &resp); err != nil {
+		return ret, err
+	}
+
+	return resp.Scenario(), nil
+}
+
+// SetScenario tries to change the rate or the paused state of the scenario
+// with the given name and returns its new state if it was successful.
+func (c *Client) SetScenario(ctx context.Context, name string, patch v1.Scenario) (ret v1.Scenario, err error) {
+	var resp v1.ScenarioJSONAPI
+
+	patch.Name = name
+	apiURL := scenarioURL(name)
+	if err = c.CallAPI(ctx, http.MethodPatch, apiURL, v1.NewScenarioJSONAPI(patch), &resp); err != nil {
+		return ret, err
+	}
+
+	return resp.Scenario(), nil
+}
+
+// scenarioURL returns the API URL of the scenario, with the name escaped only
+// once, since it can contain any characters.
+func scenarioURL(name string) *url.URL {
+	path := "/v1/scenarios/"
+	return &url.URL{Path: path + name, RawPath: path + url.PathEscape(name)}
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "go.k6.io/k6/api/v1"
+)
+
+func TestScenarioNameEscaping(t *testing.T) {
+	t.Parallel()
+
+	const name = "my scen/100%"
+	var paths, rawPaths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		rawPaths = append(rawPaths, r.URL.EscapedPath())
+		rw.Header().Set("Content-Type", "application/vnd.api+json")
+		_ = json.NewEncoder(rw).Encode(v1.NewScenarioJSONAPI(v1.Scenario{Name: name}))
+	}))
+	defer srv.Close()
+
+	c, err := New(strings.TrimPrefix(srv.URL, "http://"))
+	require.NoError(t, err)
+
+	scenario, err := c.Scenario(context.Background(), name)
+	require.NoError(t, err)
+	assert.Equal(t, name, scenario.Name)
+	scenario, err = c.SetScenario(context.Background(), name, v1.Scenario{})
+	require.NoError(t, err)
+	assert.Equal(t, name, scenario.Name)
+
+	assert.Equal(t, []string{"/v1/scenarios/my scen/100%", "/v1/scenarios/my scen/100%"}, paths)
+	assert.Equal(t, []string{"/v1/scenarios/my%20scen%2F100%25", "/v1/scenarios/my%20scen%2F100%25"}, rawPaths)
+}
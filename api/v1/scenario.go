@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/executor"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/ui/pb"
+)
+
+// Scenario represents the current state of a scenario of the test run. Only
+// the paused and rate fields can be changed, and only for the executors which
+// support it.
+type Scenario struct {
+	Name     string `json:"-" yaml:"name"`
+	Executor string `json:"executor" yaml:"executor"`
+
+	Status          string   `json:"status" yaml:"status"`
+	Progress        float64  `json:"progress" yaml:"progress"`
+	ProgressDetails []string `json:"progress-details" yaml:"progress-details"`
+
+	Paused   null.Bool          `json:"paused" yaml:"paused"`
+	Rate     null.Int           `json:"rate" yaml:"rate"`
+	TimeUnit types.NullDuration `json:"time-unit" yaml:"time-unit"`
+}
+
+// pausedChecker is implemented by the executors which can be paused on their
+// own, without pausing the whole test run.
+type pausedChecker interface {
+	IsPaused() bool
+}
+
+func newScenario(exec lib.Executor) Scenario {
+	config := exec.GetConfig()
+	progress, details := exec.GetProgress().Progress()
+	scenario := Scenario{
+		Name:            config.GetName(),
+		Executor:        config.GetType(),
+		Status:          getScenarioStatus(exec.GetProgress().Status()),
+		Progress:        progress,
+		ProgressDetails: details,
+	}
+
+	if pc, ok := exec.(pausedChecker); ok {
+		scenario.Paused = null.BoolFrom(pc.IsPaused())
+	}
+	if ecar, ok := exec.(*executor.ExternallyControlledArrivalRate); ok {
+		currentConfig := ecar.GetCurrentConfig()
+		scenario.Rate = currentConfig.Rate
+		scenario.TimeUnit = types.NewNullDuration(currentConfig.TimeUnit.TimeDuration(), true)
+	}
+	return scenario
+}
+
+func getScenarioStatus(status pb.Status) string {
+	switch status {
+	case pb.Running:
+		return "running"
+	case pb.Stopping:
+		return "stopping"
+	case pb.Interrupted:
+		return "interrupted"
+	case pb.Done:
+		return "done"
+	default:
+		return "waiting"
+	}
+}
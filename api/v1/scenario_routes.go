@@ -0,0 +1,121 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/executor"
+)
+
+func handleGetScenarios(cs *ControlSurface, rw http.ResponseWriter, _ *http.Request) {
+	executors := cs.Scheduler.GetExecutors()
+	scenarios := make([]Scenario, 0, len(executors))
+	for _, exec := range executors {
+		scenarios = append(scenarios, newScenario(exec))
+	}
+
+	data, err := json.Marshal(newScenariosJSONAPI(scenarios))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, _ = rw.Write(data)
+}
+
+func getScenarioExecutor(cs *ControlSurface, name string) (lib.Executor, bool) {
+	for _, exec := range cs.Scheduler.GetExecutors() {
+		if exec.GetConfig().GetName() == name {
+			return exec, true
+		}
+	}
+	return nil, false
+}
+
+func handleGetScenario(cs *ControlSurface, rw http.ResponseWriter, _ *http.Request, name string) {
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	exec, ok := getScenarioExecutor(cs, name)
+	if !ok {
+		apiError(rw, "Not Found", "No scenario with that name was found", http.StatusNotFound)
+		return
+	}
+
+	data, err := json.Marshal(NewScenarioJSONAPI(newScenario(exec)))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = rw.Write(data)
+}
+
+func handlePatchScenario(cs *ControlSurface, rw http.ResponseWriter, r *http.Request, name string) {
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	exec, ok := getScenarioExecutor(cs, name)
+	if !ok {
+		apiError(rw, "Not Found", "No scenario with that name was found", http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		apiError(rw, "Couldn't read request", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var scenarioEnvelop ScenarioJSONAPI
+	if err = json.Unmarshal(body, &scenarioEnvelop); err != nil {
+		apiError(rw, "Invalid data", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	scenario := scenarioEnvelop.Scenario()
+	config := exec.GetConfig()
+
+	if scenario.Rate.Valid {
+		ecar, isECAR := exec.(*executor.ExternallyControlledArrivalRate)
+		if !isECAR {
+			apiError(rw, "Config update error", fmt.Sprintf(
+				"the rate of the %s executor of scenario %s can't be changed", config.GetType(), name,
+			), http.StatusBadRequest)
+			return
+		}
+		newConfig := executor.ExternallyControlledArrivalRateConfigParams{Rate: scenario.Rate}
+		if err = ecar.UpdateConfig(r.Context(), newConfig); err != nil {
+			apiError(rw, "Config update error", err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if scenario.Paused.Valid {
+		// Only the executors which track their own paused state can be paused
+		// on their own, the rest are only paused with the whole test run.
+		pausable, isPausable := exec.(lib.PausableExecutor)
+		if _, isChecker := exec.(pausedChecker); !isPausable || !isChecker {
+			apiError(rw, "Pause error", fmt.Sprintf(
+				"the %s executor of scenario %s can't be paused on its own", config.GetType(), name,
+			), http.StatusBadRequest)
+			return
+		}
+		if !scenario.Paused.Bool && cs.Scheduler.GetState().IsPaused() {
+			apiError(rw, "Pause error",
+				"the test run is paused, it has to be resumed before its scenarios", http.StatusBadRequest)
+			return
+		}
+		if err = pausable.SetPaused(scenario.Paused.Bool); err != nil {
+			apiError(rw, "Pause error", err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	data, err := json.Marshal(NewScenarioJSONAPI(newScenario(exec)))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = rw.Write(data)
+}
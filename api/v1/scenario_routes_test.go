@@ -0,0 +1,163 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/execution/local"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/minirunner"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+func TestScenarios(t *testing.T) {
+	t.Parallel()
+
+	scenarios := lib.ScenarioConfigs{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"ecar": {"executor": "externally-controlled-arrival-rate", "rate": 10, "duration": "0", "preAllocatedVUs": 2},
+		"looping": {"executor": "constant-vus", "vus": 1, "duration": "1h"}
+	}`), &scenarios))
+	require.Empty(t, scenarios.Validate())
+
+	testState := getTestRunState(t, lib.Options{Scenarios: scenarios}, &minirunner.MiniRunner{
+		Fn: func(ctx context.Context, _ *lib.State, _ chan<- metrics.SampleContainer) error {
+			select {
+			case <-ctx.Done():
+			case <-time.After(10 * time.Millisecond):
+			}
+			return nil
+		},
+	})
+	execScheduler, err := execution.NewScheduler(testState, local.NewController())
+	require.NoError(t, err)
+
+	globalCtx, globalCancel := context.WithCancel(context.Background())
+	defer globalCancel()
+	runCtx, runAbort := execution.NewTestRunContext(globalCtx, testState.Logger)
+	defer runAbort(fmt.Errorf("unexpected abort"))
+
+	samples := make(chan metrics.SampleContainer, 1000)
+	go func() {
+		for range samples { //nolint:revive
+		}
+	}()
+	cs := &ControlSurface{
+		RunCtx:    runCtx,
+		Samples:   samples,
+		Scheduler: execScheduler,
+		RunState:  testState,
+	}
+
+	stopEmission, err := execScheduler.Init(runCtx, samples)
+	require.NoError(t, err)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	defer func() {
+		runAbort(fmt.Errorf("custom cancel signal"))
+		wg.Wait()
+	}()
+	go func() {
+		assert.ErrorContains(t, execScheduler.Run(globalCtx, runCtx, samples), "custom cancel signal")
+		stopEmission()
+		close(samples)
+		wg.Done()
+	}()
+	// wait for the executors to start
+	time.Sleep(200 * time.Millisecond)
+
+	request := func(method, path, payload string) (int, []byte) {
+		rw := httptest.NewRecorder()
+		NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(method, path, bytes.NewBufferString(payload)))
+		res := rw.Result()
+		require.NoError(t, res.Body.Close())
+		return res.StatusCode, rw.Body.Bytes()
+	}
+	patch := func(name, attributes string) (int, Scenario) {
+		code, body := request(http.MethodPatch, "/v1/scenarios/"+name,
+			`{"data":{"type":"scenarios","id":"`+name+`","attributes":`+attributes+`}}`)
+		var doc ScenarioJSONAPI
+		if code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(body, &doc))
+		}
+		return code, doc.Scenario()
+	}
+
+	t.Run("list", func(t *testing.T) {
+		code, body := request(http.MethodGet, "/v1/scenarios", "")
+		require.Equal(t, http.StatusOK, code)
+
+		var doc ScenariosJSONAPI
+		require.NoError(t, json.Unmarshal(body, &doc))
+		list := doc.Scenarios()
+		require.Len(t, list, 2)
+
+		assert.Equal(t, "ecar", list[0].Name)
+		assert.Equal(t, "externally-controlled-arrival-rate", list[0].Executor)
+		assert.Equal(t, "running", list[0].Status)
+		assert.Equal(t, null.BoolFrom(false), list[0].Paused)
+		assert.Equal(t, null.IntFrom(10), list[0].Rate)
+		assert.Equal(t, types.NullDurationFrom(time.Second), list[0].TimeUnit)
+		assert.NotEmpty(t, list[0].ProgressDetails)
+
+		assert.Equal(t, "looping", list[1].Name)
+		assert.Equal(t, "constant-vus", list[1].Executor)
+		assert.Equal(t, "running", list[1].Status)
+		assert.Greater(t, list[1].Progress, 0.0)
+		assert.False(t, list[1].Paused.Valid)
+		assert.False(t, list[1].Rate.Valid)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		code, body := request(http.MethodGet, "/v1/scenarios/ecar", "")
+		require.Equal(t, http.StatusOK, code)
+		var doc ScenarioJSONAPI
+		require.NoError(t, json.Unmarshal(body, &doc))
+		assert.Equal(t, "scenarios", doc.Data.Type)
+		assert.Equal(t, "ecar", doc.Scenario().Name)
+
+		code, _ = request(http.MethodGet, "/v1/scenarios/unknown", "")
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("patch", func(t *testing.T) {
+		code, scenario := patch("ecar", `{"rate":50}`)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, null.IntFrom(50), scenario.Rate)
+		assert.Equal(t, null.BoolFrom(false), scenario.Paused)
+
+		code, scenario = patch("ecar", `{"paused":true}`)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, null.BoolFrom(true), scenario.Paused)
+		assert.Equal(t, null.IntFrom(50), scenario.Rate)
+		// the rest of the test keeps running
+		assert.False(t, execScheduler.GetState().IsPaused())
+
+		code, scenario = patch("ecar", `{"paused":false}`)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, null.BoolFrom(false), scenario.Paused)
+
+		code, _ = patch("ecar", `{"rate":-1}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = patch("looping", `{"rate":10}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = patch("looping", `{"paused":true}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = patch("unknown", `{"rate":10}`)
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+}
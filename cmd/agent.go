@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"encoding/json"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/execution/distributed"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/output"
+)
+
+const agentArchivePath = "/archive.tar"
+
+// getCmdAgent returns the `k6 agent` sub-command, which connects to a `k6
+// coordinator` and runs the part of the test that the coordinator assigns to
+// it. It is basically a `k6 run` with a different way of loading the test.
+func getCmdAgent(gs *state.GlobalState) *cobra.Command {
+	c := &cmdRun{gs: gs}
+	var controller *distributed.AgentController
+
+	c.loadConfiguredTest = func(cmd *cobra.Command, args []string) (*loadedAndConfiguredTest, execution.Controller, error) {
+		conn, err := distributed.Dial(args[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		client := distributed.NewDistributedTestClient(conn)
+
+		gs.Logger.Infof("Connecting to the coordinator at %s...", args[0])
+		resp, err := client.Register(gs.Ctx, &distributed.RegisterRequest{})
+		if err != nil {
+			return nil, nil, err
+		}
+		gs.Logger.Infof("Registered as instance %d", resp.InstanceID)
+
+		controller, err = distributed.NewAgentController(gs.Ctx, resp.InstanceID, client, gs.Logger)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var agentOptions lib.Options
+		if err = json.Unmarshal(resp.Options, &agentOptions); err != nil {
+			return nil, nil, err
+		}
+
+		// The test archive is loaded from an in-memory filesystem, as if the
+		// user had executed `k6 run archive.tar`.
+		pseudoFS := afero.NewMemMapFs()
+		if err = fsext.WriteFile(pseudoFS, agentArchivePath, resp.Archive, 0o644); err != nil {
+			return nil, nil, err
+		}
+		agentGS := *gs
+		agentGS.FS = pseudoFS
+		agentGS.Getwd = func() (string, error) { return "/", nil }
+
+		getAgentConfig := func(*pflag.FlagSet) (Config, error) {
+			return Config{Options: agentOptions}, nil
+		}
+		test, err := loadAndConfigureLocalTest(&agentGS, cmd, []string{agentArchivePath}, getAgentConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// The thresholds and the end-of-test summary are calculated by the
+		// coordinator, from the metrics of all instances.
+		test.preInitState.RuntimeOptions.NoThresholds = null.BoolFrom(true)
+		test.preInitState.RuntimeOptions.NoSummary = null.BoolFrom(true)
+		c.extraOutputs = []output.Output{distributed.NewMetricsOutput(gs.Ctx, resp.InstanceID, client, gs.Logger)}
+
+		return test, controller, nil
+	}
+
+	agentCmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Join a distributed test run as an agent",
+		Long: `Join a distributed test run as an agent.
+
+The agent connects to a k6 coordinator, receives the test and its execution
+segment from it and runs its part of the test. All metrics are sent to the
+coordinator, which evaluates the thresholds and generates the end-of-test
+summary for the whole test run.`,
+		Example: getExampleText(gs, `
+  # Connect to a coordinator that is listening on the default address.
+  {{.}} agent localhost:6566`[1:]),
+		Args: exactArgsWithMsg(1, "arg should be the address of the coordinator"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Multiple agents can be started on the same machine, so the REST
+			// API is disabled to avoid port conflicts.
+			gs.Flags.Address = ""
+			err := c.run(cmd, args)
+			if controller != nil {
+				if cErr := controller.Close(); cErr != nil {
+					gs.Logger.WithError(cErr).Debug("Error while closing the connection to the coordinator")
+				}
+			}
+			return err
+		},
+	}
+
+	agentCmd.Flags().SortFlags = false
+	agentCmd.Flags().AddFlagSet(runtimeOptionFlagSet(false))
+
+	return agentCmd
+}
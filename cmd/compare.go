@@ -0,0 +1,347 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/metrics"
+)
+
+// cmdCompare handles the `k6 compare` sub-command
+type cmdCompare struct {
+	gs         *state.GlobalState
+	thresholds []string
+}
+
+func (c *cmdCompare) run(_ *cobra.Command, args []string) error {
+	baseline, err := readBaseline(c.gs, args[0])
+	if err != nil {
+		return err
+	}
+	current, err := readBaseline(c.gs, args[1])
+	if err != nil {
+		return err
+	}
+
+	thresholds := make(map[string]metrics.Thresholds)
+	for _, t := range c.thresholds {
+		metricName, source, err := parseCompareThreshold(t)
+		if err != nil {
+			return errext.WithExitCodeIfNone(err, exitcodes.InvalidConfig)
+		}
+		ths := thresholds[metricName]
+		ths.Thresholds = append(ths.Thresholds, metrics.NewThresholds([]string{source}).Thresholds...)
+		thresholds[metricName] = ths
+	}
+
+	var rows []baselineDiffRow
+	var breached []string
+	for _, name := range baseline.MetricNames() {
+		currentMetric, ok := current.Metrics[name]
+		if !ok {
+			continue
+		}
+		metricRows := diffBaselineMetric(name, baseline.Metrics[name], currentMetric)
+
+		ths, ok := thresholds[name]
+		if ok {
+			delete(thresholds, name)
+			passed, err := runCompareThresholds(ths, baseline.Metrics[name].Values, currentMetric.Values)
+			if err != nil {
+				return errext.WithExitCodeIfNone(
+					fmt.Errorf("invalid thresholds on metric '%s': %w", name, err), exitcodes.InvalidConfig)
+			}
+			if !passed {
+				breached = append(breached, name)
+			}
+			markThresholdRows(metricRows, ths.Thresholds)
+		}
+		rows = append(rows, metricRows...)
+	}
+	if len(thresholds) > 0 {
+		missing := make([]string, 0, len(thresholds))
+		for name := range thresholds {
+			missing = append(missing, name)
+		}
+		sort.Strings(missing)
+		return errext.WithExitCodeIfNone(
+			fmt.Errorf("metrics '%s' are not present in both the baseline and the current test run",
+				strings.Join(missing, ", ")),
+			exitcodes.InvalidConfig,
+		)
+	}
+
+	if err = writeBaselineDiff(c.gs.Stdout, rows); err != nil {
+		return err
+	}
+
+	if len(breached) > 0 {
+		return errext.WithExitCodeIfNone(
+			fmt.Errorf("thresholds on metrics '%s' have been crossed", strings.Join(breached, ", ")),
+			exitcodes.ThresholdsHaveFailed,
+		)
+	}
+	return nil
+}
+
+// parseCompareThreshold splits a threshold of the form `metric: expression`,
+// where the metric can be a sub-metric like `http_req_duration{status:200}`.
+func parseCompareThreshold(s string) (string, string, error) {
+	offset := 0
+	if i := strings.Index(s, "}"); i >= 0 {
+		offset = i
+	}
+	i := strings.Index(s[offset:], ":")
+	if i < 0 {
+		return "", "", fmt.Errorf("invalid threshold '%s', it should have the form 'metric: expression'", s)
+	}
+	metricName, source := strings.TrimSpace(s[:offset+i]), strings.TrimSpace(s[offset+i+1:])
+	if metricName == "" || source == "" {
+		return "", "", fmt.Errorf("invalid threshold '%s', it should have the form 'metric: expression'", s)
+	}
+	return metricName, source, nil
+}
+
+func runCompareThresholds(ths metrics.Thresholds, baselineValues, currentValues map[string]float64) (bool, error) {
+	if err := ths.Parse(); err != nil {
+		return false, err
+	}
+	for _, t := range ths.Thresholds {
+		if _, ok := currentValues[t.AggregationKey()]; !ok {
+			return false, fmt.Errorf("the current test run doesn't contain the %s value for threshold %q",
+				t.AggregationKey(), t.Source)
+		}
+	}
+	if err := ths.SetBaseline(baselineValues); err != nil {
+		return false, err
+	}
+	return ths.RunOnValues(currentValues)
+}
+
+// readBaseline reads a baseline file that was saved with --baseline-export.
+func readBaseline(gs *state.GlobalState, path string) (*metrics.Baseline, error) {
+	data, err := fsext.ReadFile(gs.FS, path)
+	if err != nil {
+		return nil, errext.WithExitCodeIfNone(
+			fmt.Errorf("couldn't read the baseline file: %w", err), exitcodes.InvalidConfig)
+	}
+	baseline, err := metrics.ParseBaseline(data)
+	if err != nil {
+		return nil, errext.WithExitCodeIfNone(fmt.Errorf("%s: %w", path, err), exitcodes.InvalidConfig)
+	}
+	return baseline, nil
+}
+
+// exportBaseline saves the aggregated values of the observed metrics to the
+// baseline file with the given path.
+func exportBaseline(
+	gs *state.GlobalState, path string, observed map[string]*metrics.Metric, duration time.Duration, options lib.Options,
+) error {
+	baseline, err := metrics.NewBaseline(observed, duration, options.SummaryTrendStats)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsext.WriteFile(gs.FS, path, append(data, '\n'), 0o644)
+}
+
+// printBaselineThresholdsDiff prints the diff table for the values that the
+// thresholds which refer to a baseline are compared against.
+func printBaselineThresholdsDiff(
+	w io.Writer, baseline *metrics.Baseline, observed map[string]*metrics.Metric, duration time.Duration,
+) error {
+	current, err := metrics.NewBaseline(observed, duration, nil)
+	if err != nil {
+		return err
+	}
+
+	var rows []baselineDiffRow
+	for _, name := range current.MetricNames() {
+		ths := observed[name].Thresholds
+		if !ths.UsesBaseline() {
+			continue
+		}
+		metricRows := diffBaselineMetric(name, baseline.Metrics[name], current.Metrics[name])
+		markThresholdRows(metricRows, ths.Thresholds)
+
+		for _, row := range metricRows {
+			if row.threshold != "" {
+				rows = append(rows, row)
+			}
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if _, err = fmt.Fprintf(w, "\n     comparison with the baseline:\n\n"); err != nil {
+		return err
+	}
+	return writeBaselineDiff(w, rows)
+}
+
+// baselineDiffRow is a single row of the table that compares the values of
+// a test run with a baseline.
+type baselineDiffRow struct {
+	metric    string
+	stat      string
+	contains  metrics.ValueType
+	baseline  float64
+	current   float64
+	threshold string
+	failed    bool
+}
+
+func diffBaselineMetric(name string, baseline, current metrics.BaselineMetric) []baselineDiffRow {
+	stats := make([]string, 0, len(baseline.Values))
+	for stat := range baseline.Values {
+		if _, ok := current.Values[stat]; ok {
+			stats = append(stats, stat)
+		}
+	}
+	sort.Strings(stats)
+
+	rows := make([]baselineDiffRow, len(stats))
+	for i, stat := range stats {
+		contains := current.Contains
+		if (current.Type == metrics.Trend && stat == "count") || current.Type == metrics.Rate {
+			contains = metrics.Default
+		}
+		rows[i] = baselineDiffRow{
+			metric:   name,
+			stat:     stat,
+			contains: contains,
+			baseline: baseline.Values[stat],
+			current:  current.Values[stat],
+		}
+	}
+	return rows
+}
+
+// markThresholdRows adds the thresholds, and whether they failed, to the rows
+// of the values they are evaluated against.
+func markThresholdRows(rows []baselineDiffRow, thresholds []*metrics.Threshold) {
+	for i := range rows {
+		for _, t := range thresholds {
+			if t.AggregationKey() != rows[i].stat {
+				continue
+			}
+			if rows[i].threshold != "" {
+				rows[i].threshold += ", "
+			}
+			rows[i].threshold += t.Source
+			rows[i].failed = rows[i].failed || t.LastFailed
+		}
+	}
+}
+
+func writeBaselineDiff(w io.Writer, rows []baselineDiffRow) error {
+	buf := &bytes.Buffer{}
+	tw := tabwriter.NewWriter(buf, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "     METRIC\tSTAT\tBASELINE\tCURRENT\tCHANGE\tTHRESHOLD")
+	for _, row := range rows {
+		mark := " "
+		if row.threshold != "" {
+			mark = "✓"
+			if row.failed {
+				mark = "✗"
+			}
+		}
+		fmt.Fprintf(tw, "   %s %s\t%s\t%s\t%s\t%s\t%s\n",
+			mark, row.metric, row.stat,
+			formatBaselineValue(row.baseline, row.contains), formatBaselineValue(row.current, row.contains),
+			formatBaselineChange(row.baseline, row.current), row.threshold,
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func formatBaselineValue(v float64, contains metrics.ValueType) string {
+	switch contains {
+	case metrics.Time:
+		return strconv.FormatFloat(v, 'f', 2, 64) + "ms"
+	case metrics.Data:
+		return strconv.FormatFloat(v, 'f', 0, 64) + " B"
+	default:
+		if v == math.Trunc(v) {
+			return strconv.FormatFloat(v, 'f', 0, 64)
+		}
+		return strconv.FormatFloat(v, 'f', 4, 64)
+	}
+}
+
+func formatBaselineChange(baseline, current float64) string {
+	if baseline == 0 {
+		if current == 0 {
+			return "+0.00%"
+		}
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.2f%%", (current-baseline)/math.Abs(baseline)*100)
+}
+
+func getCmdCompare(gs *state.GlobalState) *cobra.Command {
+	c := &cmdCompare{gs: gs}
+
+	exampleText := getExampleText(gs, `
+  # Save the results of a test run as a baseline.
+  {{.}} run --baseline-export baseline.json script.js
+
+  # Fail a later test run if its p(95) latency regressed by more than 10%.
+  {{.}} run --baseline baseline.json script.js # with a 'p(95)<baseline*1.1' threshold
+
+  # Compare two saved test runs.
+  {{.}} run --baseline-export current.json script.js
+  {{.}} compare --threshold 'http_req_duration: p(95)<baseline*1.1' baseline.json current.json`[1:])
+
+	compareCmd := &cobra.Command{
+		Use:   "compare",
+		Short: "Compare the results of two test runs",
+		Long: `Compare the results of two test runs.
+
+The results are baseline files, saved with the --baseline-export option of
+k6 run. All values present in both files are printed as a diff table. The
+thresholds given with --threshold can refer to the value of the baseline,
+e.g. 'p(95)<baseline*1.1', and if any of them fails, k6 exits with a non-zero
+exit code.`,
+		Example: exampleText,
+		Args:    exactArgsWithMsg(2, "args should be the paths of the baseline and of the current results"),
+		RunE:    c.run,
+	}
+
+	compareCmd.Flags().SortFlags = false
+	compareCmd.Flags().AddFlagSet(c.flagSet())
+
+	return compareCmd
+}
+
+func (c *cmdCompare) flagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.StringArrayVar(&c.thresholds, "threshold", nil,
+		"add a threshold of the form `metric: expression`, e.g. 'http_req_duration: p(95)<baseline*1.1'")
+	return flags
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/cmd/tests"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/lib/fsext"
+)
+
+const (
+	compareBaseline = `{"testRunDuration":"10s","metrics":{
+		"http_req_duration":{"type":"trend","contains":"time","values":{"avg":100,"p(95)":200}},
+		"http_req_duration{status:200}":{"type":"trend","contains":"time","values":{"avg":90,"p(95)":180}},
+		"checks":{"type":"rate","contains":"default","values":{"rate":0.99}}
+	}}`
+	compareCurrent = `{"testRunDuration":"10s","metrics":{
+		"http_req_duration":{"type":"trend","contains":"time","values":{"avg":105,"p(95)":250}},
+		"http_req_duration{status:200}":{"type":"trend","contains":"time","values":{"avg":95,"p(95)":190}},
+		"checks":{"type":"rate","contains":"default","values":{"rate":0.98}}
+	}}`
+)
+
+func TestCompareCmd(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		thresholds   []string
+		exitCode     exitcodes.ExitCode
+		expectedRows []string
+		stderr       string
+	}{
+		{
+			name: "no thresholds",
+			expectedRows: []string{
+				"     METRIC                          STAT    BASELINE   CURRENT    CHANGE    THRESHOLD",
+				"     checks                          rate    0.9900     0.9800     -1.01%",
+				"     http_req_duration               avg     100.00ms   105.00ms   +5.00%",
+				"     http_req_duration               p(95)   200.00ms   250.00ms   +25.00%",
+			},
+		},
+		{
+			name:       "passing thresholds",
+			thresholds: []string{"http_req_duration: avg<baseline*1.1", "http_req_duration{status:200}: p(95)<baseline*1.1"},
+			expectedRows: []string{
+				"   ✓ http_req_duration               avg     100.00ms   105.00ms   +5.00%    avg<baseline*1.1",
+				"   ✓ http_req_duration{status:200}   p(95)   180.00ms   190.00ms   +5.56%    p(95)<baseline*1.1",
+			},
+		},
+		{
+			name:       "regression",
+			thresholds: []string{"http_req_duration: p(95)<baseline*1.1", "checks: rate>=baseline*0.95"},
+			exitCode:   exitcodes.ThresholdsHaveFailed,
+			expectedRows: []string{
+				"   ✓ checks                          rate    0.9900     0.9800     -1.01%    rate>=baseline*0.95",
+				"   ✗ http_req_duration               p(95)   200.00ms   250.00ms   +25.00%   p(95)<baseline*1.1",
+			},
+			stderr: "thresholds on metrics 'http_req_duration' have been crossed",
+		},
+		{
+			name:       "unknown metric",
+			thresholds: []string{"foo: p(95)<baseline"},
+			exitCode:   exitcodes.InvalidConfig,
+			stderr:     "metrics 'foo' are not present in both the baseline and the current test run",
+		},
+		{
+			name:       "missing value",
+			thresholds: []string{"http_req_duration: p(99)<baseline"},
+			exitCode:   exitcodes.InvalidConfig,
+			stderr:     "the current test run doesn't contain the p(99) value",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := tests.NewGlobalTestState(t)
+			require.NoError(t, fsext.WriteFile(ts.FS, "base.json", []byte(compareBaseline), 0o644))
+			require.NoError(t, fsext.WriteFile(ts.FS, "cur.json", []byte(compareCurrent), 0o644))
+			ts.CmdArgs = []string{"k6", "compare"}
+			for _, th := range tc.thresholds {
+				ts.CmdArgs = append(ts.CmdArgs, "--threshold", th)
+			}
+			ts.CmdArgs = append(ts.CmdArgs, "base.json", "cur.json")
+			ts.ExpectedExitCode = int(tc.exitCode)
+
+			newRootCommand(ts.GlobalState).execute()
+
+			stdout := ts.Stdout.String()
+			for _, row := range tc.expectedRows {
+				assert.Contains(t, stdout, row)
+			}
+			if tc.stderr != "" {
+				assert.Contains(t, ts.Stderr.String(), tc.stderr)
+			}
+		})
+	}
+}
+
+func TestParseCompareThreshold(t *testing.T) {
+	t.Parallel()
+
+	name, source, err := parseCompareThreshold("http_req_duration{status:200}: p(95) < baseline*1.1")
+	require.NoError(t, err)
+	assert.Equal(t, "http_req_duration{status:200}", name)
+	assert.Equal(t, "p(95) < baseline*1.1", source)
+
+	name, source, err = parseCompareThreshold("checks:rate>0.9")
+	require.NoError(t, err)
+	assert.Equal(t, "checks", name)
+	assert.Equal(t, "rate>0.9", source)
+
+	_, _, err = parseCompareThreshold("p(95)<baseline")
+	require.Error(t, err)
+	_, _, err = parseCompareThreshold("checks:")
+	require.Error(t, err)
+}
@@ -217,6 +217,9 @@ func applyDefault(conf Config) Config {
 	if !conf.DNS.Policy.Valid {
 		conf.DNS.Policy = defDNS.Policy
 	}
+	if !conf.DNS.Cache.Valid {
+		conf.DNS.Cache = defDNS.Cache
+	}
 	if !conf.SetupTimeout.Valid {
 		conf.SetupTimeout.Duration = types.Duration(60 * time.Second)
 	}
@@ -262,10 +265,21 @@ func consolidateErrorMessage(errList []error, title string) error {
 	return errors.New(strings.Join(errMsgParts, "\n"))
 }
 
+// execsGetter is implemented by the executor configs which run more than one
+// function, e.g. the replay-arrival-rate with a weighted traffic shape.
+type execsGetter interface {
+	GetExecs() []string
+}
+
 func validateScenarioConfig(conf lib.ExecutorConfig, isExecutable func(string) bool) error {
-	execFn := conf.GetExec()
-	if !isExecutable(execFn) {
-		return fmt.Errorf("executor %s: function '%s' not found in exports", conf.GetName(), execFn)
+	execFns := []string{conf.GetExec()}
+	if getter, ok := conf.(execsGetter); ok {
+		execFns = getter.GetExecs()
+	}
+	for _, execFn := range execFns {
+		if !isExecutable(execFn) {
+			return fmt.Errorf("executor %s: function '%s' not found in exports", conf.GetName(), execFn)
+		}
 	}
 	return nil
 }
@@ -396,6 +396,7 @@ func getConfigConsolidationTestCases() []configConsolidationTestCase {
 				TTL:    null.NewString("5m", false),
 				Select: types.NullDNSSelect{DNSSelect: types.DNSrandom, Valid: false},
 				Policy: types.NullDNSPolicy{DNSPolicy: types.DNSpreferIPv4, Valid: false},
+				Cache:  types.NullDNSCache{DNSCache: types.DNSshared, Valid: false},
 			}, c.Options.DNS)
 		}},
 		{opts{env: []string{"K6_DNS=ttl=5,select=roundRobin"}}, exp{}, func(t *testing.T, c Config) {
@@ -403,6 +404,7 @@ func getConfigConsolidationTestCases() []configConsolidationTestCase {
 				TTL:    null.StringFrom("5"),
 				Select: types.NullDNSSelect{DNSSelect: types.DNSroundRobin, Valid: true},
 				Policy: types.NullDNSPolicy{DNSPolicy: types.DNSpreferIPv4, Valid: false},
+				Cache:  types.NullDNSCache{DNSCache: types.DNSshared, Valid: false},
 			}, c.Options.DNS)
 		}},
 		{opts{env: []string{"K6_DNS=ttl=inf,select=random,policy=preferIPv6"}}, exp{}, func(t *testing.T, c Config) {
@@ -410,6 +412,7 @@ func getConfigConsolidationTestCases() []configConsolidationTestCase {
 				TTL:    null.StringFrom("inf"),
 				Select: types.NullDNSSelect{DNSSelect: types.DNSrandom, Valid: true},
 				Policy: types.NullDNSPolicy{DNSPolicy: types.DNSpreferIPv6, Valid: true},
+				Cache:  types.NullDNSCache{DNSCache: types.DNSshared, Valid: false},
 			}, c.Options.DNS)
 		}},
 		// This is functionally invalid, but will error out in validation done in js.parseTTL().
@@ -418,6 +421,7 @@ func getConfigConsolidationTestCases() []configConsolidationTestCase {
 				TTL:    null.StringFrom("-1"),
 				Select: types.NullDNSSelect{DNSSelect: types.DNSrandom, Valid: false},
 				Policy: types.NullDNSPolicy{DNSPolicy: types.DNSpreferIPv4, Valid: false},
+				Cache:  types.NullDNSCache{DNSCache: types.DNSshared, Valid: false},
 			}, c.Options.DNS)
 		}},
 		{opts{cli: []string{"--dns", "ttl=0,blah=nope"}}, exp{cliReadError: true}, nil},
@@ -426,6 +430,7 @@ func getConfigConsolidationTestCases() []configConsolidationTestCase {
 				TTL:    null.StringFrom("0"),
 				Select: types.NullDNSSelect{DNSSelect: types.DNSrandom, Valid: false},
 				Policy: types.NullDNSPolicy{DNSPolicy: types.DNSpreferIPv4, Valid: false},
+				Cache:  types.NullDNSCache{DNSCache: types.DNSshared, Valid: false},
 			}, c.Options.DNS)
 		}},
 		{opts{cli: []string{"--dns", "ttl=5s,select="}}, exp{cliReadError: true}, nil},
@@ -437,6 +442,7 @@ func getConfigConsolidationTestCases() []configConsolidationTestCase {
 					TTL:    null.StringFrom("0"),
 					Select: types.NullDNSSelect{DNSSelect: types.DNSroundRobin, Valid: true},
 					Policy: types.NullDNSPolicy{DNSPolicy: types.DNSonlyIPv4, Valid: true},
+					Cache:  types.NullDNSCache{DNSCache: types.DNSshared, Valid: false},
 				}, c.Options.DNS)
 			},
 		},
@@ -451,6 +457,7 @@ func getConfigConsolidationTestCases() []configConsolidationTestCase {
 					TTL:    null.StringFrom("30"),
 					Select: types.NullDNSSelect{DNSSelect: types.DNSrandom, Valid: false},
 					Policy: types.NullDNSPolicy{DNSPolicy: types.DNSany, Valid: true},
+					Cache:  types.NullDNSCache{DNSCache: types.DNSshared, Valid: false},
 				}, c.Options.DNS)
 			},
 		},
@@ -467,6 +474,7 @@ func getConfigConsolidationTestCases() []configConsolidationTestCase {
 					TTL:    null.StringFrom("5"),
 					Select: types.NullDNSSelect{DNSSelect: types.DNSrandom, Valid: true},
 					Policy: types.NullDNSPolicy{DNSPolicy: types.DNSany, Valid: true},
+					Cache:  types.NullDNSCache{DNSCache: types.DNSshared, Valid: false},
 				}, c.Options.DNS)
 			},
 		},
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/converter"
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
+)
+
+// cmdConvert handles the `k6 convert` sub-command
+type cmdConvert struct {
+	gs     *state.GlobalState
+	from   string
+	output string
+	opts   converter.Options
+}
+
+func (c *cmdConvert) run(_ *cobra.Command, args []string) (err error) {
+	var in io.Reader = c.gs.Stdin
+	if args[0] != "-" {
+		f, err := c.gs.FS.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		in = f
+	}
+
+	session, err := converter.Parse(in, c.from)
+	if err != nil {
+		return errext.WithExitCodeIfNone(err, exitcodes.InvalidConfig)
+	}
+
+	if c.output == "-" {
+		return converter.Generate(c.gs.Stdout, session, c.opts)
+	}
+	f, err := c.gs.FS.Create(c.output)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil && cerr != nil {
+			err = cerr
+		}
+	}()
+	return converter.Generate(f, session, c.opts)
+}
+
+func (c *cmdConvert) flagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.StringVar(&c.from, "from", "",
+		"input format, one of `har`, `postman` or `curl`; it's detected from the input by default")
+	flags.StringVarP(&c.output, "output", "O", c.output,
+		"output filename. Dash (-) is a reserved value that causes the script to be output to stdout.")
+	flags.BoolVar(&c.opts.Correlate, "correlate", false,
+		"extract the values of the JSON responses that are used in the later requests to variables")
+	flags.BoolVar(&c.opts.NoChecks, "no-checks", false, "don't add checks of the recorded response statuses")
+	flags.StringSliceVar(&c.opts.Only, "only", nil, "only convert the requests to these hosts and their subdomains")
+	flags.StringSliceVar(&c.opts.Skip, "skip", nil, "don't convert the requests to these hosts and their subdomains")
+
+	return flags
+}
+
+func getCmdConvert(gs *state.GlobalState) *cobra.Command {
+	c := &cmdConvert{
+		gs:     gs,
+		output: "-",
+	}
+
+	exampleText := getExampleText(gs, `
+  # Convert a HAR file recorded with the browser to a script.
+  {{.}} convert -O script.js session.har
+
+  # Convert a Postman collection, extracting the values used by the later requests.
+  {{.}} convert --correlate -O script.js collection.json
+
+  # Convert the curl commands copied from the browser developer tools.
+  {{.}} convert --from curl - < commands.txt
+
+  # Only convert the requests to example.com and its subdomains.
+  {{.}} convert --only example.com -O script.js session.har`[1:])
+
+	convertCmd := &cobra.Command{
+		Use:   "convert",
+		Short: "Convert a HAR file, a Postman collection or curl commands to a k6 script",
+		Long: `Convert a HAR file, a Postman collection or curl commands to a k6 script.
+
+The requests are converted in their recorded order, grouped by the pages or
+the folders they belong to. The recorded response statuses are checked, and
+the headers that all requests have are shared between them.
+
+Dash (-) as the input reads it from stdin.`,
+		Example: exampleText,
+		Args:    cobra.ExactArgs(1),
+		RunE:    c.run,
+	}
+
+	convertCmd.Flags().SortFlags = false
+	convertCmd.Flags().AddFlagSet(c.flagSet())
+
+	return convertCmd
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/cmd/tests"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/lib/fsext"
+)
+
+func TestConvertCmd(t *testing.T) {
+	t.Parallel()
+
+	const har = `{"log": {"entries": [
+		{
+			"startedDateTime": "2024-01-01T00:00:00Z",
+			"request": {"method": "GET", "url": "https://example.com/"},
+			"response": {"status": 200, "content": {}}
+		},
+		{
+			"startedDateTime": "2024-01-01T00:00:01Z",
+			"request": {"method": "GET", "url": "https://cdn.example.org/app.js"},
+			"response": {"status": 200, "content": {}}
+		}
+	]}}`
+
+	ts := tests.NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, "session.har", []byte(har), 0o644))
+	ts.CmdArgs = []string{"k6", "convert", "--only", "example.com", "-O", "script.js", "session.har"}
+
+	newRootCommand(ts.GlobalState).execute()
+
+	data, err := fsext.ReadFile(ts.FS, "script.js")
+	require.NoError(t, err)
+	script := string(data)
+	assert.Contains(t, script, "import http from 'k6/http';")
+	assert.Contains(t, script, "res = http.get('https://example.com/');")
+	assert.Contains(t, script, "'status is 200': (r) => r.status === 200,")
+	assert.NotContains(t, script, "cdn.example.org")
+}
+
+func TestConvertCmdStdin(t *testing.T) {
+	t.Parallel()
+
+	ts := tests.NewGlobalTestState(t)
+	ts.Stdin = bytes.NewBufferString("curl -X POST https://example.com/ -d a=1")
+	ts.CmdArgs = []string{"k6", "convert", "--no-checks", "-"}
+
+	newRootCommand(ts.GlobalState).execute()
+
+	assert.Contains(t, ts.Stdout.String(), "res = http.post('https://example.com/', 'a=1', {")
+}
+
+func TestConvertCmdInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	ts := tests.NewGlobalTestState(t)
+	ts.Stdin = bytes.NewBufferString(`{"foo": "bar"}`)
+	ts.CmdArgs = []string{"k6", "convert", "-"}
+	ts.ExpectedExitCode = int(exitcodes.InvalidConfig)
+
+	newRootCommand(ts.GlobalState).execute()
+
+	assert.Contains(t, ts.Stderr.String(), "couldn't detect the format of the input")
+}
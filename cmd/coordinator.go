@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/execution/distributed"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/metrics/engine"
+)
+
+// cmdCoordinator handles the `k6 coordinator` sub-command
+type cmdCoordinator struct {
+	gs            *state.GlobalState
+	gRPCAddress   string
+	instanceCount int
+}
+
+//nolint:funlen
+func (c *cmdCoordinator) run(cmd *cobra.Command, args []string) (err error) {
+	logger := c.gs.Logger
+
+	test, err := loadAndConfigureLocalTest(c.gs, cmd, args, getPartialConfig)
+	if err != nil {
+		return err
+	}
+
+	// Similar to `k6 archive`, only the consolidated options are set back to
+	// the runner, the agents will derive their own.
+	testRunState, err := test.buildTestRunState(test.consolidatedConfig.Options)
+	if err != nil {
+		return err
+	}
+
+	archiveBuf := &bytes.Buffer{}
+	if err = testRunState.Runner.MakeArchive().Write(archiveBuf); err != nil {
+		return err
+	}
+
+	metricsEngine, err := engine.NewMetricsEngine(testRunState.Registry, logger)
+	if err != nil {
+		return err
+	}
+	rtOpts := testRunState.RuntimeOptions
+	noThresholds := rtOpts.NoThresholds.Bool
+	var baseline *metrics.Baseline
+	if rtOpts.Baseline.String != "" && !noThresholds {
+		if baseline, err = readBaseline(c.gs, rtOpts.Baseline.String); err != nil {
+			return err
+		}
+		metricsEngine.SetBaseline(baseline)
+	}
+	if err = metricsEngine.InitSubMetricsAndThresholds(test.derivedConfig.Options, noThresholds); err != nil {
+		return err
+	}
+
+	metricsIngester := metricsEngine.CreateIngester()
+	if err = metricsIngester.Start(); err != nil {
+		return err
+	}
+	groupSummary := testRunState.GroupSummary
+	if err = groupSummary.Start(); err != nil {
+		return err
+	}
+	onSamples := func(samples []metrics.SampleContainer) {
+		metricsIngester.AddMetricSamples(samples)
+		groupSummary.AddMetricSamples(samples)
+	}
+
+	coordinator, err := distributed.NewCoordinatorServer(
+		c.instanceCount, archiveBuf.Bytes(), testRunState.Registry, onSamples, logger,
+	)
+	if err != nil {
+		return err
+	}
+
+	var finalizeThresholds func() []string
+	if !noThresholds {
+		finalizeThresholds = metricsEngine.StartThresholdCalculations(
+			metricsIngester, coordinator.Abort, coordinator.GetCurrentTestRunDuration,
+		)
+	}
+
+	listener, err := net.Listen("tcp", c.gRPCAddress)
+	if err != nil {
+		return err
+	}
+	grpcServer := distributed.NewGRPCServer(coordinator)
+	go func() {
+		logger.Debugf("Starting the gRPC server on %s", c.gRPCAddress)
+		if serr := grpcServer.Serve(listener); serr != nil {
+			logger.WithError(serr).Error("gRPC server error")
+		}
+	}()
+
+	logger.Infof("Waiting for %d agents to connect to %s...", c.instanceCount, listener.Addr())
+	err = coordinator.Wait(c.gs.Ctx)
+	grpcServer.GracefulStop()
+	if err != nil {
+		return err
+	}
+	logger.Info("All agents have finished, calculating the results...")
+
+	var breachedThresholds []string
+	if finalizeThresholds != nil {
+		breachedThresholds = finalizeThresholds() // this also stops the ingester
+	} else if err = metricsIngester.Stop(); err != nil {
+		logger.WithError(err).Warn("There was a problem stopping the metrics ingester")
+	}
+	if err = groupSummary.Stop(); err != nil {
+		logger.WithError(err).Warn("There was a problem stopping the group summary")
+	}
+
+	if !rtOpts.NoSummary.Bool {
+		summaryResult, hsErr := test.initRunner.HandleSummary(c.gs.Ctx, &lib.Summary{
+			Metrics:         metricsEngine.ObservedMetrics,
+			RootGroup:       groupSummary.Group(),
+			TestRunDuration: coordinator.GetCurrentTestRunDuration(),
+			NoColor:         c.gs.Flags.NoColor,
+			UIState: lib.UIState{
+				IsStdOutTTY: c.gs.Stdout.IsTTY,
+				IsStdErrTTY: c.gs.Stderr.IsTTY,
+			},
+		})
+		if hsErr == nil {
+			hsErr = handleSummaryResult(c.gs.FS, c.gs.Stdout, c.gs.Stderr, summaryResult)
+		}
+		if hsErr != nil {
+			logger.WithError(hsErr).Error("failed to handle the end-of-test summary")
+		}
+	}
+
+	if baseline != nil || rtOpts.BaselineExport.String != "" {
+		handleBaseline(
+			c.gs, baseline, rtOpts.BaselineExport.String,
+			metricsEngine, coordinator.GetCurrentTestRunDuration(), test.derivedConfig.Options,
+		)
+	}
+
+	if len(breachedThresholds) > 0 {
+		return errext.WithAbortReasonIfNone(
+			errext.WithExitCodeIfNone(
+				fmt.Errorf("thresholds on metrics '%s' have been crossed", strings.Join(breachedThresholds, ", ")),
+				exitcodes.ThresholdsHaveFailed,
+			), errext.AbortedByThresholdsAfterTestEnd)
+	}
+	return nil
+}
+
+func (c *cmdCoordinator) flagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.AddFlagSet(optionFlagSet())
+	flags.AddFlagSet(runtimeOptionFlagSet(false))
+	flags.StringVar(&c.gRPCAddress, "grpc-address", c.gRPCAddress, "address on which to listen for agents")
+	flags.IntVar(&c.instanceCount, "instance-count", c.instanceCount, "number of agents that will run the test")
+	return flags
+}
+
+func getCmdCoordinator(gs *state.GlobalState) *cobra.Command {
+	c := &cmdCoordinator{
+		gs:            gs,
+		gRPCAddress:   "localhost:6566",
+		instanceCount: 1,
+	}
+
+	exampleText := getExampleText(gs, `
+  # Split the test between 3 agents.
+  {{.}} coordinator --instance-count 3 script.js
+
+  # Then, start each agent, on the same or on other machines.
+  {{.}} agent localhost:6566`[1:])
+
+	coordinatorCmd := &cobra.Command{
+		Use:   "coordinator",
+		Short: "Start a distributed test coordinator",
+		Long: `Start a distributed test coordinator.
+
+The coordinator splits the test between the given number of agents, by handing
+out a different execution segment to each of them. It synchronizes their
+execution, aggregates their metrics, evaluates the thresholds and generates the
+end-of-test summary for the whole test run.`,
+		Example: exampleText,
+		Args:    exactArgsWithMsg(1, "arg should either be \"-\", if reading script from stdin, or a path to a script file"),
+		RunE:    c.run,
+	}
+
+	coordinatorCmd.Flags().SortFlags = false
+	coordinatorCmd.Flags().AddFlagSet(c.flagSet())
+
+	return coordinatorCmd
+}
@@ -1,14 +1,17 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"path"
+	"strings"
 	"text/template"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/converter"
 	"go.k6.io/k6/lib/fsext"
 )
 
@@ -76,20 +79,184 @@ export default function() {
 }
 `))
 
+//nolint:gochecknoglobals
+var openAPIScriptTemplate = template.Must(template.New("openapi").Funcs(template.FuncMap{
+	"js":         converter.QuoteJS,
+	"jsonIndent": jsonIndent,
+	"oneLine":    func(s string) string { return strings.Join(strings.Fields(s), " ") },
+	"request":    openAPIRequest,
+}).Parse(`import http from 'k6/http';
+import { check } from 'k6';
+
+// The base URL of {{ with oneLine .API.Title }}{{ . }}{{ else }}the API{{ end }}, which can be changed with the
+// BASE_URL environment variable.
+const BASE_URL = __ENV.BASE_URL || {{ js .API.BaseURL }};
+
+// The total number of requests per second, which is split between the
+// operations by their weights.
+const TOTAL_RATE = 10;
+
+export const options = {
+  // Every operation has its own scenario, with a rate that is proportional to
+  // its weight. The weights are set with the x-k6-weight extension of the
+  // operations in the spec.
+  scenarios: {
+{{- range .API.Operations }}
+    {{ .Name }}: {
+      executor: 'constant-arrival-rate',
+      exec: '{{ .Name }}',
+      rate: Math.max(1, Math.round((TOTAL_RATE * {{ .Weight }}) / {{ $.API.TotalWeight }})),
+      timeUnit: '1s',
+      duration: '1m',
+      preAllocatedVUs: 5,
+      maxVUs: 50,
+    },
+{{- end }}
+  },
+};
+
+// matchesSchema returns whether the value matches a JSON schema of the spec.
+function matchesSchema(value, schema) {
+  if (value === null) {
+    return schema.nullable === true || schema.type === undefined;
+  }
+  if (schema.enum && !schema.enum.includes(value)) {
+    return false;
+  }
+  if (schema.allOf && !schema.allOf.every((s) => matchesSchema(value, s))) {
+    return false;
+  }
+  if (schema.anyOf && !schema.anyOf.some((s) => matchesSchema(value, s))) {
+    return false;
+  }
+  switch (schema.type) {
+    case 'object':
+      return typeof value === 'object' && !Array.isArray(value) &&
+        (schema.required || []).every((name) => name in value) &&
+        Object.entries(schema.properties || {}).every(
+          ([name, s]) => !(name in value) || matchesSchema(value[name], s),
+        );
+    case 'array':
+      return Array.isArray(value) && (!schema.items || value.every((item) => matchesSchema(item, schema.items)));
+    case 'integer':
+      return Number.isInteger(value);
+    case 'number':
+    case 'string':
+    case 'boolean':
+      return typeof value === schema.type;
+    default:
+      return true;
+  }
+}
+
+// bodyMatches returns whether the response has a JSON body that matches the
+// schema.
+function bodyMatches(res, schema) {
+  try {
+    return matchesSchema(res.json(), schema);
+  } catch (e) {
+    return false;
+  }
+}
+{{ range .API.Operations }}
+{{- if .ResponseSchema }}
+const {{ .Name }}Schema = {{ jsonIndent .ResponseSchema "" }};
+{{ end }}
+// {{ .Method }} {{ .Path }}{{ with oneLine .Summary }}: {{ . }}{{ end }}
+export function {{ .Name }}() {
+{{- if ne .Body nil }}
+  const body = {{ jsonIndent .Body "  " }};
+{{- end }}
+  const res = {{ request . }}, {
+{{- if or .Headers .ContentType }}
+    headers: {
+{{- with .ContentType }}
+      'Content-Type': {{ js . }},
+{{- end }}
+{{- range .Headers }}
+      {{ js .Name }}: {{ js .Value }},
+{{- end }}
+    },
+{{- end }}
+    tags: { name: {{ js .Path }} },
+  });
+  check(res, {
+{{- if .Status }}
+    'status is {{ .Status }}': (r) => r.status === {{ .Status }},
+{{- else }}
+    'status is 2xx': (r) => r.status >= 200 && r.status < 300,
+{{- end }}
+{{- if .ResponseSchema }}
+    'body matches the schema': (r) => bodyMatches(r, {{ .Name }}Schema),
+{{- end }}
+  });
+}
+{{ end }}
+// The default function calls every operation once, which is useful for a
+// quick check of the API with ` + "`k6 run --iterations 1 {{ .ScriptName }}`" + `.
+export default function () {
+{{- range .API.Operations }}
+  {{ .Name }}();
+{{- end }}
+}
+`))
+
 type initScriptTemplateArgs struct {
 	ScriptName string
 }
 
+type openAPIScriptTemplateArgs struct {
+	ScriptName string
+	API        *converter.API
+}
+
+// jsonIndent returns the indented JSON encoding of the value, to be used as a
+// JavaScript literal in a line with the prefix as its indentation.
+func jsonIndent(v interface{}, prefix string) (string, error) {
+	data, err := json.MarshalIndent(v, prefix, "  ")
+	return string(data), err
+}
+
+// openAPIRequest returns the start of the k6/http call of an operation, without
+// the params and the closing parenthesis.
+func openAPIRequest(op *converter.Operation) string {
+	url := "BASE_URL + " + converter.QuoteJS(op.URL)
+	body := "null"
+	switch {
+	case op.Body == nil:
+	case op.ContentType == "application/x-www-form-urlencoded":
+		// k6 encodes the objects as forms.
+		body = "body"
+	default:
+		body = "JSON.stringify(body)"
+	}
+
+	switch op.Method {
+	case "GET", "HEAD":
+		if op.Body == nil {
+			return fmt.Sprintf("http.%s(%s", strings.ToLower(op.Method), url)
+		}
+	case "POST", "PUT", "PATCH", "OPTIONS":
+		return fmt.Sprintf("http.%s(%s, %s", strings.ToLower(op.Method), url, body)
+	case "DELETE":
+		return fmt.Sprintf("http.del(%s, %s", url, body)
+	}
+	return fmt.Sprintf("http.request(%s, %s, %s", converter.QuoteJS(op.Method), url, body)
+}
+
 // newScriptCmd represents the `k6 new` command
 type newScriptCmd struct {
 	gs             *state.GlobalState
 	overwriteFiles bool
+	fromOpenAPI    string
 }
 
 func (c *newScriptCmd) flagSet() *pflag.FlagSet {
 	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
 	flags.SortFlags = false
 	flags.BoolVarP(&c.overwriteFiles, "force", "f", false, "Overwrite existing files")
+	flags.StringVar(&c.fromOpenAPI, "from-openapi", "",
+		"Generate the script from the operations of an OpenAPI 3 spec in YAML or JSON")
 
 	return flags
 }
@@ -109,6 +276,13 @@ func (c *newScriptCmd) run(cmd *cobra.Command, args []string) error { //nolint:r
 		return fmt.Errorf("%s already exists, please use the `--force` flag if you want overwrite it", target)
 	}
 
+	var api *converter.API
+	if c.fromOpenAPI != "" {
+		if api, err = c.readOpenAPI(); err != nil {
+			return err
+		}
+	}
+
 	fd, err := c.gs.FS.Create(target)
 	if err != nil {
 		return err
@@ -117,9 +291,17 @@ func (c *newScriptCmd) run(cmd *cobra.Command, args []string) error { //nolint:r
 		_ = fd.Close() // we may think to check the error and log
 	}()
 
-	if err := defaultNewScriptTemplate.Execute(fd, initScriptTemplateArgs{
-		ScriptName: path.Base(target),
-	}); err != nil {
+	if api != nil {
+		err = openAPIScriptTemplate.Execute(fd, openAPIScriptTemplateArgs{
+			ScriptName: path.Base(target),
+			API:        api,
+		})
+	} else {
+		err = defaultNewScriptTemplate.Execute(fd, initScriptTemplateArgs{
+			ScriptName: path.Base(target),
+		})
+	}
+	if err != nil {
 		return err
 	}
 
@@ -134,6 +316,17 @@ func (c *newScriptCmd) run(cmd *cobra.Command, args []string) error { //nolint:r
 	return nil
 }
 
+func (c *newScriptCmd) readOpenAPI() (*converter.API, error) {
+	f, err := c.gs.FS.Open(c.fromOpenAPI)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return converter.ParseOpenAPI(f)
+}
+
 func getCmdNewScript(gs *state.GlobalState) *cobra.Command {
 	c := &newScriptCmd{gs: gs}
 
@@ -145,7 +338,10 @@ func getCmdNewScript(gs *state.GlobalState) *cobra.Command {
   {{.}} new test.js
 
   # Overwrite existing test.js with a minimal k6 script
-  {{.}} new -f test.js`[1:])
+  {{.}} new -f test.js
+
+  # Create a script with a scenario for every operation of an OpenAPI spec
+  {{.}} new --from-openapi openapi.yaml api-test.js`[1:])
 
 	initCmd := &cobra.Command{
 		Use:   "new",
@@ -156,6 +352,10 @@ This command will create a minimal k6 script in the current directory and
 store it in the file specified by the first argument. If no argument is
 provided, the script will be stored in script.js.
 
+With --from-openapi, the script has a function and a scenario for every
+operation of the spec, with example request bodies based on the schemas
+and checks of the response statuses and bodies.
+
 This command will not overwrite existing files.`,
 		Example: exampleText,
 		Args:    cobra.MaximumNArgs(1),
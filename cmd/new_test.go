@@ -95,3 +95,74 @@ func TestNewScriptCmd_FileExists_Overwrite(t *testing.T) {
 	assert.Contains(t, string(data), "export const options = {")
 	assert.Contains(t, string(data), "export default function() {")
 }
+
+func TestNewScriptCmd_FromOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	const spec = `
+openapi: 3.0.3
+info:
+  title: Petstore
+servers:
+  - url: https://petstore.example.com
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      summary: List the pets
+      x-k6-weight: 3
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {type: array, items: {type: string}}
+    post:
+      operationId: createPet
+      requestBody:
+        content:
+          application/json:
+            schema: {type: object, properties: {name: {type: string, example: Rex}}}
+      responses:
+        '201': {description: created}
+`
+
+	ts := tests.NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, "openapi.yaml", []byte(spec), 0o644))
+	ts.CmdArgs = []string{"k6", "new", "--from-openapi", "openapi.yaml", "api.js"}
+
+	newRootCommand(ts.GlobalState).execute()
+
+	data, err := fsext.ReadFile(ts.FS, "api.js")
+	require.NoError(t, err)
+
+	jsData := string(data)
+	assert.Contains(t, jsData, "const BASE_URL = __ENV.BASE_URL || 'https://petstore.example.com';")
+	assert.Contains(t, jsData, "exec: 'listPets',\n      rate: Math.max(1, Math.round((TOTAL_RATE * 3) / 4)),")
+	assert.Contains(t, jsData, "exec: 'createPet',\n      rate: Math.max(1, Math.round((TOTAL_RATE * 1) / 4)),")
+	assert.Contains(t, jsData, "const listPetsSchema = {\n  \"items\": {\n    \"type\": \"string\"\n  },\n")
+	assert.Contains(t, jsData, "// GET /pets: List the pets\nexport function listPets() {\n"+
+		"  const res = http.get(BASE_URL + '/pets', {\n")
+	assert.Contains(t, jsData, "'body matches the schema': (r) => bodyMatches(r, listPetsSchema),")
+	assert.Contains(t, jsData, "  const body = {\n    \"name\": \"Rex\"\n  };\n"+
+		"  const res = http.post(BASE_URL + '/pets', JSON.stringify(body), {\n"+
+		"    headers: {\n      'Content-Type': 'application/json',\n    },\n")
+	assert.Contains(t, jsData, "'status is 201': (r) => r.status === 201,")
+	assert.Contains(t, jsData, "export default function () {\n  listPets();\n  createPet();\n}")
+}
+
+func TestNewScriptCmd_FromOpenAPIInvalid(t *testing.T) {
+	t.Parallel()
+
+	ts := tests.NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, "openapi.yaml", []byte(`swagger: "2.0"`), 0o644))
+	ts.CmdArgs = []string{"k6", "new", "--from-openapi", "openapi.yaml"}
+	ts.ExpectedExitCode = -1
+
+	newRootCommand(ts.GlobalState).execute()
+
+	assert.Contains(t, ts.Stderr.String(), "only 3.x is supported")
+	exists, err := fsext.Exists(ts.FS, defaultNewScriptName)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -43,6 +43,7 @@ func optionFlagSet() *pflag.FlagSet {
 	flags.Bool("insecure-skip-tls-verify", false, "skip verification of TLS certificates")
 	flags.Bool("no-connection-reuse", false, "disable keep-alive connections")
 	flags.Bool("no-vu-connection-reuse", false, "don't reuse connections between iterations")
+	flags.Bool("http3", false, "send the HTTP requests over HTTP/3 (QUIC)")
 	flags.Duration("min-iteration-duration", 0, "minimum amount of time k6 will take executing a single iteration")
 	flags.BoolP("throw", "w", false, "throw warnings (like failed http requests) as errors")
 	flags.StringSlice("blacklist-ip", nil, "blacklist an `ip range` from being called")
@@ -57,6 +58,7 @@ func optionFlagSet() *pflag.FlagSet {
 	)
 	flags.StringSlice("summary-trend-stats", nil, sumTrendStatsHelp)
 	flags.String("summary-time-unit", "", "define the time unit used to display the trend stats. Possible units are: 's', 'ms' and 'us'") //nolint:lll
+	flags.String("trend-sink", "", "how trend metric values are stored, 'exact' (default) or a memory-bounded 'histogram'")
 	// system-tags must have a default value, but we can't specify it here, otherwiese, it will always override others.
 	// set it to nil here, and add the default in applyDefault() instead.
 	systemTagsCliHelpText := fmt.Sprintf(
@@ -73,7 +75,9 @@ func optionFlagSet() *pflag.FlagSet {
 		"for a persistent cache, '0' to disable the cache,\nor a positive duration, e.g. '1s', '1m', etc. "+
 		"Milliseconds are assumed if no unit is provided.\n"+
 		"Possible select values to return a single IP are: 'first', 'random' or 'roundRobin'.\n"+
-		"Possible policy values are: 'preferIPv4', 'preferIPv6', 'onlyIPv4', 'onlyIPv6' or 'any'.\n")
+		"Possible policy values are: 'preferIPv4', 'preferIPv6', 'onlyIPv4', 'onlyIPv6' or 'any'.\n"+
+		"Possible cache values are: 'shared' for all VUs or 'vu' for a separate cache per VU.\n"+
+		"The nameservers are separated by '|', e.g. '1.1.1.1|tcp://8.8.8.8|tls://1.1.1.1|https://dns.google/dns-query'.\n")
 	return flags
 }
 
@@ -95,6 +99,7 @@ func getOptions(flags *pflag.FlagSet) (lib.Options, error) {
 		InsecureSkipTLSVerify:   getNullBool(flags, "insecure-skip-tls-verify"),
 		NoConnectionReuse:       getNullBool(flags, "no-connection-reuse"),
 		NoVUConnectionReuse:     getNullBool(flags, "no-vu-connection-reuse"),
+		HTTP3:                   getNullBool(flags, "http3"),
 		MinIterationDuration:    getNullDuration(flags, "min-iteration-duration"),
 		Throw:                   getNullBool(flags, "throw"),
 		DiscardResponseBodies:   getNullBool(flags, "discard-response-bodies"),
@@ -210,6 +215,17 @@ func getOptions(flags *pflag.FlagSet) (lib.Options, error) {
 		opts.SummaryTimeUnit = null.StringFrom(summaryTimeUnit)
 	}
 
+	trendSink, err := flags.GetString("trend-sink")
+	if err != nil {
+		return opts, err
+	}
+	if trendSink != "" {
+		if _, err = metrics.ParseTrendSinkType(trendSink); err != nil {
+			return opts, err
+		}
+		opts.TrendSink = null.StringFrom(trendSink)
+	}
+
 	runTags, err := flags.GetStringSlice("tag")
 	if err != nil {
 		return opts, err
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
@@ -25,7 +26,9 @@ import (
 	"go.k6.io/k6/execution/local"
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/consts"
 	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/lib/har"
 	"go.k6.io/k6/lib/trace"
 	"go.k6.io/k6/metrics"
 	"go.k6.io/k6/metrics/engine"
@@ -39,6 +42,10 @@ type cmdRun struct {
 
 	// TODO: figure out something more elegant?
 	loadConfiguredTest func(cmd *cobra.Command, args []string) (*loadedAndConfiguredTest, execution.Controller, error)
+
+	// extraOutputs are used in addition to the ones the user has configured,
+	// e.g. for sending the metrics to the coordinator in distributed runs.
+	extraOutputs []output.Output
 }
 
 const (
@@ -111,6 +118,11 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 			}
 		}()
 	}
+	finishHAR, err := c.setupHARRecorder(cmd.Flags(), test)
+	if err != nil {
+		return err
+	}
+	defer finishHAR()
 
 	if err = c.setupTracerProvider(globalCtx, test); err != nil {
 		return err
@@ -164,6 +176,7 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 	if err != nil {
 		return err
 	}
+	outputs = append(outputs, c.extraOutputs...)
 
 	outputs = append(outputs, testRunState.GroupSummary)
 
@@ -172,10 +185,19 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 		return err
 	}
 
+	rtOpts := testRunState.RuntimeOptions
+	var baseline *metrics.Baseline
+	if rtOpts.Baseline.String != "" && !rtOpts.NoThresholds.Bool {
+		if baseline, err = readBaseline(c.gs, rtOpts.Baseline.String); err != nil {
+			return err
+		}
+		metricsEngine.SetBaseline(baseline)
+	}
+
 	// We'll need to pipe metrics to the MetricsEngine and process them if any
-	// of these are enabled: thresholds, end-of-test summary
-	shouldProcessMetrics := (!testRunState.RuntimeOptions.NoSummary.Bool ||
-		!testRunState.RuntimeOptions.NoThresholds.Bool)
+	// of these are enabled: thresholds, end-of-test summary, baseline export
+	shouldProcessMetrics := (!rtOpts.NoSummary.Bool || !rtOpts.NoThresholds.Bool ||
+		rtOpts.BaselineExport.String != "")
 	var metricsIngester *engine.OutputIngester
 	if shouldProcessMetrics {
 		err = metricsEngine.InitSubMetricsAndThresholds(conf.Options, testRunState.RuntimeOptions.NoThresholds.Bool)
@@ -186,9 +208,20 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 		// thresholds or the end-of-test summary are enabled.
 		metricsIngester = metricsEngine.CreateIngester()
 		outputs = append(outputs, metricsIngester)
+		testRunState.SLOWatcher = metricsEngine
 	}
 
 	executionState := execScheduler.GetState()
+	if baseline != nil || rtOpts.BaselineExport.String != "" {
+		// This runs after the thresholds are finalized and after the end-of-test
+		// summary, so the diff table is printed right below it.
+		defer func() {
+			handleBaseline(
+				c.gs, baseline, rtOpts.BaselineExport.String,
+				metricsEngine, executionState.GetCurrentTestRunDuration(), conf.Options,
+			)
+		}()
+	}
 	if !testRunState.RuntimeOptions.NoSummary.Bool {
 		defer func() {
 			logger.Debug("Generating the end-of-test summary...")
@@ -440,9 +473,58 @@ func (c *cmdRun) flagSet() *pflag.FlagSet {
 	flags.AddFlagSet(optionFlagSet())
 	flags.AddFlagSet(runtimeOptionFlagSet(true))
 	flags.AddFlagSet(configFlagSet())
+	flags.String("har", "", "record all HTTP requests and their responses to a HAR `file`")
+	flags.Float64("har-sample-rate", 1, "the share of the HTTP requests recorded to the HAR file, between 0 and 1")
+	flags.UintSlice("har-vus", nil, "record only the HTTP requests of the VUs with the given IDs to the HAR file")
 	return flags
 }
 
+// setupHARRecorder creates the HAR recorder, if it was enabled with the --har
+// flag, and returns a function that finishes the HAR file.
+func (c *cmdRun) setupHARRecorder(flags *pflag.FlagSet, test *loadedAndConfiguredTest) (func(), error) {
+	path, err := flags.GetString("har")
+	if err != nil || path == "" {
+		return func() {}, err
+	}
+	sampleRate, err := flags.GetFloat64("har-sample-rate")
+	if err != nil {
+		return nil, err
+	}
+	vuIDs, err := flags.GetUintSlice("har-vus")
+	if err != nil {
+		return nil, err
+	}
+	vus := make([]uint64, len(vuIDs))
+	for i, id := range vuIDs {
+		vus[i] = uint64(id)
+	}
+
+	f, err := c.gs.FS.OpenFile(path, syscall.O_WRONLY|syscall.O_CREAT|syscall.O_TRUNC, 0o666)
+	if err != nil {
+		return nil, fmt.Errorf("could not open the HAR file '%s': %w", path, err)
+	}
+	w := bufio.NewWriter(f)
+	recorder, err := har.NewRecorder(w, har.Creator{Name: "k6", Version: consts.Version}, sampleRate, vus)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	test.preInitState.HARRecorder = recorder
+
+	return func() {
+		err := recorder.Close()
+		if err == nil {
+			err = w.Flush()
+		}
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			c.gs.Logger.WithError(err).Errorf("Failed to write the HAR file '%s'", path)
+		}
+	}, nil
+}
+
 func (c *cmdRun) setupTracerProvider(ctx context.Context, test *loadedAndConfiguredTest) error {
 	ro := test.preInitState.RuntimeOptions
 	if ro.TracesOutput.String == "none" {
@@ -505,6 +587,29 @@ a commandline interface for interacting with it.`,
 	return runCmd
 }
 
+// handleBaseline prints the comparison with the baseline, for the thresholds
+// that refer to it, and saves the metrics of this test run as a new baseline.
+// It holds the metrics lock of the engine while reading the observed metrics.
+func handleBaseline(
+	gs *state.GlobalState, baseline *metrics.Baseline, exportPath string,
+	metricsEngine *engine.MetricsEngine, duration time.Duration, options lib.Options,
+) {
+	metricsEngine.MetricsLock.Lock()
+	defer metricsEngine.MetricsLock.Unlock()
+	observed := metricsEngine.ObservedMetrics
+
+	if baseline != nil {
+		if err := printBaselineThresholdsDiff(gs.Stdout, baseline, observed, duration); err != nil {
+			gs.Logger.WithError(err).Error("Failed to compare the test run with the baseline")
+		}
+	}
+	if exportPath != "" {
+		if err := exportBaseline(gs, exportPath, observed, duration, options); err != nil {
+			gs.Logger.WithError(err).Error("Failed to export the baseline")
+		}
+	}
+}
+
 func handleSummaryResult(fs fsext.Fs, stdOut, stdErr io.Writer, result map[string]io.Reader) error {
 	var errs []error
 
@@ -37,6 +37,8 @@ experimental_enhanced: esbuild-based transpiling for TypeScript and ES6+ support
 		"",
 		"output the end-of-test summary report to JSON file",
 	)
+	flags.String("baseline", "", "compare the thresholds that refer to a `baseline` against the given baseline file")
+	flags.String("baseline-export", "", "save the aggregated metrics to a baseline file for later comparisons")
 	flags.String("traces-output", "none",
 		"set the output for k6 traces, possible values are none,otel[=host:port]")
 	return flags
@@ -69,6 +71,8 @@ func getRuntimeOptions(flags *pflag.FlagSet, environment map[string]string) (lib
 		NoSummary:            getNullBool(flags, "no-summary"),
 		SummaryExport:        getNullString(flags, "summary-export"),
 		TracesOutput:         getNullString(flags, "traces-output"),
+		Baseline:             getNullString(flags, "baseline"),
+		BaselineExport:       getNullString(flags, "baseline-export"),
 		Env:                  make(map[string]string),
 	}
 
@@ -101,6 +105,18 @@ func getRuntimeOptions(flags *pflag.FlagSet, environment map[string]string) (lib
 		}
 	}
 
+	if envVar, ok := environment["K6_BASELINE"]; ok {
+		if !opts.Baseline.Valid {
+			opts.Baseline = null.StringFrom(envVar)
+		}
+	}
+
+	if envVar, ok := environment["K6_BASELINE_EXPORT"]; ok {
+		if !opts.BaselineExport.Valid {
+			opts.BaselineExport = null.StringFrom(envVar)
+		}
+	}
+
 	if envVar, ok := environment["SSLKEYLOGFILE"]; ok {
 		if !opts.KeyWriter.Valid {
 			opts.KeyWriter = null.StringFrom(envVar)
@@ -364,6 +364,19 @@ func TestRuntimeOptions(t *testing.T) {
 				TracesOutput:         defaultTracesOutput,
 			},
 		},
+		"baseline from env overwritten by CLI": {
+			useSysEnv: false,
+			systemEnv: map[string]string{"K6_BASELINE": "foo.json", "K6_BASELINE_EXPORT": "bar.json"},
+			cliFlags:  []string{"--baseline", "baz.json"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    defaultCompatMode,
+				Env:                  map[string]string{},
+				Baseline:             null.NewString("baz.json", true),
+				BaselineExport:       null.NewString("bar.json", true),
+				TracesOutput:         defaultTracesOutput,
+			},
+		},
 		"env var error detected even when CLI flags overwrite 1": {
 			useSysEnv: false,
 			systemEnv: map[string]string{"K6_NO_THRESHOLDS": "boo"},
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 
 	"github.com/spf13/cobra"
@@ -17,18 +18,47 @@ func getCmdScale(gs *state.GlobalState) *cobra.Command {
 		Short: "Scale a running test",
 		Long: `Scale a running test.
 
+  The VUs are changed in the externally-controlled scenario, and the rate in
+  an externally-controlled-arrival-rate scenario, the first one by default.
+
   Use the global --address flag to specify the URL to the API server.`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			vus := getNullInt64(cmd.Flags(), "vus")
 			maxVUs := getNullInt64(cmd.Flags(), "max")
-			if !vus.Valid && !maxVUs.Valid {
-				return errors.New("Specify either -u/--vus or -m/--max") //nolint:golint,stylecheck
+			rate := getNullInt64(cmd.Flags(), "rate")
+			scenario, err := cmd.Flags().GetString("scenario")
+			if err != nil {
+				return err
+			}
+			if !vus.Valid && !maxVUs.Valid && !rate.Valid {
+				return errors.New("Specify either -u/--vus, -m/--max or -r/--rate") //nolint:golint,stylecheck
+			}
+			if rate.Valid && (vus.Valid || maxVUs.Valid) {
+				return errors.New("the rate can't be changed together with the VUs")
+			}
+			if scenario != "" && !rate.Valid {
+				return errors.New("the --scenario flag can only be used with -r/--rate")
 			}
 
 			c, err := client.New(gs.Flags.Address)
 			if err != nil {
 				return err
 			}
+
+			if rate.Valid {
+				if scenario == "" {
+					if scenario, err = getFirstArrivalRateScenario(gs.Ctx, c); err != nil {
+						return err
+					}
+				}
+				updated, err := c.SetScenario(gs.Ctx, scenario, v1.Scenario{Rate: rate})
+				if err != nil {
+					return err
+				}
+
+				return yamlPrint(gs.Stdout, updated)
+			}
+
 			status, err := c.SetStatus(gs.Ctx, v1.Status{VUs: vus, VUsMax: maxVUs})
 			if err != nil {
 				return err
@@ -40,6 +70,24 @@ func getCmdScale(gs *state.GlobalState) *cobra.Command {
 
 	scaleCmd.Flags().Int64P("vus", "u", 1, "number of virtual users")
 	scaleCmd.Flags().Int64P("max", "m", 0, "max available virtual users")
+	scaleCmd.Flags().Int64P("rate", "r", 0, "number of iterations started per time unit of the scenario")
+	scaleCmd.Flags().String("scenario", "", "name of the `scenario` to change the rate of")
 
 	return scaleCmd
 }
+
+// getFirstArrivalRateScenario returns the name of the first scenario whose
+// rate can be changed.
+func getFirstArrivalRateScenario(ctx context.Context, c *client.Client) (string, error) {
+	scenarios, err := c.Scenarios(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, scenario := range scenarios {
+		if scenario.Executor == "externally-controlled-arrival-rate" {
+			return scenario.Name, nil
+		}
+	}
+	return "", errors.New(
+		"an externally-controlled-arrival-rate executor needs to be configured for changing the rate")
+}
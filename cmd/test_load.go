@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"sort"
 	"sync"
 	"syscall"
 
@@ -203,6 +204,11 @@ func (lt *loadedTest) consolidateDeriveAndValidateConfig(
 		return nil, err
 	}
 
+	gs.Logger.Debug("Loading the files of the scenarios...")
+	if err = lt.loadScenarioFiles(consolidatedConfig.Scenarios); err != nil {
+		return nil, errext.WithExitCodeIfNone(err, exitcodes.InvalidConfig)
+	}
+
 	gs.Logger.Debug("Parsing thresholds and validating config...")
 	// Parse the thresholds, only if the --no-threshold flag is not set.
 	// If parsing the threshold expressions failed, consider it as an
@@ -233,6 +239,40 @@ func (lt *loadedTest) consolidateDeriveAndValidateConfig(
 	}, nil
 }
 
+// loadScenarioFiles loads the files, which the scenarios read some of their
+// options from, through the filesystem of the test. This way they are read
+// from the archive, when the test is run from one, and packaged in it, when
+// it's created.
+func (lt *loadedTest) loadScenarioFiles(scenarios lib.ScenarioConfigs) error {
+	arc := lt.initRunner.MakeArchive()
+	fs, pwd := arc.Filesystems["file"], arc.PwdURL
+	readFile := func(path string) ([]byte, error) {
+		filename := fsext.Abs(pwd.Path, path)
+		// the files opened by the script are the only ones allowed after
+		// its initialization, so the files of the options are allowed too
+		if allower, ok := fs.(fsext.OnlyCachedPathAllower); ok {
+			allower.AllowPath(filename)
+		}
+		return fsext.ReadFile(fs, filename)
+	}
+
+	names := make([]string, 0, len(scenarios))
+	for name := range scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		config, ok := scenarios[name].(lib.ExecutorConfigWithFiles)
+		if !ok {
+			continue
+		}
+		if err := config.LoadFiles(readFile); err != nil {
+			return fmt.Errorf("could not load the files of the scenario %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
 // loadedAndConfiguredTest contains the whole loadedTest, as well as the
 // consolidated test config and the full test run state.
 type loadedAndConfiguredTest struct {
@@ -268,6 +308,12 @@ func (lct *loadedAndConfiguredTest) buildTestRunState(
 		return nil, err
 	}
 
+	trendSinkType, err := metrics.ParseTrendSinkType(lct.derivedConfig.TrendSink.String)
+	if err != nil {
+		return nil, err
+	}
+	lct.preInitState.Registry.SetTrendSinkType(trendSinkType)
+
 	// it pre-loads system certificates to avoid doing it on the first TLS request.
 	// This is done async to avoid blocking the rest of the loading process as it will not stop if it fails.
 	go loadSystemCertPool(lct.preInitState.Logger)
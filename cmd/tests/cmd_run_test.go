@@ -385,6 +385,51 @@ func testSSLKEYLOGFILE(t *testing.T, ts *GlobalTestState, filePath string) {
 	assert.Regexp(t, "^CLIENT_[A-Z_]+ [0-9a-f]+ [0-9a-f]+\n", string(sslloglines))
 }
 
+func TestHAR(t *testing.T) {
+	t.Parallel()
+
+	tb := httpmultibin.NewHTTPMultiBin(t)
+	ts := NewGlobalTestState(t)
+	ts.CmdArgs = []string{"k6", "run", "--har", "out.har", "--har-vus", "2", "--vus", "2", "--iterations", "4", "-"}
+	ts.Stdin = bytes.NewReader([]byte(tb.Replacer.Replace(`
+    import http from "k6/http"
+    import { group } from "k6"
+
+    export default () => {
+      group("my group", () => {
+        http.get("HTTPBIN_IP_URL/get?vu=" + __VU);
+      });
+    }
+  `)))
+
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	data, err := fsext.ReadFile(ts.FS, "out.har")
+	require.NoError(t, err)
+	var result struct {
+		Log struct {
+			Creator struct{ Name string }
+			Entries []struct {
+				Request  struct{ URL string }
+				Response struct{ Status int }
+				VU       uint64 `json:"_vu"`
+				Scenario string `json:"_scenario"`
+				Group    string `json:"_group"`
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(data, &result))
+	assert.Equal(t, "k6", result.Log.Creator.Name)
+	require.Len(t, result.Log.Entries, 2)
+	for _, entry := range result.Log.Entries {
+		assert.Equal(t, tb.Replacer.Replace("HTTPBIN_IP_URL/get?vu=2"), entry.Request.URL)
+		assert.Equal(t, http.StatusOK, entry.Response.Status)
+		assert.Equal(t, uint64(2), entry.VU)
+		assert.Equal(t, "default", entry.Scenario)
+		assert.Equal(t, "::my group", entry.Group)
+	}
+}
+
 func TestThresholdDeprecationWarnings(t *testing.T) {
 	t.Parallel()
 
@@ -441,7 +486,7 @@ func TestExecutionTestOptionsDefaultValues(t *testing.T) {
 	loglines := ts.LoggerHook.Drain()
 	require.Len(t, loglines, 1)
 
-	expected := `{"paused":null,"executionSegment":null,"executionSegmentSequence":null,"noSetup":null,"setupTimeout":null,"noTeardown":null,"teardownTimeout":null,"rps":null,"dns":{"ttl":null,"select":null,"policy":null},"maxRedirects":null,"userAgent":null,"batch":null,"batchPerHost":null,"httpDebug":null,"insecureSkipTLSVerify":null,"tlsCipherSuites":null,"tlsVersion":null,"tlsAuth":null,"throw":null,"thresholds":null,"blacklistIPs":null,"blockHostnames":null,"hosts":null,"noConnectionReuse":null,"noVUConnectionReuse":null,"minIterationDuration":null,"ext":null,"summaryTrendStats":["avg", "min", "med", "max", "p(90)", "p(95)"],"summaryTimeUnit":null,"systemTags":["check","error","error_code","expected_response","group","method","name","proto","scenario","service","status","subproto","tls_version","url"],"tags":null,"metricSamplesBufferSize":null,"noCookiesReset":null,"discardResponseBodies":null,"consoleOutput":null,"scenarios":{"default":{"vus":null,"iterations":1,"executor":"shared-iterations","maxDuration":null,"startTime":null,"env":null,"tags":null,"gracefulStop":null,"exec":null}},"localIPs":null}`
+	expected := `{"paused":null,"executionSegment":null,"executionSegmentSequence":null,"noSetup":null,"setupTimeout":null,"noTeardown":null,"teardownTimeout":null,"rps":null,"dns":{"ttl":null,"select":null,"policy":null,"nameservers":null,"cache":null},"maxRedirects":null,"userAgent":null,"batch":null,"batchPerHost":null,"httpDebug":null,"insecureSkipTLSVerify":null,"tlsCipherSuites":null,"tlsVersion":null,"tlsAuth":null,"throw":null,"thresholds":null,"blacklistIPs":null,"blockHostnames":null,"hosts":null,"noConnectionReuse":null,"noVUConnectionReuse":null,"http3":null,"proxy":null,"network":null,"minIterationDuration":null,"ext":null,"summaryTrendStats":["avg", "min", "med", "max", "p(90)", "p(95)"],"summaryTimeUnit":null,"trendSink":null,"systemTags":["attempt","check","error","error_code","expected_response","group","method","name","proto","scenario","service","status","subproto","tls_version","url"],"tags":null,"metricSamplesBufferSize":null,"noCookiesReset":null,"discardResponseBodies":null,"consoleOutput":null,"scenarios":{"default":{"vus":null,"iterations":1,"executor":"shared-iterations","maxDuration":null,"startTime":null,"env":null,"tags":null,"gracefulStop":null,"exec":null}},"localIPs":null}`
 	assert.JSONEq(t, expected, loglines[0].Message)
 }
 
@@ -1543,6 +1588,127 @@ func TestMinIterationDuration(t *testing.T) {
 	assert.Contains(t, stdout, "✓ test_counter.........: 3")
 }
 
+func TestBreakingPointArrivalRate(t *testing.T) {
+	t.Parallel()
+	script := `
+		export let options = {
+			scenarios: {
+				breaking_point: {
+					executor: 'breaking-point-arrival-rate',
+					startRate: 5,
+					rateStep: 5,
+					maxRate: 10,
+					stepDuration: '1s',
+					preAllocatedVUs: 2,
+					slos: {
+						'iteration_duration{scenario:breaking_point}': ['p(95)<1000'],
+					},
+				},
+			},
+		};
+
+		export default function () {};
+	`
+
+	ts := getSingleFileTestState(t, script, []string{"--log-output=stdout"}, 0)
+
+	start := time.Now()
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+	assert.Less(t, time.Since(start), 10*time.Second, "expected the executor to stop when the maxRate met the SLOs")
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Contains(t, stdout, "The highest arrival rate that met the SLOs was 10 iterations/1s")
+	assert.Contains(t, stdout, "max_sustainable_rate............: 10")
+	assert.Contains(t, stdout, "breaking_point ✓ [ 100% ]")
+}
+
+func TestReplayArrivalRateFromArchive(t *testing.T) {
+	t.Parallel()
+	script := `
+		import { Counter } from 'k6/metrics';
+
+		const browse = new Counter('browse');
+		const checkout = new Counter('checkout');
+
+		export let options = {
+			scenarios: {
+				replay: {
+					executor: 'replay-arrival-rate',
+					file: 'shapes/traffic.csv',
+					timeCompression: 60,
+					preAllocatedVUs: 2,
+				},
+			},
+			thresholds: {
+				browse: ['count>5'],
+				checkout: ['count>0', 'count<5'],
+			},
+		};
+
+		export function browsing() { browse.add(1) };
+		export function checkingOut() { checkout.add(1) };
+	`
+	shape := "offset,rate,browsing,checkingOut\n0s,10,3,1\n1m,10,3,1\n"
+
+	ts := NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "test.js"), []byte(script), 0o644))
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "shapes/traffic.csv"), []byte(shape), 0o644))
+	ts.CmdArgs = []string{"k6", "archive", "test.js"}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	data, err := fsext.ReadFile(ts.FS, "archive.tar")
+	require.NoError(t, err)
+
+	// the traffic shape is only in the archive
+	ts = NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "archive.tar"), data, 0o644))
+	ts.CmdArgs = []string{"k6", "run", "--log-output=stdout", "archive.tar"}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	t.Log(stdout)
+	assert.Contains(t, stdout, "replay: Up to 10.00 iterations/s for 1s replayed from shapes/traffic.csv")
+	assert.Contains(t, stdout, "replay ✓ [ 100% ]")
+}
+
+func TestStartAfterExecutionRequirements(t *testing.T) {
+	t.Parallel()
+	script := `
+		export let options = {
+			scenarios: {
+				warmup: {
+					executor: 'constant-vus',
+					vus: 2,
+					duration: '1m',
+					gracefulStop: '0s',
+				},
+				main: {
+					executor: 'constant-vus',
+					vus: 5,
+					duration: '2m',
+					gracefulStop: '0s',
+					startAfter: ['warmup'],
+					startTime: '30s',
+				},
+			},
+		};
+
+		export default function () {};
+	`
+
+	ts := getSingleFileTestState(t, script, nil, 0)
+	ts.CmdArgs = []string{"k6", "inspect", "--execution-requirements", "test.js"}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	// main can start 30s after warmup starts and end 30s + 2m after the
+	// planned end of warmup, but it never runs in parallel with warmup
+	stdout := ts.Stdout.String()
+	assert.Equal(t, "3m30s", gjson.Get(stdout, "totalDuration").String())
+	assert.Equal(t, int64(5), gjson.Get(stdout, "maxVUs").Int())
+	assert.Equal(t, []interface{}{"warmup"}, gjson.Get(stdout, "scenarios.main.startAfter").Value())
+}
+
 func TestMetricNameError(t *testing.T) {
 	t.Parallel()
 	script := `
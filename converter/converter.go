@@ -0,0 +1,184 @@
+// Package converter converts recorded HTTP sessions, like HAR files, Postman
+// collections and curl commands, to k6 scripts. It also parses the OpenAPI
+// specs that the scripts of `k6 new` are generated from.
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// Session is a converted sequence of requests, split in groups.
+type Session struct {
+	Groups []*Group
+	// Variables are the values that the requests can reference with
+	// {{name}} placeholders.
+	Variables map[string]string
+	// InsecureSkipTLSVerify is set if the session was recorded without TLS
+	// verification.
+	InsecureSkipTLSVerify bool
+}
+
+// Group is a named sequence of requests. The requests of a group with an
+// empty name aren't put in a group in the script.
+type Group struct {
+	Name     string
+	Requests []*Request
+}
+
+// Request is a single request of a session. Its URL, header values and body
+// can contain {{name}} placeholders for the session variables.
+type Request struct {
+	Method  string
+	URL     string
+	Headers []Header
+	Body    string
+	// Response is the recorded response, if there is one.
+	Response *Response
+}
+
+// Header is a single request header.
+type Header struct {
+	Name, Value string
+}
+
+// Response is the recorded response of a request.
+type Response struct {
+	Status      int
+	ContentType string
+	Body        string
+}
+
+// Options control which requests are converted and how.
+type Options struct {
+	// Only are the hosts whose requests are converted, if there are any.
+	Only []string
+	// Skip are the hosts whose requests aren't converted.
+	Skip []string
+	// Correlate enables the extraction of the values from the JSON responses
+	// that are used in the later requests.
+	Correlate bool
+	// NoChecks disables the checks of the recorded response statuses.
+	NoChecks bool
+}
+
+// filter returns a copy of the session with only the requests that should be
+// converted according to the options.
+func (o Options) filter(session *Session) *Session {
+	result := &Session{
+		Variables:             session.Variables,
+		InsecureSkipTLSVerify: session.InsecureSkipTLSVerify,
+	}
+	for _, group := range session.Groups {
+		filtered := &Group{Name: group.Name}
+		for _, req := range group.Requests {
+			if o.shouldConvert(req) {
+				filtered.Requests = append(filtered.Requests, req)
+			}
+		}
+		if len(filtered.Requests) > 0 {
+			result.Groups = append(result.Groups, filtered)
+		}
+	}
+	return result
+}
+
+func (o Options) shouldConvert(req *Request) bool {
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		// It could be a URL with placeholders, which can't be filtered.
+		return len(o.Only) == 0
+	}
+	host := u.Hostname()
+	matches := func(hosts []string) bool {
+		for _, h := range hosts {
+			if host == h || strings.HasSuffix(host, "."+h) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(o.Only) > 0 && !matches(o.Only) {
+		return false
+	}
+	return !matches(o.Skip)
+}
+
+// ignoredHeaders are the headers that are set by k6 itself and are dropped
+// from the converted requests.
+//
+//nolint:gochecknoglobals
+var ignoredHeaders = map[string]bool{
+	"content-length":    true,
+	"host":              true,
+	"connection":        true,
+	"accept-encoding":   true,
+	"transfer-encoding": true,
+}
+
+// isIgnoredHeader returns whether the header should be dropped, which is also
+// the case for the HTTP/2 pseudo-headers that browsers record.
+func isIgnoredHeader(name string) bool {
+	return strings.HasPrefix(name, ":") || ignoredHeaders[strings.ToLower(name)]
+}
+
+// The formats of the inputs that can be converted.
+const (
+	FormatHAR     = "har"
+	FormatPostman = "postman"
+	FormatCurl    = "curl"
+)
+
+// Parse parses the input in the given format. If the format is empty, it's
+// detected from the input.
+func Parse(r io.Reader, format string) (*Session, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	// Some tools write a byte order mark at the start of their HAR files.
+	data = bytes.TrimPrefix(data, []byte("\uFEFF"))
+	if format == "" {
+		format = detectFormat(data)
+	}
+
+	switch format {
+	case FormatHAR:
+		return ParseHAR(bytes.NewReader(data))
+	case FormatPostman:
+		return ParsePostman(bytes.NewReader(data))
+	case FormatCurl:
+		return ParseCurl(bytes.NewReader(data))
+	case "":
+		return nil, errors.New("couldn't detect the format of the input, it should be a HAR file, " +
+			"a Postman collection or curl commands")
+	default:
+		return nil, fmt.Errorf("unsupported format %q, it should be one of %q, %q or %q",
+			format, FormatHAR, FormatPostman, FormatCurl)
+	}
+}
+
+func detectFormat(data []byte) string {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("curl ")) {
+		return FormatCurl
+	}
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return ""
+	}
+	if _, ok := keys["log"]; ok {
+		return FormatHAR
+	}
+	_, hasInfo := keys["info"]
+	_, hasItem := keys["item"]
+	if hasInfo && hasItem {
+		return FormatPostman
+	}
+	return ""
+}
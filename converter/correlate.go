@@ -0,0 +1,235 @@
+package converter
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// minCorrelatedLength is the minimum length of the response values that are
+// correlated, so that short and common values like "ok" or "true" aren't.
+const minCorrelatedLength = 6
+
+// extraction is a value that is extracted from a response to a variable.
+type extraction struct {
+	name string
+	// path is the gjson path of the value, as it's used by Response.json().
+	path string
+}
+
+// correlationSource is the last response that a value was seen in.
+type correlationSource struct {
+	req       *Request
+	path      string
+	extracted bool
+}
+
+// correlate finds the string values of the JSON responses that are used in
+// the later requests, and replaces them with placeholders for variables. It
+// returns a copy of the session and the extractions of its requests.
+//
+// The values that were sent in a request before they were first seen in a
+// response aren't correlated, since they didn't come from the server.
+func correlate(session *Session) (*Session, map[*Request][]extraction) {
+	result := &Session{
+		Variables:             make(map[string]string, len(session.Variables)),
+		InsecureSkipTLSVerify: session.InsecureSkipTLSVerify,
+	}
+	names := make(map[string]bool, len(session.Variables))
+	for name, value := range session.Variables {
+		result.Variables[name] = value
+		names[name] = true
+	}
+
+	sources := make(map[string]*correlationSource)
+	valueNames := make(map[string]string)
+	extractions := make(map[*Request][]extraction)
+	var sent strings.Builder
+
+	for _, group := range session.Groups {
+		converted := &Group{Name: group.Name}
+		for _, original := range group.Requests {
+			req := cloneRequest(original)
+			for _, value := range sortedByLength(sources) {
+				if !req.replace(value, func() string {
+					if valueNames[value] == "" {
+						valueNames[value] = uniqueName(variableName(sources[value].path), names)
+					}
+					return "{{" + valueNames[value] + "}}"
+				}) {
+					continue
+				}
+				if src := sources[value]; !src.extracted {
+					src.extracted = true
+					extractions[src.req] = append(extractions[src.req],
+						extraction{name: valueNames[value], path: src.path})
+				}
+			}
+
+			req.visit(func(s string) {
+				sent.WriteString(s)
+				sent.WriteByte('\n')
+			})
+			if req.Response != nil {
+				for value, path := range jsonStringValues(req.Response.Body) {
+					if valueNames[value] != "" || !strings.Contains(sent.String(), value) {
+						sources[value] = &correlationSource{req: req, path: path}
+					}
+				}
+			}
+			converted.Requests = append(converted.Requests, req)
+		}
+		result.Groups = append(result.Groups, converted)
+	}
+	return result, extractions
+}
+
+func cloneRequest(req *Request) *Request {
+	clone := *req
+	clone.Headers = append([]Header(nil), req.Headers...)
+	return &clone
+}
+
+// replace replaces all occurrences of the value in the URL, the header values
+// and the body of the request, and returns whether there were any.
+func (r *Request) replace(value string, placeholder func() string) bool {
+	replaced := false
+	replace := func(s *string) {
+		if strings.Contains(*s, value) {
+			*s = strings.ReplaceAll(*s, value, placeholder())
+			replaced = true
+		}
+	}
+	replace(&r.URL)
+	for i := range r.Headers {
+		replace(&r.Headers[i].Value)
+	}
+	replace(&r.Body)
+	return replaced
+}
+
+// visit calls fn with the URL, the header values and the body of the request.
+func (r *Request) visit(fn func(string)) {
+	fn(r.URL)
+	for _, h := range r.Headers {
+		fn(h.Value)
+	}
+	fn(r.Body)
+}
+
+// sortedByLength returns the values of the sources from the longest to the
+// shortest, so that a value that is a part of another one is replaced last.
+func sortedByLength(sources map[string]*correlationSource) []string {
+	values := make([]string, 0, len(sources))
+	for value := range sources {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+	return values
+}
+
+// jsonStringValues returns the string values of a JSON document with their
+// gjson paths. It returns nil if the body isn't JSON.
+func jsonStringValues(body string) map[string]string {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		return nil
+	}
+	values := make(map[string]string)
+	var walk func(v interface{}, path string)
+	walk = func(v interface{}, path string) {
+		join := func(key string) string {
+			if path == "" {
+				return key
+			}
+			return path + "." + key
+		}
+		switch v := v.(type) {
+		case string:
+			if _, seen := values[v]; !seen && len(v) >= minCorrelatedLength && path != "" {
+				values[v] = path
+			}
+		case []interface{}:
+			for i, item := range v {
+				walk(item, join(strconv.Itoa(i)))
+			}
+		case map[string]interface{}:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				walk(v[key], join(escapeGJSONKey(key)))
+			}
+		}
+	}
+	walk(doc, "")
+	return values
+}
+
+func escapeGJSONKey(key string) string {
+	var b strings.Builder
+	for _, c := range key {
+		if strings.ContainsRune(`.*?|#@\!=<>%`, c) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// variableName returns a JavaScript identifier based on the last key of a
+// gjson path that isn't an array index.
+func variableName(path string) string {
+	keys := strings.Split(strings.ReplaceAll(path, `\.`, "_"), ".")
+	key := ""
+	for i := len(keys) - 1; i >= 0 && key == ""; i-- {
+		if _, err := strconv.Atoi(keys[i]); err != nil {
+			key = keys[i]
+		}
+	}
+
+	return identifier(key, "value")
+}
+
+// identifier returns a camel case JavaScript identifier based on s, which
+// starts with the prefix if s doesn't start with a letter.
+func identifier(s, prefix string) string {
+	var b strings.Builder
+	upper := false
+	for _, c := range s {
+		if c >= unicode.MaxASCII || (!unicode.IsLetter(c) && !unicode.IsDigit(c)) {
+			upper = true
+			continue
+		}
+		if upper && b.Len() > 0 {
+			c = unicode.ToUpper(c)
+		}
+		b.WriteRune(c)
+		upper = false
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = prefix + name
+	}
+	return name
+}
+
+// uniqueName returns the name, or the name with a number suffix if it's
+// already used.
+func uniqueName(name string, names map[string]bool) string {
+	unique := name
+	for i := 2; names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	names[unique] = true
+	return unique
+}
@@ -0,0 +1,301 @@
+package converter
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// ParseCurl parses one or more curl command lines, as they are copied from the
+// browser developer tools. Every command becomes a request of a single
+// unnamed group.
+func ParseCurl(r io.Reader) (*Session, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the curl commands: %w", err)
+	}
+	commands, err := splitShellWords(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse the curl commands: %w", err)
+	}
+
+	session := &Session{}
+	group := &Group{}
+	for _, args := range commands {
+		if len(args) == 0 {
+			continue
+		}
+		if args[0] != "curl" {
+			return nil, fmt.Errorf("%q isn't a curl command", args[0])
+		}
+		req, err := newCurlRequest(session, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		group.Requests = append(group.Requests, req)
+	}
+	if len(group.Requests) == 0 {
+		return nil, errors.New("there are no curl commands")
+	}
+	session.Groups = append(session.Groups, group)
+	return session, nil
+}
+
+// curlIgnoredFlags are the curl flags that don't change the request, along with
+// whether they take a value.
+//
+//nolint:gochecknoglobals
+var curlIgnoredFlags = map[string]bool{
+	"-s": false, "--silent": false,
+	"-S": false, "--show-error": false,
+	"-v": false, "--verbose": false,
+	"-i": false, "--include": false,
+	"-L": false, "--location": false,
+	"--compressed": false,
+	"-f":           false, "--fail": false,
+	"-o": true, "--output": true,
+	"-m": true, "--max-time": true,
+	"--connect-timeout": true,
+}
+
+//nolint:cyclop,funlen
+func newCurlRequest(session *Session, args []string) (*Request, error) {
+	req := &Request{}
+	var data []string
+	var get, head bool
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			if req.URL != "" {
+				return nil, fmt.Errorf("curl commands with multiple URLs aren't supported, got %q", arg)
+			}
+			req.URL = arg
+			continue
+		}
+
+		flag, value, hasValue := arg, "", false
+		if strings.HasPrefix(flag, "--") {
+			if name, v, ok := strings.Cut(flag, "="); ok {
+				flag, value, hasValue = name, v, true
+			}
+		} else if len(flag) > 2 {
+			// The value of a short flag can be attached to it, like -XPOST.
+			flag, value, hasValue = flag[:2], flag[2:], true
+		}
+		nextValue := func() (string, error) {
+			if hasValue {
+				return value, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("the curl flag %s needs a value", flag)
+			}
+			i++
+			return args[i], nil
+		}
+
+		if takesValue, ok := curlIgnoredFlags[flag]; ok {
+			if takesValue {
+				if _, err := nextValue(); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		switch flag {
+		case "-G", "--get":
+			get = true
+			continue
+		case "-I", "--head":
+			head = true
+			continue
+		case "-k", "--insecure":
+			session.InsecureSkipTLSVerify = true
+			continue
+		}
+
+		v, err := nextValue()
+		if err != nil {
+			return nil, err
+		}
+		switch flag {
+		case "-X", "--request":
+			req.Method = strings.ToUpper(v)
+		case "--url":
+			req.URL = v
+		case "-H", "--header":
+			name, value, ok := strings.Cut(v, ":")
+			if !ok {
+				return nil, fmt.Errorf("invalid curl header %q", v)
+			}
+			name = strings.TrimSpace(name)
+			if !isIgnoredHeader(name) {
+				req.Headers = append(req.Headers, Header{Name: name, Value: strings.TrimSpace(value)})
+			}
+		case "-d", "--data", "--data-raw", "--data-binary", "--data-ascii":
+			if strings.HasPrefix(v, "@") && flag != "--data-raw" {
+				return nil, fmt.Errorf("reading the curl data from the file %q isn't supported", v[1:])
+			}
+			data = append(data, v)
+		case "--data-urlencode":
+			if name, value, ok := strings.Cut(v, "="); ok {
+				data = append(data, name+"="+url.QueryEscape(value))
+			} else {
+				data = append(data, url.QueryEscape(v))
+			}
+		case "-u", "--user":
+			credentials := base64.StdEncoding.EncodeToString([]byte(v))
+			req.Headers = append(req.Headers, Header{Name: "Authorization", Value: "Basic " + credentials})
+		case "-b", "--cookie":
+			if !strings.Contains(v, "=") {
+				return nil, fmt.Errorf("reading the curl cookies from the file %q isn't supported", v)
+			}
+			req.Headers = append(req.Headers, Header{Name: "Cookie", Value: v})
+		case "-A", "--user-agent":
+			req.Headers = append(req.Headers, Header{Name: "User-Agent", Value: v})
+		case "-e", "--referer":
+			req.Headers = append(req.Headers, Header{Name: "Referer", Value: v})
+		default:
+			return nil, fmt.Errorf("the curl flag %s isn't supported", flag)
+		}
+	}
+
+	if req.URL == "" {
+		return nil, errors.New("the curl command has no URL")
+	}
+	if !strings.Contains(req.URL, "://") {
+		req.URL = "http://" + req.URL
+	}
+	body := strings.Join(data, "&")
+	switch {
+	case get && body != "":
+		sep := "?"
+		if strings.Contains(req.URL, "?") {
+			sep = "&"
+		}
+		req.URL += sep + body
+	case body != "":
+		req.Body = body
+		if !hasHeader(req.Headers, "Content-Type") {
+			req.Headers = append(req.Headers, Header{Name: "Content-Type", Value: "application/x-www-form-urlencoded"})
+		}
+	}
+	if req.Method == "" {
+		switch {
+		case head:
+			req.Method = "HEAD"
+		case req.Body != "":
+			req.Method = "POST"
+		default:
+			req.Method = "GET"
+		}
+	}
+	return req, nil
+}
+
+//nolint:gochecknoglobals
+var ansiCEscapes = map[rune]rune{
+	'n': '\n', 'r': '\r', 't': '\t', '\\': '\\', '\'': '\'', '"': '"',
+}
+
+// splitShellWords splits the input to commands and their words, following
+// the quoting rules of a POSIX shell. The commands are separated by new lines
+// or semicolons, and backslash-escaped new lines continue a command.
+//
+//nolint:cyclop
+func splitShellWords(input string) ([][]string, error) {
+	var (
+		commands [][]string
+		words    []string
+		word     strings.Builder
+		inWord   bool
+	)
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	endCommand := func() {
+		endWord()
+		if len(words) > 0 {
+			commands = append(commands, words)
+			words = nil
+		}
+	}
+
+	runes := []rune(input)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == '\\':
+			if i+1 >= len(runes) {
+				return nil, errors.New("unexpected backslash at the end")
+			}
+			i++
+			if runes[i] == '\n' || (runes[i] == '\r' && i+1 < len(runes) && runes[i+1] == '\n') {
+				if runes[i] == '\r' {
+					i++
+				}
+				endWord()
+				continue
+			}
+			word.WriteRune(runes[i])
+			inWord = true
+		case c == '\'':
+			end := strings.IndexRune(string(runes[i+1:]), '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated single quote")
+			}
+			quoted := []rune(string(runes[i+1:])[:end])
+			word.WriteString(string(quoted))
+			i += len(quoted) + 1
+			inWord = true
+		case c == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+				}
+				word.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, errors.New("unterminated double quote")
+			}
+			inWord = true
+		case c == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			// The ANSI-C quoting, which the browsers use for the bodies with
+			// special characters.
+			for i += 2; i < len(runes) && runes[i] != '\''; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					if escaped, ok := ansiCEscapes[runes[i]]; ok {
+						word.WriteRune(escaped)
+						continue
+					}
+					word.WriteRune('\\')
+				}
+				word.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, errors.New("unterminated single quote")
+			}
+			inWord = true
+		case c == '\n' || c == ';':
+			endCommand()
+		case c == ' ' || c == '\t' || c == '\r':
+			endWord()
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+	endCommand()
+	return commands, nil
+}
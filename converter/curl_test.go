@@ -0,0 +1,116 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCurl(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		commands string
+		expected []*Request
+		insecure bool
+	}{
+		{
+			name:     "get",
+			commands: `curl https://example.com/`,
+			expected: []*Request{{Method: "GET", URL: "https://example.com/"}},
+		},
+		{
+			name: "browser copy",
+			commands: "curl 'https://example.com/api' \\\n" +
+				"  -H 'accept: application/json' \\\n" +
+				"  -H 'accept-encoding: gzip' \\\n" +
+				"  -H \"x-quote: \\\"a\\\"\" \\\n" +
+				"  --data-raw $'{\"note\":\"it\\'s\\n\"}' \\\n" +
+				"  --compressed",
+			expected: []*Request{{
+				Method: "POST",
+				URL:    "https://example.com/api",
+				Headers: []Header{
+					{Name: "accept", Value: "application/json"},
+					{Name: "x-quote", Value: `"a"`},
+					{Name: "Content-Type", Value: "application/x-www-form-urlencoded"},
+				},
+				Body: "{\"note\":\"it's\n\"}",
+			}},
+		},
+		{
+			name:     "data",
+			commands: `curl -XPUT example.com -d a=1 --data-urlencode 'b=x y' -u user:pass -A k6 -e ref -b c=1 -k`,
+			expected: []*Request{{
+				Method: "PUT",
+				URL:    "http://example.com",
+				Headers: []Header{
+					{Name: "Authorization", Value: "Basic dXNlcjpwYXNz"},
+					{Name: "User-Agent", Value: "k6"},
+					{Name: "Referer", Value: "ref"},
+					{Name: "Cookie", Value: "c=1"},
+					{Name: "Content-Type", Value: "application/x-www-form-urlencoded"},
+				},
+				Body: "a=1&b=x+y",
+			}},
+			insecure: true,
+		},
+		{
+			name:     "get with data",
+			commands: `curl -G --url 'https://example.com/?a=1' -d b=2 -s -o /dev/null`,
+			expected: []*Request{{Method: "GET", URL: "https://example.com/?a=1&b=2"}},
+		},
+		{
+			name:     "multiple",
+			commands: "curl -I https://example.com/a\n\ncurl -X delete https://example.com/b; curl https://example.com/c",
+			expected: []*Request{
+				{Method: "HEAD", URL: "https://example.com/a"},
+				{Method: "DELETE", URL: "https://example.com/b"},
+				{Method: "GET", URL: "https://example.com/c"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			session, err := ParseCurl(strings.NewReader(tc.commands))
+			require.NoError(t, err)
+			require.Len(t, session.Groups, 1)
+			assert.Equal(t, tc.expected, session.Groups[0].Requests)
+			assert.Equal(t, tc.insecure, session.InsecureSkipTLSVerify)
+		})
+	}
+}
+
+func TestParseCurlErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		commands, err string
+	}{
+		{commands: "", err: "there are no curl commands"},
+		{commands: "wget https://example.com", err: `"wget" isn't a curl command`},
+		{commands: "curl 'https://example.com", err: "unterminated single quote"},
+		{commands: "curl -X", err: "the curl flag -X needs a value"},
+		{commands: "curl --http3 https://example.com", err: "the curl flag --http3 isn't supported"},
+		{commands: "curl -d @body.json https://example.com", err: `reading the curl data from the file "body.json"`},
+		{commands: "curl -H", err: "needs a value"},
+		{commands: "curl -s", err: "the curl command has no URL"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.commands, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParseCurl(strings.NewReader(tc.commands))
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
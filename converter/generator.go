@@ -0,0 +1,318 @@
+package converter
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// placeholderRegexp matches the {{name}} placeholders of the variables.
+var placeholderRegexp = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`) //nolint:gochecknoglobals
+
+// identifierRegexp matches the names that can be used as JavaScript property
+// names without quoting.
+var identifierRegexp = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`) //nolint:gochecknoglobals
+
+// Generate writes a k6 script that makes the requests of the session.
+func Generate(w io.Writer, session *Session, opts Options) error {
+	session = opts.filter(session)
+	if len(session.Groups) == 0 {
+		return errors.New("there are no requests to convert")
+	}
+	var extractions map[*Request][]extraction
+	if opts.Correlate {
+		session, extractions = correlate(session)
+	}
+
+	g := &generator{
+		w:           bufio.NewWriter(w),
+		session:     session,
+		opts:        opts,
+		extractions: extractions,
+		variables:   make(map[string]bool),
+	}
+	for name := range session.Variables {
+		g.variables[name] = true
+	}
+	for _, list := range extractions {
+		for _, e := range list {
+			g.variables[e.name] = true
+		}
+	}
+	g.defaultHeaders = commonHeaders(session)
+
+	g.writeScript()
+	return g.w.Flush()
+}
+
+type generator struct {
+	w              *bufio.Writer
+	session        *Session
+	opts           Options
+	extractions    map[*Request][]extraction
+	variables      map[string]bool
+	defaultHeaders []Header
+}
+
+func (g *generator) printf(indent int, format string, args ...interface{}) {
+	if format != "" {
+		g.w.WriteString(strings.Repeat("  ", indent))
+		fmt.Fprintf(g.w, format, args...)
+	}
+	g.w.WriteByte('\n')
+}
+
+func (g *generator) writeScript() {
+	g.printf(0, "import http from 'k6/http';")
+	g.printf(0, "import { %s } from 'k6';", strings.Join(g.imports(), ", "))
+	g.printf(0, "")
+
+	if g.session.InsecureSkipTLSVerify {
+		g.printf(0, "export const options = {")
+		g.printf(1, "insecureSkipTLSVerify: true,")
+		g.printf(0, "};")
+		g.printf(0, "")
+	}
+
+	if len(g.defaultHeaders) > 0 {
+		g.printf(0, "const defaultHeaders = {")
+		for _, h := range g.defaultHeaders {
+			g.printf(1, "%s: %s,", g.propertyName(h.Name), g.stringLiteral(h.Value))
+		}
+		g.printf(0, "};")
+		g.printf(0, "")
+	}
+
+	g.printf(0, "export default function () {")
+	switch {
+	case len(g.session.Variables) > 0:
+		g.printf(1, "const vars = {")
+		names := make([]string, 0, len(g.session.Variables))
+		for name := range g.session.Variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			g.printf(2, "%s: %s,", g.propertyName(name), g.stringLiteral(g.session.Variables[name]))
+		}
+		g.printf(1, "};")
+	case len(g.variables) > 0:
+		g.printf(1, "const vars = {};")
+	}
+	g.printf(1, "let res;")
+
+	for _, group := range g.session.Groups {
+		g.printf(0, "")
+		if group.Name == "" {
+			for i, req := range group.Requests {
+				if i > 0 {
+					g.printf(0, "")
+				}
+				g.writeRequest(1, req)
+			}
+			continue
+		}
+		g.printf(1, "group(%s, () => {", g.stringLiteral(group.Name))
+		for i, req := range group.Requests {
+			if i > 0 {
+				g.printf(0, "")
+			}
+			g.writeRequest(2, req)
+		}
+		g.printf(1, "});")
+	}
+
+	g.printf(0, "")
+	g.printf(1, "sleep(1);")
+	g.printf(0, "}")
+}
+
+// imports returns the functions of the k6 module that the script uses.
+func (g *generator) imports() []string {
+	var hasCheck, hasGroup bool
+	for _, group := range g.session.Groups {
+		hasGroup = hasGroup || group.Name != ""
+		for _, req := range group.Requests {
+			hasCheck = hasCheck || (req.Response != nil && !g.opts.NoChecks)
+		}
+	}
+
+	var imports []string
+	if hasCheck {
+		imports = append(imports, "check")
+	}
+	if hasGroup {
+		imports = append(imports, "group")
+	}
+	return append(imports, "sleep")
+}
+
+func (g *generator) writeRequest(indent int, req *Request) {
+	url := g.stringLiteral(req.URL)
+	params := g.params(indent, req)
+	body := "null"
+	if req.Body != "" {
+		body = g.stringLiteral(req.Body)
+	}
+
+	var call string
+	switch method := strings.ToUpper(req.Method); {
+	case (method == "GET" || method == "HEAD") && req.Body == "":
+		call = fmt.Sprintf("http.%s(%s", strings.ToLower(method), url)
+	case method == "POST" || method == "PUT" || method == "PATCH" || method == "OPTIONS":
+		call = fmt.Sprintf("http.%s(%s, %s", strings.ToLower(method), url, body)
+	case method == "DELETE":
+		call = fmt.Sprintf("http.del(%s, %s", url, body)
+	default:
+		call = fmt.Sprintf("http.request(%s, %s, %s", g.stringLiteral(method), url, body)
+	}
+	if params == "" {
+		g.printf(indent, "res = %s);", call)
+	} else {
+		g.printf(indent, "res = %s, %s);", call, params)
+	}
+
+	if req.Response != nil && !g.opts.NoChecks {
+		g.printf(indent, "check(res, {")
+		g.printf(indent+1, "'status is %d': (r) => r.status === %d,", req.Response.Status, req.Response.Status)
+		g.printf(indent, "});")
+	}
+	for _, e := range g.extractions[req] {
+		g.printf(indent, "%s = res.json(%s);", g.variable(e.name), g.stringLiteral(e.path))
+	}
+}
+
+// params returns the params object of the request, or an empty string if it
+// doesn't need one.
+func (g *generator) params(indent int, req *Request) string {
+	var headers []Header
+	for _, h := range req.Headers {
+		if !containsHeader(g.defaultHeaders, h) {
+			headers = append(headers, h)
+		}
+	}
+	if len(headers) == 0 {
+		if len(g.defaultHeaders) == 0 {
+			return ""
+		}
+		return "{ headers: defaultHeaders }"
+	}
+
+	var b strings.Builder
+	b.WriteString("{\n")
+	b.WriteString(strings.Repeat("  ", indent+1) + "headers: {\n")
+	if len(g.defaultHeaders) > 0 {
+		b.WriteString(strings.Repeat("  ", indent+2) + "...defaultHeaders,\n")
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&b, "%s%s: %s,\n", strings.Repeat("  ", indent+2), g.propertyName(h.Name), g.stringLiteral(h.Value))
+	}
+	b.WriteString(strings.Repeat("  ", indent+1) + "},\n")
+	b.WriteString(strings.Repeat("  ", indent) + "}")
+	return b.String()
+}
+
+func (g *generator) variable(name string) string {
+	if identifierRegexp.MatchString(name) {
+		return "vars." + name
+	}
+	return "vars[" + g.stringLiteral(name) + "]"
+}
+
+func (g *generator) propertyName(name string) string {
+	if identifierRegexp.MatchString(name) {
+		return name
+	}
+	return QuoteJS(name)
+}
+
+// stringLiteral returns a JavaScript string literal of s, which is a template
+// literal if s has placeholders for the known variables.
+func (g *generator) stringLiteral(s string) string {
+	matches := placeholderRegexp.FindAllStringSubmatchIndex(s, -1)
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		name := s[m[2]:m[3]]
+		if !g.variables[name] {
+			continue
+		}
+		b.WriteString(strings.ReplaceAll(quote(s[last:m[0]], '`'), "${", `\${`))
+		b.WriteString("${" + g.variable(name) + "}")
+		last = m[1]
+	}
+	if last == 0 {
+		return QuoteJS(s)
+	}
+	b.WriteString(strings.ReplaceAll(quote(s[last:], '`'), "${", `\${`))
+	return "`" + b.String() + "`"
+}
+
+// QuoteJS returns a single-quoted JavaScript string literal of s.
+func QuoteJS(s string) string {
+	return "'" + quote(s, '\'') + "'"
+}
+
+// quote escapes s for a JavaScript string literal with the given delimiter,
+// without adding the delimiters.
+func quote(s string, delimiter rune) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch {
+		case c == '\\' || c == delimiter:
+			b.WriteByte('\\')
+			b.WriteRune(c)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c < 0x20 || c == 0x7f || c == '\u2028' || c == '\u2029':
+			fmt.Fprintf(&b, `\u%04x`, c)
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// commonHeaders returns the headers that all requests of the session have, if
+// there is more than one request.
+func commonHeaders(session *Session) []Header {
+	var requests []*Request
+	for _, group := range session.Groups {
+		requests = append(requests, group.Requests...)
+	}
+	if len(requests) < 2 {
+		return nil
+	}
+
+	var common []Header
+	for _, h := range requests[0].Headers {
+		shared := true
+		for _, req := range requests[1:] {
+			if !containsHeader(req.Headers, h) {
+				shared = false
+				break
+			}
+		}
+		if shared && !containsHeader(common, h) {
+			common = append(common, h)
+		}
+	}
+	return common
+}
+
+func containsHeader(headers []Header, header Header) bool {
+	for _, h := range headers {
+		if strings.EqualFold(h.Name, header.Name) && h.Value == header.Value {
+			return true
+		}
+	}
+	return false
+}
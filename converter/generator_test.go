@@ -0,0 +1,179 @@
+package converter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	session := &Session{
+		Variables:             map[string]string{"baseUrl": "https://example.com", "api-key": "s3cr3t"},
+		InsecureSkipTLSVerify: true,
+		Groups: []*Group{
+			{
+				Requests: []*Request{{
+					Method:   "GET",
+					URL:      "{{baseUrl}}/",
+					Headers:  []Header{{Name: "User-Agent", Value: "browser"}},
+					Response: &Response{Status: 200},
+				}},
+			},
+			{
+				Name: "Orders",
+				Requests: []*Request{
+					{
+						Method: "POST",
+						URL:    "{{baseUrl}}/orders?id={{$guid}}",
+						Headers: []Header{
+							{Name: "user-agent", Value: "browser"},
+							{Name: "Content-Type", Value: "application/json"},
+							{Name: "X-Key", Value: "{{api-key}}"},
+						},
+						Body:     `{"note":"it's ${fine}"}`,
+						Response: &Response{Status: 201},
+					},
+					{Method: "DELETE", URL: "https://cdn.example.com/1", Headers: []Header{{Name: "User-Agent", Value: "browser"}}},
+					{Method: "PURGE", URL: "https://example.com/cache", Headers: []Header{{Name: "User-Agent", Value: "browser"}}},
+				},
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, Generate(&out, session, Options{Skip: []string{"cdn.example.com"}}))
+	assert.Equal(t, `import http from 'k6/http';
+import { check, group, sleep } from 'k6';
+
+export const options = {
+  insecureSkipTLSVerify: true,
+};
+
+const defaultHeaders = {
+  'User-Agent': 'browser',
+};
+
+export default function () {
+  const vars = {
+    'api-key': 's3cr3t',
+    baseUrl: 'https://example.com',
+  };
+  let res;
+
+  res = http.get(`+"`${vars.baseUrl}/`"+`, { headers: defaultHeaders });
+  check(res, {
+    'status is 200': (r) => r.status === 200,
+  });
+
+  group('Orders', () => {
+    res = http.post(`+"`${vars.baseUrl}/orders?id={{$guid}}`"+`, '{"note":"it\'s ${fine}"}', {
+      headers: {
+        ...defaultHeaders,
+        'Content-Type': 'application/json',
+        'X-Key': `+"`${vars['api-key']}`"+`,
+      },
+    });
+    check(res, {
+      'status is 201': (r) => r.status === 201,
+    });
+
+    res = http.request('PURGE', 'https://example.com/cache', null, { headers: defaultHeaders });
+  });
+
+  sleep(1);
+}
+`, out.String())
+}
+
+func TestGenerateCorrelate(t *testing.T) {
+	t.Parallel()
+
+	session := &Session{Groups: []*Group{{Requests: []*Request{
+		{
+			Method: "POST",
+			URL:    "https://example.com/login",
+			Body:   `{"user":"admin123"}`,
+			Response: &Response{
+				Status: 200,
+				Body:   `{"user":"admin123","auth":{"access-token":"tok3n-1"},"items":[{"id":"item-42"}]}`,
+			},
+		},
+		{
+			Method:   "GET",
+			URL:      "https://example.com/items/item-42",
+			Headers:  []Header{{Name: "Authorization", Value: "Bearer tok3n-1"}},
+			Response: &Response{Status: 200, Body: `{"auth":{"access-token":"tok3n-2"}}`},
+		},
+		{
+			Method:  "GET",
+			URL:     "https://example.com/items/item-42?user=admin123",
+			Headers: []Header{{Name: "Authorization", Value: "Bearer tok3n-2"}},
+		},
+	}}}}
+
+	var out bytes.Buffer
+	require.NoError(t, Generate(&out, session, Options{Correlate: true, NoChecks: true}))
+	script := out.String()
+
+	assert.Contains(t, script, "import { sleep } from 'k6';\n")
+	assert.Contains(t, script, "  const vars = {};\n")
+	assert.Contains(t, script, "res = http.post('https://example.com/login', '{\"user\":\"admin123\"}');\n"+
+		"  vars.id = res.json('items.0.id');\n"+
+		"  vars.accessToken = res.json('auth.access-token');\n")
+	assert.Contains(t, script, "res = http.get(`https://example.com/items/${vars.id}`, {\n"+
+		"    headers: {\n"+
+		"      Authorization: `Bearer ${vars.accessToken}`,\n"+
+		"    },\n"+
+		"  });\n"+
+		"  vars.accessToken2 = res.json('auth.access-token');\n")
+	assert.Contains(t, script, "http.get(`https://example.com/items/${vars.id}?user=admin123`, {\n"+
+		"    headers: {\n"+
+		"      Authorization: `Bearer ${vars.accessToken2}`,\n")
+	assert.NotContains(t, script, "check(")
+
+	// The correlation works on a copy of the session.
+	assert.Equal(t, "https://example.com/items/item-42", session.Groups[0].Requests[1].URL)
+}
+
+func TestGenerateNoRequests(t *testing.T) {
+	t.Parallel()
+
+	session := &Session{Groups: []*Group{{Requests: []*Request{{Method: "GET", URL: "https://example.com/"}}}}}
+	err := Generate(&bytes.Buffer{}, session, Options{Only: []string{"test.k6.io"}})
+	assert.ErrorContains(t, err, "there are no requests to convert")
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name, input, format, err string
+	}{
+		{name: "har", input: "\uFEFF" + testHAR},
+		{name: "postman", input: testPostmanCollection},
+		{name: "curl", input: "  curl https://example.com"},
+		{name: "explicit", input: "curl https://example.com", format: FormatCurl},
+		{name: "unknown", input: `{"foo": "bar"}`, err: "couldn't detect the format"},
+		{name: "unsupported", input: `{}`, format: "insomnia", err: `unsupported format "insomnia"`},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			session, err := Parse(strings.NewReader(tc.input), tc.format)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, session.Groups)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package converter
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"go.k6.io/k6/lib/har"
+)
+
+// ParseHAR parses a HAR file. The entries are grouped by the pages they
+// belong to, and they are sorted by the time they were started, since the
+// browsers don't always record them in order.
+func ParseHAR(r io.Reader) (*Session, error) {
+	var archive har.HAR
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("couldn't parse the HAR file: %w", err)
+	}
+	if archive.Log == nil {
+		return nil, errors.New("couldn't parse the HAR file: there is no log")
+	}
+
+	entries := make([]*har.Entry, 0, len(archive.Log.Entries))
+	for _, entry := range archive.Log.Entries {
+		if entry != nil && entry.Request != nil {
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartedDateTime.Before(entries[j].StartedDateTime)
+	})
+
+	pageTitles := make(map[string]string, len(archive.Log.Pages))
+	for _, page := range archive.Log.Pages {
+		pageTitles[page.ID] = page.Title
+	}
+
+	session := &Session{}
+	var group *Group
+	for _, entry := range entries {
+		name := pageTitles[entry.PageRef]
+		if name == "" {
+			name = entry.PageRef
+		}
+		if group == nil || group.Name != name {
+			group = &Group{Name: name}
+			session.Groups = append(session.Groups, group)
+		}
+		group.Requests = append(group.Requests, newHARRequest(entry))
+	}
+	return session, nil
+}
+
+func newHARRequest(entry *har.Entry) *Request {
+	req := &Request{
+		Method: entry.Request.Method,
+		URL:    entry.Request.URL,
+	}
+	for _, header := range entry.Request.Headers {
+		if !isIgnoredHeader(header.Name) {
+			req.Headers = append(req.Headers, Header{Name: header.Name, Value: header.Value})
+		}
+	}
+	if entry.Request.PostData != nil {
+		req.Body = entry.Request.PostData.Text
+	}
+
+	// A status of 0 means that the request failed or was blocked.
+	if resp := entry.Response; resp != nil && resp.Status > 0 {
+		req.Response = &Response{
+			Status:      resp.Status,
+			ContentType: resp.Content.MimeType,
+			Body:        resp.Content.Text,
+		}
+		if resp.Content.Encoding == "base64" {
+			body, err := base64.StdEncoding.DecodeString(resp.Content.Text)
+			if err == nil {
+				req.Response.Body = string(body)
+			}
+		}
+	}
+	return req
+}
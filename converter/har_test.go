@@ -0,0 +1,91 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testHAR = `{
+  "log": {
+    "version": "1.2",
+    "creator": {"name": "test", "version": "1.0"},
+    "pages": [
+      {"startedDateTime": "2024-01-01T00:00:00Z", "id": "page_1", "title": "Login"},
+      {"startedDateTime": "2024-01-01T00:00:05Z", "id": "page_2", "title": ""}
+    ],
+    "entries": [
+      {
+        "pageref": "page_1",
+        "startedDateTime": "2024-01-01T00:00:01Z",
+        "request": {
+          "method": "POST",
+          "url": "https://example.com/login",
+          "headers": [
+            {"name": ":authority", "value": "example.com"},
+            {"name": "Content-Type", "value": "application/json"},
+            {"name": "Content-Length", "value": "17"}
+          ],
+          "postData": {"mimeType": "application/json", "text": "{\"user\":\"admin\"}"}
+        },
+        "response": {
+          "status": 200,
+          "content": {"mimeType": "application/json", "text": "eyJ0b2tlbiI6ImFiY2RlZjEyMyJ9", "encoding": "base64"}
+        }
+      },
+      {
+        "pageref": "page_1",
+        "startedDateTime": "2024-01-01T00:00:00Z",
+        "request": {"method": "GET", "url": "https://example.com/"},
+        "response": {"status": 0, "content": {}}
+      },
+      {
+        "pageref": "page_2",
+        "startedDateTime": "2024-01-01T00:00:06Z",
+        "request": {"method": "GET", "url": "https://example.com/profile"},
+        "response": {"status": 200, "content": {"mimeType": "text/html", "text": "<html></html>"}}
+      }
+    ]
+  }
+}`
+
+func TestParseHAR(t *testing.T) {
+	t.Parallel()
+
+	session, err := ParseHAR(strings.NewReader(testHAR))
+	require.NoError(t, err)
+
+	require.Len(t, session.Groups, 2)
+	assert.Equal(t, "Login", session.Groups[0].Name)
+	assert.Equal(t, "page_2", session.Groups[1].Name)
+
+	login := session.Groups[0].Requests
+	require.Len(t, login, 2)
+	assert.Equal(t, &Request{Method: "GET", URL: "https://example.com/"}, login[0])
+	assert.Equal(t, &Request{
+		Method:  "POST",
+		URL:     "https://example.com/login",
+		Headers: []Header{{Name: "Content-Type", Value: "application/json"}},
+		Body:    `{"user":"admin"}`,
+		Response: &Response{
+			Status:      200,
+			ContentType: "application/json",
+			Body:        `{"token":"abcdef123"}`,
+		},
+	}, login[1])
+
+	require.Len(t, session.Groups[1].Requests, 1)
+	assert.Equal(t, "<html></html>", session.Groups[1].Requests[0].Response.Body)
+}
+
+func TestParseHARErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseHAR(strings.NewReader(`{"log":`))
+	assert.ErrorContains(t, err, "couldn't parse the HAR file")
+
+	_, err = ParseHAR(strings.NewReader(`{}`))
+	assert.ErrorContains(t, err, "there is no log")
+}
@@ -0,0 +1,656 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// API is an HTTP API described by an OpenAPI 3 spec.
+type API struct {
+	Title string
+	// BaseURL is the URL of the first server of the spec.
+	BaseURL    string
+	Operations []*Operation
+	// TotalWeight is the sum of the weights of the operations.
+	TotalWeight float64
+}
+
+// Operation is a single operation of an API, with the example values that a
+// request for it uses.
+type Operation struct {
+	// Name is a JavaScript identifier that is unique in the API, based on the
+	// operationId if there is one.
+	Name    string
+	Method  string
+	Path    string
+	Summary string
+	// URL is the path with example values for the path parameters and the
+	// required query parameters.
+	URL     string
+	Headers []Header
+	// ContentType and Body are the media type and the example value of the
+	// request body, if the operation has one.
+	ContentType string
+	Body        interface{}
+	// Status is the status of the expected response, or 0 if any 2xx status
+	// is expected.
+	Status int
+	// ResponseSchema is the JSON schema of the expected response body, with
+	// all references resolved, if it's a JSON body.
+	ResponseSchema map[string]interface{}
+	// Weight is the relative frequency of the operation, which is set with the
+	// x-k6-weight extension and is 1 by default.
+	Weight float64
+}
+
+// The types below are the parts of the OpenAPI 3 spec that are used, as
+// described in https://spec.openapis.org/oas/v3.1.0
+type (
+	openAPIDocument struct {
+		OpenAPI string `yaml:"openapi"`
+		Info    struct {
+			Title string `yaml:"title"`
+		} `yaml:"info"`
+		Servers []struct {
+			URL string `yaml:"url"`
+		} `yaml:"servers"`
+		// Paths is decoded later, to keep the order of the paths.
+		Paths      yaml.Node `yaml:"paths"`
+		Components struct {
+			Schemas       map[string]*openAPISchema      `yaml:"schemas"`
+			Parameters    map[string]*openAPIParameter   `yaml:"parameters"`
+			RequestBodies map[string]*openAPIRequestBody `yaml:"requestBodies"`
+			Responses     map[string]*openAPIResponse    `yaml:"responses"`
+		} `yaml:"components"`
+	}
+
+	openAPIPathItem struct {
+		Parameters []*openAPIParameter `yaml:"parameters"`
+		Get        *openAPIOperation   `yaml:"get"`
+		Put        *openAPIOperation   `yaml:"put"`
+		Post       *openAPIOperation   `yaml:"post"`
+		Delete     *openAPIOperation   `yaml:"delete"`
+		Options    *openAPIOperation   `yaml:"options"`
+		Head       *openAPIOperation   `yaml:"head"`
+		Patch      *openAPIOperation   `yaml:"patch"`
+	}
+
+	openAPIOperation struct {
+		OperationID string                      `yaml:"operationId"`
+		Summary     string                      `yaml:"summary"`
+		Parameters  []*openAPIParameter         `yaml:"parameters"`
+		RequestBody *openAPIRequestBody         `yaml:"requestBody"`
+		Responses   map[string]*openAPIResponse `yaml:"responses"`
+		Weight      *float64                    `yaml:"x-k6-weight"`
+	}
+
+	openAPIParameter struct {
+		Ref      string                     `yaml:"$ref"`
+		Name     string                     `yaml:"name"`
+		In       string                     `yaml:"in"`
+		Required bool                       `yaml:"required"`
+		Schema   *openAPISchema             `yaml:"schema"`
+		Example  interface{}                `yaml:"example"`
+		Examples map[string]*openAPIExample `yaml:"examples"`
+	}
+
+	openAPIRequestBody struct {
+		Ref     string                       `yaml:"$ref"`
+		Content map[string]*openAPIMediaType `yaml:"content"`
+	}
+
+	openAPIResponse struct {
+		Ref     string                       `yaml:"$ref"`
+		Content map[string]*openAPIMediaType `yaml:"content"`
+	}
+
+	openAPIMediaType struct {
+		Schema   *openAPISchema             `yaml:"schema"`
+		Example  interface{}                `yaml:"example"`
+		Examples map[string]*openAPIExample `yaml:"examples"`
+	}
+
+	openAPIExample struct {
+		Value interface{} `yaml:"value"`
+	}
+
+	openAPISchema struct {
+		Ref        string                    `yaml:"$ref"`
+		Type       openAPISchemaType         `yaml:"type"`
+		Format     string                    `yaml:"format"`
+		Nullable   bool                      `yaml:"nullable"`
+		Properties map[string]*openAPISchema `yaml:"properties"`
+		Required   []string                  `yaml:"required"`
+		Items      *openAPISchema            `yaml:"items"`
+		Enum       []interface{}             `yaml:"enum"`
+		AllOf      []*openAPISchema          `yaml:"allOf"`
+		OneOf      []*openAPISchema          `yaml:"oneOf"`
+		AnyOf      []*openAPISchema          `yaml:"anyOf"`
+		Example    interface{}               `yaml:"example"`
+		Examples   []interface{}             `yaml:"examples"`
+		Default    interface{}               `yaml:"default"`
+		Minimum    *float64                  `yaml:"minimum"`
+	}
+
+	// openAPISchemaType is the type of a schema, which is a list of types in
+	// OpenAPI 3.1, where "null" is one of them for the nullable schemas.
+	openAPISchemaType []string
+)
+
+// UnmarshalYAML supports both a single type and a list of types.
+func (t *openAPISchemaType) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = []string{node.Value}
+		return nil
+	}
+	return node.Decode((*[]string)(t))
+}
+
+// main returns the type of the schema that isn't "null", and whether "null"
+// is one of the types.
+func (t openAPISchemaType) main() (string, bool) {
+	main, nullable := "", false
+	for _, typ := range t {
+		if typ == "null" {
+			nullable = true
+		} else if main == "" {
+			main = typ
+		}
+	}
+	return main, nullable
+}
+
+// openAPIMethods are the HTTP methods of the operations of a path, in the
+// order they are converted.
+//
+//nolint:gochecknoglobals
+var openAPIMethods = []struct {
+	method    string
+	operation func(*openAPIPathItem) *openAPIOperation
+}{
+	{"GET", func(p *openAPIPathItem) *openAPIOperation { return p.Get }},
+	{"HEAD", func(p *openAPIPathItem) *openAPIOperation { return p.Head }},
+	{"OPTIONS", func(p *openAPIPathItem) *openAPIOperation { return p.Options }},
+	{"POST", func(p *openAPIPathItem) *openAPIOperation { return p.Post }},
+	{"PUT", func(p *openAPIPathItem) *openAPIOperation { return p.Put }},
+	{"PATCH", func(p *openAPIPathItem) *openAPIOperation { return p.Patch }},
+	{"DELETE", func(p *openAPIPathItem) *openAPIOperation { return p.Delete }},
+}
+
+// ParseOpenAPI parses an OpenAPI 3 spec in YAML or JSON. The operations are
+// in the order of their paths in the spec.
+func ParseOpenAPI(r io.Reader) (*API, error) {
+	var doc openAPIDocument
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("couldn't parse the OpenAPI spec: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q, only 3.x is supported", doc.OpenAPI)
+	}
+	if doc.Paths.Kind != yaml.MappingNode || len(doc.Paths.Content) == 0 {
+		return nil, errors.New("the OpenAPI spec has no paths")
+	}
+
+	api := &API{Title: doc.Info.Title}
+	if len(doc.Servers) > 0 {
+		api.BaseURL = strings.TrimSuffix(doc.Servers[0].URL, "/")
+	}
+
+	s := &openAPISpec{doc: &doc, names: make(map[string]bool)}
+	for i := 0; i+1 < len(doc.Paths.Content); i += 2 {
+		path := doc.Paths.Content[i].Value
+		var item openAPIPathItem
+		if err := doc.Paths.Content[i+1].Decode(&item); err != nil {
+			return nil, fmt.Errorf("couldn't parse the path %q: %w", path, err)
+		}
+		for _, m := range openAPIMethods {
+			operation := m.operation(&item)
+			if operation == nil {
+				continue
+			}
+			op, err := s.newOperation(m.method, path, item.Parameters, operation)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't convert the operation %s %s: %w", m.method, path, err)
+			}
+			api.Operations = append(api.Operations, op)
+			api.TotalWeight += op.Weight
+		}
+	}
+	if len(api.Operations) == 0 {
+		return nil, errors.New("the OpenAPI spec has no operations")
+	}
+	return api, nil
+}
+
+// openAPISpec resolves the references of a spec and converts its operations.
+type openAPISpec struct {
+	doc   *openAPIDocument
+	names map[string]bool
+}
+
+// maxSchemaDepth limits the depth of the example values of the recursive
+// schemas.
+const maxSchemaDepth = 8
+
+//nolint:gochecknoglobals
+var pathParameterRegexp = regexp.MustCompile(`\{([^{}]+)\}`)
+
+func (s *openAPISpec) newOperation(
+	method, path string, pathParams []*openAPIParameter, operation *openAPIOperation,
+) (*Operation, error) {
+	op := &Operation{Method: method, Path: path, Summary: operation.Summary, Weight: 1}
+	if operation.Weight != nil {
+		if *operation.Weight <= 0 {
+			return nil, fmt.Errorf("x-k6-weight should be positive, got %v", *operation.Weight)
+		}
+		op.Weight = *operation.Weight
+	}
+	name := operation.OperationID
+	if name == "" {
+		name = strings.ToLower(method) + " " + path
+	}
+	name = identifier(name, "operation")
+	if reservedWords[name] {
+		name += "Operation"
+	}
+	op.Name = uniqueName(name, s.names)
+
+	// The parameters of the operation override the ones of the path.
+	params := make(map[string]*openAPIParameter)
+	var order []string
+	for _, p := range append(append([]*openAPIParameter(nil), pathParams...), operation.Parameters...) {
+		p, err := s.parameter(p)
+		if err != nil {
+			return nil, err
+		}
+		key := p.In + ":" + p.Name
+		if _, ok := params[key]; !ok {
+			order = append(order, key)
+		}
+		params[key] = p
+	}
+
+	var query url.Values
+	op.URL = path
+	for _, key := range order {
+		p := params[key]
+		value := s.parameterExample(p)
+		switch {
+		case p.In == "path":
+			op.URL = strings.ReplaceAll(op.URL, "{"+p.Name+"}", url.PathEscape(fmt.Sprint(value)))
+		case p.In == "query" && p.Required:
+			if query == nil {
+				query = url.Values{}
+			}
+			if values, ok := value.([]interface{}); ok {
+				for _, v := range values {
+					query.Add(p.Name, fmt.Sprint(v))
+				}
+			} else {
+				query.Add(p.Name, fmt.Sprint(value))
+			}
+		case p.In == "header" && p.Required:
+			op.Headers = append(op.Headers, Header{Name: p.Name, Value: fmt.Sprint(value)})
+		}
+	}
+	if m := pathParameterRegexp.FindString(op.URL); m != "" {
+		return nil, fmt.Errorf("the path parameter %s isn't defined", m)
+	}
+	if len(query) > 0 {
+		op.URL += "?" + query.Encode()
+	}
+
+	if err := s.setRequestBody(op, operation.RequestBody); err != nil {
+		return nil, err
+	}
+	if err := s.setResponse(op, operation.Responses); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+func (s *openAPISpec) setRequestBody(op *Operation, body *openAPIRequestBody) error {
+	if body == nil {
+		return nil
+	}
+	if ref := body.Ref; ref != "" {
+		name, err := refName(ref, "requestBodies")
+		if err != nil {
+			return err
+		}
+		if body = s.doc.Components.RequestBodies[name]; body == nil {
+			return fmt.Errorf("the reference %q isn't defined", ref)
+		}
+	}
+
+	contentType, media := jsonMediaType(body.Content)
+	if media == nil {
+		if media = body.Content["application/x-www-form-urlencoded"]; media == nil {
+			return nil
+		}
+		contentType = "application/x-www-form-urlencoded"
+	}
+	op.ContentType = contentType
+	op.Body = s.mediaExample(media)
+	return nil
+}
+
+func (s *openAPISpec) setResponse(op *Operation, responses map[string]*openAPIResponse) error {
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	sort.Strings(codes)
+
+	code := codes[0]
+	if status, err := strconv.Atoi(code); err == nil {
+		op.Status = status
+	}
+	resp := responses[code]
+	if ref := resp.Ref; ref != "" {
+		name, err := refName(ref, "responses")
+		if err != nil {
+			return err
+		}
+		if resp = s.doc.Components.Responses[name]; resp == nil {
+			return fmt.Errorf("the reference %q isn't defined", ref)
+		}
+	}
+	if _, media := jsonMediaType(resp.Content); media != nil && media.Schema != nil {
+		schema, err := s.jsonSchema(media.Schema, nil)
+		if err != nil {
+			return err
+		}
+		op.ResponseSchema = schema
+	}
+	return nil
+}
+
+// jsonMediaType returns the JSON media type of the content, if there is one.
+func jsonMediaType(content map[string]*openAPIMediaType) (string, *openAPIMediaType) {
+	if media := content["application/json"]; media != nil {
+		return "application/json", media
+	}
+	types := make([]string, 0, len(content))
+	for typ := range content {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	for _, typ := range types {
+		if strings.HasSuffix(typ, "+json") {
+			return typ, content[typ]
+		}
+	}
+	return "", nil
+}
+
+func (s *openAPISpec) parameter(p *openAPIParameter) (*openAPIParameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+	name, err := refName(p.Ref, "parameters")
+	if err != nil {
+		return nil, err
+	}
+	resolved := s.doc.Components.Parameters[name]
+	if resolved == nil {
+		return nil, fmt.Errorf("the reference %q isn't defined", p.Ref)
+	}
+	return resolved, nil
+}
+
+func (s *openAPISpec) parameterExample(p *openAPIParameter) interface{} {
+	if p.Example != nil {
+		return p.Example
+	}
+	if example := firstExample(p.Examples); example != nil {
+		return example
+	}
+	if p.Schema == nil {
+		return "example"
+	}
+	return s.example(p.Schema, 0, nil)
+}
+
+func (s *openAPISpec) mediaExample(media *openAPIMediaType) interface{} {
+	if media.Example != nil {
+		return media.Example
+	}
+	if example := firstExample(media.Examples); example != nil {
+		return example
+	}
+	if media.Schema == nil {
+		return nil
+	}
+	return s.example(media.Schema, 0, nil)
+}
+
+func firstExample(examples map[string]*openAPIExample) interface{} {
+	names := make([]string, 0, len(examples))
+	for name := range examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if examples[name] != nil && examples[name].Value != nil {
+			return examples[name].Value
+		}
+	}
+	return nil
+}
+
+// resolve returns the schema that a schema references, along with the
+// references that were followed to get to it, which are used to stop at the
+// recursive schemas.
+func (s *openAPISpec) resolve(schema *openAPISchema, refs []string) (*openAPISchema, []string, error) {
+	for schema.Ref != "" {
+		for _, ref := range refs {
+			if ref == schema.Ref {
+				return nil, refs, nil
+			}
+		}
+		name, err := refName(schema.Ref, "schemas")
+		if err != nil {
+			return nil, refs, err
+		}
+		refs = append(refs[:len(refs):len(refs)], schema.Ref)
+		resolved := s.doc.Components.Schemas[name]
+		if resolved == nil {
+			return nil, refs, fmt.Errorf("the reference %q isn't defined", schema.Ref)
+		}
+		schema = resolved
+	}
+	return schema, refs, nil
+}
+
+// example returns an example value of the schema.
+//
+//nolint:cyclop,funlen
+func (s *openAPISpec) example(schema *openAPISchema, depth int, refs []string) interface{} {
+	schema, refs, err := s.resolve(schema, refs)
+	if err != nil || schema == nil || depth > maxSchemaDepth {
+		return nil
+	}
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case len(schema.Examples) > 0:
+		return schema.Examples[0]
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case len(schema.AllOf) > 0:
+		merged := make(map[string]interface{})
+		for _, sub := range schema.AllOf {
+			if value, ok := s.example(sub, depth+1, refs).(map[string]interface{}); ok {
+				for k, v := range value {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	case len(schema.OneOf) > 0:
+		return s.example(schema.OneOf[0], depth+1, refs)
+	case len(schema.AnyOf) > 0:
+		return s.example(schema.AnyOf[0], depth+1, refs)
+	}
+
+	typ, _ := schema.Type.main()
+	if typ == "" && len(schema.Properties) > 0 {
+		typ = "object"
+	}
+	switch typ {
+	case "object":
+		value := make(map[string]interface{}, len(schema.Properties))
+		for name, property := range schema.Properties {
+			if v := s.example(property, depth+1, refs); v != nil {
+				value[name] = v
+			}
+		}
+		return value
+	case "array":
+		if schema.Items == nil {
+			return []interface{}{}
+		}
+		if item := s.example(schema.Items, depth+1, refs); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case "integer":
+		if schema.Minimum != nil {
+			return int64(*schema.Minimum)
+		}
+		return 1
+	case "number":
+		if schema.Minimum != nil {
+			return *schema.Minimum
+		}
+		return 1.5
+	case "boolean":
+		return true
+	case "string":
+		return stringExample(schema.Format)
+	default:
+		return nil
+	}
+}
+
+func stringExample(format string) string {
+	switch format {
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "date":
+		return "2024-01-01"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	case "uri", "url":
+		return "https://example.com"
+	case "ipv4":
+		return "192.0.2.1"
+	default:
+		return "example"
+	}
+}
+
+// jsonSchema returns the subset of the JSON schema of a schema that is used
+// to validate the responses, with all references resolved. The recursive
+// references are replaced with an empty schema, which matches any value.
+//
+//nolint:cyclop
+func (s *openAPISpec) jsonSchema(schema *openAPISchema, refs []string) (map[string]interface{}, error) {
+	schema, refs, err := s.resolve(schema, refs)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]interface{})
+	if schema == nil {
+		return result, nil
+	}
+
+	typ, nullable := schema.Type.main()
+	if typ == "" && len(schema.Properties) > 0 {
+		typ = "object"
+	}
+	if typ != "" {
+		result["type"] = typ
+	}
+	if nullable || schema.Nullable {
+		result["nullable"] = true
+	}
+	if len(schema.Enum) > 0 {
+		result["enum"] = schema.Enum
+	}
+	if len(schema.Required) > 0 {
+		result["required"] = schema.Required
+	}
+	if len(schema.Properties) > 0 {
+		properties := make(map[string]interface{}, len(schema.Properties))
+		for name, property := range schema.Properties {
+			if properties[name], err = s.jsonSchema(property, refs); err != nil {
+				return nil, err
+			}
+		}
+		result["properties"] = properties
+	}
+	if schema.Items != nil {
+		if result["items"], err = s.jsonSchema(schema.Items, refs); err != nil {
+			return nil, err
+		}
+	}
+
+	// oneOf is validated like anyOf, which is good enough for a check.
+	for key, list := range map[string][]*openAPISchema{
+		"allOf": schema.AllOf, "anyOf": append(append([]*openAPISchema(nil), schema.OneOf...), schema.AnyOf...),
+	} {
+		if len(list) == 0 {
+			continue
+		}
+		schemas := make([]interface{}, len(list))
+		for i, sub := range list {
+			if schemas[i], err = s.jsonSchema(sub, refs); err != nil {
+				return nil, err
+			}
+		}
+		result[key] = schemas
+	}
+	return result, nil
+}
+
+// refName returns the name of a local reference to a component of the kind.
+func refName(ref, kind string) (string, error) {
+	prefix := "#/components/" + kind + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return "", fmt.Errorf("unsupported reference %q, only references to %s are supported", ref, prefix)
+	}
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(strings.TrimPrefix(ref, prefix)), nil
+}
+
+// reservedWords are the JavaScript reserved words that can't be the names of
+// functions.
+//
+//nolint:gochecknoglobals
+var reservedWords = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true,
+	"debugger": true, "default": true, "delete": true, "do": true, "else": true, "enum": true,
+	"export": true, "extends": true, "false": true, "finally": true, "for": true, "function": true,
+	"if": true, "import": true, "in": true, "instanceof": true, "new": true, "null": true,
+	"return": true, "super": true, "switch": true, "this": true, "throw": true, "true": true,
+	"try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true,
+	"let": true, "static": true, "yield": true, "await": true,
+}
@@ -0,0 +1,221 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOpenAPISpec = `
+openapi: 3.1.0
+info:
+  title: Petstore
+servers:
+  - url: https://petstore.example.com/v1/
+paths:
+  /pets:
+    get:
+      operationId: list-pets
+      summary: List all pets
+      x-k6-weight: 3
+      parameters:
+        - name: limit
+          in: query
+          required: true
+          schema: {type: integer, minimum: 10}
+        - name: offset
+          in: query
+          schema: {type: integer}
+        - $ref: '#/components/parameters/Tenant'
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/Pet'}
+        default:
+          description: error
+    post:
+      requestBody:
+        $ref: '#/components/requestBodies/Pet'
+      responses:
+        '201':
+          $ref: '#/components/responses/Pet'
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+        schema: {type: string, format: uuid}
+    delete:
+      operationId: delete
+      responses:
+        2XX: {description: deleted}
+  /login:
+    post:
+      requestBody:
+        content:
+          application/x-www-form-urlencoded:
+            examples:
+              admin: {value: {user: admin}}
+      responses:
+        '204': {description: ok}
+components:
+  parameters:
+    Tenant:
+      name: X-Tenant
+      in: header
+      required: true
+      example: acme
+  requestBodies:
+    Pet:
+      content:
+        application/vnd.pet+json:
+          schema: {$ref: '#/components/schemas/Pet'}
+  responses:
+    Pet:
+      description: pet
+      content:
+        application/json:
+          schema: {$ref: '#/components/schemas/Pet'}
+  schemas:
+    Pet:
+      type: object
+      required: [id, name]
+      properties:
+        id: {type: integer}
+        name: {type: string, example: Rex}
+        tag: {type: [string, 'null']}
+        kind: {enum: [dog, cat]}
+        parent: {$ref: '#/components/schemas/Pet'}
+`
+
+func TestParseOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	api, err := ParseOpenAPI(strings.NewReader(testOpenAPISpec))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Petstore", api.Title)
+	assert.Equal(t, "https://petstore.example.com/v1", api.BaseURL)
+	assert.Equal(t, 6.0, api.TotalWeight)
+	require.Len(t, api.Operations, 4)
+
+	petSchema := map[string]interface{}{
+		"type":     "object",
+		"required": []string{"id", "name"},
+		"properties": map[string]interface{}{
+			"id":     map[string]interface{}{"type": "integer"},
+			"name":   map[string]interface{}{"type": "string"},
+			"tag":    map[string]interface{}{"type": "string", "nullable": true},
+			"kind":   map[string]interface{}{"enum": []interface{}{"dog", "cat"}},
+			"parent": map[string]interface{}{},
+		},
+	}
+
+	assert.Equal(t, &Operation{
+		Name:           "listPets",
+		Method:         "GET",
+		Path:           "/pets",
+		Summary:        "List all pets",
+		URL:            "/pets?limit=10",
+		Headers:        []Header{{Name: "X-Tenant", Value: "acme"}},
+		Status:         200,
+		ResponseSchema: map[string]interface{}{"type": "array", "items": petSchema},
+		Weight:         3,
+	}, api.Operations[0])
+
+	assert.Equal(t, &Operation{
+		Name:        "postPets",
+		Method:      "POST",
+		Path:        "/pets",
+		URL:         "/pets",
+		ContentType: "application/vnd.pet+json",
+		Body: map[string]interface{}{
+			"id":   1,
+			"name": "Rex",
+			"tag":  "example",
+			"kind": "dog",
+		},
+		Status:         201,
+		ResponseSchema: petSchema,
+		Weight:         1,
+	}, api.Operations[1])
+
+	assert.Equal(t, &Operation{
+		Name:   "deleteOperation",
+		Method: "DELETE",
+		Path:   "/pets/{petId}",
+		URL:    "/pets/3fa85f64-5717-4562-b3fc-2c963f66afa6",
+		Weight: 1,
+	}, api.Operations[2])
+
+	assert.Equal(t, &Operation{
+		Name:        "postLogin",
+		Method:      "POST",
+		Path:        "/login",
+		URL:         "/login",
+		ContentType: "application/x-www-form-urlencoded",
+		Body:        map[string]interface{}{"user": "admin"},
+		Status:      204,
+		Weight:      1,
+	}, api.Operations[3])
+}
+
+func TestParseOpenAPIErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name, spec, err string
+	}{
+		{
+			name: "version",
+			spec: `{"swagger": "2.0", "paths": {}}`,
+			err:  `unsupported OpenAPI version "", only 3.x is supported`,
+		},
+		{
+			name: "no paths",
+			spec: `{"openapi": "3.0.0", "paths": {}}`,
+			err:  "the OpenAPI spec has no paths",
+		},
+		{
+			name: "no operations",
+			spec: `{"openapi": "3.0.0", "paths": {"/": {}}}`,
+			err:  "the OpenAPI spec has no operations",
+		},
+		{
+			name: "undefined path parameter",
+			spec: `{"openapi": "3.0.0", "paths": {"/pets/{id}": {"get": {}}}}`,
+			err:  "couldn't convert the operation GET /pets/{id}: the path parameter {id} isn't defined",
+		},
+		{
+			name: "weight",
+			spec: `{"openapi": "3.0.0", "paths": {"/": {"get": {"x-k6-weight": 0}}}}`,
+			err:  "x-k6-weight should be positive",
+		},
+		{
+			name: "external reference",
+			spec: `{"openapi": "3.0.0", "paths": {"/": {"get": {"parameters": [{"$ref": "common.yaml#/limit"}]}}}}`,
+			err:  `unsupported reference "common.yaml#/limit"`,
+		},
+		{
+			name: "undefined reference",
+			spec: `{"openapi": "3.0.0", "paths": {"/": {"post": {"requestBody": {"$ref": "#/components/requestBodies/Pet"}}}}}`,
+			err:  `the reference "#/components/requestBodies/Pet" isn't defined`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParseOpenAPI(strings.NewReader(tc.spec))
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package converter
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// The types below are the parts of the Postman collection format v2.1 that
+// are converted, as described in https://schema.postman.com/
+type (
+	postmanCollection struct {
+		Info     postmanInfo       `json:"info"`
+		Item     []*postmanItem    `json:"item"`
+		Variable []postmanKeyValue `json:"variable"`
+		Auth     *postmanAuth      `json:"auth"`
+	}
+
+	postmanInfo struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema"`
+	}
+
+	postmanItem struct {
+		Name     string            `json:"name"`
+		Item     []*postmanItem    `json:"item"`
+		Request  *postmanRequest   `json:"request"`
+		Response []postmanResponse `json:"response"`
+		Auth     *postmanAuth      `json:"auth"`
+	}
+
+	postmanRequest struct {
+		Method string            `json:"method"`
+		URL    postmanURL        `json:"url"`
+		Header []postmanKeyValue `json:"header"`
+		Body   *postmanBody      `json:"body"`
+		Auth   *postmanAuth      `json:"auth"`
+	}
+
+	postmanURL struct {
+		Raw string `json:"raw"`
+	}
+
+	postmanBody struct {
+		Mode       string            `json:"mode"`
+		Raw        string            `json:"raw"`
+		URLEncoded []postmanKeyValue `json:"urlencoded"`
+		GraphQL    *struct {
+			Query     string `json:"query"`
+			Variables string `json:"variables"`
+		} `json:"graphql"`
+	}
+
+	postmanAuth struct {
+		Type   string            `json:"type"`
+		Bearer []postmanKeyValue `json:"bearer"`
+		Basic  []postmanKeyValue `json:"basic"`
+		APIKey []postmanKeyValue `json:"apikey"`
+	}
+
+	postmanResponse struct {
+		Code   int               `json:"code"`
+		Header []postmanKeyValue `json:"header"`
+		Body   string            `json:"body"`
+	}
+
+	postmanKeyValue struct {
+		Key      string `json:"key"`
+		Value    string `json:"value"`
+		Disabled bool   `json:"disabled"`
+	}
+)
+
+// UnmarshalJSON supports both the string and the object form of the URL.
+func (u *postmanURL) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &u.Raw)
+	}
+	type plain postmanURL
+	return json.Unmarshal(data, (*plain)(u))
+}
+
+// UnmarshalJSON supports both the string and the object form of the request.
+func (r *postmanRequest) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		r.Method = "GET"
+		return json.Unmarshal(data, &r.URL.Raw)
+	}
+	type plain postmanRequest
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+// ParsePostman parses a Postman v2.1 collection. Every folder becomes a
+// group, named after the path of the folder, and the collection variables
+// become the session variables.
+func ParsePostman(r io.Reader) (*Session, error) {
+	var collection postmanCollection
+	if err := json.NewDecoder(r).Decode(&collection); err != nil {
+		return nil, fmt.Errorf("couldn't parse the Postman collection: %w", err)
+	}
+	if collection.Info.Schema != "" && !strings.Contains(collection.Info.Schema, "v2.1") {
+		return nil, fmt.Errorf("unsupported Postman collection schema %q, only v2.1 is supported", collection.Info.Schema)
+	}
+
+	session := &Session{Variables: make(map[string]string)}
+	for _, v := range collection.Variable {
+		if !v.Disabled {
+			session.Variables[v.Key] = v.Value
+		}
+	}
+
+	var group *Group
+	var walk func(items []*postmanItem, path string, auth *postmanAuth) error
+	walk = func(items []*postmanItem, path string, auth *postmanAuth) error {
+		for _, item := range items {
+			itemAuth := auth
+			if item.Auth != nil {
+				itemAuth = item.Auth
+			}
+			if item.Request == nil {
+				name := item.Name
+				if path != "" {
+					name = path + " / " + item.Name
+				}
+				if err := walk(item.Item, name, itemAuth); err != nil {
+					return err
+				}
+				continue
+			}
+
+			req, err := newPostmanRequest(item, itemAuth)
+			if err != nil {
+				return fmt.Errorf("couldn't convert the request %q: %w", item.Name, err)
+			}
+			if group == nil || group.Name != path {
+				group = &Group{Name: path}
+				session.Groups = append(session.Groups, group)
+			}
+			group.Requests = append(group.Requests, req)
+		}
+		return nil
+	}
+	if err := walk(collection.Item, "", collection.Auth); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func newPostmanRequest(item *postmanItem, auth *postmanAuth) (*Request, error) {
+	preq := item.Request
+	req := &Request{Method: strings.ToUpper(preq.Method), URL: preq.URL.Raw}
+	if req.Method == "" {
+		req.Method = "GET"
+	}
+	if preq.Auth != nil {
+		auth = preq.Auth
+	}
+
+	for _, h := range preq.Header {
+		if !h.Disabled && !isIgnoredHeader(h.Key) {
+			req.Headers = append(req.Headers, Header{Name: h.Key, Value: h.Value})
+		}
+	}
+	authHeader, err := newPostmanAuthHeader(auth)
+	if err != nil {
+		return nil, err
+	}
+	if authHeader != nil {
+		req.Headers = append(req.Headers, *authHeader)
+	}
+
+	if body := preq.Body; body != nil {
+		switch body.Mode {
+		case "", "none", "file":
+		case "raw":
+			req.Body = body.Raw
+		case "urlencoded":
+			values := make([]string, 0, len(body.URLEncoded))
+			for _, v := range body.URLEncoded {
+				if !v.Disabled {
+					values = append(values, escapeOutsidePlaceholders(v.Key)+"="+escapeOutsidePlaceholders(v.Value))
+				}
+			}
+			req.Body = strings.Join(values, "&")
+			if !hasHeader(req.Headers, "Content-Type") {
+				req.Headers = append(req.Headers, Header{Name: "Content-Type", Value: "application/x-www-form-urlencoded"})
+			}
+		case "graphql":
+			if body.GraphQL != nil {
+				graphQL := map[string]interface{}{"query": body.GraphQL.Query}
+				if body.GraphQL.Variables != "" {
+					graphQL["variables"] = json.RawMessage(body.GraphQL.Variables)
+				}
+				data, err := json.Marshal(graphQL)
+				if err != nil {
+					return nil, fmt.Errorf("invalid GraphQL variables: %w", err)
+				}
+				req.Body = string(data)
+			}
+			if !hasHeader(req.Headers, "Content-Type") {
+				req.Headers = append(req.Headers, Header{Name: "Content-Type", Value: "application/json"})
+			}
+		default:
+			return nil, fmt.Errorf("the %q body mode isn't supported", body.Mode)
+		}
+	}
+
+	if len(item.Response) > 0 && item.Response[0].Code > 0 {
+		resp := item.Response[0]
+		req.Response = &Response{Status: resp.Code, Body: resp.Body}
+		for _, h := range resp.Header {
+			if strings.EqualFold(h.Key, "Content-Type") {
+				req.Response.ContentType = h.Value
+			}
+		}
+	}
+	return req, nil
+}
+
+func newPostmanAuthHeader(auth *postmanAuth) (*Header, error) {
+	if auth == nil {
+		return nil, nil //nolint:nilnil
+	}
+	get := func(values []postmanKeyValue, key string) string {
+		for _, v := range values {
+			if v.Key == key {
+				return v.Value
+			}
+		}
+		return ""
+	}
+
+	switch auth.Type {
+	case "", "noauth":
+		return nil, nil //nolint:nilnil
+	case "bearer":
+		return &Header{Name: "Authorization", Value: "Bearer " + get(auth.Bearer, "token")}, nil
+	case "basic":
+		credentials := get(auth.Basic, "username") + ":" + get(auth.Basic, "password")
+		if strings.Contains(credentials, "{{") {
+			return nil, errors.New("basic auth with variables isn't supported")
+		}
+		return &Header{Name: "Authorization", Value: "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))}, nil
+	case "apikey":
+		if get(auth.APIKey, "in") == "query" {
+			return nil, errors.New("API key auth in the query isn't supported")
+		}
+		return &Header{Name: get(auth.APIKey, "key"), Value: get(auth.APIKey, "value")}, nil
+	default:
+		return nil, fmt.Errorf("the %q auth type isn't supported", auth.Type)
+	}
+}
+
+// escapeOutsidePlaceholders escapes s for a URL query, except for the
+// {{name}} placeholders in it.
+func escapeOutsidePlaceholders(s string) string {
+	var b strings.Builder
+	for s != "" {
+		start := strings.Index(s, "{{")
+		end := strings.Index(s[max(start, 0):], "}}")
+		if start < 0 || end < 0 {
+			b.WriteString(url.QueryEscape(s))
+			break
+		}
+		end += start + 2
+		b.WriteString(url.QueryEscape(s[:start]))
+		b.WriteString(s[start:end])
+		s = s[end:]
+	}
+	return b.String()
+}
+
+func hasHeader(headers []Header, name string) bool {
+	for _, h := range headers {
+		if strings.EqualFold(h.Name, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPostmanCollection = `{
+  "info": {
+    "name": "API",
+    "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+  },
+  "variable": [
+    {"key": "baseUrl", "value": "https://api.example.com"},
+    {"key": "unused", "value": "x", "disabled": true}
+  ],
+  "auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}"}]},
+  "item": [
+    {"name": "Health", "request": "https://api.example.com/health"},
+    {
+      "name": "Users",
+      "item": [
+        {
+          "name": "Create",
+          "request": {
+            "method": "post",
+            "url": {"raw": "{{baseUrl}}/users", "host": ["{{baseUrl}}"], "path": ["users"]},
+            "header": [
+              {"key": "Accept", "value": "application/json"},
+              {"key": "X-Debug", "value": "1", "disabled": true}
+            ],
+            "body": {
+              "mode": "urlencoded",
+              "urlencoded": [
+                {"key": "name", "value": "John Doe"},
+                {"key": "id", "value": "{{id}}"}
+              ]
+            }
+          },
+          "response": [
+            {"code": 201, "header": [{"key": "Content-Type", "value": "application/json"}], "body": "{}"}
+          ]
+        },
+        {
+          "name": "Admin",
+          "item": [
+            {
+              "name": "List",
+              "request": {
+                "method": "GET",
+                "url": "{{baseUrl}}/admin/users",
+                "auth": {"type": "noauth"}
+              }
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}`
+
+func TestParsePostman(t *testing.T) {
+	t.Parallel()
+
+	session, err := ParsePostman(strings.NewReader(testPostmanCollection))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"baseUrl": "https://api.example.com"}, session.Variables)
+	require.Len(t, session.Groups, 3)
+	assert.Equal(t, "", session.Groups[0].Name)
+	assert.Equal(t, "Users", session.Groups[1].Name)
+	assert.Equal(t, "Users / Admin", session.Groups[2].Name)
+
+	assert.Equal(t, &Request{
+		Method:  "GET",
+		URL:     "https://api.example.com/health",
+		Headers: []Header{{Name: "Authorization", Value: "Bearer {{token}}"}},
+	}, session.Groups[0].Requests[0])
+	assert.Equal(t, &Request{
+		Method: "POST",
+		URL:    "{{baseUrl}}/users",
+		Headers: []Header{
+			{Name: "Accept", Value: "application/json"},
+			{Name: "Authorization", Value: "Bearer {{token}}"},
+			{Name: "Content-Type", Value: "application/x-www-form-urlencoded"},
+		},
+		Body:     "name=John+Doe&id={{id}}",
+		Response: &Response{Status: 201, ContentType: "application/json", Body: "{}"},
+	}, session.Groups[1].Requests[0])
+	assert.Equal(t, &Request{Method: "GET", URL: "{{baseUrl}}/admin/users"}, session.Groups[2].Requests[0])
+}
+
+func TestParsePostmanErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name, collection, err string
+	}{
+		{
+			name:       "schema",
+			collection: `{"info": {"schema": "https://schema.getpostman.com/json/collection/v2.0.0/collection.json"}}`,
+			err:        "only v2.1 is supported",
+		},
+		{
+			name:       "body mode",
+			collection: `{"item": [{"name": "Upload", "request": {"url": "/", "body": {"mode": "formdata"}}}]}`,
+			err:        `couldn't convert the request "Upload": the "formdata" body mode isn't supported`,
+		},
+		{
+			name:       "auth type",
+			collection: `{"auth": {"type": "oauth2"}, "item": [{"name": "Get", "request": "/"}]}`,
+			err:        `the "oauth2" auth type isn't supported`,
+		},
+		{
+			name: "basic auth variables",
+			collection: `{"item": [{"name": "Get", "request": {"url": "/", "auth": {"type": "basic", "basic": [
+				{"key": "username", "value": "{{user}}"}]}}}]}`,
+			err: "basic auth with variables isn't supported",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParsePostman(strings.NewReader(tc.collection))
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
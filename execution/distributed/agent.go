@@ -0,0 +1,196 @@
+package distributed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.k6.io/k6/execution"
+)
+
+// AgentController implements the execution.Controller interface for a single
+// agent instance, by relaying all of the operations to the coordinator.
+type AgentController struct {
+	instanceID uint32
+	cnc        CommandAndControlClient
+	logger     logrus.FieldLogger
+
+	sendMx sync.Mutex
+
+	mx        sync.Mutex
+	streamErr error
+	waits     map[string][]chan error
+	dataReqs  map[string][]chan *ControllerMessage
+}
+
+var _ execution.Controller = &AgentController{}
+
+// NewAgentController starts a new command-and-control stream with the
+// coordinator and returns an execution.Controller that uses it.
+func NewAgentController(
+	ctx context.Context, instanceID uint32, client DistributedTestClient, logger logrus.FieldLogger,
+) (*AgentController, error) {
+	cnc, err := client.CommandAndControl(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ac := &AgentController{
+		instanceID: instanceID,
+		cnc:        cnc,
+		logger:     logger.WithField("component", "agent-controller"),
+		waits:      make(map[string][]chan error),
+		dataReqs:   make(map[string][]chan *ControllerMessage),
+	}
+
+	// Let the coordinator know who we are, so it can send us messages
+	if err := ac.send(&AgentMessage{}); err != nil {
+		return nil, err
+	}
+
+	go ac.receiveLoop()
+
+	return ac, nil
+}
+
+func (ac *AgentController) send(msg *AgentMessage) error {
+	ac.sendMx.Lock()
+	defer ac.sendMx.Unlock()
+	msg.InstanceID = ac.instanceID
+	return ac.cnc.Send(msg)
+}
+
+func (ac *AgentController) receiveLoop() {
+	for {
+		msg, err := ac.cnc.Recv()
+		if err != nil {
+			ac.failAll(err)
+			return
+		}
+
+		switch {
+		case msg.DoneWaitWithID != "":
+			ac.mx.Lock()
+			waits := ac.waits[msg.DoneWaitWithID]
+			delete(ac.waits, msg.DoneWaitWithID)
+			ac.mx.Unlock()
+
+			waitErr := errFromString(msg.WaitError)
+			for _, ch := range waits {
+				ch <- waitErr
+			}
+		case msg.CreateDataWithID != "":
+			ac.resolveDataRequest(msg.CreateDataWithID, msg)
+		case msg.DataWithID != nil:
+			ac.resolveDataRequest(msg.DataWithID.ID, msg)
+		default:
+			ac.logger.Warnf("Received an unknown message from the coordinator")
+		}
+	}
+}
+
+func (ac *AgentController) resolveDataRequest(dataID string, msg *ControllerMessage) {
+	ac.mx.Lock()
+	reqs := ac.dataReqs[dataID]
+	delete(ac.dataReqs, dataID)
+	ac.mx.Unlock()
+
+	for _, ch := range reqs {
+		ch <- msg
+	}
+}
+
+// failAll releases everything that is waiting on the coordinator with the
+// given error, since the connection to it was lost.
+func (ac *AgentController) failAll(err error) {
+	if errors.Is(err, io.EOF) || status.Code(err) == codes.Canceled {
+		ac.logger.Debug("Command and control stream was closed")
+	} else {
+		ac.logger.WithError(err).Error("Lost the connection to the coordinator")
+	}
+	err = fmt.Errorf("lost the connection to the coordinator: %w", err)
+
+	ac.mx.Lock()
+	defer ac.mx.Unlock()
+
+	ac.streamErr = err
+	for eventID, waits := range ac.waits {
+		for _, ch := range waits {
+			ch <- err
+		}
+		delete(ac.waits, eventID)
+	}
+	for dataID, reqs := range ac.dataReqs {
+		for _, ch := range reqs {
+			ch <- &ControllerMessage{DataWithID: &DataPacket{ID: dataID, Error: err.Error()}}
+		}
+		delete(ac.dataReqs, dataID)
+	}
+}
+
+// GetOrCreateData asks the coordinator for the data chunk with the given ID.
+// If this instance is the first one to ask for it, the coordinator tells it to
+// call the callback and the result is then sent to all other instances.
+func (ac *AgentController) GetOrCreateData(dataID string, callback func() ([]byte, error)) ([]byte, error) {
+	ch := make(chan *ControllerMessage, 1)
+	ac.mx.Lock()
+	if ac.streamErr != nil {
+		ac.mx.Unlock()
+		return nil, ac.streamErr
+	}
+	ac.dataReqs[dataID] = append(ac.dataReqs[dataID], ch)
+	ac.mx.Unlock()
+
+	if err := ac.send(&AgentMessage{GetOrCreateDataWithID: dataID}); err != nil {
+		return nil, err
+	}
+
+	msg := <-ch
+	if msg.DataWithID != nil {
+		return msg.DataWithID.Data, errFromString(msg.DataWithID.Error)
+	}
+
+	ac.logger.Debugf("Creating the data chunk '%s'...", dataID)
+	data, err := callback()
+	sendErr := ac.send(&AgentMessage{CreatedData: &DataPacket{ID: dataID, Data: data, Error: errToString(err)}})
+	if err == nil {
+		err = sendErr
+	}
+	return data, err
+}
+
+// Subscribe registers a listener for the given event ID, which is resolved
+// once the coordinator has received a signal about it from all instances.
+func (ac *AgentController) Subscribe(eventID string) func() error {
+	ch := make(chan error, 1)
+	ac.mx.Lock()
+	if ac.streamErr != nil {
+		ch <- ac.streamErr
+	} else {
+		ac.waits[eventID] = append(ac.waits[eventID], ch)
+	}
+	ac.mx.Unlock()
+
+	return func() error {
+		return <-ch
+	}
+}
+
+// Signal lets the coordinator know that this instance has reached the given
+// event ID, or that it had an error.
+func (ac *AgentController) Signal(eventID string, err error) error {
+	return ac.send(&AgentMessage{SignalAndWaitOnID: eventID, SignalError: errToString(err)})
+}
+
+// Close finishes the command-and-control stream.
+func (ac *AgentController) Close() error {
+	ac.sendMx.Lock()
+	defer ac.sendMx.Unlock()
+	return ac.cnc.CloseSend()
+}
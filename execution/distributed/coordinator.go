@@ -0,0 +1,382 @@
+package distributed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+// testRunStartEventID is the ID of the event that the execution.Scheduler
+// signals right before it starts the actual test run. The coordinator uses it
+// to know when to start measuring the test run duration.
+const testRunStartEventID = "scheduler-run-start"
+
+// CoordinatorServer coordinates multiple k6 agents. It hands out execution
+// segments to them, implements the server side of the distributed
+// execution.Controller and receives their metrics.
+type CoordinatorServer struct {
+	instanceCount int
+	archive       []byte
+	segments      []*lib.ExecutionSegment
+	ess           lib.ExecutionSegmentSequence
+	registry      *metrics.Registry
+	onSamples     func([]metrics.SampleContainer)
+	logger        logrus.FieldLogger
+
+	registeredInstances uint32
+	testStartTime       atomic.Value // time.Time
+	abortError          atomic.Value // string
+
+	mx         sync.Mutex
+	streams    map[uint32]*instanceStream
+	waits      map[string]*eventWait
+	dataChunks map[string]*dataChunk
+	done       map[uint32]struct{}
+	allDone    chan struct{}
+}
+
+type instanceStream struct {
+	sendMx sync.Mutex
+	stream CommandAndControlServer
+}
+
+func (is *instanceStream) send(msg *ControllerMessage) error {
+	is.sendMx.Lock()
+	defer is.sendMx.Unlock()
+	return is.stream.Send(msg)
+}
+
+type eventWait struct {
+	signaled map[uint32]struct{}
+	finished bool
+	err      string
+}
+
+type dataChunk struct {
+	creator uint32
+	data    *DataPacket
+	waiting []uint32
+}
+
+var _ DistributedTestServer = &CoordinatorServer{}
+
+// NewCoordinatorServer initializes and returns a new CoordinatorServer for the
+// given number of instances. Every sample that the agents send is resolved
+// against the given registry and passed to the onSamples callback.
+func NewCoordinatorServer(
+	instanceCount int, archive []byte, registry *metrics.Registry,
+	onSamples func([]metrics.SampleContainer), logger logrus.FieldLogger,
+) (*CoordinatorServer, error) {
+	if instanceCount < 1 {
+		return nil, fmt.Errorf("the number of instances should be at least 1, but it was %d", instanceCount)
+	}
+	var fullSegment *lib.ExecutionSegment // nil is the whole (0:1] segment
+	segments, err := fullSegment.Split(int64(instanceCount))
+	if err != nil {
+		return nil, err
+	}
+	ess, err := lib.NewExecutionSegmentSequence(segments...)
+	if err != nil {
+		return nil, err
+	}
+
+	cs := &CoordinatorServer{
+		instanceCount: instanceCount,
+		archive:       archive,
+		segments:      segments,
+		ess:           ess,
+		registry:      registry,
+		onSamples:     onSamples,
+		logger:        logger.WithField("component", "coordinator"),
+		streams:       make(map[uint32]*instanceStream),
+		waits:         make(map[string]*eventWait),
+		dataChunks:    make(map[string]*dataChunk),
+		done:          make(map[uint32]struct{}),
+		allDone:       make(chan struct{}),
+	}
+	cs.abortError.Store("")
+	return cs, nil
+}
+
+// Register hands out the next free execution segment to the agent that called
+// it, together with the test archive.
+func (cs *CoordinatorServer) Register(context.Context, *RegisterRequest) (*RegisterResponse, error) {
+	instanceID := atomic.AddUint32(&cs.registeredInstances, 1)
+	if instanceID > uint32(cs.instanceCount) { //nolint:gosec
+		return nil, fmt.Errorf("we don't need any more instances, all %d have already registered", cs.instanceCount)
+	}
+	cs.logger.Infof("Instance %d of %d connected!", instanceID, cs.instanceCount)
+
+	ess := cs.ess
+	options, err := json.Marshal(lib.Options{
+		ExecutionSegment:         cs.segments[instanceID-1],
+		ExecutionSegmentSequence: &ess,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &RegisterResponse{
+		InstanceID: instanceID,
+		Archive:    cs.archive,
+		Options:    options,
+	}, nil
+}
+
+// CommandAndControl handles the bi-directional stream with a single agent, over
+// which the execution.Controller operations are coordinated.
+func (cs *CoordinatorServer) CommandAndControl(stream CommandAndControlServer) error {
+	msg, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	instanceID := msg.InstanceID
+	if instanceID == 0 || instanceID > uint32(cs.instanceCount) { //nolint:gosec
+		return fmt.Errorf("invalid instance ID %d", instanceID)
+	}
+
+	is := &instanceStream{stream: stream}
+	cs.mx.Lock()
+	if _, ok := cs.streams[instanceID]; ok {
+		cs.mx.Unlock()
+		return fmt.Errorf("instance %d is already connected", instanceID)
+	}
+	cs.streams[instanceID] = is
+	cs.mx.Unlock()
+
+	logger := cs.logger.WithField("instance", instanceID)
+	logger.Debug("Command and control stream started")
+
+	for {
+		if err = cs.handleAgentMessage(msg); err != nil {
+			logger.WithError(err).Error("Error while handling an agent message")
+			break
+		}
+		msg, err = stream.Recv()
+		if err != nil {
+			break
+		}
+	}
+
+	cs.mx.Lock()
+	delete(cs.streams, instanceID)
+	cs.mx.Unlock()
+
+	cs.mx.Lock()
+	_, isDone := cs.done[instanceID]
+	cs.mx.Unlock()
+	if isDone || errors.Is(err, io.EOF) {
+		logger.Debug("Command and control stream finished")
+		return nil
+	}
+	cs.instanceDisconnected(instanceID, err)
+	return err
+}
+
+func (cs *CoordinatorServer) handleAgentMessage(msg *AgentMessage) error {
+	switch {
+	case msg.SignalAndWaitOnID != "":
+		cs.handleSignal(msg.InstanceID, msg.SignalAndWaitOnID, msg.SignalError)
+	case msg.GetOrCreateDataWithID != "":
+		return cs.handleGetOrCreateData(msg.InstanceID, msg.GetOrCreateDataWithID)
+	case msg.CreatedData != nil:
+		cs.handleCreatedData(msg.InstanceID, msg.CreatedData)
+	}
+	return nil
+}
+
+func (cs *CoordinatorServer) handleSignal(instanceID uint32, eventID string, signalErr string) {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+
+	ew, ok := cs.waits[eventID]
+	if !ok {
+		ew = &eventWait{signaled: make(map[uint32]struct{})}
+		cs.waits[eventID] = ew
+	}
+	if ew.finished {
+		// some other instance already had an error, so we directly return it
+		if err := cs.sendTo(instanceID, &ControllerMessage{DoneWaitWithID: eventID, WaitError: ew.err}); err != nil {
+			cs.logger.WithField("instance", instanceID).WithError(err).Error("Could not send message")
+		}
+		return
+	}
+	ew.signaled[instanceID] = struct{}{}
+
+	if signalErr != "" {
+		cs.logger.WithField("instance", instanceID).Errorf("Instance signaled an error for '%s': %s", eventID, signalErr)
+		signalErr = fmt.Sprintf("instance %d: %s", instanceID, signalErr)
+	} else if len(ew.signaled) < cs.instanceCount {
+		return // we still need to wait for the other instances
+	}
+
+	ew.finished = true
+	ew.err = signalErr
+	if eventID == testRunStartEventID && signalErr == "" {
+		cs.testStartTime.Store(time.Now())
+	}
+	cs.logger.Debugf("All instances reached '%s'", eventID)
+	cs.broadcast(&ControllerMessage{DoneWaitWithID: eventID, WaitError: signalErr})
+}
+
+func (cs *CoordinatorServer) handleGetOrCreateData(instanceID uint32, dataID string) error {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+
+	chunk, ok := cs.dataChunks[dataID]
+	if !ok {
+		cs.dataChunks[dataID] = &dataChunk{creator: instanceID}
+		return cs.sendTo(instanceID, &ControllerMessage{CreateDataWithID: dataID})
+	}
+	if chunk.data == nil {
+		chunk.waiting = append(chunk.waiting, instanceID)
+		return nil
+	}
+	return cs.sendTo(instanceID, &ControllerMessage{DataWithID: chunk.data})
+}
+
+func (cs *CoordinatorServer) handleCreatedData(instanceID uint32, data *DataPacket) {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+
+	chunk, ok := cs.dataChunks[data.ID]
+	if !ok || chunk.creator != instanceID || chunk.data != nil {
+		cs.logger.WithField("instance", instanceID).Warnf("Received unexpected data for '%s'", data.ID)
+		return
+	}
+	chunk.data = data
+	for _, waitingID := range chunk.waiting {
+		if err := cs.sendTo(waitingID, &ControllerMessage{DataWithID: data}); err != nil {
+			cs.logger.WithField("instance", waitingID).WithError(err).Error("Could not send data")
+		}
+	}
+	chunk.waiting = nil
+}
+
+// sendTo sends the message to the given instance. It should be called with the
+// lock held.
+func (cs *CoordinatorServer) sendTo(instanceID uint32, msg *ControllerMessage) error {
+	is, ok := cs.streams[instanceID]
+	if !ok {
+		return fmt.Errorf("instance %d is not connected", instanceID)
+	}
+	msg.InstanceID = instanceID
+	return is.send(msg)
+}
+
+// broadcast sends the message to all connected instances. It should be called
+// with the lock held.
+func (cs *CoordinatorServer) broadcast(msg *ControllerMessage) {
+	for instanceID := range cs.streams {
+		msgCopy := *msg
+		if err := cs.sendTo(instanceID, &msgCopy); err != nil {
+			cs.logger.WithField("instance", instanceID).WithError(err).Error("Could not send message")
+		}
+	}
+}
+
+// instanceDisconnected releases everyone that is waiting on an event, since
+// the disconnected instance will never reach it, and marks it as done.
+func (cs *CoordinatorServer) instanceDisconnected(instanceID uint32, err error) {
+	cs.logger.WithField("instance", instanceID).WithError(err).Error("Instance disconnected unexpectedly")
+	waitErr := fmt.Sprintf("instance %d disconnected unexpectedly", instanceID)
+
+	cs.mx.Lock()
+	for eventID, ew := range cs.waits {
+		if ew.finished {
+			continue
+		}
+		ew.finished = true
+		ew.err = waitErr
+		cs.broadcast(&ControllerMessage{DoneWaitWithID: eventID, WaitError: waitErr})
+	}
+	for dataID, chunk := range cs.dataChunks {
+		if chunk.data != nil || chunk.creator != instanceID {
+			continue
+		}
+		chunk.data = &DataPacket{ID: dataID, Error: waitErr}
+		for _, waitingID := range chunk.waiting {
+			_ = cs.sendTo(waitingID, &ControllerMessage{DataWithID: chunk.data})
+		}
+		chunk.waiting = nil
+	}
+	cs.mx.Unlock()
+
+	cs.markDone(instanceID)
+}
+
+// SendMetrics receives a batch of metric samples from an agent and passes them
+// to the onSamples callback.
+func (cs *CoordinatorServer) SendMetrics(_ context.Context, dump *MetricsDump) (*MetricsDumpResponse, error) {
+	if len(dump.Samples) > 0 {
+		samples := make(metrics.Samples, 0, len(dump.Samples))
+		rootTags := cs.registry.RootTagSet()
+		for _, sd := range dump.Samples {
+			sample, err := sd.makeSample(cs.registry, rootTags)
+			if err != nil {
+				return nil, err
+			}
+			samples = append(samples, sample)
+		}
+		cs.onSamples([]metrics.SampleContainer{samples})
+	}
+	if dump.Done {
+		cs.logger.WithField("instance", dump.InstanceID).Info("Instance finished")
+		cs.markDone(dump.InstanceID)
+	}
+	return &MetricsDumpResponse{AbortError: cs.abortError.Load().(string)}, nil //nolint:forcetypeassert
+}
+
+func (cs *CoordinatorServer) markDone(instanceID uint32) {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+	if _, ok := cs.done[instanceID]; ok {
+		return
+	}
+	cs.done[instanceID] = struct{}{}
+	if len(cs.done) == cs.instanceCount {
+		close(cs.allDone)
+	}
+}
+
+// Abort marks the test run as aborted with the given error. The agents will
+// receive it as a response to their next metrics batch and stop their test
+// runs as well.
+func (cs *CoordinatorServer) Abort(err error) {
+	if err == nil {
+		return
+	}
+	cs.logger.WithError(err).Warn("Aborting the test run")
+	cs.abortError.Store(err.Error())
+}
+
+// GetCurrentTestRunDuration returns how long the test has been running since
+// all instances started their test runs, or 0 if it hasn't started yet.
+func (cs *CoordinatorServer) GetCurrentTestRunDuration() time.Duration {
+	startTime, ok := cs.testStartTime.Load().(time.Time)
+	if !ok {
+		return 0
+	}
+	return time.Since(startTime)
+}
+
+// Wait blocks until all instances have finished their test runs or until the
+// given context is done.
+func (cs *CoordinatorServer) Wait(ctx context.Context) error {
+	select {
+	case <-cs.allDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,311 @@
+// Package distributed implements the execution.Controller interface for
+// distributed (multi-machine) k6 execution, as well as the coordinator server
+// that the different k6 agent instances connect to.
+//
+// The communication between the coordinator and the agents happens over gRPC.
+// Instead of generated protobuf code, the service is described by hand and the
+// messages are simple Go structs that are serialized as JSON by a custom codec.
+package distributed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"go.k6.io/k6/metrics"
+)
+
+const serviceName = "k6.distributed.DistributedTest"
+
+// RegisterRequest is sent by the agents when they first connect to the
+// coordinator.
+type RegisterRequest struct{}
+
+// RegisterResponse contains everything an agent needs to run its part of the
+// test: its unique instance ID, the test archive and the options that contain
+// its execution segment and the whole execution segment sequence.
+type RegisterResponse struct {
+	InstanceID uint32 `json:"instanceID"`
+	Archive    []byte `json:"archive"`
+	Options    []byte `json:"options"` // JSON-encoded lib.Options
+}
+
+// DataPacket is used to transfer the data chunks created by the
+// Controller.GetOrCreateData() callbacks.
+type DataPacket struct {
+	ID    string `json:"id"`
+	Data  []byte `json:"data,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// AgentMessage is a command-and-control message sent by an agent to the
+// coordinator. Only one of its fields (besides the instance ID) should be set.
+type AgentMessage struct {
+	InstanceID uint32 `json:"instanceID"`
+
+	SignalAndWaitOnID     string      `json:"signalAndWaitOnID,omitempty"`
+	SignalError           string      `json:"signalError,omitempty"`
+	GetOrCreateDataWithID string      `json:"getOrCreateDataWithID,omitempty"`
+	CreatedData           *DataPacket `json:"createdData,omitempty"`
+}
+
+// ControllerMessage is a command-and-control message sent by the coordinator
+// to an agent. Only one of its fields (besides the instance ID) should be set.
+type ControllerMessage struct {
+	InstanceID uint32 `json:"instanceID"`
+
+	DoneWaitWithID   string      `json:"doneWaitWithID,omitempty"`
+	WaitError        string      `json:"waitError,omitempty"`
+	CreateDataWithID string      `json:"createDataWithID,omitempty"`
+	DataWithID       *DataPacket `json:"dataWithID,omitempty"`
+}
+
+// SampleDump is the serializable version of a single metrics.Sample.
+type SampleDump struct {
+	Metric    string             `json:"metric"`
+	Type      metrics.MetricType `json:"type"`
+	ValueType metrics.ValueType  `json:"valueType"`
+	Tags      map[string]string  `json:"tags,omitempty"`
+	Metadata  map[string]string  `json:"metadata,omitempty"`
+	Time      time.Time          `json:"time"`
+	Value     float64            `json:"value"`
+}
+
+// makeSample turns the SampleDump back into a metrics.Sample, with the metric
+// from the given registry.
+func (sd SampleDump) makeSample(registry *metrics.Registry, rootTags *metrics.TagSet) (metrics.Sample, error) {
+	metric, err := registry.NewMetric(sd.Metric, sd.Type, sd.ValueType)
+	if err != nil {
+		return metrics.Sample{}, err
+	}
+	return metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: metric,
+			Tags:   rootTags.WithTagsFromMap(sd.Tags),
+		},
+		Time:     sd.Time,
+		Value:    sd.Value,
+		Metadata: sd.Metadata,
+	}, nil
+}
+
+// MetricsDump is a batch of metric samples that an agent sends to the
+// coordinator. The last batch an agent sends has Done set to true.
+type MetricsDump struct {
+	InstanceID uint32       `json:"instanceID"`
+	Samples    []SampleDump `json:"samples,omitempty"`
+	Done       bool         `json:"done,omitempty"`
+}
+
+// MetricsDumpResponse is returned by the coordinator for every MetricsDump. If
+// AbortError is not empty, the coordinator has aborted the test run (e.g.
+// because of an abortOnFail threshold) and the agent should stop as well.
+type MetricsDumpResponse struct {
+	AbortError string `json:"abortError,omitempty"`
+}
+
+// DistributedTestServer is the server API of the coordinator.
+type DistributedTestServer interface {
+	Register(context.Context, *RegisterRequest) (*RegisterResponse, error)
+	CommandAndControl(CommandAndControlServer) error
+	SendMetrics(context.Context, *MetricsDump) (*MetricsDumpResponse, error)
+}
+
+// CommandAndControlServer is the coordinator side of the bi-directional
+// command-and-control stream.
+type CommandAndControlServer interface {
+	Send(*ControllerMessage) error
+	Recv() (*AgentMessage, error)
+	grpc.ServerStream
+}
+
+// CommandAndControlClient is the agent side of the bi-directional
+// command-and-control stream.
+type CommandAndControlClient interface {
+	Send(*AgentMessage) error
+	Recv() (*ControllerMessage, error)
+	grpc.ClientStream
+}
+
+// DistributedTestClient is the client API that agents use to communicate with
+// the coordinator.
+type DistributedTestClient interface {
+	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
+	CommandAndControl(ctx context.Context, opts ...grpc.CallOption) (CommandAndControlClient, error)
+	SendMetrics(ctx context.Context, in *MetricsDump, opts ...grpc.CallOption) (*MetricsDumpResponse, error)
+}
+
+// jsonCodec is a gRPC codec that serializes the messages as JSON.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+func (jsonCodec) Name() string { return "json" }
+
+// Dial creates a new gRPC client connection to the coordinator with the given
+// address, configured with the codec the coordinator expects.
+func Dial(address string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	opts = append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})),
+	}, opts...)
+	return grpc.NewClient(address, opts...)
+}
+
+// NewGRPCServer returns a new gRPC server with the given coordinator
+// registered in it.
+func NewGRPCServer(srv DistributedTestServer, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{grpc.ForceServerCodec(jsonCodec{})}, opts...)
+	s := grpc.NewServer(opts...)
+	s.RegisterService(&serviceDesc, srv)
+	return s
+}
+
+type distributedTestClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewDistributedTestClient returns a new client for the coordinator API that
+// uses the given connection, which should have been created with Dial().
+func NewDistributedTestClient(cc grpc.ClientConnInterface) DistributedTestClient {
+	return &distributedTestClient{cc: cc}
+}
+
+func (c *distributedTestClient) Register(
+	ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption,
+) (*RegisterResponse, error) {
+	out := new(RegisterResponse)
+	if err := c.cc.Invoke(ctx, "/"+serviceName+"/Register", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *distributedTestClient) CommandAndControl(
+	ctx context.Context, opts ...grpc.CallOption,
+) (CommandAndControlClient, error) {
+	stream, err := c.cc.NewStream(ctx, &serviceDesc.Streams[0], "/"+serviceName+"/CommandAndControl", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &commandAndControlClient{stream}, nil
+}
+
+func (c *distributedTestClient) SendMetrics(
+	ctx context.Context, in *MetricsDump, opts ...grpc.CallOption,
+) (*MetricsDumpResponse, error) {
+	out := new(MetricsDumpResponse)
+	if err := c.cc.Invoke(ctx, "/"+serviceName+"/SendMetrics", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type commandAndControlClient struct {
+	grpc.ClientStream
+}
+
+func (x *commandAndControlClient) Send(m *AgentMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *commandAndControlClient) Recv() (*ControllerMessage, error) {
+	m := new(ControllerMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+type commandAndControlServer struct {
+	grpc.ServerStream
+}
+
+func (x *commandAndControlServer) Send(m *ControllerMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *commandAndControlServer) Recv() (*AgentMessage, error) {
+	m := new(AgentMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func registerHandler(
+	srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	in := new(RegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistributedTestServer).Register(ctx, in) //nolint:forcetypeassert
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + serviceName + "/Register"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistributedTestServer).Register(ctx, req.(*RegisterRequest)) //nolint:forcetypeassert
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func sendMetricsHandler(
+	srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	in := new(MetricsDump)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DistributedTestServer).SendMetrics(ctx, in) //nolint:forcetypeassert
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + serviceName + "/SendMetrics"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DistributedTestServer).SendMetrics(ctx, req.(*MetricsDump)) //nolint:forcetypeassert
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func commandAndControlHandler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DistributedTestServer).CommandAndControl(&commandAndControlServer{stream}) //nolint:forcetypeassert
+}
+
+var serviceDesc = grpc.ServiceDesc{ //nolint:gochecknoglobals
+	ServiceName: serviceName,
+	HandlerType: (*DistributedTestServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Register", Handler: registerHandler},
+		{MethodName: "SendMetrics", Handler: sendMetricsHandler},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CommandAndControl",
+			Handler:       commandAndControlHandler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+}
+
+// errFromString is a helper that converts the serialized error strings back
+// into errors.
+func errFromString(s string) error {
+	if s == "" {
+		return nil
+	}
+	return errors.New(s)
+}
+
+func errToString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,226 @@
+package distributed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/metrics"
+)
+
+type testCluster struct {
+	coordinator *CoordinatorServer
+	clients     []DistributedTestClient
+	controllers []*AgentController
+	registry    *metrics.Registry
+
+	samplesMx sync.Mutex
+	samples   []metrics.Sample
+}
+
+func newTestCluster(t *testing.T, instanceCount int) *testCluster {
+	t.Helper()
+	logger := testutils.NewLogger(t)
+
+	tc := &testCluster{registry: metrics.NewRegistry()}
+	onSamples := func(containers []metrics.SampleContainer) {
+		tc.samplesMx.Lock()
+		defer tc.samplesMx.Unlock()
+		for _, c := range containers {
+			tc.samples = append(tc.samples, c.GetSamples()...)
+		}
+	}
+
+	coordinator, err := NewCoordinatorServer(instanceCount, []byte("archive"), tc.registry, onSamples, logger)
+	require.NoError(t, err)
+	tc.coordinator = coordinator
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := NewGRPCServer(coordinator)
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	for i := 0; i < instanceCount; i++ {
+		conn, err := Dial(listener.Addr().String())
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+
+		client := NewDistributedTestClient(conn)
+		resp, err := client.Register(ctx, &RegisterRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("archive"), resp.Archive)
+
+		controller, err := NewAgentController(ctx, resp.InstanceID, client, logger)
+		require.NoError(t, err)
+
+		tc.clients = append(tc.clients, client)
+		tc.controllers = append(tc.controllers, controller)
+	}
+
+	return tc
+}
+
+func (tc *testCluster) runOnAll(t *testing.T, fn func(i int, c execution.Controller) error) []error {
+	t.Helper()
+	errs := make([]error, len(tc.controllers))
+	wg := sync.WaitGroup{}
+	for i, c := range tc.controllers {
+		wg.Add(1)
+		go func(i int, c execution.Controller) {
+			defer wg.Done()
+			errs[i] = fn(i, c)
+		}(i, c)
+	}
+	wg.Wait()
+	return errs
+}
+
+func TestCoordinatorRegister(t *testing.T) {
+	t.Parallel()
+
+	logger := testutils.NewLogger(t)
+	coordinator, err := NewCoordinatorServer(3, []byte("archive"), metrics.NewRegistry(), nil, logger)
+	require.NoError(t, err)
+
+	expected := []string{"0:1/3", "1/3:2/3", "2/3:1"}
+	for i, exp := range expected {
+		resp, err := coordinator.Register(context.Background(), &RegisterRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, uint32(i+1), resp.InstanceID) //nolint:gosec
+
+		var opts lib.Options
+		require.NoError(t, json.Unmarshal(resp.Options, &opts))
+		assert.Equal(t, exp, opts.ExecutionSegment.String())
+		assert.Equal(t, "0,1/3,2/3,1", opts.ExecutionSegmentSequence.String())
+	}
+
+	_, err = coordinator.Register(context.Background(), &RegisterRequest{})
+	require.ErrorContains(t, err, "all 3 have already registered")
+}
+
+func TestSignalAndWait(t *testing.T) {
+	t.Parallel()
+	tc := newTestCluster(t, 3)
+
+	var reached int32
+	errs := tc.runOnAll(t, func(i int, c execution.Controller) error {
+		time.Sleep(time.Duration(i) * 50 * time.Millisecond)
+		atomic.AddInt32(&reached, 1)
+		if err := execution.SignalAndWait(c, "test-event"); err != nil {
+			return err
+		}
+		if r := atomic.LoadInt32(&reached); r != 3 {
+			return errors.New("released before all instances reached the barrier")
+		}
+		return nil
+	})
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+}
+
+func TestSignalErrorOrWait(t *testing.T) {
+	t.Parallel()
+	tc := newTestCluster(t, 3)
+
+	errs := tc.runOnAll(t, func(i int, c execution.Controller) error {
+		var err error
+		if i == 1 {
+			err = errors.New("something bad happened")
+		}
+		return execution.SignalErrorOrWait(c, "test-event", err)
+	})
+	for _, err := range errs {
+		require.ErrorContains(t, err, "something bad happened")
+	}
+}
+
+func TestGetOrCreateData(t *testing.T) {
+	t.Parallel()
+	tc := newTestCluster(t, 4)
+
+	var calls int32
+	results := make([][]byte, 4)
+	errs := tc.runOnAll(t, func(i int, c execution.Controller) error {
+		data, err := c.GetOrCreateData("setup", func() ([]byte, error) {
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(50 * time.Millisecond)
+			return []byte("setup data"), nil
+		})
+		results[i] = data
+		return err
+	})
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for i, err := range errs {
+		require.NoError(t, err)
+		assert.Equal(t, []byte("setup data"), results[i])
+	}
+
+	_, err := tc.controllers[0].GetOrCreateData("teardown", func() ([]byte, error) {
+		return nil, errors.New("teardown error")
+	})
+	require.ErrorContains(t, err, "teardown error")
+	_, err = tc.controllers[1].GetOrCreateData("teardown", func() ([]byte, error) {
+		t.Error("the callback should not be called")
+		return nil, nil
+	})
+	require.ErrorContains(t, err, "teardown error")
+}
+
+func TestSendMetrics(t *testing.T) {
+	t.Parallel()
+	tc := newTestCluster(t, 2)
+
+	now := time.Now()
+	for i, client := range tc.clients {
+		_, err := client.SendMetrics(context.Background(), &MetricsDump{
+			InstanceID: uint32(i + 1), //nolint:gosec
+			Samples: []SampleDump{{
+				Metric:    "my_trend",
+				Type:      metrics.Trend,
+				ValueType: metrics.Time,
+				Tags:      map[string]string{"instance": string(rune('a' + i))},
+				Time:      now,
+				Value:     float64(i + 1),
+			}},
+		})
+		require.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, tc.coordinator.Wait(ctx), context.DeadlineExceeded)
+
+	tc.coordinator.Abort(errors.New("thresholds were crossed"))
+	for i, client := range tc.clients {
+		resp, err := client.SendMetrics(context.Background(), &MetricsDump{InstanceID: uint32(i + 1), Done: true}) //nolint:gosec
+		require.NoError(t, err)
+		assert.Equal(t, "thresholds were crossed", resp.AbortError)
+	}
+	require.NoError(t, tc.coordinator.Wait(context.Background()))
+
+	require.Len(t, tc.samples, 2)
+	metric := tc.registry.Get("my_trend")
+	require.NotNil(t, metric)
+	for i, s := range tc.samples {
+		assert.Equal(t, metric, s.Metric)
+		assert.Equal(t, float64(i+1), s.Value)
+		tag, _ := s.Tags.Get("instance")
+		assert.Equal(t, string(rune('a'+i)), tag)
+	}
+}
@@ -0,0 +1,107 @@
+package distributed
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/output"
+)
+
+const metricsFlushRate = 1 * time.Second
+
+// MetricsOutput is an output that is used by the agents to send all of their
+// metric samples to the coordinator, so it can calculate the thresholds and
+// the end-of-test summary for the whole test run.
+type MetricsOutput struct {
+	output.SampleBuffer
+
+	ctx             context.Context //nolint:containedctx
+	instanceID      uint32
+	client          DistributedTestClient
+	logger          logrus.FieldLogger
+	periodicFlusher *output.PeriodicFlusher
+	testRunStop     func(error)
+	aborted         bool
+}
+
+var _ output.WithTestRunStop = &MetricsOutput{}
+
+// NewMetricsOutput returns a new MetricsOutput for the given agent instance.
+func NewMetricsOutput(
+	ctx context.Context, instanceID uint32, client DistributedTestClient, logger logrus.FieldLogger,
+) *MetricsOutput {
+	return &MetricsOutput{
+		ctx:        ctx,
+		instanceID: instanceID,
+		client:     client,
+		logger:     logger.WithField("component", "distributed-metrics-output"),
+	}
+}
+
+// Description returns a human-readable description of the output.
+func (mo *MetricsOutput) Description() string {
+	return "k6 coordinator"
+}
+
+// SetTestRunStopCallback receives the function that stops the test run when
+// the coordinator aborts the whole test.
+func (mo *MetricsOutput) SetTestRunStopCallback(stop func(error)) {
+	mo.testRunStop = stop
+}
+
+// Start starts the periodic sending of the buffered metric samples.
+func (mo *MetricsOutput) Start() error {
+	pf, err := output.NewPeriodicFlusher(metricsFlushRate, func() { mo.flush(false) })
+	if err != nil {
+		return err
+	}
+	mo.periodicFlusher = pf
+	return nil
+}
+
+// Stop sends any remaining metric samples and lets the coordinator know that
+// this instance has finished.
+func (mo *MetricsOutput) Stop() error {
+	mo.periodicFlusher.Stop()
+	mo.flush(true)
+	return nil
+}
+
+func (mo *MetricsOutput) flush(done bool) {
+	sampleContainers := mo.GetBufferedSamples()
+	if len(sampleContainers) == 0 && !done {
+		return
+	}
+
+	dump := &MetricsDump{InstanceID: mo.instanceID, Done: done}
+	for _, sc := range sampleContainers {
+		for _, s := range sc.GetSamples() {
+			dump.Samples = append(dump.Samples, SampleDump{
+				Metric:    s.Metric.Name,
+				Type:      s.Metric.Type,
+				ValueType: s.Metric.Contains,
+				Tags:      s.Tags.Map(),
+				Metadata:  s.Metadata,
+				Time:      s.Time,
+				Value:     s.Value,
+			})
+		}
+	}
+
+	resp, err := mo.client.SendMetrics(mo.ctx, dump)
+	if err != nil {
+		mo.logger.WithError(err).Error("Could not send metrics to the coordinator")
+		return
+	}
+	if resp.AbortError != "" && !mo.aborted && mo.testRunStop != nil {
+		mo.aborted = true
+		mo.testRunStop(errext.WithAbortReasonIfNone(
+			errext.WithExitCodeIfNone(errFromString(resp.AbortError), exitcodes.ThresholdsHaveFailed),
+			errext.AbortedByThreshold,
+		))
+	}
+}
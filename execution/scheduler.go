@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -23,8 +24,8 @@ type Scheduler struct {
 	controller Controller
 
 	initProgress    *pb.ProgressBar
-	executorConfigs []lib.ExecutorConfig // sorted by (startTime, ID)
-	executors       []lib.Executor       // sorted by (startTime, ID), excludes executors with no work
+	executorConfigs []lib.ExecutorConfig // sorted by (startTime, ID), after their startAfter dependencies
+	executors       []lib.Executor       // in the same order, excludes executors with no work
 	executionPlan   []lib.ExecutionStep
 	maxDuration     time.Duration // cached value derived from the execution plan
 	maxPossibleVUs  uint64        // cached value derived from the execution plan
@@ -97,13 +98,15 @@ func (e *Scheduler) GetState() *lib.ExecutionState {
 }
 
 // GetExecutors returns the slice of configured executor instances which
-// have work, sorted by their (startTime, name) in an ascending order.
+// have work, sorted by their (startTime, name) in an ascending order and
+// after the scenarios they start after.
 func (e *Scheduler) GetExecutors() []lib.Executor {
 	return e.executors
 }
 
 // GetExecutorConfigs returns the slice of all executor configs, sorted by
-// their (startTime, name) in an ascending order.
+// their (startTime, name) in an ascending order and after the scenarios they
+// start after.
 func (e *Scheduler) GetExecutorConfigs() []lib.ExecutorConfig {
 	return e.executorConfigs
 }
@@ -324,15 +327,26 @@ func (e *Scheduler) initVUsAndExecutors(ctx context.Context, samplesOut chan<- m
 	return nil
 }
 
+// scenarioResult is used for starting the scenarios after the ones in their
+// startAfter. The done channel is closed when the scenario is over and aborted
+// is set before that if it didn't finish successfully.
+type scenarioResult struct {
+	done    chan struct{}
+	aborted bool
+}
+
 // runExecutor gets called by the public Run() method once per configured
-// executor, each time in a new goroutine. It is responsible for waiting out the
-// configured startTime for the specific executor and then running its Run()
-// method.
+// executor, each time in a new goroutine. It is responsible for waiting for
+// the scenarios in the startAfter of the specific executor to finish, then
+// waiting out its configured startTime and then running its Run() method. If
+// any of the scenarios it starts after was aborted, the executor is skipped.
 func (e *Scheduler) runExecutor(
 	runCtx context.Context, runResults chan<- error, engineOut chan<- metrics.SampleContainer, executor lib.Executor,
+	scenarioResults map[string]*scenarioResult,
 ) {
 	executorConfig := executor.GetConfig()
 	executorStartTime := executorConfig.GetStartTime()
+	executorStartAfter := executorConfig.GetStartAfter()
 	executorLogger := e.state.Test.Logger.WithFields(logrus.Fields{
 		"executor":  executorConfig.GetName(),
 		"type":      executorConfig.GetType(),
@@ -340,6 +354,51 @@ func (e *Scheduler) runExecutor(
 	})
 	executorProgress := executor.GetProgress()
 
+	// The scenario is marked as over before its result is sent, since an error
+	// cancels the run and the scenarios starting after it have to see it first.
+	var err error
+	result := scenarioResults[executorConfig.GetName()]
+	result.aborted = true // until the executor actually finishes
+	defer func() {
+		close(result.done)
+		runResults <- err
+	}()
+
+	// Check if we have to wait for other scenarios to finish first
+	if len(executorStartAfter) > 0 {
+		executorLogger = executorLogger.WithField("startAfter", executorStartAfter)
+		executorProgress.Modify(
+			pb.WithStatus(pb.Waiting),
+			pb.WithConstProgress(0, "waiting for "+strings.Join(executorStartAfter, ", ")),
+		)
+
+		executorLogger.Debugf("Waiting for the scenarios the executor starts after...")
+		for _, name := range executorStartAfter {
+			dependency := scenarioResults[name]
+			select {
+			case <-runCtx.Done():
+			case <-dependency.done:
+			}
+			// The run is also cancelled when the dependency fails, so it has to
+			// be checked even if the context is done
+			select {
+			case <-dependency.done:
+				if dependency.aborted {
+					executorLogger.Warnf("Skipping the scenario, since the scenario %s it starts after was aborted", name)
+					executorProgress.Modify(
+						pb.WithStatus(pb.Interrupted),
+						pb.WithConstProgress(0, "skipped"),
+					)
+					return
+				}
+			default:
+			}
+			if runCtx.Err() != nil {
+				return // no error since executor hasn't started yet
+			}
+		}
+	}
+
 	// Check if we have to wait before starting the actual executor execution
 	if executorStartTime > 0 {
 		startTime := time.Now()
@@ -354,8 +413,7 @@ func (e *Scheduler) runExecutor(
 		executorLogger.Debugf("Waiting for executor start time...")
 		select {
 		case <-runCtx.Done():
-			runResults <- nil // no error since executor hasn't started yet
-			return
+			return // no error since executor hasn't started yet
 		case <-time.After(executorStartTime):
 			// continue
 		}
@@ -366,13 +424,13 @@ func (e *Scheduler) runExecutor(
 		pb.WithConstProgress(0, "started"),
 	)
 	executorLogger.Debugf("Starting executor")
-	err := executor.Run(runCtx, engineOut) // executor should handle context cancel itself
+	err = executor.Run(runCtx, engineOut) // executor should handle context cancel itself
 	if err == nil {
 		executorLogger.Debugf("Executor finished successfully")
 	} else {
 		executorLogger.WithField("error", err).Errorf("Executor error")
 	}
-	runResults <- err
+	result.aborted = err != nil || runCtx.Err() != nil
 }
 
 // Init concurrently initializes all of the planned VUs and then sequentially
@@ -496,8 +554,18 @@ func (e *Scheduler) Run(globalCtx, runCtx context.Context, samplesOut chan<- met
 
 	executorsRunCtx, executorsRunCancel := context.WithCancel(withExecStateCtx)
 	defer executorsRunCancel()
+	scenarioResults := make(map[string]*scenarioResult, len(e.executorConfigs))
+	for _, config := range e.executorConfigs {
+		scenarioResults[config.GetName()] = &scenarioResult{done: make(chan struct{})}
+	}
+	for _, config := range e.executorConfigs {
+		if !config.HasWork(e.state.ExecutionTuple) {
+			// the scenarios without work for this instance are done right away
+			close(scenarioResults[config.GetName()].done)
+		}
+	}
 	for _, exec := range e.executors {
-		go e.runExecutor(executorsRunCtx, runResults, samplesOut, exec)
+		go e.runExecutor(executorsRunCtx, runResults, samplesOut, exec, scenarioResults)
 	}
 
 	// Wait for all executors to finish
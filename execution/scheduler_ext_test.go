@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/url"
 	"reflect"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -1012,6 +1013,91 @@ func TestSchedulerEndIterations(t *testing.T) {
 	}
 }
 
+func TestSchedulerStartAfter(t *testing.T) {
+	t.Parallel()
+
+	warmup := executor.NewSharedIterationsConfig("warmup")
+	warmup.VUs = null.IntFrom(2)
+	warmup.Iterations = null.IntFrom(10)
+	warmup.MaxDuration = types.NullDurationFrom(10 * time.Second)
+	main := executor.NewPerVUIterationsConfig("main")
+	main.VUs = null.IntFrom(2)
+	main.Iterations = null.IntFrom(2)
+	main.StartAfter = []string{"warmup"}
+	main.StartTime = types.NullDurationFrom(100 * time.Millisecond)
+
+	var mu sync.Mutex
+	var scenarios []string
+	runner := &minirunner.MiniRunner{
+		Fn: func(ctx context.Context, _ *lib.State, _ chan<- metrics.SampleContainer) error {
+			mu.Lock()
+			defer mu.Unlock()
+			scenarios = append(scenarios, lib.GetScenarioState(ctx).Name)
+			return nil
+		},
+		Options: lib.Options{
+			Scenarios: lib.ScenarioConfigs{main.GetName(): main, warmup.GetName(): warmup},
+		},
+	}
+	ctx, cancel, execScheduler, samples := newTestScheduler(t, runner, nil, lib.Options{})
+	defer cancel()
+
+	// the plan covers the whole duration of warmup
+	endTime, isFinal := lib.GetEndOffset(execScheduler.GetExecutionPlan())
+	assert.Equal(t, 40*time.Second+100*time.Millisecond+10*time.Minute+30*time.Second, endTime)
+	assert.True(t, isFinal)
+	assert.Equal(t, uint64(2), lib.GetMaxPlannedVUs(execScheduler.GetExecutionPlan()), "main never runs in parallel with warmup")
+
+	startTime := time.Now()
+	require.NoError(t, execScheduler.Run(ctx, ctx, samples))
+	assert.Less(t, time.Since(startTime), 10*time.Second)
+
+	require.Len(t, scenarios, 14)
+	for i, name := range scenarios {
+		if i < 10 {
+			assert.Equal(t, "warmup", name)
+		} else {
+			assert.Equal(t, "main", name)
+		}
+	}
+}
+
+func TestSchedulerStartAfterAborted(t *testing.T) {
+	t.Parallel()
+
+	warmup := executor.NewConstantVUsConfig("warmup")
+	warmup.VUs = null.IntFrom(1)
+	warmup.Duration = types.NullDurationFrom(10 * time.Second)
+	main := executor.NewPerVUIterationsConfig("main")
+	main.StartAfter = []string{"warmup"}
+	next := executor.NewPerVUIterationsConfig("next")
+	next.StartAfter = []string{"main"}
+
+	var mainIterations int64
+	runCtx, abortTest := execution.NewTestRunContext(context.Background(), testutils.NewLogger(t))
+	runner := &minirunner.MiniRunner{
+		Fn: func(ctx context.Context, _ *lib.State, _ chan<- metrics.SampleContainer) error {
+			if lib.GetScenarioState(ctx).Name != "warmup" {
+				atomic.AddInt64(&mainIterations, 1)
+				return nil
+			}
+			abortTest(errors.New("aborted"))
+			<-ctx.Done()
+			return nil
+		},
+		Options: lib.Options{
+			Scenarios: lib.ScenarioConfigs{main.GetName(): main, next.GetName(): next, warmup.GetName(): warmup},
+		},
+	}
+	ctx, cancel, execScheduler, samples := newTestScheduler(t, runner, nil, lib.Options{})
+	defer cancel()
+
+	startTime := time.Now()
+	require.ErrorContains(t, execScheduler.Run(ctx, runCtx, samples), "aborted")
+	assert.Less(t, time.Since(startTime), 5*time.Second)
+	assert.Equal(t, int64(0), atomic.LoadInt64(&mainIterations))
+}
+
 func TestSchedulerIsRunning(t *testing.T) {
 	t.Parallel()
 	runner := &minirunner.MiniRunner{
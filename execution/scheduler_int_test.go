@@ -1,15 +1,19 @@
 package execution
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.k6.io/k6/execution/local"
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/testutils"
 	"go.k6.io/k6/lib/testutils/minirunner"
 	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/ui/pb"
 )
 
 func getBogusTestRunState(tb testing.TB) *lib.TestRunState {
@@ -90,3 +94,90 @@ func TestSetPaused(t *testing.T) {
 		require.Equal(t, err, expectedErr)
 	})
 }
+
+// Just a lib.ExecutorConfig implementation with a name and startAfter
+type startAfterConfig struct {
+	lib.ExecutorConfig
+	name       string
+	startAfter []string
+}
+
+func (c startAfterConfig) GetName() string             { return c.name }
+func (c startAfterConfig) GetType() string             { return "test" }
+func (c startAfterConfig) GetStartTime() time.Duration { return 0 }
+func (c startAfterConfig) GetStartAfter() []string     { return c.startAfter }
+
+// Just a lib.Executor implementation that counts its runs and can return an error
+type startAfterExecutor struct {
+	lib.Executor
+	config   startAfterConfig
+	progress *pb.ProgressBar
+	runs     int
+	err      error
+}
+
+func (e *startAfterExecutor) GetConfig() lib.ExecutorConfig { return e.config }
+func (e *startAfterExecutor) GetProgress() *pb.ProgressBar  { return e.progress }
+
+func (e *startAfterExecutor) Run(context.Context, chan<- metrics.SampleContainer) error {
+	e.runs++
+	return e.err
+}
+
+func TestRunExecutorSkippedAfterFailedDependency(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name        string
+		cancelOnErr bool
+	}{
+		// only the failed dependency can stop the second scenario
+		{name: "run context stays alive", cancelOnErr: false},
+		// the error cancels the run, like Scheduler.Run does
+		{name: "run cancelled by the error", cancelOnErr: true},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			sched, err := NewScheduler(getBogusTestRunState(t), local.NewController())
+			require.NoError(t, err)
+
+			expectedErr := errors.New("testing executor error")
+			first := &startAfterExecutor{
+				config: startAfterConfig{name: "first"}, progress: pb.New(), err: expectedErr,
+			}
+			second := &startAfterExecutor{
+				config: startAfterConfig{name: "second", startAfter: []string{"first"}}, progress: pb.New(),
+			}
+			scenarioResults := map[string]*scenarioResult{
+				"first":  {done: make(chan struct{})},
+				"second": {done: make(chan struct{})},
+			}
+
+			runCtx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			runResults := make(chan error)
+			samples := make(chan metrics.SampleContainer)
+			go sched.runExecutor(runCtx, runResults, samples, second, scenarioResults)
+			go sched.runExecutor(runCtx, runResults, samples, first, scenarioResults)
+
+			var errs []error
+			for range scenarioResults {
+				err := <-runResults
+				if err != nil && tc.cancelOnErr {
+					cancel()
+				}
+				errs = append(errs, err)
+			}
+			assert.ElementsMatch(t, []error{expectedErr, nil}, errs)
+
+			assert.Equal(t, 1, first.runs)
+			assert.True(t, scenarioResults["first"].aborted)
+			assert.Equal(t, 0, second.runs)
+			assert.True(t, scenarioResults["second"].aborted)
+			assert.Equal(t, pb.Interrupted, second.progress.Status())
+			_, right := second.progress.Progress()
+			assert.Equal(t, []string{"skipped"}, right)
+		})
+	}
+}
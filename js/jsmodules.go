@@ -14,6 +14,9 @@ import (
 	"go.k6.io/k6/js/modules/k6/encoding"
 	"go.k6.io/k6/js/modules/k6/execution"
 	"go.k6.io/k6/js/modules/k6/experimental/fs"
+	"go.k6.io/k6/js/modules/k6/experimental/mqtt"
+	expnet "go.k6.io/k6/js/modules/k6/experimental/net"
+	"go.k6.io/k6/js/modules/k6/experimental/sse"
 	"go.k6.io/k6/js/modules/k6/experimental/streams"
 	"go.k6.io/k6/js/modules/k6/experimental/tracing"
 	"go.k6.io/k6/js/modules/k6/grpc"
@@ -38,7 +41,10 @@ func getInternalJSModules() map[string]interface{} {
 		"k6/encoding":                encoding.New(),
 		"k6/timers":                  timers.New(),
 		"k6/execution":               execution.New(),
+		"k6/experimental/mqtt":       mqtt.New(),
+		"k6/experimental/net":        expnet.New(),
 		"k6/experimental/redis":      redis.New(),
+		"k6/experimental/sse":        sse.New(),
 		"k6/experimental/streams":    streams.New(),
 		"k6/experimental/webcrypto":  webcrypto.New(),
 		"k6/experimental/websockets": &expws.RootModule{},
@@ -0,0 +1,435 @@
+package mqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/grafana/sobek"
+	"github.com/mochi-mqtt/server/v2/packets"
+	"github.com/mstoykov/k6-taskqueue-lib/taskqueue"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	eventMessage = "message"
+	eventError   = "error"
+)
+
+// client is an MQTT client, which can connect to a broker at a time. Its
+// methods return promises, which are resolved on the event loop, while the
+// packets are sent and received in the background.
+type client struct {
+	mi     *ModuleInstance
+	obj    *sobek.Object // the object that is given to js to interact with the client
+	params *clientParams
+
+	// fields that should be seen by js only be updated on the event loop
+	conn        *connection // nil while the client isn't connected
+	connecting  bool
+	tq          *taskqueue.TaskQueue
+	tagsAndMeta metrics.TagsAndMeta
+	onMessage   sobek.Callable
+	onError     sobek.Callable
+	listeners   map[string][]sobek.Callable
+}
+
+func (mi *ModuleInstance) client(c sobek.ConstructorCall) *sobek.Object {
+	rt := mi.vu.Runtime()
+	state := mi.vu.State()
+	if state == nil {
+		common.Throw(rt, errors.New("MQTT clients can't be created in the init context"))
+	}
+
+	params, err := newClientParams(mi.vu, c.Argument(0))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	if params.clientID == "" {
+		params.clientID = fmt.Sprintf("k6-%d-%08x", state.VUID, rand.Uint32()) //nolint:gosec
+	}
+
+	cl := &client{
+		mi:        mi,
+		obj:       rt.NewObject(),
+		params:    params,
+		listeners: make(map[string][]sobek.Callable),
+	}
+	cl.define()
+	return cl.obj
+}
+
+// define defines all properties and methods of the client.
+func (cl *client) define() {
+	rt := cl.mi.vu.Runtime()
+	methods := map[string]interface{}{
+		"connect":          cl.connect,
+		"publish":          cl.publish,
+		"subscribe":        cl.subscribe,
+		"unsubscribe":      cl.unsubscribe,
+		"close":            cl.close,
+		"addEventListener": cl.addEventListener,
+	}
+	for name, method := range methods {
+		must(rt, cl.obj.DefineDataProperty(
+			name, rt.ToValue(method), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	}
+	must(rt, cl.obj.DefineDataProperty(
+		"clientId", rt.ToValue(cl.params.clientID), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, cl.obj.DefineAccessorProperty( // this needs to be with an accessor as we change the value
+		"connected", rt.ToValue(func() bool {
+			return cl.conn != nil
+		}), nil, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+
+	setOn := func(property string, on *sobek.Callable) {
+		must(rt, cl.obj.DefineAccessorProperty(
+			property, rt.ToValue(func() sobek.Value {
+				if *on == nil {
+					return sobek.Null()
+				}
+				return rt.ToValue(*on)
+			}), rt.ToValue(func(call sobek.FunctionCall) sobek.Value {
+				arg := call.Argument(0)
+				// it's possible to unset handlers by setting them to null
+				if common.IsNullish(arg) {
+					*on = nil
+					return nil
+				}
+				fn, isFunc := sobek.AssertFunction(arg)
+				if !isFunc {
+					common.Throw(rt, fmt.Errorf("a value for '%s' should be callable", property))
+				}
+				*on = fn
+				return nil
+			}), sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	}
+	setOn("onmessage", &cl.onMessage)
+	setOn("onerror", &cl.onError)
+}
+
+// connect returns a promise which is resolved after the client has connected
+// to the broker. The client keeps the iteration running until it's closed.
+func (cl *client) connect(brokerURL string) *sobek.Promise {
+	rt := cl.mi.vu.Runtime()
+	u, err := parseBrokerURL(brokerURL)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	promise, resolve, reject := rt.NewPromise()
+	if cl.conn != nil || cl.connecting {
+		reject(rt.NewGoError(errors.New("the client is already connected")))
+		return promise
+	}
+	cl.connecting = true
+
+	tagsAndMeta := cl.params.tagsAndMeta.Clone()
+	systemTags := cl.mi.vu.State().Options.SystemTags
+	proto := "MQTT/3.1.1"
+	if cl.params.protocolVersion == 5 {
+		proto = "MQTT/5.0"
+	}
+	tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagProto, proto)
+	if nameTagValue, ok := tagsAndMeta.Tags.Get(metrics.TagName.String()); ok {
+		tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagURL, nameTagValue)
+	} else {
+		tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagURL, u.tag)
+		tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagName, u.tag)
+	}
+
+	callback := cl.mi.vu.RegisterCallback()
+	go func() {
+		start := time.Now()
+		conn, err := cl.dial(u)
+		if err != nil {
+			cl.mi.pushSample(&tagsAndMeta, cl.mi.metrics.ConnectionFailures, 1)
+		} else {
+			cl.mi.pushSample(&tagsAndMeta, cl.mi.metrics.Connecting, metrics.D(time.Since(start)))
+		}
+		callback(func() error {
+			cl.connecting = false
+			if err != nil {
+				reject(rt.NewGoError(fmt.Errorf("connect to %s: %w", u.tag, err)))
+				return nil
+			}
+			cl.connected(conn, tagsAndMeta)
+			resolve(sobek.Undefined())
+			return nil
+		})
+	}()
+	return promise
+}
+
+// dial establishes the connection to the broker, with the dialer of the VU.
+func (cl *client) dial(u *brokerURL) (*connection, error) {
+	state := cl.mi.vu.State()
+	ctx, cancel := context.WithTimeout(cl.mi.vu.Context(), cl.params.connectTimeout)
+	defer cancel()
+
+	conn, err := state.Dialer.DialContext(ctx, "tcp", u.address)
+	if err != nil {
+		return nil, err
+	}
+	if tp := cl.params.tls; tp != nil || u.tls {
+		if tp == nil {
+			tp = &tlsParams{}
+		}
+		config := &tls.Config{MinVersion: tls.VersionTLS12} //nolint:gosec
+		if state.TLSConfig != nil {
+			config = state.TLSConfig.Clone()
+		}
+		config.ServerName, _, _ = net.SplitHostPort(u.address)
+		if tp.serverName != "" {
+			config.ServerName = tp.serverName
+		}
+		if tp.insecureSkipVerify {
+			config.InsecureSkipVerify = true
+		}
+		tlsConn := tls.Client(conn, config)
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	connect := packets.Packet{
+		FixedHeader: packets.FixedHeader{Type: packets.Connect},
+		Connect: packets.ConnectParams{
+			ProtocolName:     []byte("MQTT"),
+			ClientIdentifier: cl.params.clientID,
+			Keepalive:        uint16(cl.params.keepAlive / time.Second),
+			Clean:            cl.params.cleanSession,
+		},
+	}
+	username, password, hasPassword := cl.params.username, cl.params.password, cl.params.password != ""
+	if u.username != "" {
+		username, password, hasPassword = u.username, u.password, u.hasPassword
+	}
+	if username != "" {
+		connect.Connect.UsernameFlag = true
+		connect.Connect.Username = []byte(username)
+	}
+	if hasPassword {
+		connect.Connect.PasswordFlag = true
+		connect.Connect.Password = []byte(password)
+	}
+
+	c := newConnection(conn, cl.params.protocolVersion)
+	if _, err = c.handshake(ctx, connect); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// connected starts reading from the connection. It has to run on the event
+// loop.
+func (cl *client) connected(conn *connection, tagsAndMeta metrics.TagsAndMeta) {
+	cl.conn = conn
+	cl.tagsAndMeta = tagsAndMeta
+	tq := taskqueue.New(cl.mi.vu.RegisterCallback)
+	cl.tq = tq
+
+	go conn.run(func(pk packets.Packet) {
+		t := time.Now()
+		cl.mi.pushSample(&tagsAndMeta, cl.mi.metrics.MessagesReceived, 1)
+		tq.Queue(func() error {
+			if cl.conn != conn {
+				return nil
+			}
+			return cl.callListeners(eventMessage, cl.newMessageEvent(pk, t))
+		})
+	})
+	go conn.keepAlive(cl.params.keepAlive)
+	go func() {
+		select {
+		case <-conn.done:
+		case <-cl.mi.vu.Context().Done():
+			conn.close(errClosed)
+		}
+		if !errors.Is(conn.err, errClosed) {
+			cl.mi.pushSample(&tagsAndMeta, cl.mi.metrics.ConnectionFailures, 1)
+		}
+		tq.Queue(func() error {
+			if cl.conn != conn {
+				return nil
+			}
+			cl.conn = nil
+			return cl.callListeners(eventError, cl.newEvent(eventError, time.Now(), map[string]interface{}{
+				"error": conn.err.Error(),
+			}))
+		})
+		tq.Close()
+	}()
+}
+
+// publish returns a promise which is resolved after the message has been
+// sent with QoS 0, or after it has been acknowledged with QoS 1 and 2.
+func (cl *client) publish(topic string, payload sobek.Value, input sobek.Value) *sobek.Promise {
+	rt := cl.mi.vu.Runtime()
+	data, err := common.ToBytes(payload.Export())
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	params, err := newPublishParams(rt, cl.tagsAndMeta, input)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	return cl.do(func(conn *connection) (interface{}, error) {
+		start := time.Now()
+		if err := conn.publish(topic, data, params.qos, params.retain); err != nil {
+			return nil, fmt.Errorf("publish to %s: %w", topic, err)
+		}
+		cl.mi.pushSample(&params.tagsAndMeta, cl.mi.metrics.MessagesSent, 1)
+		if params.qos > 0 {
+			cl.mi.pushSample(&params.tagsAndMeta, cl.mi.metrics.PublishAckDuration, metrics.D(time.Since(start)))
+		}
+		return sobek.Undefined(), nil
+	})
+}
+
+// subscribe returns a promise which is resolved with the granted QoS after
+// the subscription has been acknowledged.
+func (cl *client) subscribe(filter string, input sobek.Value) *sobek.Promise {
+	qos, err := parseSubscribeParams(cl.mi.vu.Runtime(), input)
+	if err != nil {
+		common.Throw(cl.mi.vu.Runtime(), err)
+	}
+
+	return cl.do(func(conn *connection) (interface{}, error) {
+		granted, err := conn.subscribe(filter, qos)
+		if err != nil {
+			return nil, fmt.Errorf("subscribe to %s: %w", filter, err)
+		}
+		return granted, nil
+	})
+}
+
+// unsubscribe returns a promise which is resolved after the unsubscription
+// has been acknowledged.
+func (cl *client) unsubscribe(filter string) *sobek.Promise {
+	return cl.do(func(conn *connection) (interface{}, error) {
+		if err := conn.unsubscribe(filter); err != nil {
+			return nil, fmt.Errorf("unsubscribe from %s: %w", filter, err)
+		}
+		return sobek.Undefined(), nil
+	})
+}
+
+// close returns a promise which is resolved after the client has
+// disconnected from the broker.
+func (cl *client) close() *sobek.Promise {
+	conn := cl.conn
+	cl.conn = nil
+	return cl.doWith(conn, func(conn *connection) (interface{}, error) {
+		conn.disconnect()
+		return sobek.Undefined(), nil
+	}, true)
+}
+
+// do runs the function with the connection in the background, and returns a
+// promise which is resolved with its result.
+func (cl *client) do(f func(*connection) (interface{}, error)) *sobek.Promise {
+	return cl.doWith(cl.conn, f, false)
+}
+
+func (cl *client) doWith(conn *connection, f func(*connection) (interface{}, error), allowClosed bool) *sobek.Promise {
+	rt := cl.mi.vu.Runtime()
+	promise, resolve, reject := rt.NewPromise()
+	if conn == nil {
+		if allowClosed {
+			resolve(sobek.Undefined())
+		} else {
+			reject(rt.NewGoError(errClosed))
+		}
+		return promise
+	}
+
+	callback := cl.mi.vu.RegisterCallback()
+	go func() {
+		result, err := f(conn)
+		callback(func() error {
+			if err != nil {
+				reject(rt.NewGoError(err))
+				return nil
+			}
+			resolve(result)
+			return nil
+		})
+	}()
+	return promise
+}
+
+// newMessageEvent returns the event of a received message. It needs to be
+// called on the event loop.
+func (cl *client) newMessageEvent(pk packets.Packet, t time.Time) *sobek.Object {
+	rt := cl.mi.vu.Runtime()
+	return cl.newEvent(eventMessage, t, map[string]interface{}{
+		"topic":   pk.TopicName,
+		"payload": string(pk.Payload),
+		"data":    rt.NewArrayBuffer(pk.Payload),
+		"qos":     pk.FixedHeader.Qos,
+		"retain":  pk.FixedHeader.Retain,
+	})
+}
+
+// newEvent returns an event of the given type with the given properties. It
+// needs to be called on the event loop.
+func (cl *client) newEvent(eventType string, t time.Time, properties map[string]interface{}) *sobek.Object {
+	rt := cl.mi.vu.Runtime()
+	o := rt.NewObject()
+	must(rt, o.DefineDataProperty(
+		"type", rt.ToValue(eventType), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, o.DefineDataProperty(
+		"target", cl.obj, sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, o.DefineDataProperty(
+		"timestamp", rt.ToValue(float64(t.UnixNano())/1_000_000), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	for name, value := range properties {
+		must(rt, o.DefineDataProperty(name, rt.ToValue(value), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	}
+	return o
+}
+
+// callListeners calls the on* listener and the added listeners of the given
+// type. If any of them throws, the client is disconnected and the error is
+// returned.
+func (cl *client) callListeners(eventType string, ev *sobek.Object) error {
+	listeners := cl.listeners[eventType]
+	on := cl.onMessage
+	if eventType == eventError {
+		on = cl.onError
+	}
+	if on != nil {
+		listeners = append([]sobek.Callable{on}, listeners...)
+	}
+	for _, listener := range listeners {
+		if _, err := listener(sobek.Undefined(), ev); err != nil {
+			if cl.conn != nil {
+				cl.conn.close(errClosed)
+				cl.conn = nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (cl *client) addEventListener(event string, handler sobek.Value) {
+	rt := cl.mi.vu.Runtime()
+	if event != eventMessage && event != eventError {
+		common.Throw(rt, fmt.Errorf("unknown event type %q", event))
+	}
+	fn, isFunc := sobek.AssertFunction(handler)
+	if !isFunc {
+		common.Throw(rt, fmt.Errorf("handler for event type %q isn't a callable function", event))
+	}
+	cl.listeners[event] = append(cl.listeners[event], fn)
+}
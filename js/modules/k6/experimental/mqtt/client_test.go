@@ -0,0 +1,215 @@
+package mqtt
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"log/slog"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/netext"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+type testState struct {
+	*modulestest.Runtime
+	samples chan metrics.SampleContainer
+}
+
+func newTestState(t *testing.T, tlsConfig *tls.Config) testState {
+	t.Helper()
+
+	runtime := modulestest.NewRuntime(t)
+	m, ok := New().NewModuleInstance(runtime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, runtime.VU.Runtime().Set("mqtt", m.Exports().Named))
+
+	samples := make(chan metrics.SampleContainer, 1000)
+	registry := metrics.NewRegistry()
+	runtime.MoveToVUContext(&lib.State{
+		Dialer: netext.NewDialer(net.Dialer{Timeout: 2 * time.Second},
+			netext.NewResolver(net.LookupIP, 0, types.DNSfirst, types.DNSpreferIPv4)),
+		TLSConfig: tlsConfig,
+		Samples:   samples,
+		Options: lib.Options{
+			SystemTags: metrics.NewSystemTagSet(metrics.TagProto, metrics.TagURL, metrics.TagName),
+		},
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+		Logger:         logrus.New(),
+		VUID:           1,
+	})
+
+	return testState{Runtime: runtime, samples: samples}
+}
+
+// metricCounts returns the number of samples of each metric.
+func (ts testState) metricCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, sampleContainer := range metrics.GetBufferedSamples(ts.samples) {
+		for _, sample := range sampleContainer.GetSamples() {
+			counts[sample.Metric.Name]++
+		}
+	}
+	return counts
+}
+
+// newBroker starts an in-process broker, and returns its address. If users
+// are given, only they are allowed to connect.
+func newBroker(t *testing.T, tlsConfig *tls.Config, users auth.Users) string {
+	t.Helper()
+
+	server := mochi.New(&mochi.Options{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	if users == nil {
+		require.NoError(t, server.AddHook(new(auth.AllowHook), nil))
+	} else {
+		require.NoError(t, server.AddHook(new(auth.Hook), &auth.Options{Ledger: &auth.Ledger{Users: users}}))
+	}
+	l := listeners.NewTCP(listeners.Config{ID: "t", Address: "127.0.0.1:0", TLSConfig: tlsConfig})
+	require.NoError(t, server.AddListener(l))
+	require.NoError(t, server.Serve())
+	t.Cleanup(func() { _ = server.Close() })
+	return l.Address()
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	for _, version := range []string{"3.1.1", "5"} {
+		version := version
+		t.Run(version, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestState(t, nil)
+			addr := newBroker(t, nil, nil)
+			_, err := ts.RunOnEventLoop(`(async () => {
+				const subscriber = new mqtt.Client({ protocolVersion: "` + version + `", clientId: "subscriber" });
+				const publisher = new mqtt.Client({ protocolVersion: "` + version + `" });
+				await subscriber.connect("mqtt://` + addr + `");
+				await publisher.connect("mqtt://` + addr + `");
+				if (!subscriber.connected) { throw new Error("the subscriber isn't connected") }
+
+				const messages = [];
+				const received = new Promise((resolve) => {
+					subscriber.onmessage = (e) => {
+						messages.push(e.topic + ":" + e.payload + ":" + e.qos);
+						if (messages.length == 3) { resolve() }
+					};
+				});
+				const granted = await subscriber.subscribe("devices/+/telemetry", { qos: 2 });
+				if (granted != 2) { throw new Error("wrong granted QoS " + granted) }
+
+				await publisher.publish("devices/a/telemetry", "qos0");
+				await publisher.publish("devices/b/telemetry", "qos1", { qos: 1 });
+				await publisher.publish("devices/a/status", "ignored", { qos: 1 });
+				await publisher.publish("devices/c/telemetry", new Uint8Array([113, 111, 115, 50]).buffer, { qos: 2 });
+				await received;
+
+				const expected = "devices/a/telemetry:qos0:0,devices/b/telemetry:qos1:1,devices/c/telemetry:qos2:2";
+				if (messages.join() != expected) { throw new Error("wrong messages " + messages.join()) }
+
+				await subscriber.unsubscribe("devices/+/telemetry");
+				await subscriber.close();
+				await publisher.close();
+				if (subscriber.connected) { throw new Error("the subscriber is still connected") }
+			})()`)
+			require.NoError(t, err)
+
+			counts := ts.metricCounts()
+			assert.Equal(t, 2, counts["mqtt_connecting"])
+			assert.Equal(t, 4, counts["mqtt_messages_sent"])
+			assert.Equal(t, 3, counts["mqtt_messages_received"])
+			assert.Equal(t, 3, counts["mqtt_publish_ack_duration"])
+			assert.Zero(t, counts["mqtt_connection_failures"])
+		})
+	}
+}
+
+func TestClientAuth(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t, nil)
+	addr := newBroker(t, nil, auth.Users{"k6": {Username: "k6", Password: "secret"}})
+	_, err := ts.RunOnEventLoop(`(async () => {
+		let client = new mqtt.Client({ username: "k6", password: "secret" });
+		await client.connect("mqtt://` + addr + `");
+		await client.close();
+
+		client = new mqtt.Client();
+		await client.connect("mqtt://k6:secret@` + addr + `");
+		await client.close();
+
+		try {
+			await new mqtt.Client({ username: "k6", password: "wrong" }).connect("mqtt://` + addr + `");
+			throw new Error("the client connected with a wrong password");
+		} catch (e) {
+			if (!e.message.includes("connection refused: not authorized")) { throw e }
+		}
+	})()`)
+	require.NoError(t, err)
+
+	counts := ts.metricCounts()
+	assert.Equal(t, 2, counts["mqtt_connecting"])
+	assert.Equal(t, 1, counts["mqtt_connection_failures"])
+}
+
+func TestClientTLS(t *testing.T) {
+	t.Parallel()
+
+	// The certificate of httptest is valid for example.com and 127.0.0.1.
+	srv := httptest.NewTLSServer(nil)
+	t.Cleanup(srv.Close)
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+
+	ts := newTestState(t, &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12})
+	addr := newBroker(t, &tls.Config{Certificates: srv.TLS.Certificates, MinVersion: tls.VersionTLS12}, nil)
+	_, err := ts.RunOnEventLoop(`(async () => {
+		let client = new mqtt.Client();
+		await client.connect("mqtts://` + addr + `");
+		await client.publish("devices/a/telemetry", "secure", { qos: 1 });
+		await client.close();
+
+		client = new mqtt.Client({ tls: { serverName: "example.com" } });
+		await client.connect("mqtt://` + addr + `");
+		await client.close();
+	})()`)
+	require.NoError(t, err)
+}
+
+func TestClientErrors(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t, nil)
+	_, err := ts.RunOnEventLoop(`new mqtt.Client({ qos: 1 })`)
+	require.ErrorContains(t, err, `unknown param: "qos"`)
+
+	_, err = ts.RunOnEventLoop(`new mqtt.Client().connect("http://127.0.0.1")`)
+	require.ErrorContains(t, err, "the scheme should be mqtt or mqtts")
+
+	_, err = ts.RunOnEventLoop(`new mqtt.Client().publish("topic", "data", { qos: 3 })`)
+	require.ErrorContains(t, err, "the QoS should be 0, 1 or 2")
+
+	_, err = ts.RunOnEventLoop(`(async () => {
+		try {
+			await new mqtt.Client().publish("topic", "data");
+			throw new Error("the message was published without a connection");
+		} catch (e) {
+			if (!e.message.includes("the client isn't connected")) { throw e }
+		}
+	})()`)
+	require.NoError(t, err)
+}
@@ -0,0 +1,450 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mochi-mqtt/server/v2/packets"
+)
+
+// errClosed is the error of the operations on a connection which has been
+// closed by the client.
+var errClosed = errors.New("the client isn't connected")
+
+// maxPacketSize is the maximum size of the received packets, which is the
+// maximum size allowed by MQTT.
+const maxPacketSize = 268435455
+
+// connection is an established MQTT connection, which reads the packets from
+// the broker in the background. Its methods are safe for concurrent use.
+type connection struct {
+	conn    net.Conn
+	r       *bufio.Reader
+	version byte
+
+	writeMu  sync.Mutex
+	lastSent atomic.Int64
+
+	// pending keeps the channels to which the acknowledgements of the sent
+	// packets are delivered, by their packet IDs.
+	pendingMu sync.Mutex
+	pending   map[uint16]chan packets.Packet
+	nextID    uint16
+
+	// inbound keeps the IDs of the received QoS 2 messages until they are
+	// released, so the duplicates aren't delivered again. It's used only by
+	// the read loop.
+	inbound      map[uint16]struct{}
+	lastReceived atomic.Int64
+
+	// done is closed when the connection is closed, err is the reason.
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+func newConnection(conn net.Conn, version byte) *connection {
+	c := &connection{
+		conn:    conn,
+		r:       bufio.NewReader(conn),
+		version: version,
+		pending: make(map[uint16]chan packets.Packet),
+		inbound: make(map[uint16]struct{}),
+		done:    make(chan struct{}),
+	}
+	now := time.Now().UnixNano()
+	c.lastSent.Store(now)
+	c.lastReceived.Store(now)
+	return c
+}
+
+// handshake sends the CONNECT packet and waits for the CONNACK one, until
+// the context is done.
+func (c *connection) handshake(ctx context.Context, connect packets.Packet) (packets.Packet, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = c.conn.SetDeadline(deadline)
+		defer func() { _ = c.conn.SetDeadline(time.Time{}) }()
+	}
+	stop := context.AfterFunc(ctx, func() { _ = c.conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if err := c.write(connect); err != nil {
+		return packets.Packet{}, err
+	}
+	connack, err := c.read()
+	if err != nil {
+		return packets.Packet{}, err
+	}
+	if connack.FixedHeader.Type != packets.Connack {
+		return packets.Packet{}, fmt.Errorf("unexpected %s packet instead of Connack",
+			packets.PacketNames[connack.FixedHeader.Type])
+	}
+	if connack.ReasonCode != 0 {
+		return connack, connackError(c.version, connack.ReasonCode)
+	}
+	return connack, nil
+}
+
+// connackError returns the error of a CONNACK return code of MQTT 3.1.1, or
+// of its reason code of MQTT 5.
+func connackError(version, code byte) error {
+	v3Reasons := map[byte]string{
+		1: "unacceptable protocol version",
+		2: "identifier rejected",
+		3: "server unavailable",
+		4: "bad user name or password",
+		5: "not authorized",
+	}
+	v5Reasons := map[byte]string{
+		0x80: "unspecified error",
+		0x84: "unsupported protocol version",
+		0x85: "client identifier not valid",
+		0x86: "bad user name or password",
+		0x87: "not authorized",
+		0x88: "server unavailable",
+		0x89: "server busy",
+		0x8A: "banned",
+	}
+	reasons := v3Reasons
+	if version == 5 {
+		reasons = v5Reasons
+	}
+	if reason, ok := reasons[code]; ok {
+		return fmt.Errorf("connection refused: %s", reason)
+	}
+	return fmt.Errorf("connection refused with code 0x%02X", code)
+}
+
+// write encodes and writes a packet.
+func (c *connection) write(pk packets.Packet) error {
+	pk.ProtocolVersion = c.version
+	var buf bytes.Buffer
+	var err error
+	switch pk.FixedHeader.Type {
+	case packets.Connect:
+		err = pk.ConnectEncode(&buf)
+	case packets.Publish:
+		err = pk.PublishEncode(&buf)
+	case packets.Puback:
+		err = pk.PubackEncode(&buf)
+	case packets.Pubrec:
+		err = pk.PubrecEncode(&buf)
+	case packets.Pubrel:
+		err = pk.PubrelEncode(&buf)
+	case packets.Pubcomp:
+		err = pk.PubcompEncode(&buf)
+	case packets.Subscribe:
+		err = pk.SubscribeEncode(&buf)
+	case packets.Unsubscribe:
+		err = pk.UnsubscribeEncode(&buf)
+	case packets.Pingreq:
+		err = pk.PingreqEncode(&buf)
+	case packets.Disconnect:
+		err = pk.DisconnectEncode(&buf)
+	default:
+		err = fmt.Errorf("unexpected packet type %d", pk.FixedHeader.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err = c.conn.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	c.lastSent.Store(time.Now().UnixNano())
+	return nil
+}
+
+// read reads and decodes the next packet.
+func (c *connection) read() (packets.Packet, error) {
+	pk := packets.Packet{ProtocolVersion: c.version}
+	b, err := c.r.ReadByte()
+	if err != nil {
+		return pk, err
+	}
+	if err = pk.FixedHeader.Decode(b); err != nil {
+		return pk, err
+	}
+	if pk.FixedHeader.Remaining, _, err = packets.DecodeLength(c.r); err != nil {
+		return pk, err
+	}
+	if pk.FixedHeader.Remaining > maxPacketSize {
+		return pk, packets.ErrPacketTooLarge
+	}
+	buf := make([]byte, pk.FixedHeader.Remaining)
+	if _, err = io.ReadFull(c.r, buf); err != nil {
+		return pk, err
+	}
+	c.lastReceived.Store(time.Now().UnixNano())
+
+	switch pk.FixedHeader.Type {
+	case packets.Connack:
+		err = pk.ConnackDecode(buf)
+	case packets.Publish:
+		err = pk.PublishDecode(buf)
+	case packets.Puback:
+		err = pk.PubackDecode(buf)
+	case packets.Pubrec:
+		err = pk.PubrecDecode(buf)
+	case packets.Pubrel:
+		err = pk.PubrelDecode(buf)
+	case packets.Pubcomp:
+		err = pk.PubcompDecode(buf)
+	case packets.Suback:
+		err = pk.SubackDecode(buf)
+	case packets.Unsuback:
+		err = pk.UnsubackDecode(buf)
+	case packets.Disconnect:
+		err = pk.DisconnectDecode(buf)
+	case packets.Pingresp:
+	default:
+		err = fmt.Errorf("unexpected %s packet", packets.PacketNames[pk.FixedHeader.Type])
+	}
+	return pk, err
+}
+
+// run reads the packets until the connection is closed, and calls onPublish
+// for every received message. The QoS 1 and 2 messages are acknowledged
+// after onPublish returns.
+func (c *connection) run(onPublish func(packets.Packet)) {
+	for {
+		pk, err := c.read()
+		if err != nil {
+			c.close(err)
+			return
+		}
+
+		switch pk.FixedHeader.Type {
+		case packets.Publish:
+			err = c.received(pk, onPublish)
+		case packets.Pubrel:
+			delete(c.inbound, pk.PacketID)
+			err = c.write(packets.Packet{FixedHeader: packets.FixedHeader{Type: packets.Pubcomp}, PacketID: pk.PacketID})
+		case packets.Puback, packets.Pubrec, packets.Pubcomp, packets.Suback, packets.Unsuback:
+			c.acknowledged(pk)
+		case packets.Disconnect:
+			err = fmt.Errorf("disconnected by the broker with reason code 0x%02X", pk.ReasonCode)
+		}
+		if err != nil {
+			c.close(err)
+			return
+		}
+	}
+}
+
+func (c *connection) received(pk packets.Packet, onPublish func(packets.Packet)) error {
+	switch pk.FixedHeader.Qos {
+	case 0:
+		onPublish(pk)
+		return nil
+	case 1:
+		onPublish(pk)
+		return c.write(packets.Packet{FixedHeader: packets.FixedHeader{Type: packets.Puback}, PacketID: pk.PacketID})
+	default:
+		if _, ok := c.inbound[pk.PacketID]; !ok {
+			c.inbound[pk.PacketID] = struct{}{}
+			onPublish(pk)
+		}
+		return c.write(packets.Packet{FixedHeader: packets.FixedHeader{Type: packets.Pubrec}, PacketID: pk.PacketID})
+	}
+}
+
+// acknowledged delivers an acknowledgement to the sender of the packet.
+func (c *connection) acknowledged(pk packets.Packet) {
+	c.pendingMu.Lock()
+	acks, ok := c.pending[pk.PacketID]
+	c.pendingMu.Unlock()
+	if !ok {
+		return
+	}
+	select {
+	case acks <- pk:
+	default:
+	}
+}
+
+// keepAlive sends a PINGREQ packet if nothing has been sent for the given
+// interval, and closes the connection if nothing has been received for
+// one and a half of it.
+func (c *connection) keepAlive(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.done:
+			return
+		}
+		now := time.Now()
+		if now.Sub(time.Unix(0, c.lastReceived.Load())) > interval*3/2 {
+			c.close(errors.New("the broker didn't answer within the keep alive interval"))
+			return
+		}
+		if now.Sub(time.Unix(0, c.lastSent.Load())) >= interval/2 {
+			if err := c.write(packets.Packet{FixedHeader: packets.FixedHeader{Type: packets.Pingreq}}); err != nil {
+				c.close(err)
+				return
+			}
+		}
+	}
+}
+
+// request sends a packet which is acknowledged by the broker, with a new
+// packet ID, and returns the channel to which the acknowledgements are
+// delivered. The release function has to be called after that.
+func (c *connection) request(pk packets.Packet) (acks <-chan packets.Packet, release func(), err error) {
+	c.pendingMu.Lock()
+	for i := 0; ; i++ {
+		if i == 65535 {
+			c.pendingMu.Unlock()
+			return nil, nil, errors.New("too many messages waiting for an acknowledgement")
+		}
+		c.nextID++
+		if c.nextID == 0 {
+			c.nextID = 1
+		}
+		if _, ok := c.pending[c.nextID]; !ok {
+			break
+		}
+	}
+	id := c.nextID
+	ch := make(chan packets.Packet, 1)
+	c.pending[id] = ch
+	c.pendingMu.Unlock()
+
+	release = func() {
+		c.pendingMu.Lock()
+		delete(c.pending, id)
+		c.pendingMu.Unlock()
+	}
+	pk.PacketID = id
+	if err = c.write(pk); err != nil {
+		release()
+		return nil, nil, err
+	}
+	return ch, release, nil
+}
+
+// wait waits for an acknowledgement of the given type.
+func (c *connection) wait(acks <-chan packets.Packet, packetType byte) (packets.Packet, error) {
+	select {
+	case pk := <-acks:
+		if pk.FixedHeader.Type != packetType {
+			return pk, fmt.Errorf("unexpected %s packet instead of %s",
+				packets.PacketNames[pk.FixedHeader.Type], packets.PacketNames[packetType])
+		}
+		if pk.ReasonCode >= 0x80 {
+			return pk, fmt.Errorf("%s with reason code 0x%02X", packets.PacketNames[packetType], pk.ReasonCode)
+		}
+		return pk, nil
+	case <-c.done:
+		return packets.Packet{}, c.err
+	}
+}
+
+// publish sends a message and waits for its acknowledgement, which is
+// PUBACK for QoS 1 and PUBCOMP for QoS 2.
+func (c *connection) publish(topic string, payload []byte, qos byte, retain bool) error {
+	pk := packets.Packet{
+		FixedHeader: packets.FixedHeader{Type: packets.Publish, Qos: qos, Retain: retain},
+		TopicName:   topic,
+		Payload:     payload,
+	}
+	if qos == 0 {
+		return c.write(pk)
+	}
+
+	acks, release, err := c.request(pk)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if qos == 1 {
+		_, err = c.wait(acks, packets.Puback)
+		return err
+	}
+
+	pubrec, err := c.wait(acks, packets.Pubrec)
+	if err != nil {
+		return err
+	}
+	err = c.write(packets.Packet{FixedHeader: packets.FixedHeader{Type: packets.Pubrel, Qos: 1}, PacketID: pubrec.PacketID})
+	if err != nil {
+		return err
+	}
+	_, err = c.wait(acks, packets.Pubcomp)
+	return err
+}
+
+// subscribe subscribes to the topic filter, and returns the granted QoS.
+func (c *connection) subscribe(filter string, qos byte) (byte, error) {
+	acks, release, err := c.request(packets.Packet{
+		FixedHeader: packets.FixedHeader{Type: packets.Subscribe, Qos: 1},
+		Filters:     packets.Subscriptions{{Filter: filter, Qos: qos}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	suback, err := c.wait(acks, packets.Suback)
+	if err != nil {
+		return 0, err
+	}
+	if len(suback.ReasonCodes) != 1 {
+		return 0, errors.New("invalid Suback packet")
+	}
+	if code := suback.ReasonCodes[0]; code >= 0x80 {
+		return 0, fmt.Errorf("the subscription was refused with reason code 0x%02X", code)
+	}
+	return suback.ReasonCodes[0], nil
+}
+
+// unsubscribe unsubscribes from the topic filter.
+func (c *connection) unsubscribe(filter string) error {
+	acks, release, err := c.request(packets.Packet{
+		FixedHeader: packets.FixedHeader{Type: packets.Unsubscribe, Qos: 1},
+		Filters:     packets.Subscriptions{{Filter: filter}},
+	})
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	unsuback, err := c.wait(acks, packets.Unsuback)
+	if err != nil {
+		return err
+	}
+	if len(unsuback.ReasonCodes) == 1 && unsuback.ReasonCodes[0] >= 0x80 {
+		return fmt.Errorf("the unsubscription was refused with reason code 0x%02X", unsuback.ReasonCodes[0])
+	}
+	return nil
+}
+
+// disconnect sends a DISCONNECT packet and closes the connection.
+func (c *connection) disconnect() {
+	_ = c.write(packets.Packet{FixedHeader: packets.FixedHeader{Type: packets.Disconnect}})
+	c.close(errClosed)
+}
+
+// close closes the connection with the given reason, the first one is kept.
+func (c *connection) close(err error) {
+	c.closeOnce.Do(func() {
+		c.err = err
+		close(c.done)
+		_ = c.conn.Close()
+	})
+}
@@ -0,0 +1,41 @@
+package mqtt
+
+import "go.k6.io/k6/metrics"
+
+// instanceMetrics contains the metrics for the MQTT module.
+type instanceMetrics struct {
+	Connecting         *metrics.Metric
+	ConnectionFailures *metrics.Metric
+	MessagesSent       *metrics.Metric
+	MessagesReceived   *metrics.Metric
+	PublishAckDuration *metrics.Metric
+}
+
+// registerMetrics registers and returns the metrics in the provided registry
+func registerMetrics(registry *metrics.Registry) (*instanceMetrics, error) {
+	var err error
+	m := &instanceMetrics{}
+
+	if m.Connecting, err = registry.NewMetric("mqtt_connecting", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.ConnectionFailures, err = registry.NewMetric("mqtt_connection_failures", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.MessagesSent, err = registry.NewMetric("mqtt_messages_sent", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.MessagesReceived, err = registry.NewMetric("mqtt_messages_received", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.PublishAckDuration, err = registry.NewMetric(
+		"mqtt_publish_ack_duration", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,76 @@
+// Package mqtt implements an MQTT 3.1.1 and 5 client, for load testing the
+// brokers and the services behind them with many simulated devices.
+package mqtt
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/metrics"
+)
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the MQTT module for every VU.
+	ModuleInstance struct {
+		vu      modules.VU
+		metrics *instanceMetrics
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// New returns a pointer to a new RootModule instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface to return
+// a new instance for each VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	metrics, err := registerMetrics(vu.InitEnv().Registry)
+	if err != nil {
+		common.Throw(vu.Runtime(), fmt.Errorf("failed to register MQTT module metrics: %w", err))
+	}
+
+	return &ModuleInstance{
+		vu:      vu,
+		metrics: metrics,
+	}
+}
+
+// Exports returns the exports of the MQTT module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"Client": mi.client,
+		},
+	}
+}
+
+// pushSample pushes a sample of the metric with the given tags.
+func (mi *ModuleInstance) pushSample(tagsAndMeta *metrics.TagsAndMeta, metric *metrics.Metric, value float64) {
+	metrics.PushIfNotDone(mi.vu.Context(), mi.vu.State().Samples, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{Metric: metric, Tags: tagsAndMeta.Tags},
+		Time:       time.Now(),
+		Metadata:   tagsAndMeta.Metadata,
+		Value:      value,
+	})
+}
+
+// must is a small helper that will panic if err is not nil.
+func must(rt *sobek.Runtime, err error) {
+	if err != nil {
+		common.Throw(rt, err)
+	}
+}
@@ -0,0 +1,242 @@
+package mqtt
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	defaultKeepAlive      = 60 * time.Second
+	defaultConnectTimeout = 10 * time.Second
+)
+
+// clientParams is the parameters that can be passed to the Client
+// constructor.
+type clientParams struct {
+	clientID        string
+	username        string
+	password        string
+	cleanSession    bool
+	protocolVersion byte
+	keepAlive       time.Duration
+	connectTimeout  time.Duration
+	tls             *tlsParams
+	tagsAndMeta     metrics.TagsAndMeta
+}
+
+// tlsParams is the parameters of the TLS connections, besides the TLS config
+// of the VU.
+type tlsParams struct {
+	serverName         string
+	insecureSkipVerify bool
+}
+
+// newClientParams constructs the Client parameters from the input value. If
+// no input is given, the default values are used.
+func newClientParams(vu modules.VU, input sobek.Value) (*clientParams, error) {
+	result := &clientParams{
+		cleanSession:    true,
+		protocolVersion: 4,
+		keepAlive:       defaultKeepAlive,
+		connectTimeout:  defaultConnectTimeout,
+		tagsAndMeta:     vu.State().Tags.GetCurrentValues(),
+	}
+	if common.IsNullish(input) {
+		return result, nil
+	}
+
+	rt := vu.Runtime()
+	params := input.ToObject(rt)
+	for _, k := range params.Keys() {
+		v := params.Get(k)
+		var err error
+		switch k {
+		case "clientId":
+			result.clientID = v.String()
+		case "username":
+			result.username = v.String()
+		case "password":
+			result.password = v.String()
+		case "cleanSession":
+			result.cleanSession = v.ToBoolean()
+		case "protocolVersion":
+			result.protocolVersion, err = parseProtocolVersion(v.String())
+		case "keepAlive":
+			result.keepAlive, err = types.GetDurationValue(v.Export())
+			if err == nil && (result.keepAlive < 0 || result.keepAlive > 65535*time.Second) {
+				err = errors.New("it should be between 0 and 65535s")
+			}
+		case "connectTimeout":
+			result.connectTimeout, err = types.GetDurationValue(v.Export())
+		case "tls":
+			if b, ok := v.Export().(bool); ok {
+				if b {
+					result.tls = &tlsParams{}
+				}
+				continue
+			}
+			result.tls, err = newTLSParams(rt, v)
+		case "tags":
+			err = common.ApplyCustomUserTags(rt, &result.tagsAndMeta, v)
+		default:
+			return result, fmt.Errorf("unknown param: %q", k)
+		}
+		if err != nil {
+			return result, fmt.Errorf("invalid %s param: %w", k, err)
+		}
+	}
+
+	return result, nil
+}
+
+// parseProtocolVersion returns the protocol level of an MQTT version.
+func parseProtocolVersion(v string) (byte, error) {
+	switch v {
+	case "3.1.1", "4":
+		return 4, nil
+	case "5", "5.0":
+		return 5, nil
+	default:
+		return 0, fmt.Errorf("unsupported MQTT version %q, it should be 3.1.1 or 5", v)
+	}
+}
+
+func newTLSParams(rt *sobek.Runtime, input sobek.Value) (*tlsParams, error) {
+	if _, ok := input.Export().(map[string]interface{}); !ok {
+		return nil, errors.New("it should be a boolean or an object")
+	}
+
+	result := &tlsParams{}
+	params := input.ToObject(rt)
+	for _, k := range params.Keys() {
+		switch k {
+		case "serverName":
+			result.serverName = params.Get(k).String()
+		case "insecureSkipTLSVerify":
+			result.insecureSkipVerify = params.Get(k).ToBoolean()
+		default:
+			return nil, fmt.Errorf("unknown tls param: %q", k)
+		}
+	}
+	return result, nil
+}
+
+// brokerURL is the parsed URL of a broker.
+type brokerURL struct {
+	// address is the host:port to dial
+	address string
+	tls     bool
+	// username and password are set if the URL contains them
+	username, password string
+	hasPassword        bool
+	// tag is the URL without the credentials, used for the url tag
+	tag string
+}
+
+// parseBrokerURL parses the URL of a broker, with the mqtt:// (or tcp://)
+// scheme, or the mqtts:// (or ssl://, tls://) scheme for TLS.
+func parseBrokerURL(s string) (*brokerURL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid broker URL %q: %w", s, err)
+	}
+
+	result := &brokerURL{}
+	port := "1883"
+	switch u.Scheme {
+	case "mqtt", "tcp":
+	case "mqtts", "ssl", "tls":
+		result.tls = true
+		port = "8883"
+	default:
+		return nil, fmt.Errorf("invalid broker URL %q: the scheme should be mqtt or mqtts", s)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid broker URL %q: it doesn't contain a host", s)
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	result.address = net.JoinHostPort(u.Hostname(), port)
+
+	if u.User != nil {
+		result.username = u.User.Username()
+		result.password, result.hasPassword = u.User.Password()
+		u.User = nil
+	}
+	result.tag = u.String()
+	return result, nil
+}
+
+// publishParams is the parameters that can be passed to publish.
+type publishParams struct {
+	qos         byte
+	retain      bool
+	tagsAndMeta metrics.TagsAndMeta
+}
+
+func newPublishParams(rt *sobek.Runtime, tagsAndMeta metrics.TagsAndMeta, input sobek.Value) (*publishParams, error) {
+	result := &publishParams{tagsAndMeta: tagsAndMeta.Clone()}
+	if common.IsNullish(input) {
+		return result, nil
+	}
+
+	params := input.ToObject(rt)
+	for _, k := range params.Keys() {
+		var err error
+		switch k {
+		case "qos":
+			result.qos, err = parseQoS(params.Get(k))
+		case "retain":
+			result.retain = params.Get(k).ToBoolean()
+		case "tags":
+			err = common.ApplyCustomUserTags(rt, &result.tagsAndMeta, params.Get(k))
+		default:
+			return result, fmt.Errorf("unknown param: %q", k)
+		}
+		if err != nil {
+			return result, fmt.Errorf("invalid %s param: %w", k, err)
+		}
+	}
+	return result, nil
+}
+
+// parseSubscribeParams returns the QoS passed to subscribe.
+func parseSubscribeParams(rt *sobek.Runtime, input sobek.Value) (byte, error) {
+	if common.IsNullish(input) {
+		return 0, nil
+	}
+
+	var qos byte
+	params := input.ToObject(rt)
+	for _, k := range params.Keys() {
+		switch k {
+		case "qos":
+			var err error
+			if qos, err = parseQoS(params.Get(k)); err != nil {
+				return 0, fmt.Errorf("invalid qos param: %w", err)
+			}
+		default:
+			return 0, fmt.Errorf("unknown param: %q", k)
+		}
+	}
+	return qos, nil
+}
+
+func parseQoS(v sobek.Value) (byte, error) {
+	qos := v.ToInteger()
+	if qos < 0 || qos > 2 {
+		return 0, fmt.Errorf("the QoS should be 0, 1 or 2, but it's %s", v)
+	}
+	return byte(qos), nil
+}
@@ -0,0 +1,40 @@
+package net
+
+import "go.k6.io/k6/metrics"
+
+// instanceMetrics contains the metrics for the net module.
+type instanceMetrics struct {
+	Connecting     *metrics.Metric
+	TLSHandshaking *metrics.Metric
+	DataSent       *metrics.Metric
+	DataReceived   *metrics.Metric
+	RoundTrip      *metrics.Metric
+}
+
+// registerMetrics registers and returns the metrics in the provided registry
+func registerMetrics(registry *metrics.Registry) (*instanceMetrics, error) {
+	var err error
+	m := &instanceMetrics{}
+
+	if m.Connecting, err = registry.NewMetric("net_connecting", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.TLSHandshaking, err = registry.NewMetric("net_tls_handshaking", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.DataSent, err = registry.NewMetric("net_data_sent", metrics.Counter, metrics.Data); err != nil {
+		return nil, err
+	}
+
+	if m.DataReceived, err = registry.NewMetric("net_data_received", metrics.Counter, metrics.Data); err != nil {
+		return nil, err
+	}
+
+	if m.RoundTrip, err = registry.NewMetric("net_round_trip", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
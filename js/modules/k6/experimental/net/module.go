@@ -0,0 +1,96 @@
+// Package net implements raw TCP and UDP sockets, for testing the services
+// which speak custom protocols.
+package net
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/metrics"
+)
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the net module for every VU.
+	ModuleInstance struct {
+		vu      modules.VU
+		metrics *instanceMetrics
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// New returns a pointer to a new RootModule instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface to return
+// a new instance for each VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	metrics, err := registerMetrics(vu.InitEnv().Registry)
+	if err != nil {
+		common.Throw(vu.Runtime(), fmt.Errorf("failed to register net module metrics: %w", err))
+	}
+
+	return &ModuleInstance{
+		vu:      vu,
+		metrics: metrics,
+	}
+}
+
+// Exports returns the exports of the net module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"dial":    mi.dial,
+			"openUDP": mi.openUDP,
+		},
+	}
+}
+
+// sample is the value of a metric, which is pushed by pushSamples.
+type sample struct {
+	metric *metrics.Metric
+	value  float64
+}
+
+// pushSamples pushes the samples of a socket operation with the given tags.
+func (mi *ModuleInstance) pushSamples(tagsAndMeta *metrics.TagsAndMeta, values ...sample) {
+	now := time.Now()
+	samples := make([]metrics.Sample, 0, len(values))
+	for _, v := range values {
+		samples = append(samples, metrics.Sample{
+			TimeSeries: metrics.TimeSeries{Metric: v.metric, Tags: tagsAndMeta.Tags},
+			Time:       now,
+			Metadata:   tagsAndMeta.Metadata,
+			Value:      v.value,
+		})
+	}
+	metrics.PushIfNotDone(mi.vu.Context(), mi.vu.State().Samples, metrics.ConnectedSamples{
+		Samples: samples,
+		Tags:    tagsAndMeta.Tags,
+		Time:    now,
+	})
+}
+
+// errInitContext is returned when a socket is opened in the init context.
+var errInitContext = errors.New("sockets can't be opened in the init context")
+
+// must is a small helper that will panic if err is not nil.
+func must(rt *sobek.Runtime, err error) {
+	if err != nil {
+		common.Throw(rt, err)
+	}
+}
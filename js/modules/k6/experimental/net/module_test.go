@@ -0,0 +1,314 @@
+package net
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/netext"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+type testState struct {
+	*modulestest.Runtime
+	samples chan metrics.SampleContainer
+}
+
+// newTestState returns a runtime in the VU context, whose dialer resolves
+// echo.test to localhost and blocks blocked.test.
+func newTestState(t *testing.T, tlsConfig *tls.Config) testState {
+	t.Helper()
+
+	runtime := modulestest.NewRuntime(t)
+	m, ok := New().NewModuleInstance(runtime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, runtime.VU.Runtime().Set("net", m.Exports().Named))
+
+	dialer := netext.NewDialer(net.Dialer{Timeout: 2 * time.Second},
+		netext.NewResolver(net.LookupIP, 0, types.DNSfirst, types.DNSpreferIPv4))
+	var err error
+	dialer.Hosts, err = types.NewHosts(map[string]types.Host{"echo.test": {IP: net.ParseIP("127.0.0.1")}})
+	require.NoError(t, err)
+	dialer.BlockedHostnames, err = types.NewHostnameTrie([]string{"blocked.test"})
+	require.NoError(t, err)
+
+	samples := make(chan metrics.SampleContainer, 1000)
+	registry := metrics.NewRegistry()
+	runtime.MoveToVUContext(&lib.State{
+		Dialer:    dialer,
+		TLSConfig: tlsConfig,
+		Samples:   samples,
+		Options: lib.Options{
+			SystemTags: metrics.NewSystemTagSet(metrics.TagProto, metrics.TagURL, metrics.TagName),
+		},
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+		Logger:         logrus.New(),
+	})
+
+	return testState{Runtime: runtime, samples: samples}
+}
+
+// metricURLs returns the url tags of the samples of each metric.
+func (ts testState) metricURLs() map[string][]string {
+	urls := make(map[string][]string)
+	for _, sampleContainer := range metrics.GetBufferedSamples(ts.samples) {
+		for _, sample := range sampleContainer.GetSamples() {
+			url, _ := sample.Tags.Get("url")
+			urls[sample.Metric.Name] = append(urls[sample.Metric.Name], url)
+		}
+	}
+	return urls
+}
+
+// serveTCP starts a server which answers each line with the uppercased line.
+// After a STARTTLS line, it answers OK and upgrades the connection to TLS if
+// a TLS config is given.
+func serveTCP(t *testing.T, l net.Listener, tlsConfig *tls.Config) string {
+	t.Helper()
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				r := bufio.NewReader(conn)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == "STARTTLS\n" && tlsConfig != nil {
+						_, _ = conn.Write([]byte("OK\n"))
+						conn = tls.Server(conn, tlsConfig)
+						r = bufio.NewReader(conn)
+						continue
+					}
+					if line == "QUIT\n" {
+						return
+					}
+					_, _ = conn.Write([]byte(strings.ToUpper(line)))
+				}
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestTCPSocket(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t, nil)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	_, port, _ := net.SplitHostPort(serveTCP(t, l, nil))
+
+	_, err = ts.RunOnEventLoop(`(async () => {
+		const socket = await net.dial("echo.test:` + port + `");
+		if (!socket.remoteAddress.startsWith("127.0.0.1:")) { throw new Error("wrong remoteAddress " + socket.remoteAddress) }
+
+		const written = await socket.write("ping\n");
+		if (written != 5) { throw new Error("wrong written bytes " + written) }
+		let data = String.fromCharCode(...new Uint8Array(await socket.read({ size: 5 })));
+		if (data != "PING\n") { throw new Error("wrong data " + data) }
+
+		await socket.write(new Uint8Array([104, 105, 10]).buffer);
+		data = String.fromCharCode(...new Uint8Array(await socket.read({ timeout: "1s" })));
+		if (data != "HI\n") { throw new Error("wrong data " + data) }
+
+		try {
+			await socket.read({ timeout: "10ms" });
+			throw new Error("the read didn't time out");
+		} catch (e) {
+			if (!e.message.includes("read timed out after 10ms")) { throw e }
+		}
+
+		await socket.write("QUIT\n");
+		if (await socket.read() !== null) { throw new Error("the connection wasn't closed by the server") }
+		socket.close();
+	})()`)
+	require.NoError(t, err)
+
+	url := "tcp://echo.test:" + port
+	urls := ts.metricURLs()
+	assert.Equal(t, []string{url}, urls["net_connecting"])
+	assert.Equal(t, []string{url, url, url}, urls["net_data_sent"])
+	assert.Equal(t, []string{url, url}, urls["net_data_received"])
+	assert.Equal(t, []string{url, url}, urls["net_round_trip"])
+}
+
+func TestTCPSocketTLS(t *testing.T) {
+	t.Parallel()
+
+	// The certificate of httptest is valid for example.com and 127.0.0.1.
+	srv := httptest.NewTLSServer(nil)
+	t.Cleanup(srv.Close)
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	serverConfig := &tls.Config{Certificates: srv.TLS.Certificates, MinVersion: tls.VersionTLS12}
+
+	ts := newTestState(t, &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12})
+	l, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	require.NoError(t, err)
+	tlsAddr := serveTCP(t, l, nil)
+	_, tlsPort, _ := net.SplitHostPort(tlsAddr)
+	l, err = net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	_, port, _ := net.SplitHostPort(serveTCP(t, l, serverConfig))
+
+	_, err = ts.RunOnEventLoop(`(async () => {
+		let socket = await net.dial("` + tlsAddr + `", { tls: true });
+		await socket.write("tls\n");
+		let data = String.fromCharCode(...new Uint8Array(await socket.read()));
+		if (data != "TLS\n") { throw new Error("wrong data " + data) }
+		socket.close();
+
+		socket = await net.dial("echo.test:` + port + `");
+		await socket.write("STARTTLS\n");
+		data = String.fromCharCode(...new Uint8Array(await socket.read()));
+		if (data != "OK\n") { throw new Error("wrong data " + data) }
+		await socket.upgradeTLS({ serverName: "example.com" });
+		await socket.write("upgraded\n");
+		data = String.fromCharCode(...new Uint8Array(await socket.read()));
+		if (data != "UPGRADED\n") { throw new Error("wrong data " + data) }
+		socket.close();
+
+		try {
+			await net.dial("echo.test:` + tlsPort + `", { tls: true });
+			throw new Error("the certificate was verified for the wrong name");
+		} catch (e) {
+			if (!e.message.includes("certificate is valid for example.com")) { throw e }
+		}
+	})()`)
+	require.NoError(t, err)
+
+	urls := ts.metricURLs()
+	assert.Equal(t, []string{"tls://" + tlsAddr, "tcp://echo.test:" + port}, urls["net_tls_handshaking"])
+}
+
+func TestTCPSocketErrors(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t, nil)
+	_, err := ts.RunOnEventLoop(`(async () => {
+		try {
+			await net.dial("blocked.test:80");
+			throw new Error("the blocked hostname was dialed");
+		} catch (e) {
+			if (!e.message.includes("hostname (blocked.test) is in a blocked pattern (blocked.test)")) { throw e }
+		}
+	})()`)
+	require.NoError(t, err)
+
+	_, err = ts.RunOnEventLoop(`net.dial("echo.test")`)
+	require.ErrorContains(t, err, `invalid address "echo.test"`)
+
+	_, err = ts.RunOnEventLoop(`net.dial("echo.test:80", { unknown: true })`)
+	require.ErrorContains(t, err, `unknown param: "unknown"`)
+
+	_, err = ts.RunOnEventLoop(`net.dial("echo.test:80", { tls: "yes" })`)
+	require.ErrorContains(t, err, "invalid tls param: must be a boolean or an object")
+}
+
+func TestTCPSocketClosedBeforeOpen(t *testing.T) {
+	t.Parallel()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+	closed := make(chan struct{})
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		_, _ = io.Copy(io.Discard, conn) // until the connection is closed by the client
+		close(closed)
+	}()
+	_, port, err := net.SplitHostPort(l.Addr().String())
+	require.NoError(t, err)
+
+	// The event loop isn't running, so the socket is never opened on it.
+	ts := newTestState(t, nil)
+	_, err = ts.VU.Runtime().RunString(`net.dial("echo.test:` + port + `")`)
+	require.NoError(t, err)
+
+	<-ts.samples // the connecting time, which is measured once the connection is established
+	ts.CancelContext()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the connection wasn't closed when the iteration ended")
+	}
+}
+
+func TestUDPSocket(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t, nil)
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = pc.Close() })
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = pc.WriteTo([]byte(strings.ToUpper(string(buf[:n]))), addr)
+		}
+	}()
+	_, port, _ := net.SplitHostPort(pc.LocalAddr().String())
+
+	_, err = ts.RunOnEventLoop(`
+	var messages = [];
+	var socket = net.openUDP({ tags: { custom: "tag" } });
+	socket.onmessage = (e) => {
+		messages.push(String.fromCharCode(...new Uint8Array(e.data)));
+		if (e.remoteAddress != "` + pc.LocalAddr().String() + `") { throw new Error("wrong remoteAddress " + e.remoteAddress) }
+		if (messages.length == 1) {
+			socket.sendTo("echo.test:` + port + `", "second");
+		} else {
+			if (messages.join(",") != "FIRST,SECOND") { throw new Error("wrong messages " + messages.join(",")) }
+			socket.close();
+		}
+	};
+	socket.sendTo("echo.test:` + port + `", "first");
+	socket.sendTo("blocked.test:53", "first").then(
+		() => { throw new Error("the message was sent to the blocked hostname") },
+		(e) => { if (!e.message.includes("is in a blocked pattern")) { throw e } },
+	);
+	`)
+	require.NoError(t, err)
+
+	url := "udp://echo.test:" + port
+	urls := ts.metricURLs()
+	assert.Equal(t, []string{url, url}, urls["net_data_sent"])
+	assert.Equal(t, []string{url, url}, urls["net_data_received"])
+	assert.Equal(t, []string{url, url}, urls["net_round_trip"])
+
+	_, err = ts.RunOnEventLoop(`
+	var socket = net.openUDP();
+	socket.addEventListener("message", () => {});
+	socket.close();
+	socket.addEventListener("close", () => {});
+	`)
+	require.ErrorContains(t, err, `unknown event type "close"`)
+}
@@ -0,0 +1,151 @@
+package net
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+// defaultDialTimeout is the timeout of the connection establishment, unless
+// another one is given.
+const defaultDialTimeout = 60 * time.Second
+
+// tlsParams is the parameters of a TLS connection.
+type tlsParams struct {
+	serverName         string
+	insecureSkipVerify bool
+}
+
+// dialParams is the parameters that can be passed to dial.
+type dialParams struct {
+	tls         *tlsParams
+	timeout     time.Duration
+	tagsAndMeta metrics.TagsAndMeta
+}
+
+// newDialParams constructs the dial parameters from the input value. If no
+// input is given, the default values are used.
+func newDialParams(vu modules.VU, input sobek.Value) (*dialParams, error) {
+	result := &dialParams{
+		timeout:     defaultDialTimeout,
+		tagsAndMeta: vu.State().Tags.GetCurrentValues(),
+	}
+	if common.IsNullish(input) {
+		return result, nil
+	}
+
+	rt := vu.Runtime()
+	params := input.ToObject(rt)
+	for _, k := range params.Keys() {
+		switch k {
+		case "tls":
+			v := params.Get(k)
+			if b, ok := v.Export().(bool); ok {
+				if b {
+					result.tls = &tlsParams{}
+				}
+				continue
+			}
+			var err error
+			if result.tls, err = newTLSParams(rt, v); err != nil {
+				return result, err
+			}
+		case "timeout":
+			var err error
+			result.timeout, err = types.GetDurationValue(params.Get(k).Export())
+			if err != nil {
+				return result, fmt.Errorf("invalid timeout value: %w", err)
+			}
+		case "tags":
+			if err := common.ApplyCustomUserTags(rt, &result.tagsAndMeta, params.Get(k)); err != nil {
+				return result, fmt.Errorf("metric tags: %w", err)
+			}
+		default:
+			return result, fmt.Errorf("unknown param: %q", k)
+		}
+	}
+
+	return result, nil
+}
+
+// newTLSParams constructs the TLS parameters from the input value, which is
+// used by the tls param of dial and by upgradeTLS.
+func newTLSParams(rt *sobek.Runtime, input sobek.Value) (*tlsParams, error) {
+	result := &tlsParams{}
+	if common.IsNullish(input) {
+		return result, nil
+	}
+	if _, ok := input.Export().(map[string]interface{}); !ok {
+		return result, errors.New("invalid tls param: must be a boolean or an object")
+	}
+
+	params := input.ToObject(rt)
+	for _, k := range params.Keys() {
+		switch k {
+		case "serverName":
+			result.serverName = params.Get(k).String()
+		case "insecureSkipTLSVerify":
+			result.insecureSkipVerify = params.Get(k).ToBoolean()
+		default:
+			return result, fmt.Errorf("unknown tls param: %q", k)
+		}
+	}
+	return result, nil
+}
+
+// readParams is the parameters that can be passed to read.
+type readParams struct {
+	// timeout is the maximum time to wait for the data, zero means forever.
+	timeout time.Duration
+	// size is the exact number of bytes to read, zero means any data.
+	size int64
+}
+
+func newReadParams(rt *sobek.Runtime, input sobek.Value) (*readParams, error) {
+	result := &readParams{}
+	if common.IsNullish(input) {
+		return result, nil
+	}
+
+	params := input.ToObject(rt)
+	for _, k := range params.Keys() {
+		switch k {
+		case "timeout":
+			var err error
+			result.timeout, err = types.GetDurationValue(params.Get(k).Export())
+			if err != nil {
+				return result, fmt.Errorf("invalid timeout value: %w", err)
+			}
+		case "size":
+			result.size = params.Get(k).ToInteger()
+			if result.size <= 0 || result.size > maxReadSize {
+				return result, fmt.Errorf("invalid size value: it should be between 1 and %d", maxReadSize)
+			}
+		default:
+			return result, fmt.Errorf("unknown param: %q", k)
+		}
+	}
+	return result, nil
+}
+
+// newSocketTags returns the tags of the metrics of a socket, with the proto
+// and the url system tags, unless a name tag was given.
+func newSocketTags(vu modules.VU, tagsAndMeta metrics.TagsAndMeta, proto, url string) metrics.TagsAndMeta {
+	tagsAndMeta = tagsAndMeta.Clone()
+	systemTags := vu.State().Options.SystemTags
+	tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagProto, proto)
+	if nameTagValue, ok := tagsAndMeta.Tags.Get(metrics.TagName.String()); ok {
+		tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagURL, nameTagValue)
+	} else {
+		tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagURL, url)
+		tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagName, url)
+	}
+	return tagsAndMeta
+}
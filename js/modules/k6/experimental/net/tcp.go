@@ -0,0 +1,333 @@
+package net
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/metrics"
+)
+
+// maxReadSize is the maximum size of the data returned by a single read.
+const maxReadSize = 64 * 1024
+
+// tcpSocket is a TCP connection, whose methods return promises which are
+// resolved on the event loop, while the I/O is done in the background.
+type tcpSocket struct {
+	mi          *ModuleInstance
+	obj         *sobek.Object // the object that is given to js to interact with the socket
+	host        string        // the host which was dialed, used as the server name of TLS
+	tagsAndMeta metrics.TagsAndMeta
+
+	// ctx is done when the socket is closed or the iteration ends
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// readMu and writeMu serialize the reads and the writes, both of them are
+	// held while the connection is upgraded to TLS.
+	readMu  sync.Mutex
+	writeMu sync.Mutex
+	connMu  sync.Mutex
+	conn    net.Conn
+
+	// lastWrite is the time of the last write which hasn't been answered yet,
+	// from which the round trip is measured.
+	lastWriteMu sync.Mutex
+	lastWrite   time.Time
+}
+
+// dial connects to the address over TCP, optionally with TLS, and returns a
+// promise which is resolved with the socket.
+func (mi *ModuleInstance) dial(address string, input sobek.Value) *sobek.Promise {
+	rt := mi.vu.Runtime()
+	state := mi.vu.State()
+	if state == nil {
+		common.Throw(rt, errInitContext)
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("invalid address %q: %w", address, err))
+	}
+	params, err := newDialParams(mi.vu, input)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	proto := "tcp"
+	if params.tls != nil {
+		proto = "tls"
+	}
+	s := &tcpSocket{
+		mi:          mi,
+		host:        host,
+		tagsAndMeta: newSocketTags(mi.vu, params.tagsAndMeta, proto, proto+"://"+address),
+	}
+
+	promise, resolve, reject := rt.NewPromise()
+	callback := mi.vu.RegisterCallback()
+	go func() {
+		ctx, cancel := context.WithTimeout(mi.vu.Context(), params.timeout)
+		defer cancel()
+
+		start := time.Now()
+		conn, err := state.Dialer.DialContext(ctx, "tcp", address)
+		if err == nil {
+			mi.pushSamples(&s.tagsAndMeta, sample{mi.metrics.Connecting, metrics.D(time.Since(start))})
+			if params.tls != nil {
+				conn, err = s.handshake(ctx, conn, params.tls)
+			}
+		}
+		if err == nil {
+			s.start(conn)
+		}
+		callback(func() error {
+			if err != nil {
+				reject(rt.NewGoError(fmt.Errorf("dial %s: %w", address, err)))
+				return nil
+			}
+			s.open()
+			resolve(s.obj)
+			return nil
+		})
+	}()
+	return promise
+}
+
+// start sets the established connection, which is closed when the socket is
+// closed or the iteration ends. That's done even if the iteration ends before
+// the socket is opened on the event loop, so the connection isn't leaked.
+func (s *tcpSocket) start(conn net.Conn) {
+	s.connMu.Lock()
+	s.conn = conn
+	s.connMu.Unlock()
+	s.ctx, s.cancel = context.WithCancel(s.mi.vu.Context())
+	go func() {
+		<-s.ctx.Done()
+		_ = s.getConn().Close()
+	}()
+}
+
+// open defines the object of the started socket. It has to run on the event
+// loop.
+func (s *tcpSocket) open() {
+	rt := s.mi.vu.Runtime()
+	conn := s.getConn()
+
+	s.obj = rt.NewObject()
+	must(rt, s.obj.DefineDataProperty(
+		"read", rt.ToValue(s.read), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, s.obj.DefineDataProperty(
+		"write", rt.ToValue(s.write), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, s.obj.DefineDataProperty(
+		"upgradeTLS", rt.ToValue(s.upgradeTLS), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, s.obj.DefineDataProperty(
+		"close", rt.ToValue(s.close), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, s.obj.DefineDataProperty(
+		"localAddress", rt.ToValue(conn.LocalAddr().String()), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, s.obj.DefineDataProperty(
+		"remoteAddress", rt.ToValue(conn.RemoteAddr().String()), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+}
+
+func (s *tcpSocket) getConn() net.Conn {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	return s.conn
+}
+
+// handshake establishes a TLS connection over the given connection, with the
+// TLS config of the VU.
+func (s *tcpSocket) handshake(ctx context.Context, conn net.Conn, params *tlsParams) (net.Conn, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12} //nolint:gosec
+	if state := s.mi.vu.State(); state.TLSConfig != nil {
+		config = state.TLSConfig.Clone()
+	}
+	config.ServerName = s.host
+	if params.serverName != "" {
+		config.ServerName = params.serverName
+	}
+	if params.insecureSkipVerify {
+		config.InsecureSkipVerify = true
+	}
+
+	start := time.Now()
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	s.mi.pushSamples(&s.tagsAndMeta, sample{s.mi.metrics.TLSHandshaking, metrics.D(time.Since(start))})
+	return tlsConn, nil
+}
+
+// read returns a promise which is resolved with the received data as an
+// ArrayBuffer, or with null when the connection has been closed by the peer.
+func (s *tcpSocket) read(input sobek.Value) *sobek.Promise {
+	rt := s.mi.vu.Runtime()
+	params, err := newReadParams(rt, input)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	promise, resolve, reject := rt.NewPromise()
+	callback := s.mi.vu.RegisterCallback()
+	go func() {
+		data, err := s.readData(params)
+		callback(func() error {
+			switch {
+			case errors.Is(err, io.EOF):
+				resolve(sobek.Null())
+			case err != nil:
+				reject(rt.NewGoError(err))
+			default:
+				resolve(rt.NewArrayBuffer(data))
+			}
+			return nil
+		})
+	}()
+	return promise
+}
+
+func (s *tcpSocket) readData(params *readParams) ([]byte, error) {
+	s.readMu.Lock()
+	defer s.readMu.Unlock()
+
+	conn := s.getConn()
+	var deadline time.Time
+	if params.timeout > 0 {
+		deadline = time.Now().Add(params.timeout)
+	}
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var n int
+	var err error
+	if params.size > 0 {
+		buf := make([]byte, params.size)
+		n, err = io.ReadFull(conn, buf)
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = fmt.Errorf("the connection was closed after %d of the %d bytes", n, params.size)
+		}
+		if err != nil {
+			return nil, s.readError(err, params)
+		}
+		s.received(n)
+		return buf, nil
+	}
+
+	buf := make([]byte, maxReadSize)
+	n, err = conn.Read(buf)
+	if n > 0 {
+		// The error, if any, is returned by the next read.
+		s.received(n)
+		return buf[:n], nil
+	}
+	return nil, s.readError(err, params)
+}
+
+func (s *tcpSocket) readError(err error, params *readParams) error {
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return fmt.Errorf("read timed out after %s", params.timeout)
+	}
+	return err
+}
+
+// received emits the metrics of the received data, and the round trip if
+// it's the first data received since the last write.
+func (s *tcpSocket) received(n int) {
+	samples := []sample{{s.mi.metrics.DataReceived, float64(n)}}
+	s.lastWriteMu.Lock()
+	if !s.lastWrite.IsZero() {
+		samples = append(samples, sample{s.mi.metrics.RoundTrip, metrics.D(time.Since(s.lastWrite))})
+		s.lastWrite = time.Time{}
+	}
+	s.lastWriteMu.Unlock()
+	s.mi.pushSamples(&s.tagsAndMeta, samples...)
+}
+
+// write returns a promise which is resolved with the number of written bytes.
+func (s *tcpSocket) write(input sobek.Value) *sobek.Promise {
+	rt := s.mi.vu.Runtime()
+	data, err := common.ToBytes(input.Export())
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	promise, resolve, reject := rt.NewPromise()
+	callback := s.mi.vu.RegisterCallback()
+	go func() {
+		s.writeMu.Lock()
+		// The write is recorded before it's done, as the answer can be read
+		// before Write returns.
+		s.lastWriteMu.Lock()
+		s.lastWrite = time.Now()
+		s.lastWriteMu.Unlock()
+		n, err := s.getConn().Write(data)
+		s.writeMu.Unlock()
+
+		if n > 0 {
+			s.mi.pushSamples(&s.tagsAndMeta, sample{s.mi.metrics.DataSent, float64(n)})
+		}
+		callback(func() error {
+			if err != nil {
+				reject(rt.NewGoError(err))
+				return nil
+			}
+			resolve(n)
+			return nil
+		})
+	}()
+	return promise
+}
+
+// upgradeTLS returns a promise which is resolved after the connection has
+// been upgraded to TLS, e.g. after a STARTTLS command.
+func (s *tcpSocket) upgradeTLS(input sobek.Value) *sobek.Promise {
+	rt := s.mi.vu.Runtime()
+	params, err := newTLSParams(rt, input)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	promise, resolve, reject := rt.NewPromise()
+	callback := s.mi.vu.RegisterCallback()
+	go func() {
+		s.readMu.Lock()
+		s.writeMu.Lock()
+		ctx, cancel := context.WithTimeout(s.ctx, defaultDialTimeout)
+		conn, err := s.handshake(ctx, s.getConn(), params)
+		cancel()
+		if err == nil {
+			s.connMu.Lock()
+			s.conn = conn
+			s.connMu.Unlock()
+		}
+		s.writeMu.Unlock()
+		s.readMu.Unlock()
+
+		callback(func() error {
+			if err != nil {
+				reject(rt.NewGoError(fmt.Errorf("TLS handshake: %w", err)))
+				return nil
+			}
+			resolve(sobek.Undefined())
+			return nil
+		})
+	}()
+	return promise
+}
+
+// close closes the connection, the pending reads and writes fail.
+func (s *tcpSocket) close() {
+	s.cancel()
+	_ = s.getConn().Close()
+}
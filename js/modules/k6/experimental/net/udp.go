@@ -0,0 +1,311 @@
+package net
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/grafana/sobek"
+	"github.com/mstoykov/k6-taskqueue-lib/taskqueue"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib/netext"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	eventMessage = "message"
+	eventError   = "error"
+)
+
+// packetListener is implemented by the dialers which can open UDP sockets,
+// resolving their destinations the same way as the dialed addresses.
+type packetListener interface {
+	ListenPacket() (*netext.PacketConn, error)
+	ResolveUDPAddr(ctx context.Context, addr string) (*net.UDPAddr, error)
+}
+
+// sentMessage is the last message sent to an address, from which the round
+// trip of its answer is measured.
+type sentMessage struct {
+	address string
+	t       time.Time
+}
+
+// udpSocket is an unconnected UDP socket, which sends messages to any
+// address and delivers the received ones to its message listeners.
+type udpSocket struct {
+	mi          *ModuleInstance
+	obj         *sobek.Object // the object that is given to js to interact with the socket
+	listener    packetListener
+	conn        *netext.PacketConn
+	tq          *taskqueue.TaskQueue
+	tagsAndMeta metrics.TagsAndMeta
+
+	// ctx is done when the socket is closed or the iteration ends
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	sentMu sync.Mutex
+	sent   map[string]sentMessage
+
+	// fields that should be seen by js only be updated on the event loop
+	onMessage, onError sobek.Callable
+	listeners          map[string][]sobek.Callable
+	closed             bool
+}
+
+// openUDP opens a UDP socket, which keeps the iteration running until it's
+// closed.
+func (mi *ModuleInstance) openUDP(input sobek.Value) *sobek.Object {
+	rt := mi.vu.Runtime()
+	state := mi.vu.State()
+	if state == nil {
+		common.Throw(rt, errInitContext)
+	}
+	listener, ok := state.Dialer.(packetListener)
+	if !ok {
+		common.Throw(rt, errors.New("the dialer of the VU doesn't support UDP sockets"))
+	}
+	params, err := newDialParams(mi.vu, input)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	if params.tls != nil {
+		common.Throw(rt, errors.New("UDP sockets don't support TLS"))
+	}
+	conn, err := listener.ListenPacket()
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	ctx, cancel := context.WithCancel(mi.vu.Context())
+	s := &udpSocket{
+		mi:          mi,
+		obj:         rt.NewObject(),
+		listener:    listener,
+		conn:        conn,
+		tq:          taskqueue.New(mi.vu.RegisterCallback),
+		tagsAndMeta: params.tagsAndMeta,
+		ctx:         ctx,
+		cancel:      cancel,
+		sent:        make(map[string]sentMessage),
+		listeners:   make(map[string][]sobek.Callable),
+	}
+	s.define()
+
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+	go s.loop()
+	return s.obj
+}
+
+// define defines all properties and methods of the socket.
+func (s *udpSocket) define() {
+	rt := s.mi.vu.Runtime()
+	must(rt, s.obj.DefineDataProperty(
+		"sendTo", rt.ToValue(s.sendTo), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, s.obj.DefineDataProperty(
+		"addEventListener", rt.ToValue(s.addEventListener), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, s.obj.DefineDataProperty(
+		"close", rt.ToValue(s.close), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, s.obj.DefineDataProperty(
+		"localAddress", rt.ToValue(s.conn.LocalAddr().String()), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+
+	setOn := func(property string, on *sobek.Callable) {
+		must(rt, s.obj.DefineAccessorProperty(
+			property, rt.ToValue(func() sobek.Value {
+				if *on == nil {
+					return sobek.Null()
+				}
+				return rt.ToValue(*on)
+			}), rt.ToValue(func(call sobek.FunctionCall) sobek.Value {
+				arg := call.Argument(0)
+				// it's possible to unset handlers by setting them to null
+				if common.IsNullish(arg) {
+					*on = nil
+					return nil
+				}
+				fn, isFunc := sobek.AssertFunction(arg)
+				if !isFunc {
+					common.Throw(rt, fmt.Errorf("a value for '%s' should be callable", property))
+				}
+				*on = fn
+				return nil
+			}), sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	}
+	setOn("onmessage", &s.onMessage)
+	setOn("onerror", &s.onError)
+}
+
+// loop reads the messages until the socket is closed.
+func (s *udpSocket) loop() {
+	defer s.tq.Close()
+
+	buf := make([]byte, maxReadSize)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			if s.ctx.Err() == nil {
+				s.tq.Queue(func() error {
+					if s.closed {
+						return nil
+					}
+					s.close()
+					return s.callListeners(eventError, s.newEvent(eventError, time.Now(), func(o *sobek.Object) {
+						rt := s.mi.vu.Runtime()
+						must(rt, o.DefineDataProperty(
+							"error", rt.ToValue(err.Error()), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+					}))
+				})
+			}
+			return
+		}
+
+		t := time.Now()
+		data := make([]byte, n)
+		copy(data, buf[:n])
+		s.received(addr.String(), n, t)
+		s.tq.Queue(func() error {
+			if s.closed {
+				return nil
+			}
+			return s.callListeners(eventMessage, s.newEvent(eventMessage, t, func(o *sobek.Object) {
+				rt := s.mi.vu.Runtime()
+				must(rt, o.DefineDataProperty(
+					"data", rt.ToValue(rt.NewArrayBuffer(data)), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+				must(rt, o.DefineDataProperty(
+					"remoteAddress", rt.ToValue(addr.String()), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+			}))
+		})
+	}
+}
+
+// received emits the metrics of a message received from the address, and
+// its round trip if it's the first message received since the last one sent
+// to that address. The metrics are tagged with the address it was sent to.
+func (s *udpSocket) received(from string, n int, t time.Time) {
+	s.sentMu.Lock()
+	sent, ok := s.sent[from]
+	delete(s.sent, from)
+	s.sentMu.Unlock()
+
+	address := from
+	if ok {
+		address = sent.address
+	}
+	tagsAndMeta := newSocketTags(s.mi.vu, s.tagsAndMeta, "udp", "udp://"+address)
+	samples := []sample{{s.mi.metrics.DataReceived, float64(n)}}
+	if ok {
+		samples = append(samples, sample{s.mi.metrics.RoundTrip, metrics.D(t.Sub(sent.t))})
+	}
+	s.mi.pushSamples(&tagsAndMeta, samples...)
+}
+
+// sendTo returns a promise which is resolved with the number of sent bytes,
+// after the message has been sent to the address.
+func (s *udpSocket) sendTo(address string, input sobek.Value) *sobek.Promise {
+	rt := s.mi.vu.Runtime()
+	data, err := common.ToBytes(input.Export())
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	promise, resolve, reject := rt.NewPromise()
+	callback := s.mi.vu.RegisterCallback()
+	go func() {
+		n, err := s.send(address, data)
+		callback(func() error {
+			if err != nil {
+				reject(rt.NewGoError(fmt.Errorf("send to %s: %w", address, err)))
+				return nil
+			}
+			resolve(n)
+			return nil
+		})
+	}()
+	return promise
+}
+
+func (s *udpSocket) send(address string, data []byte) (int, error) {
+	addr, err := s.listener.ResolveUDPAddr(s.ctx, address)
+	if err != nil {
+		return 0, err
+	}
+	// The message is recorded before it's sent, as the answer can be
+	// received before WriteTo returns.
+	s.sentMu.Lock()
+	s.sent[addr.String()] = sentMessage{address: address, t: time.Now()}
+	s.sentMu.Unlock()
+	n, err := s.conn.WriteTo(data, addr)
+	if err != nil {
+		return n, err
+	}
+
+	tagsAndMeta := newSocketTags(s.mi.vu, s.tagsAndMeta, "udp", "udp://"+address)
+	s.mi.pushSamples(&tagsAndMeta, sample{s.mi.metrics.DataSent, float64(n)})
+	return n, nil
+}
+
+// newEvent returns an event of the given type, with the properties set by
+// the given function. It needs to be called on the event loop.
+func (s *udpSocket) newEvent(eventType string, t time.Time, set func(*sobek.Object)) *sobek.Object {
+	rt := s.mi.vu.Runtime()
+	o := rt.NewObject()
+	must(rt, o.DefineDataProperty(
+		"type", rt.ToValue(eventType), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, o.DefineDataProperty(
+		"target", s.obj, sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, o.DefineDataProperty(
+		"timestamp", rt.ToValue(float64(t.UnixNano())/1_000_000), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	set(o)
+	return o
+}
+
+// callListeners calls the on* listener and the added listeners of the given
+// type. If any of them throws, the socket is closed and the error is returned.
+func (s *udpSocket) callListeners(eventType string, ev *sobek.Object) error {
+	listeners := s.listeners[eventType]
+	on := s.onMessage
+	if eventType == eventError {
+		on = s.onError
+	}
+	if on != nil {
+		listeners = append([]sobek.Callable{on}, listeners...)
+	}
+	for _, listener := range listeners {
+		if _, err := listener(sobek.Undefined(), ev); err != nil {
+			s.close()
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *udpSocket) addEventListener(event string, handler sobek.Value) {
+	rt := s.mi.vu.Runtime()
+	if event != eventMessage && event != eventError {
+		common.Throw(rt, fmt.Errorf("unknown event type %q", event))
+	}
+	fn, isFunc := sobek.AssertFunction(handler)
+	if !isFunc {
+		common.Throw(rt, fmt.Errorf("handler for event type %q isn't a callable function", event))
+	}
+	s.listeners[event] = append(s.listeners[event], fn)
+}
+
+// close closes the socket, which ends the iteration if nothing else is
+// pending on the event loop.
+func (s *udpSocket) close() {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.cancel()
+	_ = s.conn.Close()
+}
@@ -0,0 +1,401 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/grafana/sobek"
+	"github.com/mstoykov/k6-taskqueue-lib/taskqueue"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/metrics"
+)
+
+// ReadyState is the EventSource specification's readyState
+type ReadyState uint8
+
+const (
+	// CONNECTING is the state while the connection hasn't been established
+	// yet, or while the EventSource is reconnecting
+	CONNECTING ReadyState = iota
+	// OPEN is the state while the events are being received
+	OPEN
+	// CLOSED is the state after the connection has failed or has been closed
+	CLOSED
+)
+
+type eventSource struct {
+	vu      modules.VU
+	url     *url.URL
+	params  *eventSourceParams
+	metrics *instanceMetrics
+	tq      *taskqueue.TaskQueue
+	obj     *sobek.Object // the object that is given to js to interact with the EventSource
+
+	// ctx is done when the EventSource is closed, which stops the connection
+	// and any further reconnections
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	eventListeners *eventListeners
+
+	// fields that should be seen by js only be updated on the event loop
+	readyState ReadyState
+}
+
+func (mi *ModuleInstance) eventSource(c sobek.ConstructorCall) *sobek.Object {
+	rt := mi.vu.Runtime()
+	state := mi.vu.State()
+	if state == nil {
+		common.Throw(rt, errors.New("EventSource can't be used in the init context"))
+	}
+
+	u, err := parseURL(c.Argument(0))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	params, err := newEventSourceParams(mi.vu, c.Argument(1))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	systemTags := state.Options.SystemTags
+	if nameTagValue, ok := params.tagsAndMeta.Tags.Get(metrics.TagName.String()); ok {
+		params.tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagURL, nameTagValue)
+	} else {
+		params.tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagURL, u.String())
+		params.tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagName, u.String())
+	}
+
+	ctx, cancel := context.WithCancel(mi.vu.Context())
+	es := &eventSource{
+		vu:             mi.vu,
+		url:            u,
+		params:         params,
+		metrics:        mi.metrics,
+		tq:             taskqueue.New(mi.vu.RegisterCallback),
+		obj:            rt.NewObject(),
+		ctx:            ctx,
+		cancel:         cancel,
+		eventListeners: newEventListeners(),
+		readyState:     CONNECTING,
+	}
+	defineEventSource(rt, es)
+
+	go es.loop()
+	return es.obj
+}
+
+// parseURL parses the url from the first constructor calls argument or returns an error
+func parseURL(urlValue sobek.Value) (*url.URL, error) {
+	if common.IsNullish(urlValue) {
+		return nil, errors.New("EventSource requires a url")
+	}
+
+	urlString := urlValue.String()
+	u, err := url.Parse(urlString)
+	if err != nil {
+		return nil, fmt.Errorf("EventSource requires valid url, but got %q which resulted in %w", urlString, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("EventSource requires url with scheme http or https, but got %q", u.Scheme)
+	}
+
+	return u, nil
+}
+
+// defineEventSource defines all properties and methods for the EventSource
+func defineEventSource(rt *sobek.Runtime, es *eventSource) {
+	must(rt, es.obj.DefineDataProperty(
+		"addEventListener", rt.ToValue(es.addEventListener), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, es.obj.DefineDataProperty(
+		"close", rt.ToValue(es.close), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, es.obj.DefineDataProperty(
+		"url", rt.ToValue(es.url.String()), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, es.obj.DefineDataProperty(
+		"withCredentials", rt.ToValue(false), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, es.obj.DefineAccessorProperty( // this needs to be with an accessor as we change the value
+		"readyState", rt.ToValue(func() ReadyState {
+			return es.readyState
+		}), nil, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+
+	setOn := func(property string, el *eventListener) {
+		must(rt, es.obj.DefineAccessorProperty(
+			property, rt.ToValue(func() sobek.Value {
+				if el.on == nil {
+					return sobek.Null()
+				}
+				return rt.ToValue(el.on)
+			}), rt.ToValue(func(call sobek.FunctionCall) sobek.Value {
+				arg := call.Argument(0)
+
+				// it's possible to unset handlers by setting them to null
+				if common.IsNullish(arg) {
+					el.on = nil
+					return nil
+				}
+
+				fn, isFunc := sobek.AssertFunction(arg)
+				if !isFunc {
+					common.Throw(rt, fmt.Errorf("a value for '%s' should be callable", property))
+				}
+
+				el.on = func(v sobek.Value) (sobek.Value, error) { return fn(sobek.Undefined(), v) }
+				return nil
+			}), sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	}
+
+	setOn("onopen", es.eventListeners.getType(eventOpen))
+	setOn("onmessage", es.eventListeners.getType(eventMessage))
+	setOn("onerror", es.eventListeners.getType(eventError))
+}
+
+// loop connects to the server and reconnects every time the connection is
+// lost, until the EventSource is closed.
+func (es *eventSource) loop() {
+	defer func() {
+		es.tq.Queue(func() error {
+			es.readyState = CLOSED
+			return nil
+		})
+		es.tq.Close()
+	}()
+
+	p := &parser{reconnectionTime: es.params.reconnectionTime}
+	for es.connect(p) {
+		timer := time.NewTimer(p.reconnectionTime)
+		select {
+		case <-timer.C:
+		case <-es.ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// connect establishes a connection and reads the events from it. It returns
+// whether the EventSource should reconnect after that.
+func (es *eventSource) connect(p *parser) bool {
+	state := es.vu.State()
+
+	req, err := http.NewRequestWithContext(es.ctx, http.MethodGet, es.url.String(), nil)
+	if err != nil {
+		es.queueFail(err)
+		return false
+	}
+	req.Header = es.params.headers.Clone()
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-store")
+	if p.lastEventID != "" {
+		req.Header.Set("Last-Event-ID", p.lastEventID)
+	}
+
+	// The VU's transport uses its dialer and TLS config, so the connection
+	// is measured and configured the same way as the k6/http requests, and
+	// the redirects are followed up to the VU's maxRedirects like there.
+	client := &http.Client{
+		Transport: state.Transport,
+		CheckRedirect: func(_ *http.Request, via []*http.Request) error {
+			if int64(len(via)) > state.Options.MaxRedirects.Int64 {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+	if state.CookieJar != nil {
+		client.Jar = state.CookieJar
+	}
+
+	tagsAndMeta := es.params.tagsAndMeta.Clone()
+	start := time.Now()
+	resp, err := client.Do(req) //nolint:bodyclose
+	if err != nil {
+		if es.ctx.Err() != nil {
+			return false
+		}
+		es.queueReconnect(err)
+		return true
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	tagsAndMeta.SetSystemTagOrMetaIfEnabled(state.Options.SystemTags, metrics.TagStatus, strconv.Itoa(resp.StatusCode))
+	if resp.StatusCode != http.StatusOK {
+		es.queueFail(fmt.Errorf("unexpected response status %q", resp.Status))
+		return false
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+		es.queueFail(fmt.Errorf("unexpected response content type %q", resp.Header.Get("Content-Type")))
+		return false
+	}
+	es.tq.Queue(es.connectionOpened)
+
+	var previous time.Time
+	err = p.parse(resp.Body, func(ev *event) {
+		es.emitEventMetrics(&tagsAndMeta, ev.t.Sub(start), ev.t.Sub(previous), previous.IsZero())
+		previous = ev.t
+		es.queueEvent(ev)
+	})
+	if es.ctx.Err() != nil {
+		return false
+	}
+
+	state.Logger.WithError(err).Debugf("EventSource connection to %s was lost, reconnecting", es.url)
+	es.queueReconnect(err)
+	return true
+}
+
+// emitEventMetrics emits the metrics for a received event. The time to the
+// first event is measured from the start of the connection, while the latency
+// of the next ones is measured from the previous event.
+func (es *eventSource) emitEventMetrics(
+	tagsAndMeta *metrics.TagsAndMeta, sinceStart, sincePrevious time.Duration, first bool,
+) {
+	now := time.Now()
+	latency := metrics.Sample{
+		TimeSeries: metrics.TimeSeries{Metric: es.metrics.InterEventLatency, Tags: tagsAndMeta.Tags},
+		Time:       now,
+		Metadata:   tagsAndMeta.Metadata,
+		Value:      metrics.D(sincePrevious),
+	}
+	if first {
+		latency.Metric = es.metrics.TimeToFirstEvent
+		latency.Value = metrics.D(sinceStart)
+	}
+
+	metrics.PushIfNotDone(es.vu.Context(), es.vu.State().Samples, metrics.ConnectedSamples{
+		Samples: []metrics.Sample{
+			{
+				TimeSeries: metrics.TimeSeries{Metric: es.metrics.EventsReceived, Tags: tagsAndMeta.Tags},
+				Time:       now,
+				Metadata:   tagsAndMeta.Metadata,
+				Value:      1,
+			},
+			latency,
+		},
+		Tags: tagsAndMeta.Tags,
+		Time: now,
+	})
+}
+
+// to be run only on the eventloop
+func (es *eventSource) connectionOpened() error {
+	if es.readyState == CLOSED {
+		return nil
+	}
+	es.readyState = OPEN
+	return es.callEventListeners(eventOpen, es.newEvent(eventOpen, time.Now()))
+}
+
+// queueReconnect announces that the connection was lost and the EventSource
+// is going to reconnect.
+func (es *eventSource) queueReconnect(err error) {
+	es.tq.Queue(func() error {
+		if es.readyState == CLOSED {
+			return nil
+		}
+		es.readyState = CONNECTING
+		return es.callErrorListeners(err)
+	})
+}
+
+// queueFail announces that the connection failed and the EventSource won't
+// reconnect.
+func (es *eventSource) queueFail(err error) {
+	es.tq.Queue(func() error {
+		if es.readyState == CLOSED {
+			return nil
+		}
+		es.close()
+		return es.callErrorListeners(err)
+	})
+}
+
+func (es *eventSource) queueEvent(ev *event) {
+	es.tq.Queue(func() error {
+		if es.readyState != OPEN {
+			return nil
+		}
+
+		rt := es.vu.Runtime()
+		eventType := ev.eventType
+		if eventType == "" {
+			eventType = eventMessage
+		}
+		o := es.newEvent(eventType, ev.t)
+		must(rt, o.DefineDataProperty(
+			"data", rt.ToValue(ev.data), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+		must(rt, o.DefineDataProperty(
+			"lastEventId", rt.ToValue(ev.lastEventID), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+		must(rt, o.DefineDataProperty(
+			"origin", rt.ToValue(es.url.Scheme+"://"+es.url.Host), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+
+		return es.callEventListeners(eventType, o)
+	})
+}
+
+// newEvent return an event implementing "implements" https://dom.spec.whatwg.org/#event
+// needs to be called on the event loop
+func (es *eventSource) newEvent(eventType string, t time.Time) *sobek.Object {
+	rt := es.vu.Runtime()
+	o := rt.NewObject()
+
+	must(rt, o.DefineDataProperty(
+		"type", rt.ToValue(eventType), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, o.DefineDataProperty(
+		"target", es.obj, sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, o.DefineDataProperty(
+		// milliseconds as double as per the spec https://w3c.github.io/hr-time/#dom-domhighrestimestamp
+		"timestamp", rt.ToValue(float64(t.UnixNano())/1_000_000), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+
+	return o
+}
+
+// callEventListeners calls the listeners of the given type. If any of them
+// throws, the EventSource is closed and the error is returned.
+func (es *eventSource) callEventListeners(eventType string, ev *sobek.Object) error {
+	for _, listener := range es.eventListeners.all(eventType) {
+		if _, err := listener(ev); err != nil {
+			es.close()
+			return err
+		}
+	}
+	return nil
+}
+
+func (es *eventSource) callErrorListeners(e error) error {
+	rt := es.vu.Runtime()
+
+	ev := es.newEvent(eventError, time.Now())
+	if e != nil {
+		must(rt, ev.DefineDataProperty(
+			"error", rt.ToValue(e.Error()), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	}
+	return es.callEventListeners(eventError, ev)
+}
+
+func (es *eventSource) addEventListener(event string, handler func(sobek.Value) (sobek.Value, error)) {
+	if handler == nil {
+		common.Throw(es.vu.Runtime(), fmt.Errorf("handler for event type %q isn't a callable function", event))
+	}
+
+	es.eventListeners.add(event, handler)
+}
+
+// close closes the connection, if any, and stops any further reconnections
+func (es *eventSource) close() {
+	if es.readyState == CLOSED {
+		return
+	}
+	es.readyState = CLOSED
+	es.cancel()
+}
@@ -0,0 +1,203 @@
+package sse
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/httpmultibin"
+	"go.k6.io/k6/metrics"
+)
+
+type testState struct {
+	*modulestest.Runtime
+	tb      *httpmultibin.HTTPMultiBin
+	samples chan metrics.SampleContainer
+}
+
+func newTestState(t *testing.T) testState {
+	t.Helper()
+
+	runtime := modulestest.NewRuntime(t)
+	tb := httpmultibin.NewHTTPMultiBin(t)
+
+	m, ok := New().NewModuleInstance(runtime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, runtime.VU.Runtime().Set("EventSource", m.Exports().Named["EventSource"]))
+
+	samples := make(chan metrics.SampleContainer, 1000)
+	registry := metrics.NewRegistry()
+	runtime.MoveToVUContext(&lib.State{
+		Dialer:    tb.Dialer,
+		Transport: tb.HTTPTransport,
+		TLSConfig: tb.TLSClientConfig,
+		Samples:   samples,
+		Options: lib.Options{
+			SystemTags:   metrics.NewSystemTagSet(metrics.TagURL, metrics.TagName, metrics.TagStatus),
+			MaxRedirects: null.IntFrom(10),
+		},
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+		Logger:         logrus.New(),
+	})
+
+	return testState{Runtime: runtime, tb: tb, samples: samples}
+}
+
+// handleEvents registers a handler that sends the given event stream on every
+// connection and records the Last-Event-ID headers of the requests.
+func (ts testState) handleEvents(path string, streams ...string) *[]string {
+	var (
+		mu           sync.Mutex
+		lastEventIDs []string
+	)
+	ts.tb.Mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		connection := len(lastEventIDs)
+		lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+		mu.Unlock()
+
+		if r.Header.Get("Accept") != "text/event-stream" || connection >= len(streams) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, streams[connection])
+	})
+	return &lastEventIDs
+}
+
+func TestEventSource(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+	lastEventIDs := ts.handleEvents("/sse",
+		"retry: 10\nid: 1\ndata: hello\n\nevent: ping\ndata: {}\n\n",
+		"id: 2\ndata: again\n\n",
+	)
+
+	_, err := ts.RunOnEventLoop(ts.tb.Replacer.Replace(`
+	var calls = [];
+	var es = new EventSource("HTTPBIN_URL/sse", { headers: { "X-Test": "1" }, tags: { custom: "tag" } });
+	if (es.readyState != EventSource.CONNECTING) { throw new Error("wrong readyState: " + es.readyState) }
+	es.onopen = () => calls.push("open");
+	es.onmessage = (e) => calls.push("message " + e.data + " " + e.lastEventId);
+	es.addEventListener("ping", (e) => calls.push(e.type + " " + e.data));
+	es.onerror = (e) => {
+		calls.push("error " + (e.error || ""));
+		if (es.readyState == EventSource.CLOSED) {
+			var expected = "open,message hello 1,ping {},error ,open,message again 2,error ,"+
+				"error unexpected response status \"204 No Content\"";
+			if (calls.join(",") != expected) { throw new Error("wrong calls: " + calls.join(",")) }
+		}
+	};
+	`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "1", "2"}, *lastEventIDs)
+
+	var received, firstEvent, latency int
+	for _, sampleContainer := range metrics.GetBufferedSamples(ts.samples) {
+		for _, sample := range sampleContainer.GetSamples() {
+			tags := sample.Tags.Map()
+			assert.Equal(t, ts.tb.Replacer.Replace("HTTPBIN_URL/sse"), tags["url"])
+			assert.Equal(t, "200", tags["status"])
+			assert.Equal(t, "tag", tags["custom"])
+
+			switch sample.Metric.Name {
+			case "sse_events_received":
+				received++
+			case "sse_time_to_first_event":
+				firstEvent++
+			case "sse_inter_event_latency":
+				latency++
+			}
+		}
+	}
+	assert.Equal(t, 3, received)
+	assert.Equal(t, 2, firstEvent)
+	assert.Equal(t, 1, latency)
+}
+
+func TestEventSourceClose(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+	lastEventIDs := ts.handleEvents("/sse", "data: 1\n\ndata: 2\n\n", "data: 3\n\n")
+
+	_, err := ts.RunOnEventLoop(ts.tb.Replacer.Replace(`
+	var es = new EventSource("HTTPBIN_URL/sse", { reconnectionTime: "1ms" });
+	es.onmessage = (e) => {
+		if (e.data != "1") { throw new Error("unexpected message: " + e.data) }
+		es.close();
+		if (es.readyState != EventSource.CLOSED) { throw new Error("wrong readyState: " + es.readyState) }
+	};
+	`))
+	require.NoError(t, err)
+	assert.Len(t, *lastEventIDs, 1)
+}
+
+func TestEventSourceRedirects(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+	ts.tb.Mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/sse", http.StatusFound)
+	})
+	lastEventIDs := ts.handleEvents("/sse", "data: 1\n\n")
+
+	_, err := ts.RunOnEventLoop(ts.tb.Replacer.Replace(`
+	var es = new EventSource("HTTPBIN_URL/redirect");
+	es.onmessage = () => es.close();
+	es.onerror = (e) => { throw new Error("unexpected error: " + e.error) };
+	`))
+	require.NoError(t, err)
+	assert.Len(t, *lastEventIDs, 1)
+
+	ts.VU.State().Options.MaxRedirects = null.IntFrom(0)
+	_, err = ts.RunOnEventLoop(ts.tb.Replacer.Replace(`
+	var es = new EventSource("HTTPBIN_URL/redirect");
+	es.onerror = (e) => {
+		if (e.error != "unexpected response status \"302 Found\"") { throw new Error("wrong error: " + e.error) }
+	};
+	`))
+	require.NoError(t, err)
+	assert.Len(t, *lastEventIDs, 1, "the redirect isn't followed")
+}
+
+func TestEventSourceErrors(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+	ts.tb.Mux.HandleFunc("/not-sse", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, "data: 1\n\n")
+	})
+	ts.handleEvents("/sse", "data: 1\n\n")
+
+	_, err := ts.RunOnEventLoop(ts.tb.Replacer.Replace(`
+	var es = new EventSource("HTTPBIN_URL/not-sse");
+	es.onerror = (e) => {
+		if (!e.error.startsWith("unexpected response content type")) { throw new Error("wrong error: " + e.error) }
+	};
+	`))
+	require.NoError(t, err)
+
+	_, err = ts.RunOnEventLoop(ts.tb.Replacer.Replace(`
+	var es = new EventSource("HTTPBIN_URL/sse");
+	es.onmessage = () => { throw new Error("oops") };
+	`))
+	require.ErrorContains(t, err, "oops")
+
+	_, err = ts.RunOnEventLoop(`new EventSource("ws://example.com")`)
+	require.ErrorContains(t, err, `EventSource requires url with scheme http or https, but got "ws"`)
+
+	_, err = ts.RunOnEventLoop(`new EventSource("http://example.com", { unknown: true })`)
+	require.ErrorContains(t, err, `unknown param: "unknown"`)
+}
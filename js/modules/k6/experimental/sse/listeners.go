@@ -0,0 +1,66 @@
+package sse
+
+import (
+	"github.com/grafana/sobek"
+)
+
+const (
+	eventOpen    = "open"
+	eventMessage = "message"
+	eventError   = "error"
+)
+
+// eventListener keeps the listeners of a certain event type
+type eventListener struct {
+	// this return sobek.value *and* error in order to return error on exception instead of panic
+	// https://pkg.go.dev/github.com/grafana/sobek#hdr-Functions
+	on   func(sobek.Value) (sobek.Value, error)
+	list []func(sobek.Value) (sobek.Value, error)
+}
+
+// all returns the on* listener, if set, followed by the added listeners
+func (l *eventListener) all() []func(sobek.Value) (sobek.Value, error) {
+	if l.on == nil {
+		return l.list
+	}
+
+	return append([]func(sobek.Value) (sobek.Value, error){l.on}, l.list...)
+}
+
+// eventListeners keeps track of the eventListeners for each event type. Unlike
+// in WebSockets, the server can send events of any type, so any type of
+// listener can be added.
+type eventListeners struct {
+	types map[string]*eventListener
+}
+
+func newEventListeners() *eventListeners {
+	return &eventListeners{types: make(map[string]*eventListener)}
+}
+
+// getType returns the event listener of a certain type, creating it if needed
+func (l *eventListeners) getType(t string) *eventListener {
+	listener, ok := l.types[t]
+	if !ok {
+		listener = &eventListener{}
+		l.types[t] = listener
+	}
+
+	return listener
+}
+
+// add adds a listener of a certain type
+func (l *eventListeners) add(t string, f func(sobek.Value) (sobek.Value, error)) {
+	listener := l.getType(t)
+	listener.list = append(listener.list, f)
+}
+
+// all returns all listeners for a certain event type or an empty array
+func (l *eventListeners) all(t string) []func(sobek.Value) (sobek.Value, error) {
+	listener, ok := l.types[t]
+	if !ok {
+		return nil
+	}
+
+	return listener.all()
+}
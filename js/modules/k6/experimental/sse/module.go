@@ -0,0 +1,72 @@
+// Package sse implements an EventSource client for Server-Sent Events as defined in
+// https://html.spec.whatwg.org/multipage/server-sent-events.html
+package sse
+
+import (
+	"fmt"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+)
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the SSE module for every VU.
+	ModuleInstance struct {
+		vu      modules.VU
+		metrics *instanceMetrics
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// New returns a pointer to a new RootModule instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface to return
+// a new instance for each VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	metrics, err := registerMetrics(vu.InitEnv().Registry)
+	if err != nil {
+		common.Throw(vu.Runtime(), fmt.Errorf("failed to register SSE module metrics: %w", err))
+	}
+
+	return &ModuleInstance{
+		vu:      vu,
+		metrics: metrics,
+	}
+}
+
+// Exports returns the exports of the SSE module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	rt := mi.vu.Runtime()
+
+	constructor := rt.ToValue(mi.eventSource).ToObject(rt)
+	for name, state := range map[string]ReadyState{"CONNECTING": CONNECTING, "OPEN": OPEN, "CLOSED": CLOSED} {
+		must(rt, constructor.DefineDataProperty(
+			name, rt.ToValue(state), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	}
+
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"EventSource": constructor,
+		},
+	}
+}
+
+// must is a small helper that will panic if err is not nil.
+func must(rt *sobek.Runtime, err error) {
+	if err != nil {
+		common.Throw(rt, err)
+	}
+}
@@ -0,0 +1,78 @@
+package sse
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+// defaultReconnectionTime is the delay before reconnecting, until the server
+// sets another one with the retry field.
+const defaultReconnectionTime = 3 * time.Second
+
+// eventSourceParams is the parameters that can be passed to the EventSource
+// constructor.
+type eventSourceParams struct {
+	headers          http.Header
+	tagsAndMeta      metrics.TagsAndMeta
+	reconnectionTime time.Duration
+}
+
+// newEventSourceParams constructs the EventSource parameters from the input
+// value. If no input is given, the default values are used.
+func newEventSourceParams(vu modules.VU, input sobek.Value) (*eventSourceParams, error) {
+	result := &eventSourceParams{
+		headers:          make(http.Header),
+		tagsAndMeta:      vu.State().Tags.GetCurrentValues(),
+		reconnectionTime: defaultReconnectionTime,
+	}
+	if userAgent := vu.State().Options.UserAgent; userAgent.String != "" {
+		result.headers.Set("User-Agent", userAgent.String)
+	}
+
+	if common.IsNullish(input) {
+		return result, nil
+	}
+
+	rt := vu.Runtime()
+	params := input.ToObject(rt)
+
+	for _, k := range params.Keys() {
+		switch k {
+		case "headers":
+			headers := params.Get(k)
+			if common.IsNullish(headers) {
+				continue
+			}
+			rawHeaders, ok := headers.Export().(map[string]interface{})
+			if !ok {
+				return result, errors.New("invalid headers param: must be an object with key-value pairs")
+			}
+			for name, value := range rawHeaders {
+				result.headers.Set(name, fmt.Sprint(value))
+			}
+		case "tags":
+			if err := common.ApplyCustomUserTags(rt, &result.tagsAndMeta, params.Get(k)); err != nil {
+				return result, fmt.Errorf("metric tags: %w", err)
+			}
+		case "reconnectionTime":
+			var err error
+			result.reconnectionTime, err = types.GetDurationValue(params.Get(k).Export())
+			if err != nil {
+				return result, fmt.Errorf("invalid reconnectionTime value: %w", err)
+			}
+		default:
+			return result, fmt.Errorf("unknown param: %q", k)
+		}
+	}
+
+	return result, nil
+}
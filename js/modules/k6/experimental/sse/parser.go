@@ -0,0 +1,117 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLineSize is the maximum size of a single line of the event stream.
+const maxLineSize = 1024 * 1024
+
+// event is a single event received from the event stream.
+type event struct {
+	eventType   string
+	data        string
+	lastEventID string
+	t           time.Time
+}
+
+// parser parses event streams as defined in
+// https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation
+//
+// It keeps the last event ID and the reconnection time, which are preserved
+// between the connections of an EventSource.
+type parser struct {
+	lastEventID      string
+	reconnectionTime time.Duration
+}
+
+// parse reads the event stream from r and calls dispatch for every event,
+// until r returns an error or io.EOF.
+func (p *parser) parse(r io.Reader, dispatch func(*event)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineSize)
+	scanner.Split(newLineSplitter())
+
+	var (
+		eventType string
+		data      strings.Builder
+		idBuffer  = p.lastEventID
+		first     = true
+	)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\uFEFF")
+			first = false
+		}
+
+		if line == "" {
+			p.lastEventID = idBuffer
+			if data.Len() > 0 {
+				dispatch(&event{
+					eventType:   eventType,
+					data:        strings.TrimSuffix(data.String(), "\n"),
+					lastEventID: p.lastEventID,
+					t:           time.Now(),
+				})
+			}
+			eventType = ""
+			data.Reset()
+			continue
+		}
+		if line[0] == ':' { // a comment
+			continue
+		}
+
+		field, value, found := strings.Cut(line, ":")
+		if found {
+			value = strings.TrimPrefix(value, " ")
+		}
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				idBuffer = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(value, 10, 32); err == nil {
+				p.reconnectionTime = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+
+	return scanner.Err()
+}
+
+// newLineSplitter returns a bufio.SplitFunc that splits the lines ending with
+// CRLF, LF or CR. A CR is handled as soon as it's read, so the LF that may
+// follow it is skipped at the beginning of the next line.
+func newLineSplitter() bufio.SplitFunc {
+	var afterCR bool
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		skip := 0
+		if afterCR && len(data) > 0 {
+			afterCR = false
+			if data[0] == '\n' {
+				skip = 1
+			}
+		}
+		if i := bytes.IndexAny(data[skip:], "\r\n"); i >= 0 {
+			afterCR = data[skip+i] == '\r'
+			return skip + i + 1, data[skip : skip+i], nil
+		}
+		if atEOF && len(data) > skip {
+			return len(data), data[skip:], nil
+		}
+		return skip, nil, nil
+	}
+}
@@ -0,0 +1,76 @@
+package sse
+
+import (
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParser(t *testing.T) {
+	t.Parallel()
+
+	type parsedEvent struct {
+		eventType, data, lastEventID string
+	}
+	testCases := []struct {
+		name   string
+		stream string
+		events []parsedEvent
+		lastID string
+		retry  time.Duration
+	}{
+		{
+			name:   "data",
+			stream: "data: first\n\ndata:second\ndata: line\n\n",
+			events: []parsedEvent{{data: "first"}, {data: "second\nline"}},
+		},
+		{
+			name:   "event types and ids",
+			stream: "\uFEFFevent: ping\nid: 1\ndata: a\n\n: a comment\ndata: b\n\nid: 2\n\n",
+			events: []parsedEvent{{eventType: "ping", data: "a", lastEventID: "1"}, {data: "b", lastEventID: "1"}},
+			lastID: "2",
+		},
+		{
+			name:   "line endings",
+			stream: "data: a\r\rdata: b\r\n\r\ndata: c\n\n",
+			events: []parsedEvent{{data: "a"}, {data: "b"}, {data: "c"}},
+		},
+		{
+			name:   "retry",
+			stream: "retry: 1500\ndata: a\n\nretry: nope\n\n",
+			events: []parsedEvent{{data: "a"}},
+			retry:  1500 * time.Millisecond,
+		},
+		{
+			name:   "incomplete event",
+			stream: "data: a\n\ndata: b",
+			events: []parsedEvent{{data: "a"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := &parser{reconnectionTime: time.Second}
+			var events []parsedEvent
+			// reading a byte at a time checks that the line endings are
+			// handled correctly when they are split between reads
+			err := p.parse(iotest.OneByteReader(strings.NewReader(tc.stream)), func(ev *event) {
+				events = append(events, parsedEvent{ev.eventType, ev.data, ev.lastEventID})
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.events, events)
+			assert.Equal(t, tc.lastID, p.lastEventID)
+			if tc.retry == 0 {
+				tc.retry = time.Second
+			}
+			assert.Equal(t, tc.retry, p.reconnectionTime)
+		})
+	}
+}
@@ -36,6 +36,9 @@ type Client struct {
 	conn *grpcext.Conn
 	vu   modules.VU
 	addr string
+	pool *grpcext.Pool
+
+	metrics *instanceMetrics
 }
 
 // Load will parse the given proto files and make the file descriptors available to request.
@@ -204,13 +207,20 @@ func buildTLSConfigFromMap(parentConfig *tls.Config, tlsConfigMap map[string]int
 	return buildTLSConfig(parentConfig, cert, key, ca)
 }
 
-// Connect is a block dial to the gRPC server at the given address (host:port)
-func (c *Client) Connect(addr string, params sobek.Value) (bool, error) {
+// Connect is a block dial to the gRPC server at the given address (host:port),
+// or to the servers of a list of addresses or of a target resolved by gRPC
+// (like dns:///host:port), which are balanced by the load balancing policy.
+func (c *Client) Connect(target sobek.Value, params sobek.Value) (bool, error) {
 	state := c.vu.State()
 	if state == nil {
 		return false, common.NewInitContextError("connecting to a gRPC server in the init context is not supported")
 	}
 
+	addrs, err := parseConnectTargets(target)
+	if err != nil {
+		return false, fmt.Errorf("invalid grpc.connect() target: %w", err)
+	}
+
 	p, err := newConnectParams(c.vu, params)
 	if err != nil {
 		return false, fmt.Errorf("invalid grpc.connect() parameters: %w", err)
@@ -218,6 +228,20 @@ func (c *Client) Connect(addr string, params sobek.Value) (bool, error) {
 
 	opts := grpcext.DefaultOptions(c.vu.State)
 
+	addr := addrs[0]
+	if len(addrs) > 1 {
+		var targetOpts []grpc.DialOption
+		addr, targetOpts = grpcext.StaticTarget(addrs)
+		opts = append(opts, targetOpts...)
+	}
+	if p.LoadBalancingPolicy != "" {
+		lbOpt, err := grpcext.WithLoadBalancingPolicy(p.LoadBalancingPolicy)
+		if err != nil {
+			return false, err
+		}
+		opts = append(opts, lbOpt)
+	}
+
 	var tcred credentials.TransportCredentials
 	if !p.IsPlaintext {
 		tlsCfg := state.TLSConfig.Clone()
@@ -249,10 +273,23 @@ func (c *Client) Connect(addr string, params sobek.Value) (bool, error) {
 		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(int(p.MaxSendSize))))
 	}
 
-	c.addr = addr
-	c.conn, err = grpcext.Dial(ctx, addr, opts...)
-	if err != nil {
-		return false, err
+	c.addr = strings.Join(addrs, ",")
+	if p.Shared {
+		key, err := p.poolKey(c.addr, state.Options.UserAgent.ValueOrZero())
+		if err != nil {
+			return false, err
+		}
+		c.conn, err = c.pool.Get(ctx, key, func(ctx context.Context) (*grpcext.Conn, error) {
+			return grpcext.Dial(ctx, addr, opts...)
+		})
+		if err != nil {
+			return false, err
+		}
+	} else {
+		c.conn, err = grpcext.Dial(ctx, addr, opts...)
+		if err != nil {
+			return false, err
+		}
 	}
 
 	if !p.UseReflectionProtocol {
@@ -348,6 +385,9 @@ func (c *Client) buildInvokeRequest(
 	if err != nil {
 		return grpcReq, fmt.Errorf("invalid GRPC's client.invoke() parameters: %w", err)
 	}
+	if p.Correlate != nil {
+		return grpcReq, errors.New("invalid GRPC's client.invoke() parameters: correlate is only supported by streams")
+	}
 
 	// k6 GRPC Invoke's default timeout is 2 minutes
 	if p.Timeout == time.Duration(0) {
@@ -372,6 +412,7 @@ func (c *Client) buildInvokeRequest(
 		Message:                b,
 		TagsAndMeta:            &p.TagsAndMeta,
 		Metadata:               p.Metadata,
+		State:                  state,
 	}, nil
 }
 
@@ -6,7 +6,9 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"net"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	k6grpc "go.k6.io/k6/js/modules/k6/grpc"
@@ -19,6 +21,8 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -30,6 +34,7 @@ import (
 	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/metadata"
 	v1alphagrpc "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
 	grpcstats "google.golang.org/grpc/stats"
@@ -740,6 +745,83 @@ func TestClient(t *testing.T) {
 				err:  "load must be called in the init context",
 			},
 		},
+		{
+			name: "HealthCheck",
+			setup: func(tb *httpmultibin.HTTPMultiBin) {
+				srv := health.NewServer()
+				srv.SetServingStatus("grpc.testing.TestService", healthpb.HealthCheckResponse_NOT_SERVING)
+				healthpb.RegisterHealthServer(tb.ServerGRPC, srv)
+			},
+			initString: codeBlock{
+				code: `var client = new grpc.Client();`,
+			},
+			vuString: codeBlock{
+				code: `
+				client.connect("GRPCBIN_ADDR");
+				var resp = client.healthCheck();
+				if (resp.message.status !== grpc.HealthCheckServing) {
+					throw new Error("unexpected server status: " + resp.message.status)
+				}
+				resp = client.healthCheck("grpc.testing.TestService", { tags: { check: "health" } });
+				if (resp.message.status !== grpc.HealthCheckNotServing) {
+					throw new Error("unexpected service status: " + resp.message.status)
+				}
+				resp = client.healthCheck("grpc.testing.Unknown");
+				if (resp.status !== grpc.StatusNotFound) {
+					throw new Error("unexpected status: " + resp.status)
+				}`,
+				asserts: func(t *testing.T, rb *httpmultibin.HTTPMultiBin, samples chan metrics.SampleContainer, _ error) {
+					samplesBuf := metrics.GetBufferedSamples(samples)
+					assertMetricEmitted(t, metrics.GRPCReqDurationName, samplesBuf, rb.Replacer.Replace("GRPCBIN_ADDR/grpc.health.v1.Health/Check"))
+				},
+			},
+		},
+		{
+			name: "HealthCheckInvalidService",
+			initString: codeBlock{
+				code: `var client = new grpc.Client();`,
+			},
+			vuString: codeBlock{
+				code: `
+				client.connect("GRPCBIN_ADDR");
+				client.healthCheck(42);`,
+				err: "invalid service name '42', it needs to be a string",
+			},
+		},
+		{
+			name: "WatchHealth",
+			setup: func(tb *httpmultibin.HTTPMultiBin) {
+				srv := health.NewServer()
+				srv.SetServingStatus("grpc.testing.TestService", healthpb.HealthCheckResponse_SERVING)
+				healthpb.RegisterHealthServer(tb.ServerGRPC, srv)
+				tb.GRPCStub.EmptyCallFunc = func(context.Context, *grpc_testing.Empty) (*grpc_testing.Empty, error) {
+					srv.SetServingStatus("grpc.testing.TestService", healthpb.HealthCheckResponse_NOT_SERVING)
+					return &grpc_testing.Empty{}, nil
+				}
+			},
+			initString: codeBlock{
+				code: `
+				var client = new grpc.Client();
+				client.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");`,
+			},
+			vuString: codeBlock{
+				code: `
+				client.connect("GRPCBIN_ADDR");
+				const statuses = [];
+				const stream = client.watchHealth("grpc.testing.TestService");
+				stream.on("data", (msg) => {
+					statuses.push(msg.status);
+					if (statuses.length == 1) {
+						client.invoke("grpc.testing.TestService/EmptyCall", {});
+						return;
+					}
+					client.close();
+					if (statuses.join() !== "SERVING,NOT_SERVING") {
+						throw new Error("unexpected statuses: " + statuses.join())
+					}
+				});`,
+			},
+		},
 		{
 			name: "ReflectUnregistered",
 			initString: codeBlock{
@@ -747,7 +829,7 @@ func TestClient(t *testing.T) {
 			},
 			vuString: codeBlock{
 				code: `client.connect("GRPCBIN_ADDR", {reflect: true})`,
-				err:  "rpc error: code = Unimplemented desc = unknown service grpc.reflection.v1alpha.ServerReflection",
+				err:  "the server doesn't support the reflection v1 nor v1alpha: rpc error: code = Unimplemented desc = unknown service grpc.reflection.v1alpha.ServerReflection",
 			},
 		},
 		{
@@ -1438,3 +1520,128 @@ func TestClientConnectionReflectMetadata(t *testing.T) {
 
 	assert.True(t, foundReflectionCall, "expected to find a reflection call in the logs, but didn't")
 }
+
+// startBackend starts a plaintext gRPC server, which counts the calls to
+// EmptyCall and the accepted connections.
+func startBackend(t *testing.T) (addr string, calls, conns *atomic.Int64) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	calls, conns = new(atomic.Int64), new(atomic.Int64)
+	srv := grpc.NewServer()
+	grpc_testing.RegisterTestServiceServer(srv, &httpmultibin.GRPCStub{
+		EmptyCallFunc: func(context.Context, *grpc_testing.Empty) (*grpc_testing.Empty, error) {
+			calls.Add(1)
+			return &grpc_testing.Empty{}, nil
+		},
+	})
+	go func() { _ = srv.Serve(&countingListener{Listener: l, accepted: conns}) }()
+	t.Cleanup(srv.Stop)
+
+	return l.Addr().String(), calls, conns
+}
+
+type countingListener struct {
+	net.Listener
+	accepted *atomic.Int64
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.accepted.Add(1)
+	}
+	return conn, err
+}
+
+func TestClientLoadBalancing(t *testing.T) {
+	t.Parallel()
+
+	addr1, calls1, _ := startBackend(t)
+	addr2, calls2, _ := startBackend(t)
+
+	ts := newTestState(t)
+	_, err := ts.Run(`
+		var client = new grpc.Client();
+		client.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");`)
+	require.NoError(t, err)
+	ts.ToVUContext()
+	ts.VU.State().Options.SystemTags.Add(metrics.TagBackend)
+
+	// the calls are spread over both backends, once they're both connected
+	_, err = ts.Run(`
+		client.connect(["` + addr1 + `", "` + addr2 + `"], { plaintext: true, loadBalancingPolicy: "round_robin" });
+		for (let i = 0; i < 20; i++) {
+			const resp = client.invoke("grpc.testing.TestService/EmptyCall", {});
+			if (resp.status !== grpc.StatusOK) { throw new Error("unexpected status " + resp.status) }
+		}
+		client.close();`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), calls1.Load()+calls2.Load())
+	assert.Positive(t, calls1.Load())
+	assert.Positive(t, calls2.Load())
+
+	backends := make(map[string]int64)
+	for _, sampleContainer := range metrics.GetBufferedSamples(ts.samples) {
+		for _, sample := range sampleContainer.GetSamples() {
+			if sample.Metric.Name != metrics.GRPCReqDurationName {
+				continue
+			}
+			backend, _ := sample.Tags.Get(metrics.TagBackend.String())
+			backends[backend]++
+			url, _ := sample.Tags.Get(metrics.TagURL.String())
+			assert.Equal(t, addr1+","+addr2+"/grpc.testing.TestService/EmptyCall", url)
+		}
+	}
+	assert.Equal(t, map[string]int64{addr1: calls1.Load(), addr2: calls2.Load()}, backends)
+
+	// all the calls are sent to the first backend
+	calls1.Store(0)
+	calls2.Store(0)
+	_, err = ts.Run(`
+		client.connect(["` + addr1 + `", "` + addr2 + `"], { plaintext: true, loadBalancingPolicy: "pick_first" });
+		for (let i = 0; i < 3; i++) {
+			client.invoke("grpc.testing.TestService/EmptyCall", {});
+		}
+		client.close();`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), calls1.Load())
+	assert.Zero(t, calls2.Load())
+
+	_, err = ts.Run(`client.connect("` + addr1 + `", { plaintext: true, loadBalancingPolicy: "random" })`)
+	require.ErrorContains(t, err, `invalid loadBalancingPolicy value: '"random"'`)
+
+	_, err = ts.Run(`client.connect([], { plaintext: true })`)
+	require.ErrorContains(t, err, "the list of addresses is empty")
+}
+
+func TestClientSharedConnection(t *testing.T) {
+	t.Parallel()
+
+	addr, calls, conns := startBackend(t)
+
+	ts := newTestState(t)
+	_, err := ts.Run(`
+		var client1 = new grpc.Client();
+		var client2 = new grpc.Client();
+		client1.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");
+		client2.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");`)
+	require.NoError(t, err)
+	ts.ToVUContext()
+
+	_, err = ts.Run(`
+		client1.connect("` + addr + `", { plaintext: true, shared: true });
+		client2.connect("` + addr + `", { plaintext: true, shared: true });
+		client1.invoke("grpc.testing.TestService/EmptyCall", {});
+		client1.close();
+
+		// the connection is still open for the second client
+		const resp = client2.invoke("grpc.testing.TestService/EmptyCall", {});
+		if (resp.status !== grpc.StatusOK) { throw new Error("unexpected status " + resp.status) }
+		client2.close();`)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(2), calls.Load())
+	assert.Equal(t, int64(1), conns.Load())
+}
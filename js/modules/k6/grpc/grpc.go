@@ -9,19 +9,25 @@ import (
 	"github.com/mstoykov/k6-taskqueue-lib/taskqueue"
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib/netext/grpcext"
 	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 type (
 	// RootModule is the global module instance that will create module
 	// instances for each VU.
-	RootModule struct{}
+	RootModule struct {
+		// pool contains the connections shared by the VUs
+		pool *grpcext.Pool
+	}
 
 	// ModuleInstance represents an instance of the GRPC module for every VU.
 	ModuleInstance struct {
 		vu      modules.VU
 		exports map[string]interface{}
 		metrics *instanceMetrics
+		pool    *grpcext.Pool
 	}
 )
 
@@ -32,7 +38,7 @@ var (
 
 // New returns a pointer to a new RootModule instance.
 func New() *RootModule {
-	return &RootModule{}
+	return &RootModule{pool: grpcext.NewPool()}
 }
 
 // NewModuleInstance implements the modules.Module interface to return
@@ -47,6 +53,7 @@ func (r *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 		vu:      vu,
 		exports: make(map[string]interface{}),
 		metrics: metrics,
+		pool:    r.pool,
 	}
 
 	mi.exports["Client"] = mi.NewClient
@@ -59,7 +66,7 @@ func (r *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 // NewClient is the JS constructor for the grpc Client.
 func (mi *ModuleInstance) NewClient(_ sobek.ConstructorCall) *sobek.Object {
 	rt := mi.vu.Runtime()
-	return rt.ToValue(&Client{vu: mi.vu}).ToObject(rt)
+	return rt.ToValue(&Client{vu: mi.vu, pool: mi.pool, metrics: mi.metrics}).ToObject(rt)
 }
 
 // defineConstants defines the constant variables of the module.
@@ -86,6 +93,11 @@ func (mi *ModuleInstance) defineConstants() {
 	mustAddCode("StatusUnavailable", codes.Unavailable)
 	mustAddCode("StatusDataLoss", codes.DataLoss)
 	mustAddCode("StatusUnauthenticated", codes.Unauthenticated)
+
+	mi.exports["HealthCheckUnknown"] = healthpb.HealthCheckResponse_UNKNOWN.String()
+	mi.exports["HealthCheckServing"] = healthpb.HealthCheckResponse_SERVING.String()
+	mi.exports["HealthCheckNotServing"] = healthpb.HealthCheckResponse_NOT_SERVING.String()
+	mi.exports["HealthCheckServiceUnknown"] = healthpb.HealthCheckResponse_SERVICE_UNKNOWN.String()
 }
 
 // Exports returns the exports of the grpc module.
@@ -104,32 +116,46 @@ func (mi *ModuleInstance) stream(c sobek.ConstructorCall) *sobek.Object {
 		common.Throw(rt, fmt.Errorf("invalid GRPC Stream's client: %w", err))
 	}
 
-	methodName := sanitizeMethodName(c.Argument(1).String())
+	s, err := newStream(mi.vu, mi.metrics, client, c.Argument(1).String(), c.Argument(2))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	return s.obj
+}
+
+// newStream begins a new stream of the method with the client.
+func newStream(
+	vu modules.VU, instanceMetrics *instanceMetrics, client *Client, method string, params sobek.Value,
+) (*stream, error) {
+	rt := vu.Runtime()
+
+	methodName := sanitizeMethodName(method)
 	methodDescriptor, err := client.getMethodDescriptor(methodName)
 	if err != nil {
-		common.Throw(rt, fmt.Errorf("invalid GRPC Stream's method: %w", err))
+		return nil, fmt.Errorf("invalid GRPC Stream's method: %w", err)
 	}
 
-	p, err := newCallParams(mi.vu, c.Argument(2))
+	p, err := newCallParams(vu, params)
 	if err != nil {
-		common.Throw(rt, fmt.Errorf("invalid GRPC Stream's parameters: %w", err))
+		return nil, fmt.Errorf("invalid GRPC Stream's parameters: %w", err)
 	}
 
-	p.SetSystemTags(mi.vu.State(), client.addr, methodName)
+	p.SetSystemTags(vu.State(), client.addr, methodName)
 
-	logger := mi.vu.State().Logger.WithField("streamMethod", methodName)
+	logger := vu.State().Logger.WithField("streamMethod", methodName)
 
 	s := &stream{
-		vu:               mi.vu,
+		vu:               vu,
 		client:           client,
 		methodDescriptor: methodDescriptor,
 		method:           methodName,
 		logger:           logger,
 
-		tq: taskqueue.New(mi.vu.RegisterCallback),
+		tq: taskqueue.New(vu.RegisterCallback),
 
-		instanceMetrics: mi.metrics,
-		builtinMetrics:  mi.vu.State().BuiltinMetrics,
+		instanceMetrics: instanceMetrics,
+		builtinMetrics:  vu.State().BuiltinMetrics,
 		done:            make(chan struct{}),
 		writingState:    opened,
 
@@ -138,6 +164,8 @@ func (mi *ModuleInstance) stream(c sobek.ConstructorCall) *sobek.Object {
 		eventListeners: newEventListeners(),
 		obj:            rt.NewObject(),
 		tagsAndMeta:    &p.TagsAndMeta,
+
+		correlate: p.Correlate,
 	}
 
 	defineStream(rt, s)
@@ -146,10 +174,10 @@ func (mi *ModuleInstance) stream(c sobek.ConstructorCall) *sobek.Object {
 	if err != nil {
 		s.tq.Close()
 
-		common.Throw(rt, err)
+		return nil, err
 	}
 
-	return s.obj
+	return s, nil
 }
 
 // extractClient extracts & validates a grpc.Client from a sobek.Value.
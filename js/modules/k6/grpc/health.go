@@ -0,0 +1,81 @@
+package grpc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/grafana/sobek"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib/netext/grpcext"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// The methods of the standard gRPC health checking protocol.
+const (
+	healthCheckMethod = "/grpc.health.v1.Health/Check"
+	healthWatchMethod = "/grpc.health.v1.Health/Watch"
+)
+
+// HealthCheck checks the health of the service with the standard gRPC health
+// checking protocol, without loading its definitions. The server's overall
+// health is checked if no service is given. The status of the response's
+// message is one of the HealthCheck* constants.
+func (c *Client) HealthCheck(service sobek.Value, params sobek.Value) (*grpcext.InvokeResponse, error) {
+	req, err := c.healthCheckRequest(service)
+	if err != nil {
+		return nil, err
+	}
+
+	grpcReq, err := c.buildInvokeRequest(healthCheckMethod, req, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.conn.Invoke(c.vu.Context(), grpcReq)
+}
+
+// WatchHealth returns a stream which receives a message each time the status
+// of the service changes, with the standard gRPC health checking protocol.
+func (c *Client) WatchHealth(service sobek.Value, params sobek.Value) (*sobek.Object, error) {
+	req, err := c.healthCheckRequest(service)
+	if err != nil {
+		return nil, err
+	}
+	if c.vu.State() == nil {
+		return nil, common.NewInitContextError("watching the health in the init context is not supported")
+	}
+	if c.conn == nil {
+		return nil, errors.New("no gRPC connection, you must call connect first")
+	}
+
+	s, err := newStream(c.vu, c.metrics, c, healthWatchMethod, params)
+	if err != nil {
+		return nil, err
+	}
+	s.write(req)
+	s.end()
+
+	return s.obj, nil
+}
+
+// healthCheckRequest returns the request for the service, and makes the
+// health checking methods available to the client.
+func (c *Client) healthCheckRequest(service sobek.Value) (sobek.Value, error) {
+	name := ""
+	if !common.IsNullish(service) {
+		var ok bool
+		if name, ok = service.Export().(string); !ok {
+			return nil, fmt.Errorf("invalid service name '%#v', it needs to be a string", service.Export())
+		}
+	}
+
+	if c.mds == nil {
+		c.mds = make(map[string]protoreflect.MethodDescriptor)
+	}
+	methods := healthpb.File_grpc_health_v1_health_proto.Services().ByName("Health").Methods()
+	c.mds[healthCheckMethod] = methods.ByName("Check")
+	c.mds[healthWatchMethod] = methods.ByName("Watch")
+
+	return c.vu.Runtime().ToValue(map[string]interface{}{"service": name}), nil
+}
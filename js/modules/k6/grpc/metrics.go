@@ -7,6 +7,11 @@ type instanceMetrics struct {
 	Streams                 *metrics.Metric
 	StreamsMessagesSent     *metrics.Metric
 	StreamsMessagesReceived *metrics.Metric
+
+	// the metrics of the streams' messages, if they're correlated
+	StreamsMessageRoundTrip    *metrics.Metric
+	StreamsMessageSentSize     *metrics.Metric
+	StreamsMessageReceivedSize *metrics.Metric
 }
 
 // registerMetrics registers and returns the metrics in the provided registry
@@ -26,5 +31,20 @@ func registerMetrics(registry *metrics.Registry) (*instanceMetrics, error) {
 		return nil, err
 	}
 
+	if m.StreamsMessageRoundTrip, err = registry.NewMetric(
+		"grpc_streams_msg_round_trip", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.StreamsMessageSentSize, err = registry.NewMetric(
+		"grpc_streams_msg_sent_size", metrics.Trend, metrics.Data); err != nil {
+		return nil, err
+	}
+
+	if m.StreamsMessageReceivedSize, err = registry.NewMetric(
+		"grpc_streams_msg_received_size", metrics.Trend, metrics.Data); err != nil {
+		return nil, err
+	}
+
 	return m, nil
 }
@@ -1,6 +1,7 @@
 package grpc
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules"
 	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/netext/grpcext"
 	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
 	"google.golang.org/grpc/metadata"
@@ -22,6 +24,9 @@ type callParams struct {
 	TagsAndMeta            metrics.TagsAndMeta
 	Timeout                time.Duration
 	DiscardResponseMessage bool
+	// Correlate returns the correlation ID of a stream's message, which
+	// is used to measure the round trip of the messages.
+	Correlate sobek.Callable
 }
 
 // newCallParams constructs the call parameters from the input value.
@@ -61,6 +66,12 @@ func newCallParams(vu modules.VU, input sobek.Value) (*callParams, error) {
 			}
 		case "discardResponseMessage":
 			result.DiscardResponseMessage = params.Get(k).ToBoolean()
+		case "correlate":
+			fn, ok := sobek.AssertFunction(params.Get(k))
+			if !ok {
+				return result, errors.New("invalid correlate param: it needs to be a function")
+			}
+			result.Correlate = fn
 		default:
 			return result, fmt.Errorf("unknown param: %q", k)
 		}
@@ -131,6 +142,8 @@ type connectParams struct {
 	MaxReceiveSize        int64
 	MaxSendSize           int64
 	TLS                   map[string]interface{}
+	LoadBalancingPolicy   string
+	Shared                bool
 }
 
 func newConnectParams(vu modules.VU, input sobek.Value) (*connectParams, error) { //nolint:gocognit
@@ -201,6 +214,19 @@ func newConnectParams(vu modules.VU, input sobek.Value) (*connectParams, error)
 			if err := parseConnectTLSParam(result, v); err != nil {
 				return result, err
 			}
+		case "loadBalancingPolicy":
+			var ok bool
+			result.LoadBalancingPolicy, ok = v.(string)
+			if !ok || (result.LoadBalancingPolicy != grpcext.PickFirst && result.LoadBalancingPolicy != grpcext.RoundRobin) {
+				return result, fmt.Errorf("invalid loadBalancingPolicy value: '%#v', it needs to be %q or %q",
+					v, grpcext.PickFirst, grpcext.RoundRobin)
+			}
+		case "shared":
+			var ok bool
+			result.Shared, ok = v.(bool)
+			if !ok {
+				return result, fmt.Errorf("invalid shared value: '%#v', it needs to be boolean", v)
+			}
 		default:
 			return result, fmt.Errorf("unknown connect param: %q", k)
 		}
@@ -209,6 +235,55 @@ func newConnectParams(vu modules.VU, input sobek.Value) (*connectParams, error)
 	return result, nil
 }
 
+// poolKey returns the key of the connection in the pool shared by the VUs.
+// Only the connections to the same target with the same parameters are
+// shared.
+func (p *connectParams) poolKey(target, userAgent string) (string, error) {
+	key, err := json.Marshal(map[string]interface{}{
+		"target":              target,
+		"plaintext":           p.IsPlaintext,
+		"maxReceiveSize":      p.MaxReceiveSize,
+		"maxSendSize":         p.MaxSendSize,
+		"tls":                 p.TLS,
+		"loadBalancingPolicy": p.LoadBalancingPolicy,
+		"userAgent":           userAgent,
+	})
+	if err != nil {
+		return "", fmt.Errorf("invalid shared connection parameters: %w", err)
+	}
+	return string(key), nil
+}
+
+// parseConnectTargets returns the addresses of the target passed to connect,
+// which is either an address or a list of addresses.
+func parseConnectTargets(input sobek.Value) ([]string, error) {
+	if common.IsNullish(input) {
+		return nil, errors.New("the address is required")
+	}
+	switch v := input.Export().(type) {
+	case string:
+		if v == "" {
+			return nil, errors.New("the address is required")
+		}
+		return []string{v}, nil
+	case []interface{}:
+		if len(v) == 0 {
+			return nil, errors.New("the list of addresses is empty")
+		}
+		addrs := make([]string, len(v))
+		for i, addr := range v {
+			s, ok := addr.(string)
+			if !ok || s == "" {
+				return nil, fmt.Errorf("invalid address '%#v', it needs to be a non-empty string", addr)
+			}
+			addrs[i] = s
+		}
+		return addrs, nil
+	default:
+		return nil, fmt.Errorf("invalid address '%#v', it needs to be a string or an array of strings", v)
+	}
+}
+
 func parseConnectTLSParam(params *connectParams, v interface{}) error {
 	var ok bool
 	params.TLS, ok = v.(map[string]interface{})
@@ -11,6 +11,7 @@ import (
 
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib/netext"
 	"go.k6.io/k6/lib/netext/grpcext"
 	"go.k6.io/k6/metrics"
 
@@ -24,8 +25,9 @@ import (
 
 // message is a struct that
 type message struct {
-	isClosing bool
-	msg       []byte
+	isClosing     bool
+	msg           []byte
+	correlationID string
 }
 
 const (
@@ -60,6 +62,11 @@ type stream struct {
 	eventListeners *eventListeners
 
 	timeoutCancel context.CancelFunc
+
+	// correlate returns the correlation IDs of the messages, it's only
+	// called on the event loop
+	correlate  sobek.Callable
+	correlated netext.CorrelatedMessages
 }
 
 // defineStream defines the sobek.Object that is given to js to interact with the Stream
@@ -81,6 +88,7 @@ func (s *stream) beginStream(p *callParams) error {
 		DiscardResponseMessage: p.DiscardResponseMessage,
 		TagsAndMeta:            &p.TagsAndMeta,
 		Metadata:               p.Metadata,
+		State:                  s.vu.State(),
 	}
 
 	ctx := s.vu.Context()
@@ -142,19 +150,29 @@ func (s *stream) loop() {
 	}
 }
 
-func (s *stream) queueMessage(msg interface{}) {
+func (s *stream) queueMessage(msg interface{}, size int) {
+	received := time.Now()
 	metrics.PushIfNotDone(s.vu.Context(), s.vu.State().Samples, metrics.Sample{
 		TimeSeries: metrics.TimeSeries{
 			Metric: s.instanceMetrics.StreamsMessagesReceived,
 			Tags:   s.tagsAndMeta.Tags,
 		},
-		Time:     time.Now(),
+		Time:     received,
 		Metadata: s.tagsAndMeta.Metadata,
 		Value:    1,
 	})
+	if s.correlate != nil {
+		s.pushSample(s.instanceMetrics.StreamsMessageReceivedSize, received, float64(size))
+	}
 
 	s.tq.Queue(func() error {
 		rt := s.vu.Runtime()
+		if err := s.trackRoundTrip(rt.ToValue(msg), received); err != nil {
+			_ = s.closeWithError(err)
+
+			return err
+		}
+
 		listeners := s.eventListeners.all(eventData)
 
 		for _, messageListener := range listeners {
@@ -174,7 +192,7 @@ func (s *stream) readData(wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	for {
-		msg, err := s.stream.ReceiveConverted()
+		msg, size, err := s.stream.ReceiveConverted()
 
 		if err != nil && !isRegularClosing(err) {
 			s.logger.WithError(err).Debug("error while reading from the stream")
@@ -197,7 +215,7 @@ func (s *stream) readData(wg *sync.WaitGroup) {
 		}
 
 		if msg != nil || !reflect.ValueOf(msg).IsNil() {
-			s.queueMessage(msg)
+			s.queueMessage(msg, size)
 		}
 	}
 }
@@ -235,11 +253,18 @@ func (s *stream) writeData(wg *sync.WaitGroup) {
 					return
 				}
 
-				err := s.stream.Send(msg.msg)
+				sent := time.Now()
+				if msg.correlationID != "" {
+					s.correlated.Add(msg.correlationID, sent)
+				}
+				size, err := s.stream.Send(msg.msg)
 				if err != nil {
 					s.processSendError(err)
 					return
 				}
+				if s.correlate != nil {
+					s.pushSample(s.instanceMetrics.StreamsMessageSentSize, sent, float64(size))
+				}
 
 				metrics.PushIfNotDone(s.vu.Context(), s.vu.State().Samples, metrics.Sample{
 					TimeSeries: metrics.TimeSeries{
@@ -322,7 +347,56 @@ func (s *stream) write(input sobek.Value) {
 		s.logger.WithError(err).Warnf("can't marshal message")
 	}
 
-	s.writeQueueCh <- message{msg: b}
+	id, err := s.correlationID(input, "outgoing")
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	s.writeQueueCh <- message{msg: b, correlationID: id}
+}
+
+// correlationID returns the correlation ID of the message, or an empty
+// string if the stream doesn't correlate its messages or the message
+// doesn't have an ID.
+func (s *stream) correlationID(msg sobek.Value, direction string) (string, error) {
+	if s.correlate == nil {
+		return "", nil
+	}
+
+	id, err := s.correlate(sobek.Undefined(), msg, s.vu.Runtime().ToValue(direction))
+	if err != nil {
+		return "", err
+	}
+	if common.IsNullish(id) {
+		return "", nil
+	}
+	return id.String(), nil
+}
+
+// trackRoundTrip pushes the round trip of the received message, if a message
+// with the same correlation ID was sent.
+func (s *stream) trackRoundTrip(msg sobek.Value, received time.Time) error {
+	id, err := s.correlationID(msg, "incoming")
+	if err != nil || id == "" {
+		return err
+	}
+
+	if sent, ok := s.correlated.Take(id); ok {
+		s.pushSample(s.instanceMetrics.StreamsMessageRoundTrip, received, metrics.D(received.Sub(sent)))
+	}
+	return nil
+}
+
+func (s *stream) pushSample(metric *metrics.Metric, t time.Time, value float64) {
+	metrics.PushIfNotDone(s.vu.Context(), s.vu.State().Samples, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: metric,
+			Tags:   s.tagsAndMeta.Tags,
+		},
+		Time:     t,
+		Metadata: s.tagsAndMeta.Metadata,
+		Value:    value,
+	})
 }
 
 // end closes client the stream
@@ -493,6 +493,102 @@ func TestStream_UndefinedHandler(t *testing.T) {
 	require.ErrorContains(t, err, "handler for \"data\" event isn't a callable function")
 }
 
+func TestStream_Correlate(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+
+	stub := grpc_wrappers_testing.Register(ts.httpBin.ServerGRPC)
+	stub.TestStreamImplementation = func(stream grpc_wrappers_testing.Service_TestStreamServer) error {
+		result := ""
+
+		for {
+			msg, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return stream.SendAndClose(&wrappers.StringValue{
+					Value: strings.TrimRight(result, " "),
+				})
+			}
+
+			if err != nil {
+				return err
+			}
+
+			result += msg.Value + " "
+		}
+	}
+
+	initString := codeBlock{
+		code: `
+		var client = new grpc.Client();
+		client.load([], "../../../../lib/testutils/httpmultibin/grpc_wrappers_testing/test.proto");`,
+	}
+	vuString := codeBlock{
+		code: `
+		client.connect("GRPCBIN_ADDR");
+		let stream = new grpc.Stream(client, "grpc.wrappers.testing.Service/TestStream", {
+			tags: { "tag1": "value1" },
+			correlate: function (msg, direction) {
+				call(direction + ': ' + msg);
+				return direction == 'incoming' ? msg.split(' ')[0] : msg;
+			},
+		});
+		stream.write('Hey');
+		stream.write('John');
+		stream.end();
+		`,
+	}
+
+	val, err := ts.Run(initString.code)
+	assertResponse(t, initString, err, val, ts)
+
+	ts.ToVUContext()
+
+	val, err = ts.RunOnEventLoop(vuString.code)
+	assertResponse(t, vuString, err, val, ts)
+
+	assert.Equal(t, []string{"outgoing: Hey", "outgoing: John", "incoming: Hey John"}, ts.callRecorder.Recorded())
+
+	counts := make(map[string]int)
+	for _, samples := range metrics.GetBufferedSamples(ts.samples) {
+		for _, sample := range samples.GetSamples() {
+			counts[sample.Metric.Name]++
+			if strings.HasPrefix(sample.Metric.Name, "grpc_streams_msg_") {
+				assertTags(t, sample, map[string]string{"tag1": "value1"})
+				assert.Positive(t, sample.Value)
+			}
+		}
+	}
+	assert.Equal(t, 1, counts["grpc_streams_msg_round_trip"])
+	assert.Equal(t, 2, counts["grpc_streams_msg_sent_size"])
+	assert.Equal(t, 1, counts["grpc_streams_msg_received_size"])
+}
+
+func TestStream_InvalidCorrelate(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+
+	initString := codeBlock{
+		code: `
+		var client = new grpc.Client();
+		client.load([], "../../../../lib/testutils/httpmultibin/grpc_wrappers_testing/test.proto");`,
+	}
+
+	val, err := ts.Run(initString.code)
+	assertResponse(t, initString, err, val, ts)
+
+	ts.ToVUContext()
+
+	_, err = ts.Run(`
+	client.connect("GRPCBIN_ADDR");
+	new grpc.Stream(client, "grpc.wrappers.testing.Service/TestStream", { correlate: "id" })`)
+	require.ErrorContains(t, err, "invalid correlate param: it needs to be a function")
+
+	_, err = ts.Run(`client.invoke("grpc.wrappers.testing.Service/TestString", "a", { correlate: () => "id" })`)
+	require.ErrorContains(t, err, "correlate is only supported by streams")
+}
+
 // TestStream_MetricsTagsMetadata tests that the metrics tags are correctly
 // added to samples.
 func TestStream_MetricsTagsMetadata(t *testing.T) {
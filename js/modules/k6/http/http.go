@@ -5,6 +5,7 @@ import (
 	"net/http/cookiejar"
 
 	"github.com/grafana/sobek"
+	"github.com/santhosh-tekuri/jsonschema/v5"
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules"
 	"go.k6.io/k6/lib/netext"
@@ -24,6 +25,8 @@ type ModuleInstance struct {
 	rootModule    *RootModule
 	defaultClient *Client
 	exports       *sobek.Object
+	// schemas are the compiled schemas of Response.validate().
+	schemas map[string]*jsonschema.Schema
 }
 
 var (
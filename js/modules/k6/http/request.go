@@ -16,6 +16,7 @@ import (
 	"gopkg.in/guregu/null.v3"
 
 	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/netext/httpext"
 	"go.k6.io/k6/lib/types"
 )
@@ -169,9 +170,11 @@ func (c *Client) parseRequest(
 		Timeout:          60 * time.Second,
 		Throw:            state.Options.Throw.Bool,
 		Redirects:        state.Options.MaxRedirects,
+		HTTP3:            state.Options.HTTP3.Bool,
 		Cookies:          make(map[string]*httpext.HTTPRequestCookie),
 		ResponseCallback: c.responseCallback,
 		TagsAndMeta:      c.moduleInstance.vu.State().Tags.GetCurrentValues(),
+		Proxy:            state.Proxy,
 	}
 
 	if state.Options.DiscardResponseBodies.Bool {
@@ -367,6 +370,20 @@ func (c *Client) parseRequest(
 				}
 			case "auth":
 				result.Auth = params.Get(k).String()
+			case "http3":
+				result.HTTP3 = params.Get(k).ToBoolean()
+			case "proxy":
+				proxy, err := lib.ParseProxyURL(params.Get(k).String())
+				if err != nil {
+					return nil, err
+				}
+				result.Proxy = proxy
+			case "retry":
+				retry, err := parseRetryPolicy(rt, params.Get(k))
+				if err != nil {
+					return nil, fmt.Errorf("invalid retry value: %w", err)
+				}
+				result.Retry = retry
 			case "timeout":
 				t, err := types.GetDurationValue(params.Get(k).Export())
 				if err != nil {
@@ -401,6 +418,58 @@ func (c *Client) parseRequest(
 	return result, nil
 }
 
+// parseRetryPolicy parses the retry param, which is either the maximum number
+// of attempts or an object with the maxAttempts, statuses, errorCodes, backoff
+// and maxBackoff properties.
+func parseRetryPolicy(rt *sobek.Runtime, v sobek.Value) (*httpext.RetryPolicy, error) {
+	if sobek.IsUndefined(v) || sobek.IsNull(v) {
+		return nil, nil //nolint:nilnil
+	}
+	var obj *sobek.Object
+	maxAttempts := v
+	if _, ok := v.Export().(map[string]interface{}); ok {
+		obj = v.ToObject(rt)
+		if maxAttempts = obj.Get("maxAttempts"); maxAttempts == nil {
+			maxAttempts = sobek.Undefined()
+		}
+	}
+
+	policy := httpext.NewRetryPolicy(int(maxAttempts.ToInteger()))
+	if policy.MaxAttempts < 1 {
+		return nil, errors.New("maxAttempts should be at least 1")
+	}
+	if obj == nil {
+		return policy, nil
+	}
+	for _, key := range []string{"statuses", "errorCodes"} {
+		values := obj.Get(key)
+		if values == nil || sobek.IsUndefined(values) || sobek.IsNull(values) {
+			continue
+		}
+		var ints []int
+		if err := rt.ExportTo(values, &ints); err != nil {
+			return nil, fmt.Errorf("%s should be an array of numbers: %w", key, err)
+		}
+		if key == "statuses" {
+			policy.Statuses = ints
+		} else {
+			policy.ErrorCodes = ints
+		}
+	}
+	for key, dst := range map[string]*time.Duration{"backoff": &policy.Backoff, "maxBackoff": &policy.MaxBackoff} {
+		value := obj.Get(key)
+		if value == nil || sobek.IsUndefined(value) || sobek.IsNull(value) {
+			continue
+		}
+		d, err := types.GetDurationValue(value.Export())
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		*dst = d
+	}
+	return policy, nil
+}
+
 func (c *Client) prepareBatchArray(requests []interface{}) (
 	[]httpext.BatchParsedHTTPRequest, []*Response, error,
 ) {
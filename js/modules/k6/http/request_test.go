@@ -39,6 +39,7 @@ import (
 	"go.k6.io/k6/js/modulestest"
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/netext"
+	"go.k6.io/k6/lib/netext/httpext"
 	"go.k6.io/k6/lib/testutils"
 	"go.k6.io/k6/lib/testutils/httpmultibin"
 	"go.k6.io/k6/metrics"
@@ -158,6 +159,7 @@ func newTestCase(t testing.TB) *httpTestCase {
 			"group": lib.RootGroupPath,
 		})),
 		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		HTTP3Transport: httpext.NewHTTP3Transport(tb.Dialer, tb.TLSClientConfig),
 	}
 
 	runtime, mi := getTestModuleInstance(t)
@@ -499,6 +501,65 @@ func TestRequest(t *testing.T) {
 			}
 		}
 	})
+	t.Run("HTTP/3", func(t *testing.T) {
+		metrics.GetBufferedSamples(samples) // Clean up buffered samples from previous tests
+		_, err := rt.RunString(sr(`
+		var res = http.request("GET", "HTTP3BIN_URL/get", null, { http3: true });
+		if (res.status != 200) { throw new Error("wrong status: " + res.status) }
+		if (res.proto != "HTTP/3.0") { throw new Error("wrong proto: " + res.proto) }
+		if (res.timings.connecting <= 0) { throw new Error("wrong connecting: " + res.timings.connecting) }
+		if (res.timings.tls_handshaking != 0) { throw new Error("wrong tls_handshaking: " + res.timings.tls_handshaking) }
+
+		res = http.post("HTTP3BIN_URL/post", "some body", { http3: true });
+		if (res.status != 200) { throw new Error("wrong status: " + res.status) }
+		if (res.json().data != "some body") { throw new Error("wrong body: " + res.body) }
+		if (res.timings.connecting != 0) { throw new Error("the connection wasn't reused") }
+		`))
+		assert.NoError(t, err)
+
+		bufSamples := metrics.GetBufferedSamples(samples)
+		assertRequestMetricsEmitted(t, bufSamples, "GET", sr("HTTP3BIN_URL/get"), 200, "")
+		assertRequestMetricsEmitted(t, bufSamples, "POST", sr("HTTP3BIN_URL/post"), 200, "")
+		for _, sampleC := range bufSamples {
+			for _, sample := range sampleC.GetSamples() {
+				proto, ok := sample.Tags.Get("proto")
+				assert.True(t, ok)
+				assert.Equal(t, "HTTP/3.0", proto)
+			}
+		}
+	})
+	t.Run("Retry", func(t *testing.T) {
+		metrics.GetBufferedSamples(samples) // Clean up buffered samples from previous tests
+		_, err := rt.RunString(sr(`
+		var res = http.get("HTTPBIN_URL/status/503", { retry: { maxAttempts: 3, backoff: "1ms" } });
+		if (res.status != 503) { throw new Error("wrong status: " + res.status) }
+		if (res.attempts != 3) { throw new Error("wrong attempts: " + res.attempts) }
+
+		res = http.get("HTTPBIN_URL/status/404", { retry: 3 });
+		if (res.attempts != 1) { throw new Error("wrong attempts: " + res.attempts) }
+		`))
+		require.NoError(t, err)
+
+		attempts := map[string]int{}
+		for _, sampleC := range metrics.GetBufferedSamples(samples) {
+			for _, sample := range sampleC.GetSamples() {
+				if sample.Metric.Name != metrics.HTTPReqsName {
+					continue
+				}
+				attempt, _ := sample.Tags.Get("attempt")
+				attempts[attempt]++
+			}
+		}
+		assert.Equal(t, map[string]int{"1": 2, "2": 1, "3": 1}, attempts)
+
+		_, err = rt.RunString(`http.get("http://example.com", { retry: { maxAttempts: 0 } });`)
+		require.ErrorContains(t, err, "invalid retry value: maxAttempts should be at least 1")
+
+		for _, retry := range []string{"0", "-3"} {
+			_, err = rt.RunString(`http.get("http://example.com", { retry: ` + retry + ` });`)
+			require.ErrorContains(t, err, "invalid retry value: maxAttempts should be at least 1")
+		}
+	})
 	t.Run("Invalid", func(t *testing.T) {
 		ts.hook.Reset()
 
@@ -1923,7 +1984,7 @@ func TestRedirectMetricTags(t *testing.T) {
 
 	checkTags := func(sc metrics.SampleContainer, expTags map[string]string) {
 		allSamples := sc.GetSamples()
-		assert.Len(t, allSamples, 9)
+		assert.Len(t, allSamples, 10)
 		for _, s := range allSamples {
 			assert.Equal(t, expTags, s.Tags.Map())
 		}
@@ -110,6 +110,46 @@ func (res *Response) JSON(selector ...string) sobek.Value {
 	return rt.ToValue(res.cachedJSON)
 }
 
+// Validate validates the body of a response as JSON against a JSON schema of
+// draft 2020-12, which is either an object or a JSON string. With the metric
+// param, the result is also emitted as a sample of http_req_schema_valid.
+func (res *Response) Validate(schema sobek.Value, params sobek.Value) (SchemaValidationResult, error) {
+	mi := res.client.moduleInstance
+	if mi.vu.State() == nil {
+		return SchemaValidationResult{}, errors.New("validating responses in the init context is not supported")
+	}
+	rt := mi.vu.Runtime()
+
+	p, err := parseSchemaValidationParams(rt, params)
+	if err != nil {
+		return SchemaValidationResult{}, err
+	}
+	compiled, err := mi.compileSchema(schema)
+	if err != nil {
+		return SchemaValidationResult{}, err
+	}
+
+	var result SchemaValidationResult
+	if res.Body == nil {
+		result.Errors = []SchemaValidationError{{
+			Message: "the body is null - this likely was because of a request error getting the response",
+		}}
+	} else {
+		body, err := common.ToBytes(res.Body)
+		if err != nil {
+			return SchemaValidationResult{}, err
+		}
+		result = validateSchema(compiled, body)
+	}
+
+	if p.metric {
+		if err := res.emitSchemaValid(params, result.Valid); err != nil {
+			return SchemaValidationResult{}, err
+		}
+	}
+	return result, nil
+}
+
 func checkErrorInJSON(input []byte, offset int, err error) error {
 	lf := '\n'
 	str := string(input)
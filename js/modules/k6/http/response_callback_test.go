@@ -94,6 +94,7 @@ func TestResponseCallbackInAction(t *testing.T) {
 	HTTPMetricsWithoutFailed := []string{
 		metrics.HTTPReqsName,
 		metrics.HTTPReqBlockedName,
+		metrics.HTTPReqDNSLookupName,
 		metrics.HTTPReqConnectingName,
 		metrics.HTTPReqDurationName,
 		metrics.HTTPReqReceivingName,
@@ -304,6 +305,7 @@ func TestResponseCallbackBatch(t *testing.T) {
 	HTTPMetricsWithoutFailed := []string{
 		metrics.HTTPReqsName,
 		metrics.HTTPReqBlockedName,
+		metrics.HTTPReqDNSLookupName,
 		metrics.HTTPReqConnectingName,
 		metrics.HTTPReqDurationName,
 		metrics.HTTPReqReceivingName,
@@ -422,6 +424,7 @@ func TestResponseCallbackInActionWithoutPassedTag(t *testing.T) {
 		metrics.HTTPReqsName,
 		metrics.HTTPReqFailedName,
 		metrics.HTTPReqBlockedName,
+		metrics.HTTPReqDNSLookupName,
 		metrics.HTTPReqConnectingName,
 		metrics.HTTPReqDurationName,
 		metrics.HTTPReqReceivingName,
@@ -484,6 +487,7 @@ func TestDigestWithResponseCallback(t *testing.T) {
 		metrics.HTTPReqsName,
 		metrics.HTTPReqFailedName,
 		metrics.HTTPReqBlockedName,
+		metrics.HTTPReqDNSLookupName,
 		metrics.HTTPReqConnectingName,
 		metrics.HTTPReqDurationName,
 		metrics.HTTPReqReceivingName,
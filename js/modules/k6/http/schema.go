@@ -0,0 +1,188 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/sobek"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/metrics"
+)
+
+// schemaURL is the URL of the schemas that are compiled, which is only used
+// in the keyword locations of the errors.
+const schemaURL = "schema.json"
+
+// errExternalSchemaRef is returned for the references to other schemas, which
+// aren't loaded to avoid making requests that aren't in the script.
+var errExternalSchemaRef = errors.New("references to external schemas aren't supported")
+
+// SchemaValidationResult is the result of Response.validate().
+type SchemaValidationResult struct {
+	Valid  bool                    `js:"valid"`
+	Errors []SchemaValidationError `js:"errors"`
+}
+
+// SchemaValidationError is a single reason why a response doesn't match a
+// schema.
+type SchemaValidationError struct {
+	// InstanceLocation is the JSON pointer to the invalid value of the body.
+	InstanceLocation string `js:"instanceLocation"`
+	// KeywordLocation is the JSON pointer to the keyword of the schema that
+	// the value doesn't match.
+	KeywordLocation string `js:"keywordLocation"`
+	Message         string `js:"message"`
+}
+
+type schemaValidationParams struct {
+	metric bool
+}
+
+// compileSchema compiles a JSON schema, which is either a JSON string or an
+// object. The compiled schemas are cached, since they are usually validated
+// in every iteration.
+func (mi *ModuleInstance) compileSchema(v sobek.Value) (*jsonschema.Schema, error) {
+	if common.IsNullish(v) {
+		return nil, errors.New("no schema provided to validate")
+	}
+	source, ok := v.Export().(string)
+	if !ok {
+		data, err := json.Marshal(v.Export())
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema: %w", err)
+		}
+		source = string(data)
+	}
+	if schema, ok := mi.schemas[source]; ok {
+		return schema, nil
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	compiler.LoadURL = func(string) (io.ReadCloser, error) {
+		return nil, errExternalSchemaRef
+	}
+	if err := compiler.AddResource(schemaURL, bytes.NewReader([]byte(source))); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	schema, err := compiler.Compile(schemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	if mi.schemas == nil {
+		mi.schemas = make(map[string]*jsonschema.Schema)
+	}
+	mi.schemas[source] = schema
+	return schema, nil
+}
+
+// validateSchema validates the body against the schema, and returns all the
+// reasons why it doesn't match it.
+func validateSchema(schema *jsonschema.Schema, body []byte) SchemaValidationResult {
+	// The numbers are decoded as json.Number to validate large integers.
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	err := decoder.Decode(&doc)
+	if err == nil && decoder.More() {
+		err = errors.New("invalid character after top-level value")
+	}
+	if err != nil {
+		return SchemaValidationResult{Errors: []SchemaValidationError{{
+			Message: "the body isn't valid JSON: " + err.Error(),
+		}}}
+	}
+
+	err = schema.Validate(doc)
+	if err == nil {
+		return SchemaValidationResult{Valid: true, Errors: []SchemaValidationError{}}
+	}
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return SchemaValidationResult{Errors: []SchemaValidationError{{Message: err.Error()}}}
+	}
+
+	// Only the causes at the leaves of the tree tell what is wrong, the others
+	// only tell which keyword they are under.
+	var result SchemaValidationResult
+	var walk func(*jsonschema.ValidationError)
+	walk = func(ve *jsonschema.ValidationError) {
+		if len(ve.Causes) == 0 {
+			result.Errors = append(result.Errors, SchemaValidationError{
+				InstanceLocation: ve.InstanceLocation,
+				KeywordLocation:  ve.KeywordLocation,
+				Message:          ve.Message,
+			})
+		}
+		for _, cause := range ve.Causes {
+			walk(cause)
+		}
+	}
+	walk(validationErr)
+	sort.SliceStable(result.Errors, func(i, j int) bool {
+		return result.Errors[i].InstanceLocation < result.Errors[j].InstanceLocation
+	})
+	return result
+}
+
+func parseSchemaValidationParams(rt *sobek.Runtime, v sobek.Value) (schemaValidationParams, error) {
+	var params schemaValidationParams
+	if common.IsNullish(v) {
+		return params, nil
+	}
+	obj := v.ToObject(rt)
+	for _, k := range obj.Keys() {
+		switch k {
+		case "metric":
+			params.metric = obj.Get(k).ToBoolean()
+		case "tags":
+			// The tags are applied when the metric is emitted.
+		default:
+			return params, fmt.Errorf("unknown validate() parameter '%s'", k)
+		}
+	}
+	return params, nil
+}
+
+// emitSchemaValid emits the result of a validation as a sample of the
+// http_req_schema_valid metric.
+func (res *Response) emitSchemaValid(params sobek.Value, valid bool) error {
+	vu := res.client.moduleInstance.vu
+	state := vu.State()
+	rt := vu.Runtime()
+
+	tagsAndMeta := state.Tags.GetCurrentValues()
+	if res.Request != nil {
+		tagsAndMeta.SetSystemTagOrMetaIfEnabled(state.Options.SystemTags, metrics.TagMethod, res.Request.Method)
+		tagsAndMeta.SetSystemTagOrMetaIfEnabled(state.Options.SystemTags, metrics.TagName, res.Request.URL)
+	}
+	tagsAndMeta.SetSystemTagOrMetaIfEnabled(state.Options.SystemTags, metrics.TagStatus, strconv.Itoa(res.Status))
+	if !common.IsNullish(params) {
+		if err := common.ApplyCustomUserTags(rt, &tagsAndMeta, params.ToObject(rt).Get("tags")); err != nil {
+			return err
+		}
+	}
+
+	value := 0.0
+	if valid {
+		value = 1
+	}
+	metrics.PushIfNotDone(vu.Context(), state.Samples, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: state.BuiltinMetrics.HTTPReqSchemaValid,
+			Tags:   tagsAndMeta.Tags,
+		},
+		Time:     time.Now(),
+		Value:    value,
+		Metadata: tagsAndMeta.Metadata,
+	})
+	return nil
+}
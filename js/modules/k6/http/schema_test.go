@@ -0,0 +1,128 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/metrics"
+)
+
+func TestResponseValidate(t *testing.T) {
+	t.Parallel()
+	ts := newTestCase(t)
+	tb := ts.tb
+	rt := ts.runtime.VU.Runtime()
+	sr := tb.Replacer.Replace
+
+	tb.Mux.HandleFunc("/json", jsonHandler)
+	tb.Mux.HandleFunc("/invalidjson", invalidJSONHandler)
+
+	_, err := rt.RunString(sr(`
+		var friendsSchema = {
+			type: "object",
+			required: ["glossary"],
+			properties: {
+				glossary: {
+					type: "object",
+					properties: {
+						friends: {
+							type: "array",
+							items: {
+								type: "object",
+								required: ["first", "age"],
+								properties: { age: { type: "integer", maximum: 60 } },
+							},
+						},
+					},
+				},
+			},
+		};
+		var res = http.get("HTTPBIN_URL/json");
+	`))
+	require.NoError(t, err)
+	metrics.GetBufferedSamples(ts.samples)
+
+	t.Run("Valid", func(t *testing.T) {
+		v, err := rt.RunString(`JSON.stringify(res.validate({ type: "object", required: ["glossary"] }))`)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"valid": true, "errors": []}`, v.String())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		v, err := rt.RunString(`JSON.stringify(res.validate(friendsSchema))`)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"valid": false, "errors": [{
+			"instanceLocation": "/glossary/friends/1/age",
+			"keywordLocation": "/properties/glossary/properties/friends/items/properties/age/maximum",
+			"message": "must be <= 60 but found 68"
+		}]}`, v.String())
+	})
+
+	t.Run("JSONString", func(t *testing.T) {
+		v, err := rt.RunString(`res.validate('{"$defs": {"g": {"type": "string"}}, "properties": {"glossary": {"$ref": "#/$defs/g"}}}').errors[0].message`)
+		require.NoError(t, err)
+		assert.Equal(t, "expected string, but got object", v.String())
+	})
+
+	t.Run("InvalidBody", func(t *testing.T) {
+		v, err := rt.RunString(sr(`http.get("HTTPBIN_URL/invalidjson").validate({}).errors[0].message`))
+		require.NoError(t, err)
+		assert.Contains(t, v.String(), "the body isn't valid JSON")
+	})
+
+	t.Run("NoResponseBody", func(t *testing.T) {
+		v, err := rt.RunString(sr(`http.get("HTTPBIN_URL/json", {responseType: 'none'}).validate({}).valid`))
+		require.NoError(t, err)
+		assert.False(t, v.ToBoolean())
+	})
+
+	t.Run("InvalidSchema", func(t *testing.T) {
+		_, err := rt.RunString(`res.validate({ type: 5 })`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid schema")
+
+		_, err = rt.RunString(`res.validate({ $ref: "https://example.com/schema.json" })`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "references to external schemas aren't supported")
+
+		_, err = rt.RunString(`res.validate()`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no schema provided to validate")
+	})
+
+	t.Run("UnknownParam", func(t *testing.T) {
+		_, err := rt.RunString(`res.validate({}, { metrics: true })`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown validate() parameter 'metrics'")
+	})
+
+	t.Run("Metric", func(t *testing.T) {
+		metrics.GetBufferedSamples(ts.samples)
+		_, err := rt.RunString(`
+			res.validate(friendsSchema);
+			res.validate({ type: "object" }, { metric: true });
+			res.validate(friendsSchema, { metric: true, tags: { payload: "friends" } });
+		`)
+		require.NoError(t, err)
+
+		var samples []metrics.Sample
+		for _, container := range metrics.GetBufferedSamples(ts.samples) {
+			for _, sample := range container.GetSamples() {
+				if sample.Metric.Name == metrics.HTTPReqSchemaValidName {
+					samples = append(samples, sample)
+				}
+			}
+		}
+		require.Len(t, samples, 2)
+
+		assert.Equal(t, 1.0, samples[0].Value)
+		tags := samples[0].Tags.Map()
+		assert.Equal(t, "GET", tags["method"])
+		assert.Equal(t, sr("HTTPBIN_URL/json"), tags["name"])
+		assert.Equal(t, "200", tags["status"])
+
+		assert.Equal(t, 0.0, samples[1].Value)
+		assert.Equal(t, "friends", samples[1].Tags.Map()["payload"])
+	})
+}
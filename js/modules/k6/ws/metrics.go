@@ -0,0 +1,32 @@
+package ws
+
+import "go.k6.io/k6/metrics"
+
+// instanceMetrics contains the metrics of the correlated messages, which
+// aren't builtin because they're only emitted when a correlate function is
+// given to ws.connect().
+type instanceMetrics struct {
+	MessageRoundTrip    *metrics.Metric
+	MessageSentSize     *metrics.Metric
+	MessageReceivedSize *metrics.Metric
+}
+
+// registerMetrics registers and returns the metrics in the provided registry
+func registerMetrics(registry *metrics.Registry) (*instanceMetrics, error) {
+	var err error
+	m := &instanceMetrics{}
+
+	if m.MessageRoundTrip, err = registry.NewMetric("ws_msg_round_trip", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.MessageSentSize, err = registry.NewMetric("ws_msg_sent_size", metrics.Trend, metrics.Data); err != nil {
+		return nil, err
+	}
+
+	if m.MessageReceivedSize, err = registry.NewMetric("ws_msg_received_size", metrics.Trend, metrics.Data); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -23,6 +23,7 @@ import (
 	"go.k6.io/k6/js/modules"
 	httpModule "go.k6.io/k6/js/modules/k6/http"
 	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/netext"
 	"go.k6.io/k6/metrics"
 )
 
@@ -33,8 +34,9 @@ type (
 
 	// WS represents a module instance of the WebSocket module.
 	WS struct {
-		vu  modules.VU
-		obj *sobek.Object
+		vu      modules.VU
+		obj     *sobek.Object
+		metrics *instanceMetrics
 	}
 )
 
@@ -52,8 +54,14 @@ func New() *RootModule {
 // a new instance for each VU.
 func (*RootModule) NewModuleInstance(m modules.VU) modules.Instance {
 	rt := m.Runtime()
+	metrics, err := registerMetrics(m.InitEnv().Registry)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("failed to register WebSocket module metrics: %w", err))
+	}
+
 	mi := &WS{
-		vu: m,
+		vu:      m,
+		metrics: metrics,
 	}
 	obj := rt.NewObject()
 	if err := obj.Set("connect", mi.Connect); err != nil {
@@ -83,6 +91,12 @@ type Socket struct {
 	tagsAndMeta    *metrics.TagsAndMeta
 	samplesOutput  chan<- metrics.SampleContainer
 	builtinMetrics *metrics.BuiltinMetrics
+	metrics        *instanceMetrics
+
+	// correlate returns the correlation IDs of the messages, and
+	// correlated keeps when the messages with an ID were sent
+	correlate  sobek.Callable
+	correlated netext.CorrelatedMessages
 }
 
 // HTTPResponse is the http response returned by ws.connect.
@@ -95,8 +109,9 @@ type HTTPResponse struct {
 }
 
 type message struct {
-	mtype int // message type consts as defined in gorilla/websocket/conn.go
-	data  []byte
+	mtype    int // message type consts as defined in gorilla/websocket/conn.go
+	data     []byte
+	received time.Time
 }
 
 type wsConnectArgs struct {
@@ -105,6 +120,7 @@ type wsConnectArgs struct {
 	enableCompression bool
 	cookieJar         *cookiejar.Jar
 	tagsAndMeta       *metrics.TagsAndMeta
+	correlate         sobek.Callable
 }
 
 const writeWait = 10 * time.Second
@@ -207,18 +223,26 @@ func (mi *WS) Connect(url string, args ...sobek.Value) (*HTTPResponse, error) {
 					Metric: socket.builtinMetrics.WSMessagesReceived,
 					Tags:   socket.tagsAndMeta.Tags,
 				},
-				Time:     time.Now(),
+				Time:     msg.received,
 				Metadata: socket.tagsAndMeta.Metadata,
 				Value:    1,
 			})
 
+			var data sobek.Value
+			event := "message"
 			if msg.mtype == websocket.BinaryMessage {
 				ab := rt.NewArrayBuffer(msg.data)
-				socket.handleEvent("binaryMessage", rt.ToValue(&ab))
+				data, event = rt.ToValue(&ab), "binaryMessage"
 			} else {
-				socket.handleEvent("message", rt.ToValue(string(msg.data)))
+				data = rt.ToValue(string(msg.data))
 			}
 
+			if err := socket.trackRoundTrip(data, len(msg.data), msg.received); err != nil {
+				_ = socket.closeConnection(websocket.CloseGoingAway)
+				return nil, err
+			}
+			socket.handleEvent(event, data)
+
 		case readErr := <-readErrChan:
 			socket.handleEvent("error", rt.ToValue(readErr))
 
@@ -301,6 +325,9 @@ func (mi *WS) dial(
 		samplesOutput:      state.Samples,
 		tagsAndMeta:        args.tagsAndMeta,
 		builtinMetrics:     state.BuiltinMetrics,
+		metrics:            mi.metrics,
+
+		correlate: args.correlate,
 	}
 
 	connEndHook := socket.pushSessionMetrics(connStart, connEnd)
@@ -327,6 +354,7 @@ func (s *Socket) handleEvent(event string, args ...sobek.Value) {
 
 // Send writes the given string message to the connection.
 func (s *Socket) Send(message string) {
+	s.trackSent(s.rt.ToValue(message), len(message))
 	if err := s.conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
 		s.handleEvent("error", s.rt.ToValue(err))
 	}
@@ -350,6 +378,7 @@ func (s *Socket) SendBinary(message sobek.Value) {
 
 	msg := message.Export()
 	if ab, ok := msg.(sobek.ArrayBuffer); ok {
+		s.trackSent(message, len(ab.Bytes()))
 		if err := s.conn.WriteMessage(websocket.BinaryMessage, ab.Bytes()); err != nil {
 			s.handleEvent("error", s.rt.ToValue(err))
 		}
@@ -375,6 +404,69 @@ func (s *Socket) SendBinary(message sobek.Value) {
 	})
 }
 
+// trackSent pushes the size of the message which is about to be sent, and
+// keeps when it was sent if it has a correlation ID.
+func (s *Socket) trackSent(message sobek.Value, size int) {
+	if s.correlate == nil {
+		return
+	}
+
+	id, err := s.correlationID(message, "outgoing")
+	if err != nil {
+		common.Throw(s.rt, err)
+	}
+
+	now := time.Now()
+	if id != "" {
+		s.correlated.Add(id, now)
+	}
+	s.pushSample(s.metrics.MessageSentSize, now, float64(size))
+}
+
+// trackRoundTrip pushes the size of the received message, and its round trip
+// if a message with the same correlation ID was sent.
+func (s *Socket) trackRoundTrip(message sobek.Value, size int, received time.Time) error {
+	if s.correlate == nil {
+		return nil
+	}
+
+	s.pushSample(s.metrics.MessageReceivedSize, received, float64(size))
+
+	id, err := s.correlationID(message, "incoming")
+	if err != nil || id == "" {
+		return err
+	}
+	if sent, ok := s.correlated.Take(id); ok {
+		s.pushSample(s.metrics.MessageRoundTrip, received, metrics.D(received.Sub(sent)))
+	}
+	return nil
+}
+
+// correlationID returns the correlation ID of the message, or an empty
+// string if the message doesn't have one.
+func (s *Socket) correlationID(message sobek.Value, direction string) (string, error) {
+	id, err := s.correlate(sobek.Undefined(), message, s.rt.ToValue(direction))
+	if err != nil {
+		return "", err
+	}
+	if common.IsNullish(id) {
+		return "", nil
+	}
+	return id.String(), nil
+}
+
+func (s *Socket) pushSample(metric *metrics.Metric, t time.Time, value float64) {
+	metrics.PushIfNotDone(s.ctx, s.samplesOutput, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: metric,
+			Tags:   s.tagsAndMeta.Tags,
+		},
+		Time:     t,
+		Metadata: s.tagsAndMeta.Metadata,
+		Value:    value,
+	})
+}
+
 // Ping sends a ping message over the websocket.
 func (s *Socket) Ping() {
 	deadline := time.Now().Add(writeWait)
@@ -588,7 +680,7 @@ func (s *Socket) readPump(readChan chan *message, errorChan chan error, closeCha
 		}
 
 		select {
-		case readChan <- &message{messageType, data}:
+		case readChan <- &message{messageType, data, time.Now()}:
 		case <-s.done:
 			return
 		}
@@ -699,6 +791,16 @@ func parseConnectArgs(state *lib.State, rt *sobek.Runtime, args ...sobek.Value)
 			}
 
 			parsedArgs.enableCompression = true
+		case "correlate":
+			correlateV := params.Get(k)
+			if common.IsNullish(correlateV) {
+				continue
+			}
+			correlate, isFunc := sobek.AssertFunction(correlateV)
+			if !isFunc {
+				return nil, errors.New("invalid ws.connect() correlate param: it needs to be a function")
+			}
+			parsedArgs.correlate = correlate
 		}
 	}
 
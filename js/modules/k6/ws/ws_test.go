@@ -194,6 +194,44 @@ func TestSessionSendReceive(t *testing.T) {
 	assertMetricEmittedCount(t, metrics.WSMessagesReceivedName, samplesBuf, sr("WSBIN_URL/ws-echo"), 1)
 }
 
+func TestSessionCorrelate(t *testing.T) {
+	t.Parallel()
+
+	test := newTestState(t)
+	sr := test.tb.Replacer.Replace
+	_, err := test.VU.Runtime().RunString(sr(`
+		var directions = [];
+		var params = {
+			correlate: function (data, direction) {
+				directions.push(direction);
+				return typeof data === "string" ? data.split(":")[0] : "binary";
+			},
+		};
+		var res = ws.connect("WSBIN_URL/ws-echo", params, function(socket){
+			socket.on("open", function() {
+				socket.send("1:first")
+				socket.sendBinary(new Uint8Array([1, 2, 3]).buffer)
+			})
+			socket.on("message", function (data) {
+				socket.close()
+			});
+		});
+		if (directions.join() != "outgoing,outgoing,incoming") {
+			throw new Error("wrong directions " + directions.join());
+		}
+		`))
+	require.NoError(t, err)
+	samplesBuf := metrics.GetBufferedSamples(test.samples)
+	assertMetricEmittedCount(t, "ws_msg_round_trip", samplesBuf, sr("WSBIN_URL/ws-echo"), 1)
+	assertMetricEmittedCount(t, "ws_msg_sent_size", samplesBuf, sr("WSBIN_URL/ws-echo"), 2)
+	assertMetricEmittedCount(t, "ws_msg_received_size", samplesBuf, sr("WSBIN_URL/ws-echo"), 1)
+
+	_, err = test.VU.Runtime().RunString(sr(`
+		ws.connect("WSBIN_URL/ws-echo", { correlate: "id" }, function(socket){});
+		`))
+	require.ErrorContains(t, err, "invalid ws.connect() correlate param: it needs to be a function")
+}
+
 func TestSessionInterval(t *testing.T) {
 	t.Parallel()
 	tb := httpmultibin.NewHTTPMultiBin(t)
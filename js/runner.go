@@ -10,6 +10,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/cookiejar"
+	"net/url"
 	"reflect"
 	"strconv"
 	"strings"
@@ -30,6 +31,7 @@ import (
 	"go.k6.io/k6/lib/consts"
 	"go.k6.io/k6/lib/fsext"
 	"go.k6.io/k6/lib/netext"
+	"go.k6.io/k6/lib/netext/httpext"
 	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/loader"
 	"go.k6.io/k6/metrics"
@@ -59,6 +61,10 @@ type Runner struct {
 	console    *console
 	setupData  []byte
 	BufferPool *lib.BufferPool
+
+	// newResolver returns a resolver with its own cache, for the VUs when
+	// the DNS cache isn't shared.
+	newResolver func() netext.Resolver
 }
 
 // New returns a new Runner for the provided source
@@ -154,9 +160,14 @@ func (r *Runner) newVU(
 		}
 	}
 
+	resolver := r.Resolver
+	if r.Bundle.Options.DNS.Cache.DNSCache == types.DNSvu {
+		// Every VU looks up the hosts itself, like separate clients would.
+		resolver = r.newResolver()
+	}
 	dialer := &netext.Dialer{
 		Dialer:           r.BaseDialer,
-		Resolver:         r.Resolver,
+		Resolver:         resolver,
 		Blacklist:        r.Bundle.Options.BlacklistIPs,
 		BlockedHostnames: r.Bundle.Options.BlockedHostnames.Trie,
 		Hosts:            r.Bundle.Options.Hosts.Trie,
@@ -190,8 +201,15 @@ func (r *Runner) newVU(
 		})
 		tlsConfig.NameToCertificate = nameToCert
 	}
+	var proxy *url.URL
+	if r.Bundle.Options.Proxy.String != "" {
+		if proxy, err = lib.ParseProxyURL(r.Bundle.Options.Proxy.String); err != nil {
+			return nil, err
+		}
+	}
+
 	transport := &http.Transport{
-		Proxy:               http.ProxyFromEnvironment,
+		Proxy:               httpext.ProxyFromRequest,
 		TLSClientConfig:     tlsConfig,
 		DialContext:         dialer.DialContext,
 		DisableCompression:  true,
@@ -218,6 +236,7 @@ func (r *Runner) newVU(
 		BundleInstance: *bi,
 		Runner:         r,
 		Transport:      transport,
+		HTTP3Transport: httpext.NewHTTP3Transport(dialer, tlsConfig),
 		Dialer:         dialer,
 		CookieJar:      cookieJar,
 		TLSConfig:      tlsConfig,
@@ -231,9 +250,12 @@ func (r *Runner) newVU(
 		Logger:         vu.Runner.preInitState.Logger,
 		Options:        vu.Runner.Bundle.Options,
 		Transport:      vu.Transport,
+		HTTP3Transport: vu.HTTP3Transport,
 		Dialer:         vu.Dialer,
 		TLSConfig:      vu.TLSConfig,
 		CookieJar:      cookieJar,
+		HARRecorder:    r.preInitState.HARRecorder,
+		Proxy:          proxy,
 		RPSLimit:       vu.Runner.RPSLimit,
 		BufferPool:     vu.BufferPool,
 		VUID:           vu.ID,
@@ -492,8 +514,14 @@ func (r *Runner) setResolver(dns types.DNSConfig) error {
 	if !dnsPol.Valid {
 		dnsPol = types.DefaultDNSConfig().Policy
 	}
-	r.Resolver = netext.NewResolver(
-		r.ActualResolver, ttl, dnsSel.DNSSelect, dnsPol.DNSPolicy)
+	actualResolver := r.ActualResolver
+	if len(dns.Nameservers) > 0 {
+		actualResolver = netext.NewNameserversResolver(dns.Nameservers)
+	}
+	r.newResolver = func() netext.Resolver {
+		return netext.NewResolver(actualResolver, ttl, dnsSel.DNSSelect, dnsPol.DNSPolicy)
+	}
+	r.Resolver = r.newResolver()
 
 	return nil
 }
@@ -600,14 +628,15 @@ func (r *Runner) getTimeoutFor(stage string) time.Duration {
 type VU struct {
 	BundleInstance
 
-	Runner    *Runner
-	Transport *http.Transport
-	Dialer    *netext.Dialer
-	CookieJar *cookiejar.Jar
-	TLSConfig *tls.Config
-	ID        uint64 // local to the current instance
-	IDGlobal  uint64 // global across all instances
-	iteration int64
+	Runner         *Runner
+	Transport      *http.Transport
+	HTTP3Transport *httpext.HTTP3Transport
+	Dialer         *netext.Dialer
+	CookieJar      *cookiejar.Jar
+	TLSConfig      *tls.Config
+	ID             uint64 // local to the current instance
+	IDGlobal       uint64 // global across all instances
+	iteration      int64
 
 	Console    *console
 	BufferPool *lib.BufferPool
@@ -681,6 +710,18 @@ func (u *VU) Activate(params *lib.VUActivationParams) lib.ActiveVU {
 		tagsAndMeta.SetSystemTagOrMetaIfEnabled(opts.SystemTags, metrics.TagScenario, params.Scenario)
 	})
 
+	// The network of the scenario overrides the global one, and the idle
+	// connections aren't reused when it changes between scenarios.
+	network := opts.Network
+	if scenario, ok := opts.Scenarios[params.Scenario]; ok {
+		if so := scenario.GetScenarioOptions(); so != nil && so.Network != nil {
+			network = *so.Network
+		}
+	}
+	if u.Dialer.SetNetworkProfile(network.ForVU(u.IDGlobal)) {
+		u.Transport.CloseIdleConnections()
+	}
+
 	ctx := params.RunContext
 	u.moduleVUImpl.ctx = ctx
 
@@ -863,6 +904,7 @@ func (u *VU) runFn(
 
 	if u.Runner.Bundle.Options.NoVUConnectionReuse.Bool {
 		u.Transport.CloseIdleConnections()
+		u.HTTP3Transport.CloseIdleConnections()
 	}
 
 	builtinMetrics := u.Runner.preInitState.BuiltinMetrics
@@ -1058,6 +1058,22 @@ func TestVUIntegrationBlacklistOption(t *testing.T) {
 	}
 }
 
+func TestVUIntegrationProxyOption(t *testing.T) {
+	t.Parallel()
+	r, err := getSimpleRunner(t, "/script.js", `
+					exports.options = { proxy: "socks5://127.0.0.1:1080" };
+					exports.default = function() {};
+				`)
+	require.NoError(t, err)
+
+	initVU, err := r.NewVU(context.Background(), 1, 1, make(chan metrics.SampleContainer, 100))
+	require.NoError(t, err)
+	vu, ok := initVU.(*VU)
+	require.True(t, ok)
+	require.NotNil(t, vu.state.Proxy)
+	assert.Equal(t, "socks5://127.0.0.1:1080", vu.state.Proxy.String())
+}
+
 func TestVUIntegrationBlacklistScript(t *testing.T) {
 	t.Parallel()
 	r1, err := getSimpleRunner(t, "/script.js", `
@@ -1244,6 +1260,61 @@ func TestVUIntegrationHosts(t *testing.T) {
 	}
 }
 
+func TestVUIntegrationNetworkProfile(t *testing.T) {
+	t.Parallel()
+	tb := httpmultibin.NewHTTPMultiBin(t)
+
+	r, err := getSimpleRunner(t, "/script.js", tb.Replacer.Replace(`
+		var http = require("k6/http");
+		exports.options = {
+			scenarios: {
+				fast: { executor: "shared-iterations" },
+				slow: {
+					executor: "shared-iterations",
+					options: { network: { latency: "100ms", downloadKbps: 800 } },
+				},
+			},
+		};
+		exports.default = function() {
+			http.get("HTTPBIN_URL/bytes/40000");
+		}
+	`))
+	require.NoError(t, err)
+	r.Bundle.Options.Hosts = types.NullHosts{Trie: tb.Dialer.Hosts}
+
+	// 40KB are 320 kilobits, which take 400ms with 800kbps.
+	for scenario, slow := range map[string]bool{"fast": false, "slow": true} {
+		samples := make(chan metrics.SampleContainer, 100)
+		ctx, cancel := context.WithCancel(context.Background())
+		vu, err := r.NewVU(ctx, 1, 1, samples)
+		require.NoError(t, err)
+		activeVU := vu.Activate(&lib.VUActivationParams{RunContext: ctx, Scenario: scenario})
+		require.NoError(t, activeVU.RunOnce())
+		cancel()
+
+		var receiving, duration, dataReceived float64
+		for _, sampleC := range metrics.GetBufferedSamples(samples) {
+			for _, s := range sampleC.GetSamples() {
+				switch s.Metric.Name {
+				case metrics.HTTPReqReceivingName:
+					receiving = s.Value
+				case metrics.HTTPReqDurationName:
+					duration = s.Value
+				case metrics.DataReceivedName:
+					dataReceived = s.Value
+				}
+			}
+		}
+		assert.Greater(t, dataReceived, 40000.0, scenario)
+		if slow {
+			assert.Greater(t, receiving, 300.0, scenario)
+			assert.Greater(t, duration, 400.0, scenario)
+		} else {
+			assert.Less(t, receiving, 300.0, scenario)
+		}
+	}
+}
+
 func TestVUIntegrationTLSConfig(t *testing.T) {
 	t.Parallel()
 	certPem, keyPem := generateTLSCertificate(t, "sha256-badssl.localhost", time.Now(), time.Hour)
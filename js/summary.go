@@ -87,9 +87,17 @@ func summarizeMetricsToObject(data *lib.Summary, options lib.Options, setupData
 		if len(m.Thresholds.Thresholds) > 0 {
 			thresholds := make(map[string]interface{})
 			for _, threshold := range m.Thresholds.Thresholds {
-				thresholds[threshold.Source] = map[string]interface{}{
+				thresholdData := map[string]interface{}{
 					"ok": !threshold.LastFailed,
 				}
+				if worst := threshold.WorstWindow(); worst != nil {
+					thresholdData["worst_window"] = map[string]interface{}{
+						"start": worst.Start.Format(time.RFC3339Nano),
+						"end":   worst.End.Format(time.RFC3339Nano),
+						"value": worst.Value,
+					}
+				}
+				thresholds[threshold.Source] = thresholdData
 			}
 			metricData["thresholds"] = thresholds
 		}
@@ -377,6 +377,18 @@ function summarizeMetrics(options, data, decorate) {
       )
 
     result.push(indent + fmtIndent + markColor(mark) + ' ' + fmtName + ' ' + getData(name))
+
+    forEach(metric.thresholds || {}, function (source, threshold) {
+      if (!threshold.worst_window) {
+        return
+      }
+      var aggregation = source.split(/[<>=!]/, 1)[0].trim()
+      var worstValue = humanizeValue(threshold.worst_window.value, metric, options.summaryTimeUnit)
+      var details = detailsPrefix + ' ' + source + ': worst window ' + aggregation + '=' + worstValue
+      result.push(
+        indent + fmtIndent + '  ' + decorate(details, threshold.ok ? palette.green : palette.red)
+      )
+    })
   }
 
   return result
@@ -133,6 +133,62 @@ func TestTextSummaryWithSubMetrics(t *testing.T) {
 	assert.Equal(t, "\n"+expected+"\n", string(summaryOut))
 }
 
+func TestTextSummaryWithWindowedThreshold(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	trendMetric, err := registry.NewMetric("my_trend", metrics.Trend, metrics.Time)
+	require.NoError(t, err)
+	trendMetric.Thresholds = metrics.NewThresholds([]string{"max<100 over 10s"})
+	require.NoError(t, trendMetric.Thresholds.Parse())
+
+	// The windows are advanced by the wall clock, so the spike has to be recent.
+	start := time.Now().Add(-15 * time.Second)
+	for i := 0; i < 20; i++ {
+		s := metrics.Sample{Time: start.Add(time.Duration(i) * time.Second), Value: 10}
+		if i == 10 {
+			s.Value = 150
+		}
+		trendMetric.Sink.Add(s)
+		trendMetric.Thresholds.AddSample(s, trendMetric.Sink)
+		if i >= 10 {
+			_, err = trendMetric.Thresholds.Run(trendMetric.Sink, time.Duration(i)*time.Second)
+			require.NoError(t, err)
+		}
+	}
+
+	summary := &lib.Summary{
+		Metrics:         map[string]*metrics.Metric{trendMetric.Name: trendMetric},
+		RootGroup:       &lib.Group{},
+		TestRunDuration: 20 * time.Second,
+	}
+
+	runner, err := getSimpleRunner(
+		t,
+		"/script.js",
+		`
+			exports.options = {summaryTrendStats: ["avg", "max"]};
+			exports.default = function() {/* we don't run this, metrics are mocked */};
+		`,
+		lib.RuntimeOptions{CompatibilityMode: null.NewString("base", true)},
+	)
+	require.NoError(t, err)
+
+	result, err := runner.HandleSummary(context.Background(), summary)
+	require.NoError(t, err)
+
+	require.Len(t, result, 1)
+	stdout := result["stdout"]
+	require.NotNil(t, stdout)
+
+	summaryOut, err := io.ReadAll(stdout)
+	require.NoError(t, err)
+
+	expected := "   ✗ my_trend...: avg=17ms max=150ms\n" +
+		"     ↳ max<100 over 10s: worst window max=150ms\n"
+	assert.Equal(t, "\n"+expected+"\n", string(summaryOut))
+}
+
 func createTestMetrics(t *testing.T) (map[string]*metrics.Metric, *lib.Group) {
 	registry := metrics.NewRegistry()
 	testMetrics := make(map[string]*metrics.Metric)
@@ -0,0 +1,226 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sort"
+
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+)
+
+// The distributions of the time between the iterations of the arrival-rate
+// executors.
+const (
+	// evenDistribution starts the iterations at perfectly even intervals.
+	evenDistribution = "even"
+	// poissonDistribution starts the iterations as a Poisson process, i.e.
+	// with exponentially distributed intervals.
+	poissonDistribution     = "poisson"
+	exponentialDistribution = "exponential"
+	// uniformJitterDistribution moves every iteration from its even start
+	// time by a random part of the interval.
+	uniformJitterDistribution = "uniform-jitter"
+	// cdfDistribution draws the intervals from a cumulative distribution
+	// function given as a table.
+	cdfDistribution = "cdf"
+)
+
+// defaultJitter is the part of the interval that the uniform-jitter
+// distribution moves the iterations by, if it isn't specified.
+const defaultJitter = 0.5
+
+// ArrivalDistribution is the distribution of the time between the iterations
+// started by an arrival-rate executor. Its mean is always the interval of the
+// configured rate, so the rate is met on average.
+//
+// The random values are drawn from a sequence generated from the seed, which
+// is the same for all the instances of a distributed test. Every instance
+// starts the iterations of its execution segment from the same sequence, so
+// together they start exactly the iterations a single instance would.
+type ArrivalDistribution struct {
+	Type string `json:"type"`
+	// Jitter is the part of the interval, between 0 and 1, the uniform-jitter
+	// distribution moves the iterations by
+	Jitter null.Float `json:"jitter"`
+	// CDF is the table of the cumulative distribution function of the
+	// intervals for the cdf distribution, as [probability, interval] pairs.
+	// The intervals are in any unit, since they're scaled to the rate.
+	CDF [][2]float64 `json:"cdf"`
+	// Seed is the seed of the random values, it's derived from the name of
+	// the scenario if it isn't specified
+	Seed null.Int `json:"seed"`
+}
+
+type rawArrivalDistribution ArrivalDistribution
+
+// UnmarshalJSON accepts either the full object or just the type of the
+// distribution, e.g. "poisson".
+func (ad *ArrivalDistribution) UnmarshalJSON(data []byte) error {
+	*ad = ArrivalDistribution{}
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &ad.Type)
+	}
+
+	return lib.StrictJSONUnmarshal(data, (*rawArrivalDistribution)(ad))
+}
+
+// Validate makes sure the distribution is supported and its options are
+// valid.
+func (ad *ArrivalDistribution) Validate() []error {
+	var errors []error
+	switch ad.Type {
+	case evenDistribution, poissonDistribution, exponentialDistribution:
+	case uniformJitterDistribution:
+		if ad.Jitter.Valid && (ad.Jitter.Float64 <= 0 || ad.Jitter.Float64 > 1) {
+			errors = append(errors, fmt.Errorf("the jitter of the arrival distribution must be more than 0 and at most 1"))
+		}
+	case cdfDistribution:
+		if _, err := newCDFTable(ad.CDF); err != nil {
+			errors = append(errors, fmt.Errorf("invalid CDF of the arrival distribution: %w", err))
+		}
+	default:
+		errors = append(errors, fmt.Errorf(
+			"unsupported arrival distribution %q, it should be one of %s, %s, %s, %s or %s", ad.Type,
+			evenDistribution, poissonDistribution, exponentialDistribution, uniformJitterDistribution, cdfDistribution,
+		))
+	}
+
+	if ad.Type != uniformJitterDistribution && ad.Jitter.Valid {
+		errors = append(errors, fmt.Errorf("the jitter is only supported by the %s arrival distribution",
+			uniformJitterDistribution))
+	}
+	if ad.Type != cdfDistribution && len(ad.CDF) > 0 {
+		errors = append(errors, fmt.Errorf("the cdf is only supported by the %s arrival distribution", cdfDistribution))
+	}
+	return errors
+}
+
+// newSequence returns the sequence of the start positions of the iterations,
+// or nil if they're evenly distributed.
+func (ad *ArrivalDistribution) newSequence(scenario string) *arrivalSequence {
+	if ad == nil || ad.Type == evenDistribution || ad.Type == "" {
+		return nil
+	}
+
+	seed := ad.Seed.Int64
+	if !ad.Seed.Valid {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(scenario))
+		seed = int64(h.Sum64()) //nolint:gosec
+	}
+	seq := &arrivalSequence{
+		rand: rand.New(rand.NewSource(seed)), //nolint:gosec
+		gi:   -1,
+	}
+
+	switch ad.Type {
+	case poissonDistribution, exponentialDistribution:
+		seq.interval = func(r *rand.Rand) float64 { return r.ExpFloat64() }
+	case uniformJitterDistribution:
+		seq.jitter = defaultJitter
+		if ad.Jitter.Valid {
+			seq.jitter = ad.Jitter.Float64
+		}
+	case cdfDistribution:
+		// the table was already validated
+		table, _ := newCDFTable(ad.CDF)
+		seq.interval = func(r *rand.Rand) float64 { return table.sample(r.Float64()) }
+	}
+	return seq
+}
+
+// arrivalSequence returns the start positions of the iterations, in units of
+// the mean interval between them. The evenly distributed iteration gi starts
+// at position gi.
+//
+// It draws a random value for every iteration of the whole test, including
+// the ones which aren't in the execution segment, so its positions don't
+// depend on the segment.
+type arrivalSequence struct {
+	rand *rand.Rand
+
+	// interval returns a random interval with a mean of 1, or it's nil if
+	// the iterations are only jittered from their even positions
+	interval func(*rand.Rand) float64
+	jitter   float64
+
+	gi       int64
+	position float64
+}
+
+// at returns the position of the iteration gi, it has to be called with
+// non-decreasing values of gi.
+func (s *arrivalSequence) at(gi int64) float64 {
+	for s.gi < gi {
+		s.gi++
+		if s.interval == nil {
+			s.position = math.Max(0, float64(s.gi)+s.jitter*(s.rand.Float64()-0.5))
+			continue
+		}
+		if s.gi > 0 {
+			s.position += s.interval(s.rand)
+		}
+	}
+	return s.position
+}
+
+// cdfTable is the inverse of a piecewise linear cumulative distribution
+// function, scaled to have a mean of 1.
+type cdfTable struct {
+	probabilities, values []float64
+}
+
+func newCDFTable(points [][2]float64) (*cdfTable, error) {
+	if len(points) < 2 {
+		return nil, fmt.Errorf("it needs at least 2 points")
+	}
+	if points[0][0] != 0 || points[len(points)-1][0] != 1 {
+		return nil, fmt.Errorf("the probabilities should start at 0 and end at 1")
+	}
+
+	t := &cdfTable{
+		probabilities: make([]float64, len(points)),
+		values:        make([]float64, len(points)),
+	}
+	var mean float64
+	for i, point := range points {
+		t.probabilities[i], t.values[i] = point[0], point[1]
+		if point[1] < 0 {
+			return nil, fmt.Errorf("the intervals can't be negative")
+		}
+		if i == 0 {
+			continue
+		}
+		if point[0] <= points[i-1][0] {
+			return nil, fmt.Errorf("the probabilities should be increasing")
+		}
+		if point[1] < points[i-1][1] {
+			return nil, fmt.Errorf("the intervals should be non-decreasing")
+		}
+		mean += (point[0] - points[i-1][0]) * (point[1] + points[i-1][1]) / 2
+	}
+	if mean <= 0 {
+		return nil, fmt.Errorf("the mean interval should be more than 0")
+	}
+
+	for i := range t.values {
+		t.values[i] /= mean
+	}
+	return t, nil
+}
+
+// sample returns the interval with the given cumulative probability p.
+func (t *cdfTable) sample(p float64) float64 {
+	i := sort.SearchFloat64s(t.probabilities, p)
+	if i == 0 {
+		return t.values[0]
+	}
+	p0, p1 := t.probabilities[i-1], t.probabilities[i]
+	v0, v1 := t.values[i-1], t.values[i]
+	return v0 + (v1-v0)*(p-p0)/(p1-p0)
+}
@@ -0,0 +1,184 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+func TestArrivalDistributionUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	var ad ArrivalDistribution
+	require.NoError(t, json.Unmarshal([]byte(`"poisson"`), &ad))
+	assert.Equal(t, ArrivalDistribution{Type: poissonDistribution}, ad)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"type": "uniform-jitter", "jitter": 0.2, "seed": 42}`), &ad))
+	assert.Equal(t, ArrivalDistribution{
+		Type: uniformJitterDistribution, Jitter: null.FloatFrom(0.2), Seed: null.IntFrom(42),
+	}, ad)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"type": "cdf", "cdf": [[0, 0], [1, 10]]}`), &ad))
+	assert.Equal(t, ArrivalDistribution{Type: cdfDistribution, CDF: [][2]float64{{0, 0}, {1, 10}}}, ad)
+
+	require.Error(t, json.Unmarshal([]byte(`{"type": "poisson", "rate": 1}`), &ad))
+}
+
+func TestArrivalDistributionValidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		ad  ArrivalDistribution
+		err string
+	}{
+		{ad: ArrivalDistribution{Type: evenDistribution}},
+		{ad: ArrivalDistribution{Type: poissonDistribution, Seed: null.IntFrom(1)}},
+		{ad: ArrivalDistribution{Type: exponentialDistribution}},
+		{ad: ArrivalDistribution{Type: uniformJitterDistribution}},
+		{ad: ArrivalDistribution{Type: uniformJitterDistribution, Jitter: null.FloatFrom(1)}},
+		{ad: ArrivalDistribution{Type: cdfDistribution, CDF: [][2]float64{{0, 1}, {0.9, 2}, {1, 10}}}},
+		{ad: ArrivalDistribution{Type: "gaussian"}, err: `unsupported arrival distribution "gaussian"`},
+		{ad: ArrivalDistribution{}, err: `unsupported arrival distribution ""`},
+		{
+			ad:  ArrivalDistribution{Type: uniformJitterDistribution, Jitter: null.FloatFrom(1.5)},
+			err: "the jitter of the arrival distribution must be more than 0 and at most 1",
+		},
+		{
+			ad:  ArrivalDistribution{Type: poissonDistribution, Jitter: null.FloatFrom(0.5)},
+			err: "the jitter is only supported by the uniform-jitter arrival distribution",
+		},
+		{
+			ad:  ArrivalDistribution{Type: cdfDistribution, CDF: [][2]float64{{0, 1}}},
+			err: "invalid CDF of the arrival distribution: it needs at least 2 points",
+		},
+		{
+			ad:  ArrivalDistribution{Type: cdfDistribution, CDF: [][2]float64{{0.1, 1}, {1, 2}}},
+			err: "the probabilities should start at 0 and end at 1",
+		},
+		{
+			ad:  ArrivalDistribution{Type: cdfDistribution, CDF: [][2]float64{{0, 1}, {0.5, 0.5}, {1, 2}}},
+			err: "the intervals should be non-decreasing",
+		},
+		{
+			ad:  ArrivalDistribution{Type: cdfDistribution, CDF: [][2]float64{{0, 0}, {1, 0}}},
+			err: "the mean interval should be more than 0",
+		},
+	}
+	for _, tc := range testCases {
+		errs := tc.ad.Validate()
+		if tc.err == "" {
+			assert.Empty(t, errs, tc.ad)
+			continue
+		}
+		require.Len(t, errs, 1, tc.ad)
+		assert.ErrorContains(t, errs[0], tc.err)
+	}
+}
+
+func TestArrivalSequenceMean(t *testing.T) {
+	t.Parallel()
+
+	for _, ad := range []*ArrivalDistribution{
+		{Type: poissonDistribution},
+		{Type: uniformJitterDistribution, Jitter: null.FloatFrom(1)},
+		{Type: cdfDistribution, CDF: [][2]float64{{0, 0}, {0.9, 20}, {0.99, 100}, {1, 1000}}},
+	} {
+		ad := ad
+		t.Run(ad.Type, func(t *testing.T) {
+			t.Parallel()
+
+			seq := ad.newSequence("test")
+			prev := 0.0
+			for gi := int64(0); gi < 100000; gi++ {
+				position := seq.at(gi)
+				require.GreaterOrEqual(t, position, prev)
+				prev = position
+			}
+			assert.InEpsilon(t, 100000, prev, 0.02)
+
+			// the same seed gives the same sequence
+			assert.Equal(t, prev, ad.newSequence("test").at(99999))
+			assert.NotEqual(t, prev, ad.newSequence("other").at(99999))
+		})
+	}
+}
+
+func TestRampingArrivalRateCalSegmentsWithDistribution(t *testing.T) {
+	t.Parallel()
+
+	config := RampingArrivalRateConfig{
+		BaseConfig: BaseConfig{Name: "test"},
+		TimeUnit:   types.NullDurationFrom(time.Second),
+		StartRate:  null.IntFrom(10),
+		Stages: []Stage{
+			{Duration: types.NullDurationFrom(10 * time.Second), Target: null.IntFrom(100)},
+			{Duration: types.NullDurationFrom(10 * time.Second), Target: null.IntFrom(100)},
+		},
+		ArrivalDistribution: &ArrivalDistribution{Type: poissonDistribution, Seed: null.IntFrom(7)},
+	}
+
+	getTimes := func(et *lib.ExecutionTuple) []time.Duration {
+		ch := make(chan time.Duration, 20)
+		go config.cal(et, ch)
+		var times []time.Duration
+		for d := range ch {
+			times = append(times, d)
+		}
+		return times
+	}
+
+	all := getTimes(mustNewExecutionTuple(nil, nil))
+	// the mean of the 1550 expected iterations, give or take the randomness
+	assert.InEpsilon(t, 1550, len(all), 0.05)
+	assert.NotEqual(t, getTimes(mustNewExecutionTuple(nil, nil)), getTimes(mustNewExecutionTuple(nil, nil))[1:])
+
+	sequence := newExecutionSegmentSequenceFromString("0,1/3,1/2,1")
+	var segmented []time.Duration
+	for _, segment := range []string{"0:1/3", "1/3:1/2", "1/2:1"} {
+		segmented = append(segmented, getTimes(mustNewExecutionTuple(newExecutionSegmentFromString(segment), sequence))...)
+	}
+	sort.Slice(segmented, func(i, j int) bool { return segmented[i] < segmented[j] })
+	assert.Equal(t, all, segmented)
+}
+
+func TestConstantArrivalRateRunWithDistribution(t *testing.T) {
+	t.Parallel()
+
+	var count int64
+	runner := simpleRunner(func(_ context.Context, _ *lib.State) error {
+		atomic.AddInt64(&count, 1)
+		return nil
+	})
+
+	config := getTestConstantArrivalRateConfig()
+	config.Name = "test"
+	config.Type = constantArrivalRateType
+	config.Duration = types.NullDurationFrom(time.Second)
+	config.ArrivalDistribution = &ArrivalDistribution{Type: poissonDistribution, Seed: null.IntFrom(1)}
+	require.Empty(t, config.Validate())
+
+	// the iterations which are expected to start in the first second
+	var expected int64
+	seq := config.ArrivalDistribution.newSequence(config.Name)
+	for gi := int64(0); time.Duration(float64(20*time.Millisecond)*seq.at(gi)) < time.Second; gi++ {
+		expected++
+	}
+
+	test := setupExecutorTest(t, "", "", lib.Options{}, runner, config)
+	defer test.cancel()
+
+	engineOut := make(chan metrics.SampleContainer, 1000)
+	require.NoError(t, test.executor.Run(test.ctx, engineOut))
+	assert.InDelta(t, expected, atomic.LoadInt64(&count), 1)
+}
@@ -28,6 +28,7 @@ type BaseConfig struct {
 	Name         string               `json:"-"` // set via the JS object key
 	Type         string               `json:"executor"`
 	StartTime    types.NullDuration   `json:"startTime"`
+	StartAfter   []string             `json:"startAfter,omitempty"` // scenario names, externally validated
 	GracefulStop types.NullDuration   `json:"gracefulStop"`
 	Env          map[string]string    `json:"env"`
 	Exec         null.String          `json:"exec"` // function name, externally validated
@@ -66,9 +67,20 @@ func (bc BaseConfig) Validate() (result []error) {
 	if bc.StartTime.Duration < 0 {
 		result = append(result, errors.New("the startTime can't be negative"))
 	}
+	for _, name := range bc.StartAfter {
+		if name == "" {
+			result = append(result, errors.New("the startAfter can't contain empty scenario names"))
+			break
+		}
+	}
 	if bc.GracefulStop.Duration < 0 {
 		result = append(result, errors.New("the gracefulStop timeout can't be negative"))
 	}
+	if bc.Options != nil && bc.Options.Network != nil {
+		if err := bc.Options.Network.Validate(); err != nil {
+			result = append(result, err)
+		}
+	}
 	return result
 }
 
@@ -83,11 +95,18 @@ func (bc BaseConfig) GetType() string {
 }
 
 // GetStartTime returns the starting time, relative to the beginning of the
-// actual test, that this executor is supposed to execute.
+// actual test, that this executor is supposed to execute. If the scenario has
+// startAfter, it's relative to the end of those scenarios instead.
 func (bc BaseConfig) GetStartTime() time.Duration {
 	return bc.StartTime.TimeDuration()
 }
 
+// GetStartAfter returns the names of the scenarios that have to finish before
+// this executor starts.
+func (bc BaseConfig) GetStartAfter() []string {
+	return bc.StartAfter
+}
+
 // GetGracefulStop returns how long k6 is supposed to wait for any still
 // running iterations to finish executing at the end of the normal executor
 // duration, before it actually kills them.
@@ -134,7 +153,13 @@ func (bc BaseConfig) getBaseInfo(facts ...string) string {
 	if bc.Exec.Valid {
 		facts = append(facts, fmt.Sprintf("exec: %s", bc.Exec.String))
 	}
-	if bc.StartTime.Duration > 0 {
+	switch {
+	case len(bc.StartAfter) > 0 && bc.StartTime.Duration > 0:
+		facts = append(facts, fmt.Sprintf("startAfter: %s + %s",
+			strings.Join(bc.StartAfter, " & "), bc.StartTime.Duration))
+	case len(bc.StartAfter) > 0:
+		facts = append(facts, fmt.Sprintf("startAfter: %s", strings.Join(bc.StartAfter, " & ")))
+	case bc.StartTime.Duration > 0:
 		facts = append(facts, fmt.Sprintf("startTime: %s", bc.StartTime.Duration))
 	}
 	if bc.GracefulStop.Duration > 0 {
@@ -0,0 +1,517 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/ui/pb"
+)
+
+const breakingPointArrivalRateType = "breaking-point-arrival-rate"
+
+// maxSustainableRateName is the name of the gauge with the highest arrival
+// rate, in iterations per second, that met the SLOs of a scenario.
+const maxSustainableRateName = "max_sustainable_rate"
+
+func init() {
+	lib.RegisterExecutorConfigType(
+		breakingPointArrivalRateType,
+		func(name string, rawJSON []byte) (lib.ExecutorConfig, error) {
+			config := NewBreakingPointArrivalRateConfig(name)
+			err := lib.StrictJSONUnmarshal(rawJSON, &config)
+			return config, err
+		},
+	)
+}
+
+// BreakingPointArrivalRateConfig stores the config for the breaking-point
+// arrival-rate executor, which looks for the highest arrival rate that meets
+// the SLOs.
+type BreakingPointArrivalRateConfig struct {
+	BaseConfig
+	StartRate    null.Int           `json:"startRate"`
+	TimeUnit     types.NullDuration `json:"timeUnit"`
+	RateStep     null.Int           `json:"rateStep"`
+	MaxRate      null.Int           `json:"maxRate"`
+	StepDuration types.NullDuration `json:"stepDuration"`
+
+	// Precision is the difference between a rate that met the SLOs and one
+	// that didn't, under which the search of the breaking point stops
+	Precision null.Int `json:"precision"`
+
+	// SLOs are the objectives that every step has to meet, with the
+	// thresholds syntax, evaluated on the samples of the step only
+	SLOs map[string]metrics.Thresholds `json:"slos"`
+
+	// Initialize `PreAllocatedVUs` number of VUs, and if more than that are needed,
+	// they will be dynamically allocated, until `MaxVUs` is reached, which is an
+	// absolutely hard limit on the number of VUs the executor will use
+	PreAllocatedVUs null.Int `json:"preAllocatedVUs"`
+	MaxVUs          null.Int `json:"maxVUs"`
+}
+
+// NewBreakingPointArrivalRateConfig returns a BreakingPointArrivalRateConfig
+// with default values
+func NewBreakingPointArrivalRateConfig(name string) *BreakingPointArrivalRateConfig {
+	return &BreakingPointArrivalRateConfig{
+		BaseConfig: NewBaseConfig(name, breakingPointArrivalRateType),
+		TimeUnit:   types.NewNullDuration(1*time.Second, false),
+		Precision:  null.NewInt(1, false),
+	}
+}
+
+// Make sure we implement the lib.ExecutorConfig interface
+var _ lib.ExecutorConfig = &BreakingPointArrivalRateConfig{}
+
+// GetPreAllocatedVUs is just a helper method that returns the scaled pre-allocated VUs.
+func (bparc BreakingPointArrivalRateConfig) GetPreAllocatedVUs(et *lib.ExecutionTuple) int64 {
+	return et.ScaleInt64(bparc.PreAllocatedVUs.Int64)
+}
+
+// GetMaxVUs is just a helper method that returns the scaled max VUs.
+func (bparc BreakingPointArrivalRateConfig) GetMaxVUs(et *lib.ExecutionTuple) int64 {
+	return et.ScaleInt64(bparc.MaxVUs.Int64)
+}
+
+// GetDescription returns a human-readable description of the executor options
+func (bparc BreakingPointArrivalRateConfig) GetDescription(et *lib.ExecutionTuple) string {
+	preAllocatedVUs, maxVUs := bparc.GetPreAllocatedVUs(et), bparc.GetMaxVUs(et)
+	maxVUsRange := fmt.Sprintf("maxVUs: %d", preAllocatedVUs)
+	if maxVUs > preAllocatedVUs {
+		maxVUsRange += fmt.Sprintf("-%d", maxVUs)
+	}
+
+	slos := make([]string, 0, len(bparc.SLOs))
+	for name := range bparc.SLOs {
+		slos = append(slos, name)
+	}
+	sort.Strings(slos)
+
+	return fmt.Sprintf("Up to %d iterations/%s in steps of %d per %s, while meeting the SLOs on %s%s",
+		bparc.MaxRate.Int64, bparc.TimeUnit.Duration, bparc.RateStep.Int64, bparc.StepDuration.Duration,
+		strings.Join(slos, ", "), bparc.getBaseInfo(maxVUsRange))
+}
+
+// Validate makes sure all options are configured and valid
+//
+//nolint:funlen,cyclop
+func (bparc *BreakingPointArrivalRateConfig) Validate() []error {
+	errors := bparc.BaseConfig.Validate()
+	if !bparc.StartRate.Valid {
+		errors = append(errors, fmt.Errorf("the startRate isn't specified"))
+	} else if bparc.StartRate.Int64 <= 0 {
+		errors = append(errors, fmt.Errorf("the startRate must be more than 0"))
+	}
+
+	if !bparc.RateStep.Valid {
+		errors = append(errors, fmt.Errorf("the rateStep isn't specified"))
+	} else if bparc.RateStep.Int64 <= 0 {
+		errors = append(errors, fmt.Errorf("the rateStep must be more than 0"))
+	}
+
+	if !bparc.MaxRate.Valid {
+		errors = append(errors, fmt.Errorf("the maxRate isn't specified"))
+	} else if bparc.MaxRate.Int64 < bparc.StartRate.Int64 {
+		errors = append(errors, fmt.Errorf("the maxRate can't be less than the startRate"))
+	}
+
+	if bparc.Precision.Int64 <= 0 {
+		errors = append(errors, fmt.Errorf("the precision must be more than 0"))
+	}
+
+	if bparc.TimeUnit.TimeDuration() <= 0 {
+		errors = append(errors, fmt.Errorf("the timeUnit must be more than 0"))
+	}
+
+	if !bparc.StepDuration.Valid {
+		errors = append(errors, fmt.Errorf("the stepDuration is unspecified"))
+	} else if bparc.StepDuration.TimeDuration() < minDuration {
+		errors = append(errors, fmt.Errorf(
+			"the stepDuration must be at least %s, but is %s", minDuration, bparc.StepDuration,
+		))
+	}
+
+	if len(bparc.SLOs) == 0 {
+		errors = append(errors, fmt.Errorf("there aren't any SLOs to find the breaking point with"))
+	}
+	for name, slos := range bparc.SLOs {
+		slos := slos
+		if err := slos.Parse(); err != nil {
+			errors = append(errors, fmt.Errorf("invalid SLOs on metric '%s': %w", name, err))
+		}
+	}
+
+	if !bparc.PreAllocatedVUs.Valid {
+		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs isn't specified"))
+	} else if bparc.PreAllocatedVUs.Int64 < 0 {
+		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs can't be negative"))
+	}
+
+	if !bparc.MaxVUs.Valid {
+		// TODO: don't change the config while validating
+		bparc.MaxVUs.Int64 = bparc.PreAllocatedVUs.Int64
+	} else if bparc.MaxVUs.Int64 < bparc.PreAllocatedVUs.Int64 {
+		errors = append(errors, fmt.Errorf("maxVUs can't be less than preAllocatedVUs"))
+	}
+
+	return errors
+}
+
+// getMaxSteps returns the number of steps of the longest possible search:
+// all the steps until the maxRate, and a binary search in the widest range
+// between a rate that met the SLOs and one that didn't.
+func (bparc BreakingPointArrivalRateConfig) getMaxSteps() int64 {
+	steps := (bparc.MaxRate.Int64-bparc.StartRate.Int64+bparc.RateStep.Int64-1)/bparc.RateStep.Int64 + 1
+
+	width := bparc.RateStep.Int64
+	if bparc.StartRate.Int64 > width {
+		width = bparc.StartRate.Int64
+	}
+	for width > bparc.Precision.Int64 {
+		width -= width / 2
+		steps++
+	}
+	return steps
+}
+
+// getMaxDuration returns the duration of the longest possible search,
+// including the time it takes to evaluate the SLOs after every step.
+func (bparc BreakingPointArrivalRateConfig) getMaxDuration() time.Duration {
+	return time.Duration(bparc.getMaxSteps()) * (bparc.StepDuration.TimeDuration() + lib.SLOEvaluationDelay)
+}
+
+// GetExecutionRequirements returns the number of required VUs to run the
+// executor for its whole duration (disregarding any startTime), including the
+// maximum waiting time for any iterations to gracefully stop. The duration is
+// the one of the longest possible search, the executor usually finishes sooner.
+func (bparc BreakingPointArrivalRateConfig) GetExecutionRequirements(et *lib.ExecutionTuple) []lib.ExecutionStep {
+	return []lib.ExecutionStep{
+		{
+			TimeOffset:      0,
+			PlannedVUs:      uint64(et.ScaleInt64(bparc.PreAllocatedVUs.Int64)),
+			MaxUnplannedVUs: uint64(et.ScaleInt64(bparc.MaxVUs.Int64) - et.ScaleInt64(bparc.PreAllocatedVUs.Int64)),
+		}, {
+			TimeOffset:      bparc.getMaxDuration() + bparc.GracefulStop.TimeDuration(),
+			PlannedVUs:      0,
+			MaxUnplannedVUs: 0,
+		},
+	}
+}
+
+// NewExecutor creates a new BreakingPointArrivalRate executor
+func (bparc BreakingPointArrivalRateConfig) NewExecutor(
+	es *lib.ExecutionState, logger *logrus.Entry,
+) (lib.Executor, error) {
+	return &BreakingPointArrivalRate{
+		BaseExecutor: NewBaseExecutor(&bparc, es, logger),
+		config:       bparc,
+	}, nil
+}
+
+// HasWork reports whether there is any work to be done for the given execution segment.
+func (bparc BreakingPointArrivalRateConfig) HasWork(et *lib.ExecutionTuple) bool {
+	return bparc.GetMaxVUs(et) > 0
+}
+
+// IsDistributable returns false, because the SLOs are evaluated on the metric
+// samples of the local instance only.
+func (BreakingPointArrivalRateConfig) IsDistributable() bool {
+	return false
+}
+
+// breakingPointSearch decides the rate of the next step, from the results of
+// the previous ones. It raises the rate by rateStep until the SLOs aren't met,
+// then it binary-searches between the last rate that met them and that one.
+type breakingPointSearch struct {
+	rateStep, maxRate, precision int64
+
+	// the highest rate that met the SLOs and the lowest one that didn't, 0
+	// if there hasn't been one yet
+	met, breached int64
+}
+
+// next records the result of a step, and returns the rate of the next one, or
+// false if the breaking point was found.
+func (s *breakingPointSearch) next(rate int64, met bool) (int64, bool) {
+	if met {
+		s.met = rate
+	} else {
+		s.breached = rate
+	}
+
+	if s.breached == 0 {
+		if rate >= s.maxRate {
+			return 0, false
+		}
+		return min(rate+s.rateStep, s.maxRate), true
+	}
+
+	if s.breached-s.met <= s.precision {
+		return 0, false
+	}
+	return s.met + (s.breached-s.met)/2, true
+}
+
+// BreakingPointArrivalRate raises the arrival rate step by step, while the
+// SLOs are met, to find the highest rate the system under test can sustain.
+type BreakingPointArrivalRate struct {
+	*BaseExecutor
+	config BreakingPointArrivalRateConfig
+	et     *lib.ExecutionTuple
+
+	watch              lib.SLOWatch
+	maxSustainableRate *metrics.Metric
+}
+
+// Make sure we implement the lib.Executor interface.
+var _ lib.Executor = &BreakingPointArrivalRate{}
+
+// Init values needed for the execution, and starts watching the SLOs.
+func (bpar *BreakingPointArrivalRate) Init(_ context.Context) error {
+	// err should always be nil, because Init() won't be called for executors
+	// with no work, as determined by their config's HasWork() method.
+	et, err := bpar.BaseExecutor.executionState.ExecutionTuple.GetNewExecutionTupleFromValue(bpar.config.MaxVUs.Int64)
+	if err != nil {
+		return err
+	}
+	bpar.et = et
+	bpar.iterSegIndex = lib.NewSegmentedIndex(et)
+
+	test := bpar.executionState.Test
+	if test.SLOWatcher == nil {
+		return errors.New("the breaking-point-arrival-rate executor needs the metrics to be processed, " +
+			"so it can't be used when both the thresholds and the end-of-test summary are disabled")
+	}
+	if bpar.watch, err = test.SLOWatcher.WatchSLOs(bpar.config.SLOs); err != nil {
+		return fmt.Errorf("invalid SLOs of scenario %s: %w", bpar.config.Name, err)
+	}
+	bpar.maxSustainableRate, err = test.Registry.NewMetric(maxSustainableRateName, metrics.Gauge)
+	return err
+}
+
+// Run raises the arrival rate step by step, and then binary-searches the
+// breaking point, until it's found or the maximum duration is reached.
+//
+//nolint:funlen,gocognit
+func (bpar BreakingPointArrivalRate) Run(parentCtx context.Context, out chan<- metrics.SampleContainer) (err error) {
+	defer bpar.watch.Stop()
+
+	gracefulStop := bpar.config.GetGracefulStop()
+	duration := bpar.config.getMaxDuration()
+	stepDuration := bpar.config.StepDuration.TimeDuration()
+	timeUnit := bpar.config.TimeUnit.TimeDuration()
+	preAllocatedVUs := bpar.config.GetPreAllocatedVUs(bpar.executionState.ExecutionTuple)
+	maxVUs := bpar.config.GetMaxVUs(bpar.executionState.ExecutionTuple)
+
+	// Make sure the log and the progress bar have accurate information
+	bpar.logger.WithFields(logrus.Fields{
+		"maxVUs": maxVUs, "preAllocatedVUs": preAllocatedVUs, "maxDuration": duration,
+		"stepDuration": stepDuration, "type": bpar.config.GetType(),
+	}).Debug("Starting executor run...")
+
+	activeVUsWg := &sync.WaitGroup{}
+
+	returnedVUs := make(chan struct{})
+	waitOnProgressChannel := make(chan struct{})
+	startTime, maxDurationCtx, regDurationCtx, cancel := getDurationContexts(parentCtx, duration, gracefulStop)
+	defer func() {
+		cancel()
+		<-waitOnProgressChannel
+	}()
+
+	vusPool := newActiveVUPool(bpar.executionState)
+	defer func() {
+		// Make sure all VUs aren't executing iterations anymore, for the cancel()
+		// below to deactivate them.
+		<-returnedVUs
+		// first close the vusPool so we wait for the gracefulShutdown
+		vusPool.Close()
+		cancel()
+		activeVUsWg.Wait()
+	}()
+	activeVUsCount := uint64(0)
+
+	// the current step and its local rate, in iterations per second, and
+	// whether the breaking point was found
+	var currentStep, currentRate atomic.Value
+	currentStep.Store(int64(1))
+	currentRate.Store(0.0)
+	var found atomic.Bool
+
+	vusFmt := pb.GetFixedLengthIntFormat(maxVUs)
+	progressFn := func() (float64, []string) {
+		spent := time.Since(startTime)
+		currActiveVUs := atomic.LoadUint64(&activeVUsCount)
+		progVUs := fmt.Sprintf(vusFmt+"/"+vusFmt+" VUs",
+			vusPool.Running(), currActiveVUs)
+		progStep := fmt.Sprintf("step %d", currentStep.Load())
+		progIters := fmt.Sprintf("%.2f iters/s", currentRate.Load())
+
+		right := []string{progVUs, progStep, progIters}
+		if spent > duration || found.Load() {
+			return 1, right
+		}
+		return math.Min(1, float64(spent)/float64(duration)), right
+	}
+	bpar.progress.Modify(pb.WithProgress(progressFn))
+	maxDurationCtx = lib.WithScenarioState(maxDurationCtx, &lib.ScenarioState{
+		Name:       bpar.config.Name,
+		Executor:   bpar.config.Type,
+		StartTime:  startTime,
+		ProgressFn: progressFn,
+	})
+
+	go func() {
+		trackProgress(parentCtx, maxDurationCtx, regDurationCtx, &bpar, progressFn)
+		close(waitOnProgressChannel)
+	}()
+
+	returnVU := func(u lib.InitializedVU) {
+		// Return the VU without decreasing the global active VU counter, which
+		// is done in the goroutine started by activeVUPool.AddVU, whenever the
+		// VU finishes running an iteration. This results in a more accurate
+		// report of VUs that are _actually_ active.
+		bpar.executionState.ReturnVU(u, false)
+		activeVUsWg.Done()
+	}
+
+	runIterationBasic := getIterationRunner(bpar.executionState, bpar.logger)
+	activateVU := func(initVU lib.InitializedVU) lib.ActiveVU {
+		activeVUsWg.Add(1)
+		activeVU := initVU.Activate(getVUActivationParams(
+			maxDurationCtx, bpar.config.BaseConfig, returnVU,
+			bpar.nextIterationCounters,
+		))
+		atomic.AddUint64(&activeVUsCount, 1)
+		vusPool.AddVU(maxDurationCtx, activeVU, runIterationBasic)
+		return activeVU
+	}
+
+	makeUnplannedVUCh := make(chan struct{})
+	defer close(makeUnplannedVUCh)
+	go func() {
+		defer close(returnedVUs)
+		for range makeUnplannedVUCh {
+			bpar.logger.Debug("Starting initialization of an unplanned VU...")
+			initVU, err := bpar.executionState.GetUnplannedVU(maxDurationCtx, bpar.logger)
+			if err != nil {
+				// TODO figure out how to return it to the Run goroutine
+				bpar.logger.WithError(err).Error("Error while allocating unplanned VU")
+			} else {
+				bpar.logger.Debug("The unplanned VU finished initializing successfully!")
+				activateVU(initVU)
+			}
+		}
+	}()
+
+	// Get the pre-allocated VUs in the local buffer
+	for i := int64(0); i < preAllocatedVUs; i++ {
+		initVU, err := bpar.executionState.GetPlannedVU(bpar.logger, false)
+		if err != nil {
+			return err
+		}
+		activateVU(initVU)
+	}
+
+	search := &breakingPointSearch{
+		rateStep:  bpar.config.RateStep.Int64,
+		maxRate:   bpar.config.MaxRate.Int64,
+		precision: bpar.config.Precision.Int64,
+	}
+	defer func() {
+		bpar.reportMaxSustainableRate(parentCtx, out, search.met)
+	}()
+
+	start, offsets, _ := bpar.et.GetStripedOffsets()
+	timer := time.NewTimer(time.Hour * 24)
+	iterations := &iterationStarter{
+		vusPool:               vusPool,
+		out:                   out,
+		droppedIterations:     bpar.executionState.Test.BuiltinMetrics.DroppedIterations,
+		metricTags:            bpar.getMetricTags(nil),
+		logger:                bpar.logger,
+		maxVUs:                maxVUs,
+		remainingUnplannedVUs: maxVUs - preAllocatedVUs,
+		makeUnplannedVUCh:     makeUnplannedVUCh,
+	}
+	stepStart := startTime
+	for step, rate, more := int64(1), bpar.config.StartRate.Int64, true; more; step++ {
+		scaledRate := getScaledArrivalRate(bpar.et.Segment, rate, timeUnit)
+		ratePerSec, _ := getArrivalRatePerSec(scaledRate).Float64()
+		currentStep.Store(step)
+		currentRate.Store(ratePerSec)
+		bpar.logger.WithFields(logrus.Fields{"step": step, "rate": rate}).Debug("Starting a step...")
+
+		// here the we need the not scaled one
+		notScaledTickerPeriod := getTickerPeriod(big.NewRat(rate, int64(timeUnit))).TimeDuration()
+		bpar.watch.Reset()
+		for li, gi := 0, start; ; li, gi = li+1, gi+offsets[li%len(offsets)] {
+			offset := notScaledTickerPeriod * time.Duration(gi)
+			if offset >= stepDuration {
+				offset = stepDuration
+			}
+			timer.Reset(offset - time.Since(stepStart))
+			select {
+			case <-timer.C:
+			case <-regDurationCtx.Done():
+				return nil
+			}
+			if offset == stepDuration {
+				break
+			}
+
+			iterations.start(parentCtx)
+		}
+		evaluationStart := time.Now()
+		breached, err := bpar.watch.Evaluate()
+		if err != nil {
+			return err
+		}
+		// The next step starts once the SLOs are evaluated, for its full duration.
+		stepStart = stepStart.Add(stepDuration + time.Since(evaluationStart))
+		bpar.logger.WithFields(logrus.Fields{
+			"step": step, "rate": rate, "breached": breached,
+		}).Debug("The step finished")
+		rate, more = search.next(rate, len(breached) == 0)
+	}
+	found.Store(true)
+	return nil
+}
+
+// reportMaxSustainableRate logs the highest rate that met the SLOs and pushes
+// it, in iterations per second, as the value of the max_sustainable_rate
+// gauge, so it's shown in the end-of-test summary.
+func (bpar BreakingPointArrivalRate) reportMaxSustainableRate(
+	ctx context.Context, out chan<- metrics.SampleContainer, rate int64,
+) {
+	if rate == 0 {
+		bpar.logger.Warn("None of the arrival rates met the SLOs")
+		return
+	}
+
+	bpar.logger.Infof("The highest arrival rate that met the SLOs was %d iterations/%s",
+		rate, bpar.config.TimeUnit.Duration)
+	ratePerSec, _ := getArrivalRatePerSec(big.NewRat(rate, int64(bpar.config.TimeUnit.TimeDuration()))).Float64()
+	metrics.PushIfNotDone(ctx, out, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: bpar.maxSustainableRate,
+			Tags:   bpar.getMetricTags(nil),
+		},
+		Time:  time.Now(),
+		Value: ratePerSec,
+	})
+}
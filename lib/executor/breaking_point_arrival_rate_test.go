@@ -0,0 +1,155 @@
+package executor
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+// iterationsSLOWatcher is an SLO watcher whose objectives are breached when
+// more than maxIterations iterations were started in the window.
+type iterationsSLOWatcher struct {
+	iterations    int64
+	maxIterations int64
+}
+
+func (w *iterationsSLOWatcher) WatchSLOs(map[string]metrics.Thresholds) (lib.SLOWatch, error) {
+	return w, nil
+}
+
+func (w *iterationsSLOWatcher) Reset() {
+	atomic.StoreInt64(&w.iterations, 0)
+}
+
+func (w *iterationsSLOWatcher) Evaluate() ([]string, error) {
+	if atomic.LoadInt64(&w.iterations) > w.maxIterations {
+		return []string{"iterations"}, nil
+	}
+	return nil, nil
+}
+
+func (w *iterationsSLOWatcher) Stop() {}
+
+func getTestBreakingPointArrivalRateConfig() *BreakingPointArrivalRateConfig {
+	config := NewBreakingPointArrivalRateConfig("breaking_point")
+	config.GracefulStop = types.NullDurationFrom(0)
+	config.StartRate = null.IntFrom(10)
+	config.RateStep = null.IntFrom(10)
+	config.MaxRate = null.IntFrom(50)
+	config.Precision = null.IntFrom(2)
+	config.StepDuration = types.NullDurationFrom(time.Second)
+	config.SLOs = map[string]metrics.Thresholds{"iterations": metrics.NewThresholds([]string{"count<28"})}
+	config.PreAllocatedVUs = null.IntFrom(10)
+	config.MaxVUs = null.IntFrom(10)
+	return config
+}
+
+func TestBreakingPointSearch(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		breakingAt int64
+		rates      []int64
+		met        int64
+	}{
+		{name: "never breached", breakingAt: 1000, rates: []int64{10, 20, 30, 40, 45}, met: 45},
+		{name: "breached first", breakingAt: 5, rates: []int64{10, 5, 2, 3, 4}, met: 4},
+		{name: "breached later", breakingAt: 28, rates: []int64{10, 20, 30, 25, 27, 28}, met: 27},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := &breakingPointSearch{rateStep: 10, maxRate: 45, precision: 1}
+			var rates []int64
+			for rate, more := int64(10), true; more; {
+				rates = append(rates, rate)
+				rate, more = s.next(rate, rate < tc.breakingAt)
+			}
+			assert.Equal(t, tc.rates, rates)
+			assert.Equal(t, tc.met, s.met)
+		})
+	}
+}
+
+func TestBreakingPointArrivalRateMaxDuration(t *testing.T) {
+	t.Parallel()
+
+	config := getTestBreakingPointArrivalRateConfig()
+	require.Empty(t, config.Validate())
+	// 5 steps to the maxRate and 3 to halve the rateStep down to the precision
+	assert.EqualValues(t, 8, config.getMaxSteps())
+
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	endOffset, isFinal := lib.GetEndOffset(config.GetExecutionRequirements(et))
+	assert.Equal(t, 8*(time.Second+lib.SLOEvaluationDelay), endOffset)
+	assert.True(t, isFinal)
+}
+
+func TestBreakingPointArrivalRateRun(t *testing.T) {
+	t.Parallel()
+
+	watcher := &iterationsSLOWatcher{maxIterations: 27}
+	runner := simpleRunner(func(_ context.Context, _ *lib.State) error {
+		atomic.AddInt64(&watcher.iterations, 1)
+		return nil
+	})
+
+	config := getTestBreakingPointArrivalRateConfig()
+	require.Empty(t, config.Validate())
+	options := lib.Options{}.Apply(runner.GetOptions())
+	testRunState := getTestRunState(t, options, runner)
+	testRunState.SLOWatcher = watcher
+
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	execReqs := config.GetExecutionRequirements(et)
+	es := lib.NewExecutionState(testRunState, et, lib.GetMaxPlannedVUs(execReqs), lib.GetMaxPossibleVUs(execReqs))
+	ctx, cancel, executor, _ := setupExecutor(t, config, es)
+	defer cancel()
+
+	engineOut := make(chan metrics.SampleContainer, 1000)
+	start := time.Now()
+	require.NoError(t, executor.Run(ctx, engineOut))
+	// the rates of the steps are 10, 20, 30, 25, 27 and 28
+	assert.InDelta(t, 6*time.Second, time.Since(start), float64(500*time.Millisecond))
+
+	var maxSustainableRate []float64
+	for _, sample := range metrics.GetBufferedSamples(engineOut) {
+		for _, s := range sample.GetSamples() {
+			if s.Metric.Name == maxSustainableRateName {
+				maxSustainableRate = append(maxSustainableRate, s.Value)
+			}
+		}
+	}
+	assert.Equal(t, []float64{27}, maxSustainableRate)
+}
+
+func TestBreakingPointArrivalRateWithoutSLOWatcher(t *testing.T) {
+	t.Parallel()
+
+	runner := simpleRunner(func(_ context.Context, _ *lib.State) error { return nil })
+	config := getTestBreakingPointArrivalRateConfig()
+	require.Empty(t, config.Validate())
+	testRunState := getTestRunState(t, lib.Options{}, runner)
+
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	es := lib.NewExecutionState(testRunState, et, 10, 10)
+	executor, err := config.NewExecutor(es, logrus.NewEntry(logrus.New()))
+	require.NoError(t, err)
+	require.ErrorContains(t, executor.Init(context.Background()), "needs the metrics to be processed")
+}
@@ -38,6 +38,10 @@ type ConstantArrivalRateConfig struct {
 	TimeUnit types.NullDuration `json:"timeUnit"`
 	Duration types.NullDuration `json:"duration"`
 
+	// ArrivalDistribution is the distribution of the time between the
+	// iterations, they're started at even intervals if it's nil
+	ArrivalDistribution *ArrivalDistribution `json:"arrivalDistribution,omitempty"`
+
 	// Initialize `PreAllocatedVUs` number of VUs, and if more than that are needed,
 	// they will be dynamically allocated, until `MaxVUs` is reached, which is an
 	// absolutely hard limit on the number of VUs the executor will use
@@ -108,6 +112,10 @@ func (carc *ConstantArrivalRateConfig) Validate() []error {
 		))
 	}
 
+	if carc.ArrivalDistribution != nil {
+		errors = append(errors, carc.ArrivalDistribution.Validate()...)
+	}
+
 	if !carc.PreAllocatedVUs.Valid {
 		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs isn't specified"))
 	} else if carc.PreAllocatedVUs.Int64 < 0 {
@@ -285,7 +293,6 @@ func (car ConstantArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 		return activeVU
 	}
 
-	remainingUnplannedVUs := maxVUs - preAllocatedVUs
 	makeUnplannedVUCh := make(chan struct{})
 	defer close(makeUnplannedVUCh)
 	go func() {
@@ -321,45 +328,27 @@ func (car ConstantArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 			int64(car.config.TimeUnit.TimeDuration()),
 		)).TimeDuration()
 
-	droppedIterationMetric := car.executionState.Test.BuiltinMetrics.DroppedIterations
-	shownWarning := false
-	metricTags := car.getMetricTags(nil)
+	arrivals := car.config.ArrivalDistribution.newSequence(car.config.Name)
+	iterations := &iterationStarter{
+		vusPool:               vusPool,
+		out:                   out,
+		droppedIterations:     car.executionState.Test.BuiltinMetrics.DroppedIterations,
+		metricTags:            car.getMetricTags(nil),
+		logger:                car.logger,
+		maxVUs:                maxVUs,
+		remainingUnplannedVUs: maxVUs - preAllocatedVUs,
+		makeUnplannedVUCh:     makeUnplannedVUCh,
+	}
 	for li, gi := 0, start; ; li, gi = li+1, gi+offsets[li%len(offsets)] {
-		t := notScaledTickerPeriod*time.Duration(gi) - time.Since(startTime)
+		offset := notScaledTickerPeriod * time.Duration(gi)
+		if arrivals != nil {
+			offset = time.Duration(float64(notScaledTickerPeriod) * arrivals.at(gi))
+		}
+		t := offset - time.Since(startTime)
 		timer.Reset(t)
 		select {
 		case <-timer.C:
-			if vusPool.TryRunIteration() {
-				continue
-			}
-
-			// Since there aren't any free VUs available, consider this iteration
-			// dropped - we aren't going to try to recover it, but
-
-			metrics.PushIfNotDone(parentCtx, out, metrics.Sample{
-				TimeSeries: metrics.TimeSeries{
-					Metric: droppedIterationMetric,
-					Tags:   metricTags,
-				},
-				Time:  time.Now(),
-				Value: 1,
-			})
-
-			// We'll try to start allocating another VU in the background,
-			// non-blockingly, if we have remainingUnplannedVUs...
-			if remainingUnplannedVUs == 0 {
-				if !shownWarning {
-					car.logger.Warningf("Insufficient VUs, reached %d active VUs and cannot initialize more", maxVUs)
-					shownWarning = true
-				}
-				continue
-			}
-
-			select {
-			case makeUnplannedVUCh <- struct{}{}: // great!
-				remainingUnplannedVUs--
-			default: // we're already allocating a new VU
-			}
+			iterations.start(parentCtx)
 
 		case <-regDurationCtx.Done():
 			return nil
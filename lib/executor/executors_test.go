@@ -105,6 +105,91 @@ var configMapTestCases = []configMapTestCase{
 	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startTime": "-10s"}}`, exp{validationError: true}},
 	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "exec": ""}}`, exp{validationError: true}},
 	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "gracefulStop": "-2s"}}`, exp{validationError: true}},
+	// startAfter
+	{
+		`{"main": {"executor": "constant-vus", "vus": 10, "duration": "2m", "gracefulStop": "0s",
+		    "startAfter": ["warmup"], "startTime": "10s"},
+		  "warmup": {"executor": "constant-vus", "vus": 5, "duration": "1m", "gracefulStop": "0s", "startTime": "20s"}}`,
+		exp{custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			assert.Empty(t, cm.Validate())
+			assert.Equal(t, []string{"warmup"}, cm["main"].GetStartAfter())
+
+			sorted := cm.GetSortedConfigs()
+			require.Len(t, sorted, 2)
+			assert.Equal(t, "warmup", sorted[0].GetName())
+			assert.Equal(t, "main", sorted[1].GetName())
+
+			et, err := lib.NewExecutionTuple(nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, "10 looping VUs for 2m0s (startAfter: warmup + 10s)", cm["main"].GetDescription(et))
+
+			// main can start 10s after warmup starts, if it finishes right
+			// away, and it ends 10s + 2m after the planned end of warmup, but
+			// it never runs in parallel with warmup
+			assert.Equal(t, []lib.ExecutionStep{
+				{TimeOffset: 20 * time.Second, PlannedVUs: 5},
+				{TimeOffset: 30 * time.Second, PlannedVUs: 10},
+				{TimeOffset: 210 * time.Second, PlannedVUs: 0},
+			}, cm.GetFullExecutionRequirements(et))
+		}},
+	},
+	{
+		`{"first": {"executor": "shared-iterations", "iterations": 10, "maxDuration": "1m", "gracefulStop": "0s"},
+		  "second": {"executor": "constant-vus", "vus": 2, "duration": "1m", "gracefulStop": "0s", "startAfter": ["first"]},
+		  "third": {"executor": "per-vu-iterations", "vus": 3, "iterations": 1, "maxDuration": "30s",
+		    "gracefulStop": "0s", "startAfter": ["first", "second"]}}`,
+		exp{custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			assert.Empty(t, cm.Validate())
+
+			et, err := lib.NewExecutionTuple(nil, nil)
+			require.NoError(t, err)
+			totalReqs := cm.GetFullExecutionRequirements(et)
+			endOffset, isFinal := lib.GetEndOffset(totalReqs)
+			assert.Equal(t, 150*time.Second, endOffset)
+			assert.True(t, isFinal)
+			assert.Equal(t, uint64(3), lib.GetMaxPlannedVUs(totalReqs), "the sequential scenarios never run in parallel")
+		}},
+	},
+	{
+		`{"stream": {"executor": "externally-controlled-arrival-rate", "rate": 10, "duration": "0", "preAllocatedVUs": 2},
+		  "after": {"executor": "constant-vus", "vus": 3, "duration": "1m", "gracefulStop": "0s", "startAfter": ["stream"]}}`,
+		exp{custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			assert.Empty(t, cm.Validate())
+
+			// the infinite scenario can be stopped at any time, but after
+			// starts only after it's stopped
+			et, err := lib.NewExecutionTuple(nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, uint64(3), lib.GetMaxPlannedVUs(cm.GetFullExecutionRequirements(et)))
+		}},
+	},
+	{
+		`{"first": {"executor": "shared-iterations", "vus": 5, "iterations": 10, "maxDuration": "1m", "gracefulStop": "0s"},
+		  "second": {"executor": "constant-vus", "vus": 10, "duration": "2m", "gracefulStop": "0s", "startAfter": ["first"]},
+		  "fixed": {"executor": "constant-vus", "vus": 3, "duration": "10s", "gracefulStop": "0s", "startTime": "30s"}}`,
+		exp{custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			assert.Empty(t, cm.Validate())
+
+			// first can finish long before its maxDuration, so second can
+			// run in parallel with fixed
+			et, err := lib.NewExecutionTuple(nil, nil)
+			require.NoError(t, err)
+			totalReqs := cm.GetFullExecutionRequirements(et)
+			assert.Equal(t, uint64(13), lib.GetMaxPlannedVUs(totalReqs))
+			assert.Equal(t, lib.ExecutionStep{TimeOffset: 30 * time.Second, PlannedVUs: 13}, totalReqs[len(totalReqs)-3])
+			endOffset, isFinal := lib.GetEndOffset(totalReqs)
+			assert.Equal(t, 180*time.Second, endOffset)
+			assert.True(t, isFinal)
+		}},
+	},
+	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startAfter": [""]}}`, exp{validationError: true}},
+	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startAfter": ["other"]}}`, exp{validationError: true}},
+	{`{"aname": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startAfter": ["aname"]}}`, exp{validationError: true}},
+	{
+		`{"a": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startAfter": ["b"]},
+		  "b": {"executor": "constant-vus", "vus": 10, "duration": "10s", "startAfter": ["a"]}}`,
+		exp{validationError: true},
+	},
 	// ramping-vus
 	{
 		`{"varloops": {"executor": "ramping-vus", "startVUs": 20, "gracefulStop": "15s", "gracefulRampDown": "10s",
@@ -358,6 +443,17 @@ var configMapTestCases = []configMapTestCase{
 	{`{"carrival": {"executor": "constant-arrival-rate", "rate": 10, "duration": "10m", "preAllocatedVUs": 20, "maxVUs": 15}}`, exp{validationError: true}},
 	{`{"carrival": {"executor": "constant-arrival-rate", "rate": 10, "duration": "0s", "preAllocatedVUs": 20, "maxVUs": 25}}`, exp{validationError: true}},
 	{`{"carrival": {"executor": "constant-arrival-rate", "rate": 10, "duration": "10m", "preAllocatedVUs": -2, "maxVUs": 25}}`, exp{validationError: true}},
+	{
+		`{"carrival": {"executor": "constant-arrival-rate", "rate": 10, "duration": "10m", "preAllocatedVUs": 20, "arrivalDistribution": "poisson"}}`,
+		exp{custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			assert.Empty(t, cm["carrival"].Validate())
+			require.Equal(t, &ArrivalDistribution{Type: poissonDistribution},
+				cm["carrival"].(*ConstantArrivalRateConfig).ArrivalDistribution)
+		}},
+	},
+	{`{"carrival": {"executor": "constant-arrival-rate", "rate": 10, "duration": "10m", "preAllocatedVUs": 20, "arrivalDistribution": "gaussian"}}`, exp{validationError: true}},
+	{`{"carrival": {"executor": "constant-arrival-rate", "rate": 10, "duration": "10m", "preAllocatedVUs": 20, "arrivalDistribution": {"type": "uniform-jitter", "jitter": 2}}}`, exp{validationError: true}},
+	{`{"carrival": {"executor": "constant-arrival-rate", "rate": 10, "duration": "10m", "preAllocatedVUs": 20, "arrivalDistribution": {"type": "poisson", "rate": 2}}}`, exp{parseError: true}},
 	// ramping-arrival-rate
 	{
 		`{"varrival": {"executor": "ramping-arrival-rate", "startRate": 10, "timeUnit": "30s", "preAllocatedVUs": 20,
@@ -408,6 +504,66 @@ var configMapTestCases = []configMapTestCase{
 	{`{"varrival": {"executor": "ramping-arrival-rate", "preAllocatedVUs": 20, "maxVUs": 50, "stages": [{"duration": "5m", "target": 10}], "timeUnit": "-1s"}}`, exp{validationError: true}},
 	{`{"varrival": {"executor": "ramping-arrival-rate", "preAllocatedVUs": 20, "maxVUs": 50, "stages": [{"duration": "5m", "target": 10}], "timeUnit": "0s"}}`, exp{validationError: true}},
 	{`{"varrival": {"executor": "ramping-arrival-rate", "preAllocatedVUs": 30, "maxVUs": 20, "stages": [{"duration": "5m", "target": 10}]}}`, exp{validationError: true}},
+	{`{"varrival": {"executor": "ramping-arrival-rate", "preAllocatedVUs": 20, "stages": [{"duration": "5m", "target": 10}], "arrivalDistribution": {"type": "cdf", "cdf": [[0, 1], [1, 3]]}}}`, exp{}},
+	{`{"varrival": {"executor": "ramping-arrival-rate", "preAllocatedVUs": 20, "stages": [{"duration": "5m", "target": 10}], "arrivalDistribution": {"type": "cdf", "cdf": [[0, 1]]}}}`, exp{validationError: true}},
+	{
+		`{"bp": {"executor": "breaking-point-arrival-rate", "startRate": 10, "rateStep": 10, "maxRate": 100,
+		"stepDuration": "1m", "slos": {"http_req_duration": ["p(95)<500"], "http_req_failed": ["rate<0.01"]},
+		"preAllocatedVUs": 20, "maxVUs": 50}}`,
+		exp{custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			et, err := lib.NewExecutionTuple(nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, "Up to 100 iterations/1s in steps of 10 per 1m0s, while meeting the SLOs on "+
+				"http_req_duration, http_req_failed (maxVUs: 20-50, gracefulStop: 30s)", cm["bp"].GetDescription(et))
+			assert.False(t, cm["bp"].IsDistributable())
+
+			// 10 steps to the maxRate and 4 to halve the rateStep down to the default precision
+			endOffset, isFinal := lib.GetEndOffset(cm["bp"].GetExecutionRequirements(et))
+			assert.Equal(t, 14*(time.Minute+lib.SLOEvaluationDelay)+30*time.Second, endOffset)
+			assert.True(t, isFinal)
+		}},
+	},
+	{`{"bp": {"executor": "breaking-point-arrival-rate", "startRate": 10, "rateStep": 10, "maxRate": 100, "stepDuration": "1m", "preAllocatedVUs": 20}}`, exp{validationError: true}},
+	{`{"bp": {"executor": "breaking-point-arrival-rate", "startRate": 10, "rateStep": 10, "maxRate": 5, "stepDuration": "1m", "slos": {"http_reqs": ["count<10"]}, "preAllocatedVUs": 20}}`, exp{validationError: true}},
+	{`{"bp": {"executor": "breaking-point-arrival-rate", "startRate": 10, "rateStep": 10, "maxRate": 100, "stepDuration": "1m", "slos": {"http_reqs": ["count<"]}, "preAllocatedVUs": 20}}`, exp{validationError: true}},
+	{`{"bp": {"executor": "breaking-point-arrival-rate", "startRate": 10, "rateStep": 0, "maxRate": 100, "stepDuration": "1m", "slos": {"http_reqs": ["count<10"]}, "preAllocatedVUs": 20}}`, exp{validationError: true}},
+	// replay-arrival-rate
+	{
+		`{"replay": {"executor": "replay-arrival-rate", "file": "shape.csv", "timeUnit": "1m", "timeCompression": 24, "preAllocatedVUs": 20}}`,
+		exp{validationError: true, custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			sched := NewReplayArrivalRateConfig("replay")
+			sched.File = null.StringFrom("shape.csv")
+			sched.TimeUnit = types.NullDurationFrom(time.Minute)
+			sched.TimeCompression = null.FloatFrom(24)
+			sched.PreAllocatedVUs = null.IntFrom(20)
+			sched.MaxVUs = null.NewInt(20, false) // set by Validate()
+			require.Equal(t, lib.ScenarioConfigs{"replay": sched}, cm)
+
+			// the traffic shape isn't loaded yet
+			errs := cm["replay"].Validate()
+			require.Len(t, errs, 1)
+			assert.ErrorContains(t, errs[0], `the traffic shape wasn't loaded from "shape.csv"`)
+		}},
+	},
+	{`{"replay": {"executor": "replay-arrival-rate", "preAllocatedVUs": 20}}`, exp{validationError: true}},
+	{`{"replay": {"executor": "replay-arrival-rate", "file": "shape.csv", "rate": 10, "preAllocatedVUs": 20}}`, exp{parseError: true}},
+	// externally-controlled-arrival-rate
+	{
+		`{"ecar": {"executor": "externally-controlled-arrival-rate", "rate": 10, "duration": "0", "preAllocatedVUs": 20}}`,
+		exp{custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			sched := NewExternallyControlledArrivalRateConfig("ecar")
+			sched.Rate = null.IntFrom(10)
+			sched.Duration = types.NullDurationFrom(0)
+			sched.PreAllocatedVUs = null.IntFrom(20)
+			sched.MaxVUs = null.NewInt(20, false)
+			require.Empty(t, cm["ecar"].Validate())
+			require.Equal(t, cm, lib.ScenarioConfigs{"ecar": sched})
+			assert.False(t, cm["ecar"].IsDistributable())
+		}},
+	},
+	{`{"ecar": {"executor": "externally-controlled-arrival-rate", "rate": 10, "preAllocatedVUs": 20}}`, exp{validationError: true}},
+	{`{"ecar": {"executor": "externally-controlled-arrival-rate", "duration": "1m", "preAllocatedVUs": 20}}`, exp{validationError: true}},
+	{`{"ecar": {"executor": "externally-controlled-arrival-rate", "rate": 10, "duration": "1m", "preAllocatedVUs": 20, "maxVUs": 10}}`, exp{validationError: true}},
 	// TODO: more tests of mixed executors and execution plans
 
 	// scenario options
@@ -286,7 +286,9 @@ func (mex *ExternallyControlled) UpdateConfig(ctx context.Context, newConf inter
 }
 
 // This is a helper function that is used in run for non-infinite durations.
-func (mex *ExternallyControlled) stopWhenDurationIsReached(ctx context.Context, duration time.Duration, cancel func()) {
+func (mex *ExternallyControlled) stopWhenDurationIsReached(
+	ctx context.Context, startOffset, duration time.Duration, cancel func(),
+) {
 	ctxDone := ctx.Done()
 	checkInterval := time.NewTicker(100 * time.Millisecond)
 	for {
@@ -298,7 +300,7 @@ func (mex *ExternallyControlled) stopWhenDurationIsReached(ctx context.Context,
 		// TODO: something saner and more optimized that sleeps for pauses and
 		// doesn't depend on the global execution state?
 		case <-checkInterval.C:
-			elapsed := mex.executionState.GetCurrentTestRunDuration() - mex.config.StartTime.TimeDuration()
+			elapsed := mex.executionState.GetCurrentTestRunDuration() - startOffset
 			if elapsed >= duration {
 				cancel()
 				return
@@ -358,6 +360,7 @@ type externallyControlledRunState struct {
 	executor        *ExternallyControlled
 	startMaxVUs     int64             // the scaled number of initially configured MaxVUs
 	duration        time.Duration     // the total duration of the executor, could be 0 for infinite
+	startOffset     time.Duration     // the test run duration when the executor started
 	activeVUsCount  *int64            // the current number of active VUs, used only for the progress display
 	maxVUs          *int64            // the current number of initialized VUs
 	vuHandles       []*manualVUHandle // handles for manipulating and tracking all of the VUs
@@ -394,7 +397,7 @@ func (rs *externallyControlledRunState) progressFn() (float64, []string) {
 
 	// TODO: use a saner way to calculate the elapsed time, without relying on
 	// the global execution state...
-	elapsed := rs.executor.executionState.GetCurrentTestRunDuration() - rs.executor.config.StartTime.TimeDuration()
+	elapsed := rs.executor.executionState.GetCurrentTestRunDuration() - rs.startOffset
 	if elapsed > rs.duration {
 		return 1, right
 	}
@@ -496,9 +499,11 @@ func (mex *ExternallyControlled) Run(parentCtx context.Context, _ chan<- metrics
 		<-waitOnProgressChannel
 	}()
 
+	// it isn't the startTime, since the executor could start after other scenarios
+	startOffset := mex.executionState.GetCurrentTestRunDuration()
 	duration := currentControlConfig.Duration.TimeDuration()
 	if duration > 0 { // Only keep track of duration if it's not infinite
-		go mex.stopWhenDurationIsReached(ctx, duration, cancel)
+		go mex.stopWhenDurationIsReached(ctx, startOffset, duration, cancel)
 	}
 
 	mex.logger.WithFields(
@@ -519,6 +524,7 @@ func (mex *ExternallyControlled) Run(parentCtx context.Context, _ chan<- metrics
 		executor:        mex,
 		startMaxVUs:     startMaxVUs,
 		duration:        duration,
+		startOffset:     startOffset,
 		vuHandles:       make([]*manualVUHandle, startMaxVUs),
 		currentlyPaused: false,
 		activeVUsCount:  new(int64),
@@ -0,0 +1,456 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/ui/pb"
+)
+
+const externallyControlledArrivalRateType = "externally-controlled-arrival-rate"
+
+func init() {
+	lib.RegisterExecutorConfigType(
+		externallyControlledArrivalRateType,
+		func(name string, rawJSON []byte) (lib.ExecutorConfig, error) {
+			config := NewExternallyControlledArrivalRateConfig(name)
+			err := lib.StrictJSONUnmarshal(rawJSON, &config)
+			return config, err
+		},
+	)
+}
+
+// ExternallyControlledArrivalRateConfigParams contains the options of the
+// externally controlled arrival-rate executor which can be changed while it's
+// running.
+type ExternallyControlledArrivalRateConfigParams struct {
+	Rate null.Int `json:"rate"`
+}
+
+// Validate just checks the control options in isolation.
+func (ecarp ExternallyControlledArrivalRateConfigParams) Validate() (errors []error) {
+	if !ecarp.Rate.Valid {
+		errors = append(errors, fmt.Errorf("the iteration rate isn't specified"))
+	} else if ecarp.Rate.Int64 < 0 {
+		errors = append(errors, fmt.Errorf("the iteration rate can't be negative"))
+	}
+	return errors
+}
+
+// ExternallyControlledArrivalRateConfig stores the config for the externally
+// controlled arrival-rate executor. It starts iterations at the configured rate
+// like the constant-arrival-rate executor, but the rate can be changed and the
+// executor can be paused and resumed through the REST API. The duration can be
+// 0, which means "infinite duration", i.e. the user has to manually abort the
+// script.
+type ExternallyControlledArrivalRateConfig struct {
+	BaseConfig
+	ExternallyControlledArrivalRateConfigParams
+	TimeUnit types.NullDuration `json:"timeUnit"`
+	Duration types.NullDuration `json:"duration"` // 0 is a valid value, meaning infinite duration
+
+	// Initialize `PreAllocatedVUs` number of VUs, and if more than that are needed,
+	// they will be dynamically allocated, until `MaxVUs` is reached, which is an
+	// absolutely hard limit on the number of VUs the executor will use
+	PreAllocatedVUs null.Int `json:"preAllocatedVUs"`
+	MaxVUs          null.Int `json:"maxVUs"`
+}
+
+// NewExternallyControlledArrivalRateConfig returns an
+// ExternallyControlledArrivalRateConfig with default values
+func NewExternallyControlledArrivalRateConfig(name string) *ExternallyControlledArrivalRateConfig {
+	return &ExternallyControlledArrivalRateConfig{
+		BaseConfig: NewBaseConfig(name, externallyControlledArrivalRateType),
+		TimeUnit:   types.NewNullDuration(1*time.Second, false),
+	}
+}
+
+// Make sure we implement the lib.ExecutorConfig interface
+var _ lib.ExecutorConfig = &ExternallyControlledArrivalRateConfig{}
+
+// GetPreAllocatedVUs is just a helper method that returns the scaled pre-allocated VUs.
+func (ecarc ExternallyControlledArrivalRateConfig) GetPreAllocatedVUs(et *lib.ExecutionTuple) int64 {
+	return et.ScaleInt64(ecarc.PreAllocatedVUs.Int64)
+}
+
+// GetMaxVUs is just a helper method that returns the scaled max VUs.
+func (ecarc ExternallyControlledArrivalRateConfig) GetMaxVUs(et *lib.ExecutionTuple) int64 {
+	return et.ScaleInt64(ecarc.MaxVUs.Int64)
+}
+
+// GetDescription returns a human-readable description of the executor options
+func (ecarc ExternallyControlledArrivalRateConfig) GetDescription(et *lib.ExecutionTuple) string {
+	preAllocatedVUs, maxVUs := ecarc.GetPreAllocatedVUs(et), ecarc.GetMaxVUs(et)
+	maxVUsRange := fmt.Sprintf("maxVUs: %d", preAllocatedVUs)
+	if maxVUs > preAllocatedVUs {
+		maxVUsRange += fmt.Sprintf("-%d", maxVUs)
+	}
+
+	duration := "infinite"
+	if ecarc.Duration.Duration != 0 {
+		duration = ecarc.Duration.String()
+	}
+	arrRate := getScaledArrivalRate(et.Segment, ecarc.Rate.Int64, ecarc.TimeUnit.TimeDuration())
+	arrRatePerSec, _ := getArrivalRatePerSec(arrRate).Float64()
+
+	return fmt.Sprintf("Externally controlled %.2f iterations/s at the start, %s duration%s",
+		arrRatePerSec, duration, ecarc.getBaseInfo(maxVUsRange))
+}
+
+// Validate makes sure all options are configured and valid
+func (ecarc *ExternallyControlledArrivalRateConfig) Validate() []error {
+	errors := append(ecarc.BaseConfig.Validate(), ecarc.ExternallyControlledArrivalRateConfigParams.Validate()...)
+
+	if ecarc.TimeUnit.TimeDuration() <= 0 {
+		errors = append(errors, fmt.Errorf("the timeUnit must be more than 0"))
+	}
+
+	if !ecarc.Duration.Valid {
+		errors = append(errors, fmt.Errorf("the duration must be specified, for infinite duration use 0"))
+	} else if ecarc.Duration.TimeDuration() < 0 {
+		errors = append(errors, fmt.Errorf("the duration can't be negative, for infinite duration use 0"))
+	}
+
+	if !ecarc.PreAllocatedVUs.Valid {
+		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs isn't specified"))
+	} else if ecarc.PreAllocatedVUs.Int64 < 0 {
+		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs can't be negative"))
+	}
+
+	if !ecarc.MaxVUs.Valid {
+		// TODO: don't change the config while validating
+		ecarc.MaxVUs.Int64 = ecarc.PreAllocatedVUs.Int64
+	} else if ecarc.MaxVUs.Int64 < ecarc.PreAllocatedVUs.Int64 {
+		errors = append(errors, fmt.Errorf("maxVUs can't be less than preAllocatedVUs"))
+	}
+
+	return errors
+}
+
+// GetExecutionRequirements returns the number of required VUs to run the
+// executor for its whole duration, including the maximum waiting time for any
+// iterations to gracefully stop. Like the externally controlled executor, it
+// doesn't emit the last step to relinquish the VUs if the duration is infinite.
+func (ecarc ExternallyControlledArrivalRateConfig) GetExecutionRequirements(
+	et *lib.ExecutionTuple,
+) []lib.ExecutionStep {
+	startVUs := lib.ExecutionStep{
+		TimeOffset:      0,
+		PlannedVUs:      uint64(ecarc.GetPreAllocatedVUs(et)),
+		MaxUnplannedVUs: uint64(ecarc.GetMaxVUs(et) - ecarc.GetPreAllocatedVUs(et)),
+	}
+
+	duration := ecarc.Duration.TimeDuration()
+	if duration == 0 {
+		// Infinite duration, don't emit 0 VUs at the end since there's no planned end
+		return []lib.ExecutionStep{startVUs}
+	}
+	return []lib.ExecutionStep{startVUs, {
+		TimeOffset:      duration + ecarc.GracefulStop.TimeDuration(),
+		PlannedVUs:      0,
+		MaxUnplannedVUs: 0,
+	}}
+}
+
+// IsDistributable returns false, since the rate is changed through the REST
+// API of a single k6 instance.
+func (ExternallyControlledArrivalRateConfig) IsDistributable() bool {
+	return false
+}
+
+// NewExecutor creates a new ExternallyControlledArrivalRate executor
+func (ecarc ExternallyControlledArrivalRateConfig) NewExecutor(
+	es *lib.ExecutionState, logger *logrus.Entry,
+) (lib.Executor, error) {
+	return &ExternallyControlledArrivalRate{
+		BaseExecutor: NewBaseExecutor(&ecarc, es, logger),
+		config:       ecarc,
+		rate:         ecarc.Rate.Int64,
+		changes:      make(chan struct{}, 1),
+	}, nil
+}
+
+// HasWork reports whether there is any work to be done for the given execution segment.
+func (ecarc ExternallyControlledArrivalRateConfig) HasWork(et *lib.ExecutionTuple) bool {
+	return ecarc.GetMaxVUs(et) > 0
+}
+
+// ExternallyControlledArrivalRate starts iterations at a rate which can be
+// changed through the REST API while it's running. It can also be paused and
+// resumed on its own, without pausing the rest of the test. It implements both
+// the lib.PausableExecutor and the lib.LiveUpdatableExecutor interfaces.
+type ExternallyControlledArrivalRate struct {
+	*BaseExecutor
+	config ExternallyControlledArrivalRateConfig
+
+	// the current rate and paused state, the Run loop is notified about their
+	// changes through the changes channel
+	lock    sync.Mutex
+	rate    int64
+	paused  bool
+	changes chan struct{}
+}
+
+// Make sure we implement all the interfaces
+var (
+	_ lib.Executor              = &ExternallyControlledArrivalRate{}
+	_ lib.PausableExecutor      = &ExternallyControlledArrivalRate{}
+	_ lib.LiveUpdatableExecutor = &ExternallyControlledArrivalRate{}
+)
+
+// GetCurrentConfig returns the executor's configuration with the current rate.
+func (ecar *ExternallyControlledArrivalRate) GetCurrentConfig() ExternallyControlledArrivalRateConfig {
+	ecar.lock.Lock()
+	defer ecar.lock.Unlock()
+	config := ecar.config
+	config.Rate = null.IntFrom(ecar.rate)
+	return config
+}
+
+// IsPaused returns whether the executor is currently paused.
+func (ecar *ExternallyControlledArrivalRate) IsPaused() bool {
+	ecar.lock.Lock()
+	defer ecar.lock.Unlock()
+	return ecar.paused
+}
+
+// SetPaused pauses or resumes the executor. Unlike the externally controlled
+// executor, it can be paused before it has started, then it starts paused.
+func (ecar *ExternallyControlledArrivalRate) SetPaused(paused bool) error {
+	ecar.lock.Lock()
+	ecar.paused = paused
+	ecar.lock.Unlock()
+	ecar.notifyChange()
+	return nil
+}
+
+// UpdateConfig validates the supplied config and changes the rate in real time.
+func (ecar *ExternallyControlledArrivalRate) UpdateConfig(_ context.Context, newConf interface{}) error {
+	newConfigParams, ok := newConf.(ExternallyControlledArrivalRateConfigParams)
+	if !ok {
+		return errors.New("invalid config type")
+	}
+	if errs := newConfigParams.Validate(); len(errs) != 0 {
+		return fmt.Errorf("invalid configuration supplied: %s", lib.ConcatErrors(errs, ", "))
+	}
+
+	ecar.lock.Lock()
+	ecar.rate = newConfigParams.Rate.Int64
+	ecar.lock.Unlock()
+	ecar.notifyChange()
+	return nil
+}
+
+func (ecar *ExternallyControlledArrivalRate) notifyChange() {
+	select {
+	case ecar.changes <- struct{}{}:
+	default: // the Run loop is already notified
+	}
+}
+
+// getTickerPeriod returns the time between the iterations at the current
+// rate, scaled for the execution segment, and whether the executor is paused.
+// The period is 0 if there shouldn't be any iterations.
+func (ecar *ExternallyControlledArrivalRate) getTickerPeriod() (time.Duration, bool) {
+	ecar.lock.Lock()
+	defer ecar.lock.Unlock()
+	arrivalRate := getScaledArrivalRate(
+		ecar.executionState.ExecutionTuple.Segment, ecar.rate, ecar.config.TimeUnit.TimeDuration())
+	return getTickerPeriod(arrivalRate).TimeDuration(), ecar.paused
+}
+
+// Run starts iterations at the current rate until the duration is reached, or
+// until the test is manually stopped if the duration is infinite.
+//
+//nolint:funlen,gocognit
+func (ecar *ExternallyControlledArrivalRate) Run(parentCtx context.Context, out chan<- metrics.SampleContainer) error {
+	gracefulStop := ecar.config.GetGracefulStop()
+	duration := ecar.config.Duration.TimeDuration()
+	preAllocatedVUs := ecar.config.GetPreAllocatedVUs(ecar.executionState.ExecutionTuple)
+	maxVUs := ecar.config.GetMaxVUs(ecar.executionState.ExecutionTuple)
+
+	ecar.logger.WithFields(logrus.Fields{
+		"maxVUs": maxVUs, "preAllocatedVUs": preAllocatedVUs, "duration": duration,
+		"type": ecar.config.GetType(),
+	}).Debug("Starting executor run...")
+
+	activeVUsWg := &sync.WaitGroup{}
+
+	returnedVUs := make(chan struct{})
+	waitOnProgressChannel := make(chan struct{})
+	var (
+		startTime                      time.Time
+		maxDurationCtx, regDurationCtx context.Context
+		cancel                         func()
+	)
+	if duration > 0 {
+		startTime, maxDurationCtx, regDurationCtx, cancel = getDurationContexts(parentCtx, duration, gracefulStop)
+	} else {
+		// Infinite duration, so the executor runs until the test is stopped
+		startTime = time.Now()
+		maxDurationCtx, cancel = context.WithCancel(parentCtx)
+		regDurationCtx = maxDurationCtx
+	}
+	defer func() {
+		cancel()
+		<-waitOnProgressChannel
+	}()
+
+	vusPool := newActiveVUPool(ecar.executionState)
+	defer func() {
+		// Make sure all VUs aren't executing iterations anymore, for the cancel()
+		// below to deactivate them.
+		<-returnedVUs
+		// first close the vusPool so we wait for the gracefulShutdown
+		vusPool.Close()
+		cancel()
+		activeVUsWg.Wait()
+	}()
+	activeVUsCount := uint64(0)
+
+	vusFmt := pb.GetFixedLengthIntFormat(maxVUs)
+	progressFn := func() (float64, []string) {
+		spent := time.Since(startTime)
+		currActiveVUs := atomic.LoadUint64(&activeVUsCount)
+		progVUs := fmt.Sprintf(vusFmt+"/"+vusFmt+" VUs", vusPool.Running(), currActiveVUs)
+
+		period, paused := ecar.getTickerPeriod()
+		progIters := "paused"
+		if !paused {
+			var ratePerSec float64
+			if period > 0 {
+				ratePerSec = float64(time.Second) / float64(period)
+			}
+			progIters = fmt.Sprintf("%.2f iters/s", ratePerSec)
+		}
+
+		if duration == 0 {
+			return 0, []string{progVUs, pb.GetFixedLengthDuration(spent, 0), progIters}
+		}
+		right := []string{progVUs, duration.String(), progIters}
+		if spent > duration {
+			return 1, right
+		}
+		right[1] = fmt.Sprintf("%s/%s", pb.GetFixedLengthDuration(spent, duration), duration)
+		return math.Min(1, float64(spent)/float64(duration)), right
+	}
+	ecar.progress.Modify(pb.WithProgress(progressFn))
+	maxDurationCtx = lib.WithScenarioState(maxDurationCtx, &lib.ScenarioState{
+		Name:       ecar.config.Name,
+		Executor:   ecar.config.Type,
+		StartTime:  startTime,
+		ProgressFn: progressFn,
+	})
+
+	go func() {
+		trackProgress(parentCtx, maxDurationCtx, regDurationCtx, ecar, progressFn)
+		close(waitOnProgressChannel)
+	}()
+
+	returnVU := func(u lib.InitializedVU) {
+		ecar.executionState.ReturnVU(u, false)
+		activeVUsWg.Done()
+	}
+
+	runIterationBasic := getIterationRunner(ecar.executionState, ecar.logger)
+	activateVU := func(initVU lib.InitializedVU) lib.ActiveVU {
+		activeVUsWg.Add(1)
+		activeVU := initVU.Activate(getVUActivationParams(
+			maxDurationCtx, ecar.config.BaseConfig, returnVU,
+			ecar.nextIterationCounters,
+		))
+		atomic.AddUint64(&activeVUsCount, 1)
+		vusPool.AddVU(maxDurationCtx, activeVU, runIterationBasic)
+		return activeVU
+	}
+
+	makeUnplannedVUCh := make(chan struct{})
+	defer close(makeUnplannedVUCh)
+	go func() {
+		defer close(returnedVUs)
+		for range makeUnplannedVUCh {
+			ecar.logger.Debug("Starting initialization of an unplanned VU...")
+			initVU, err := ecar.executionState.GetUnplannedVU(maxDurationCtx, ecar.logger)
+			if err != nil {
+				ecar.logger.WithError(err).Error("Error while allocating unplanned VU")
+			} else {
+				ecar.logger.Debug("The unplanned VU finished initializing successfully!")
+				activateVU(initVU)
+			}
+		}
+	}()
+
+	// Get the pre-allocated VUs in the local buffer
+	for i := int64(0); i < preAllocatedVUs; i++ {
+		initVU, err := ecar.executionState.GetPlannedVU(ecar.logger, false)
+		if err != nil {
+			return err
+		}
+		activateVU(initVU)
+	}
+
+	iterations := &iterationStarter{
+		vusPool:               vusPool,
+		out:                   out,
+		droppedIterations:     ecar.executionState.Test.BuiltinMetrics.DroppedIterations,
+		metricTags:            ecar.getMetricTags(nil),
+		logger:                ecar.logger,
+		maxVUs:                maxVUs,
+		remainingUnplannedVUs: maxVUs - preAllocatedVUs,
+		makeUnplannedVUCh:     makeUnplannedVUCh,
+	}
+	timer := time.NewTimer(time.Hour * 24)
+	defer timer.Stop()
+
+	// The next iteration starts one period after the previous one, at the
+	// current rate, so a change of the rate is applied right away. The first
+	// iteration, and the first one after a resume, start immediately.
+	var lastIteration time.Time
+	for {
+		period, paused := ecar.getTickerPeriod()
+		var (
+			next    time.Time
+			timerCh <-chan time.Time
+		)
+		if !paused && period > 0 {
+			next = time.Now()
+			if !lastIteration.IsZero() {
+				next = lastIteration.Add(period)
+			}
+			timer.Reset(time.Until(next))
+			timerCh = timer.C
+		}
+
+		select {
+		case <-timerCh:
+			lastIteration = next
+			iterations.start(parentCtx)
+
+		case <-ecar.changes:
+			if timerCh != nil && !timer.Stop() {
+				<-timer.C
+			}
+			// don't try to catch up with a higher rate, or after a pause
+			newPeriod, nowPaused := ecar.getTickerPeriod()
+			if now := time.Now(); nowPaused {
+				lastIteration = time.Time{}
+			} else if !lastIteration.IsZero() && lastIteration.Add(newPeriod).Before(now) {
+				lastIteration = now.Add(-newPeriod)
+			}
+
+		case <-regDurationCtx.Done():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package executor
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+func getTestExternallyControlledArrivalRateConfig() *ExternallyControlledArrivalRateConfig {
+	config := NewExternallyControlledArrivalRateConfig("test")
+	config.Rate = null.IntFrom(20)
+	config.Duration = types.NullDurationFrom(2 * time.Second)
+	config.PreAllocatedVUs = null.IntFrom(5)
+	config.MaxVUs = null.IntFrom(10)
+	return config
+}
+
+func TestExternallyControlledArrivalRateConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	config := getTestExternallyControlledArrivalRateConfig()
+	require.Empty(t, config.Validate())
+
+	config.Rate = null.IntFrom(0)
+	config.Duration = types.NullDurationFrom(0)
+	require.Empty(t, config.Validate())
+
+	config.Rate = null.IntFrom(-1)
+	config.Duration = types.NewNullDuration(0, false)
+	config.MaxVUs = null.IntFrom(2)
+	errs := config.Validate()
+	require.Len(t, errs, 3)
+	assert.Contains(t, errs[0].Error(), "the iteration rate can't be negative")
+	assert.Contains(t, errs[1].Error(), "the duration must be specified, for infinite duration use 0")
+	assert.Contains(t, errs[2].Error(), "maxVUs can't be less than preAllocatedVUs")
+}
+
+func TestExternallyControlledArrivalRateConfigRequirements(t *testing.T) {
+	t.Parallel()
+
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+
+	config := getTestExternallyControlledArrivalRateConfig()
+	assert.Equal(t, []lib.ExecutionStep{
+		{TimeOffset: 0, PlannedVUs: 5, MaxUnplannedVUs: 5},
+		{TimeOffset: 32 * time.Second, PlannedVUs: 0, MaxUnplannedVUs: 0},
+	}, config.GetExecutionRequirements(et))
+	assert.Equal(t,
+		"Externally controlled 20.00 iterations/s at the start, 2s duration (maxVUs: 5-10, gracefulStop: 30s)",
+		config.GetDescription(et))
+
+	// there's no end with an infinite duration
+	config.Duration = types.NullDurationFrom(0)
+	assert.Equal(t, []lib.ExecutionStep{
+		{TimeOffset: 0, PlannedVUs: 5, MaxUnplannedVUs: 5},
+	}, config.GetExecutionRequirements(et))
+}
+
+func TestExternallyControlledArrivalRateRun(t *testing.T) {
+	t.Parallel()
+
+	var count int64
+	runner := simpleRunner(func(_ context.Context, _ *lib.State) error {
+		atomic.AddInt64(&count, 1)
+		return nil
+	})
+
+	test := setupExecutorTest(t, "", "", lib.Options{}, runner, getTestExternallyControlledArrivalRateConfig())
+	defer test.cancel()
+	ecar, ok := test.executor.(*ExternallyControlledArrivalRate)
+	require.True(t, ok)
+
+	errCh := make(chan error, 1)
+	engineOut := make(chan metrics.SampleContainer, 1000)
+	go func() { errCh <- test.executor.Run(test.ctx, engineOut) }()
+
+	// 20 iterations/s for 0.5s, paused for 0.5s, then 40 iterations/s for 1s
+	time.Sleep(500 * time.Millisecond)
+	require.NoError(t, ecar.SetPaused(true))
+	assert.True(t, ecar.IsPaused())
+	time.Sleep(50 * time.Millisecond) // for any already started iteration
+	paused := atomic.LoadInt64(&count)
+	assert.InDelta(t, 10, paused, 2)
+
+	time.Sleep(450 * time.Millisecond)
+	assert.Equal(t, paused, atomic.LoadInt64(&count))
+	require.NoError(t, ecar.UpdateConfig(test.ctx, ExternallyControlledArrivalRateConfigParams{Rate: null.IntFrom(40)}))
+	assert.Equal(t, null.IntFrom(40), ecar.GetCurrentConfig().Rate)
+	require.NoError(t, ecar.SetPaused(false))
+
+	require.NoError(t, <-errCh)
+	assert.InDelta(t, 50, atomic.LoadInt64(&count), 3)
+
+	err := ecar.UpdateConfig(test.ctx, ExternallyControlledArrivalRateConfigParams{Rate: null.IntFrom(-1)})
+	assert.EqualError(t, err, "invalid configuration supplied: the iteration rate can't be negative")
+}
@@ -38,6 +38,10 @@ type RampingArrivalRateConfig struct {
 	TimeUnit  types.NullDuration `json:"timeUnit"`
 	Stages    []Stage            `json:"stages"`
 
+	// ArrivalDistribution is the distribution of the time between the
+	// iterations, they're started at even intervals if it's nil
+	ArrivalDistribution *ArrivalDistribution `json:"arrivalDistribution,omitempty"`
+
 	// Initialize `PreAllocatedVUs` number of VUs, and if more than that are needed,
 	// they will be dynamically allocated, until `MaxVUs` is reached, which is an
 	// absolutely hard limit on the number of VUs the executor will use
@@ -97,6 +101,10 @@ func (varc *RampingArrivalRateConfig) Validate() []error {
 
 	errors = append(errors, validateStages(varc.Stages)...)
 
+	if varc.ArrivalDistribution != nil {
+		errors = append(errors, varc.ArrivalDistribution.Validate()...)
+	}
+
 	if !varc.PreAllocatedVUs.Valid {
 		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs isn't specified"))
 	} else if varc.PreAllocatedVUs.Int64 < 0 {
@@ -171,7 +179,31 @@ func (varr *RampingArrivalRate) Init(_ context.Context) error {
 	return err //nolint:wrapcheck
 }
 
-// cal calculates the  transtitions between stages and gives the next full value produced by the
+// cal sends the start times of the iterations of the execution segment to ch,
+// and closes it after the last stage.
+func (varc RampingArrivalRateConfig) cal(et *lib.ExecutionTuple, ch chan<- time.Duration) {
+	defer close(ch) // TODO: maybe this is not a good design - closing a channel we get
+	timeUnit := float64(varc.TimeUnit.Duration)
+	stages := make([]rateStage, len(varc.Stages))
+	for i, stage := range varc.Stages {
+		stages[i] = rateStage{
+			duration: stage.Duration.TimeDuration(),
+			target:   float64(stage.Target.ValueOrZero()) / timeUnit,
+		}
+	}
+	rampArrivals(et, varc.ArrivalDistribution.newSequence(varc.Name),
+		float64(varc.StartRate.ValueOrZero())/timeUnit, stages,
+		func(_ int, _ int64, offset time.Duration) { ch <- offset })
+}
+
+// rateStage is a stage of an arrival rate changing linearly to the target,
+// which is in events per nanosecond.
+type rateStage struct {
+	duration time.Duration
+	target   float64
+}
+
+// rampArrivals calculates the  transtitions between stages and gives the next full value produced by the
 // stages. In this explanation we are talking about events and in practice those events are starting
 // of an iteration, but could really be anything that needs to occur at a constant or linear rate.
 //
@@ -231,7 +263,16 @@ func (varr *RampingArrivalRate) Init(_ context.Context) error {
 // The specific implementation here can only go forward and does incorporate
 // the striping algorithm from the lib.ExecutionTuple for additional speed up but this could
 // possibly be refactored if need for this arises.
-func (varc RampingArrivalRateConfig) cal(et *lib.ExecutionTuple, ch chan<- time.Duration) {
+//
+// With an arrival distribution, the area of event n is moved to where the
+// distribution puts it, instead of n, and the same formulas give its time.
+//
+// emit is called with the index of the stage, the global index of the event and
+// its time, for every event of the execution segment.
+func rampArrivals(
+	et *lib.ExecutionTuple, arrivals *arrivalSequence, startRate float64, stages []rateStage,
+	emit func(stage int, gi int64, offset time.Duration),
+) {
 	start, offsets, _ := et.GetStripedOffsets()
 	li := -1
 	// TODO: move this to a utility function, or directly what GetStripedOffsets uses once we see everywhere we will use it
@@ -239,37 +280,41 @@ func (varc RampingArrivalRateConfig) cal(et *lib.ExecutionTuple, ch chan<- time.
 		li++
 		return offsets[li%len(offsets)]
 	}
-	defer close(ch) // TODO: maybe this is not a good design - closing a channel we get
+	area := func(i float64) float64 {
+		if arrivals == nil {
+			return i
+		}
+		return arrivals.at(int64(i)-1) + 1
+	}
 	var (
 		stageStart                   time.Duration
-		timeUnit                     = float64(varc.TimeUnit.Duration)
 		doneSoFar, endCount, to, dur float64
-		from                         = float64(varc.StartRate.ValueOrZero()) / timeUnit
+		from                         = startRate
 		// start .. starts at 0 but the algorithm works with area so we need to start from 1 not 0
 		i = float64(start + 1)
 	)
 
-	for _, stage := range varc.Stages {
-		to = float64(stage.Target.ValueOrZero()) / timeUnit
-		dur = float64(stage.Duration.Duration)
+	for si, stage := range stages {
+		to = stage.target
+		dur = float64(stage.duration)
 		if from != to { // ramp up/down
 			endCount += dur * ((to-from)/2 + from)
-			for ; i <= endCount; i += float64(next()) {
+			for ; area(i) <= endCount; i += float64(next()) {
 				// TODO: try to twist this in a way to be able to get i (the only changing part)
 				// somewhere where it is less in the middle of the equation
-				x := (from*dur - noNegativeSqrt(dur*(from*from*dur+2*(i-doneSoFar)*(to-from)))) / (from - to)
+				x := (from*dur - noNegativeSqrt(dur*(from*from*dur+2*(area(i)-doneSoFar)*(to-from)))) / (from - to)
 
-				ch <- time.Duration(x) + stageStart
+				emit(si, int64(i)-1, time.Duration(x)+stageStart)
 			}
 		} else {
 			endCount += dur * to
-			for ; i <= endCount; i += float64(next()) {
-				ch <- time.Duration((i-doneSoFar)/to) + stageStart
+			for ; area(i) <= endCount; i += float64(next()) {
+				emit(si, int64(i)-1, time.Duration((area(i)-doneSoFar)/to)+stageStart)
 			}
 		}
 		doneSoFar = endCount
 		from = to
-		stageStart += stage.Duration.TimeDuration()
+		stageStart += stage.duration
 	}
 }
 
@@ -407,7 +452,6 @@ func (varr RampingArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 		return activeVU
 	}
 
-	remainingUnplannedVUs := maxVUs - preAllocatedVUs
 	makeUnplannedVUCh := make(chan struct{})
 	defer close(makeUnplannedVUCh)
 	go func() {
@@ -440,8 +484,16 @@ func (varr RampingArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 	start := time.Now()
 	ch := make(chan time.Duration, 10) // buffer 10 iteration times ahead
 	var prevTime time.Duration
-	shownWarning := false
-	metricTags := varr.getMetricTags(nil)
+	iterations := &iterationStarter{
+		vusPool:               vusPool,
+		out:                   out,
+		droppedIterations:     varr.executionState.Test.BuiltinMetrics.DroppedIterations,
+		metricTags:            varr.getMetricTags(nil),
+		logger:                varr.logger,
+		maxVUs:                maxVUs,
+		remainingUnplannedVUs: maxVUs - preAllocatedVUs,
+		makeUnplannedVUCh:     makeUnplannedVUCh,
+	}
 	go varr.config.cal(varr.et, ch)
 	for nextTime := range ch {
 		select {
@@ -461,38 +513,60 @@ func (varr RampingArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 			}
 		}
 
-		if vusPool.TryRunIteration() {
-			continue
-		}
+		iterations.start(parentCtx)
+	}
+	return nil
+}
 
-		// Since there aren't any free VUs available, consider this iteration
-		// dropped - we aren't going to try to recover it, but
-		metrics.PushIfNotDone(parentCtx, out, metrics.Sample{
-			TimeSeries: metrics.TimeSeries{
-				Metric: varr.executionState.Test.BuiltinMetrics.DroppedIterations,
-				Tags:   metricTags,
-			},
-			Time:  time.Now(),
-			Value: 1,
-		})
-
-		// We'll try to start allocating another VU in the background,
-		// non-blockingly, if we have remainingUnplannedVUs...
-		if remainingUnplannedVUs == 0 {
-			if !shownWarning {
-				varr.logger.Warningf("Insufficient VUs, reached %d active VUs and cannot initialize more", maxVUs)
-				shownWarning = true
-			}
-			continue
-		}
+// iterationStarter starts the iterations of the arrival-rate executors on the
+// free VUs of their pool.
+type iterationStarter struct {
+	vusPool           *activeVUPool
+	out               chan<- metrics.SampleContainer
+	droppedIterations *metrics.Metric
+	metricTags        *metrics.TagSet
+	logger            *logrus.Entry
+
+	maxVUs                int64
+	remainingUnplannedVUs int64
+	makeUnplannedVUCh     chan<- struct{}
+	shownWarning          bool
+}
 
-		select {
-		case makeUnplannedVUCh <- struct{}{}: // great!
-			remainingUnplannedVUs--
-		default: // we're already allocating a new VU
+// start runs an iteration on a free VU. If there isn't one, the iteration is
+// dropped and an unplanned VU is initialized in the background, if there are
+// any left.
+func (is *iterationStarter) start(ctx context.Context) {
+	if is.vusPool.TryRunIteration() {
+		return
+	}
+
+	// Since there aren't any free VUs available, consider this iteration
+	// dropped - we aren't going to try to recover it, but we'll count it
+	metrics.PushIfNotDone(ctx, is.out, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: is.droppedIterations,
+			Tags:   is.metricTags,
+		},
+		Time:  time.Now(),
+		Value: 1,
+	})
+
+	// We'll try to start allocating another VU in the background,
+	// non-blockingly, if we have remainingUnplannedVUs...
+	if is.remainingUnplannedVUs == 0 {
+		if !is.shownWarning {
+			is.logger.Warningf("Insufficient VUs, reached %d active VUs and cannot initialize more", is.maxVUs)
+			is.shownWarning = true
 		}
+		return
+	}
+
+	select {
+	case is.makeUnplannedVUCh <- struct{}{}: // great!
+		is.remainingUnplannedVUs--
+	default: // we're already allocating a new VU
 	}
-	return nil
 }
 
 // activeVUPool controls the activeVUs
@@ -0,0 +1,502 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/ui/pb"
+)
+
+const replayArrivalRateType = "replay-arrival-rate"
+
+func init() {
+	lib.RegisterExecutorConfigType(
+		replayArrivalRateType,
+		func(name string, rawJSON []byte) (lib.ExecutorConfig, error) {
+			config := NewReplayArrivalRateConfig(name)
+			err := lib.StrictJSONUnmarshal(rawJSON, &config)
+			return config, err
+		},
+	)
+}
+
+// ReplayArrivalRateConfig stores config for the replay-arrival-rate executor,
+// which replays a recorded traffic shape, e.g. the rates of the requests to
+// a production system during a day.
+type ReplayArrivalRateConfig struct {
+	BaseConfig
+	// File is the path of the CSV or JSON file with the traffic shape,
+	// relative to the directory of the script
+	File     null.String        `json:"file"`
+	TimeUnit types.NullDuration `json:"timeUnit"`
+	// TimeCompression is how many times faster than it was recorded the
+	// traffic shape is replayed. The rates themselves aren't changed.
+	TimeCompression null.Float `json:"timeCompression"`
+
+	// ArrivalDistribution is the distribution of the time between the
+	// iterations, they're started at even intervals if it's nil
+	ArrivalDistribution *ArrivalDistribution `json:"arrivalDistribution,omitempty"`
+
+	// Initialize `PreAllocatedVUs` number of VUs, and if more than that are needed,
+	// they will be dynamically allocated, until `MaxVUs` is reached, which is an
+	// absolutely hard limit on the number of VUs the executor will use
+	PreAllocatedVUs null.Int `json:"preAllocatedVUs"`
+	MaxVUs          null.Int `json:"maxVUs"`
+
+	// shape is loaded from the file by LoadFiles
+	shape *trafficShape
+}
+
+// NewReplayArrivalRateConfig returns a ReplayArrivalRateConfig with default values
+func NewReplayArrivalRateConfig(name string) *ReplayArrivalRateConfig {
+	return &ReplayArrivalRateConfig{
+		BaseConfig:      NewBaseConfig(name, replayArrivalRateType),
+		TimeUnit:        types.NewNullDuration(1*time.Second, false),
+		TimeCompression: null.NewFloat(1, false),
+	}
+}
+
+// Make sure we implement the lib.ExecutorConfigWithFiles interface
+var _ lib.ExecutorConfigWithFiles = &ReplayArrivalRateConfig{}
+
+// LoadFiles reads the traffic shape from its file.
+func (rarc *ReplayArrivalRateConfig) LoadFiles(readFile func(path string) ([]byte, error)) error {
+	if rarc.File.String == "" {
+		return nil // Validate() will complain
+	}
+	data, err := readFile(rarc.File.String)
+	if err != nil {
+		return err
+	}
+	rarc.shape, err = parseTrafficShape(rarc.File.String, data)
+	if err != nil {
+		return fmt.Errorf("invalid traffic shape in %q: %w", rarc.File.String, err)
+	}
+	return nil
+}
+
+// GetPreAllocatedVUs is just a helper method that returns the scaled pre-allocated VUs.
+func (rarc ReplayArrivalRateConfig) GetPreAllocatedVUs(et *lib.ExecutionTuple) int64 {
+	return et.ScaleInt64(rarc.PreAllocatedVUs.Int64)
+}
+
+// GetMaxVUs is just a helper method that returns the scaled max VUs.
+func (rarc ReplayArrivalRateConfig) GetMaxVUs(et *lib.ExecutionTuple) int64 {
+	return et.ScaleInt64(rarc.MaxVUs.Int64)
+}
+
+// GetExecs returns the functions which the executor runs, i.e. the weighted
+// functions of the traffic shape, or just the exec one if it isn't weighted.
+func (rarc ReplayArrivalRateConfig) GetExecs() []string {
+	if rarc.shape == nil || len(rarc.shape.execs) == 0 {
+		return []string{rarc.GetExec()}
+	}
+	return rarc.shape.execs
+}
+
+// getDuration returns how long the replay of the traffic shape is.
+func (rarc ReplayArrivalRateConfig) getDuration() time.Duration {
+	if rarc.shape == nil {
+		return 0
+	}
+	return rarc.shape.duration(rarc.TimeCompression.Float64)
+}
+
+// getMaxArrivalRatePerSec returns the highest scaled rate of the traffic shape
+// in iterations per second.
+func (rarc ReplayArrivalRateConfig) getMaxArrivalRatePerSec(segment *lib.ExecutionSegment) float64 {
+	if rarc.shape == nil {
+		return 0
+	}
+	return rarc.shape.maxRate() * segment.FloatLength() * float64(time.Second) / float64(rarc.TimeUnit.Duration)
+}
+
+// GetDescription returns a human-readable description of the executor options
+func (rarc ReplayArrivalRateConfig) GetDescription(et *lib.ExecutionTuple) string {
+	maxVUsRange := fmt.Sprintf("maxVUs: %d", et.ScaleInt64(rarc.PreAllocatedVUs.Int64))
+	if rarc.MaxVUs.Int64 > rarc.PreAllocatedVUs.Int64 {
+		maxVUsRange += fmt.Sprintf("-%d", et.ScaleInt64(rarc.MaxVUs.Int64))
+	}
+
+	return fmt.Sprintf("Up to %.2f iterations/s for %s replayed from %s%s",
+		rarc.getMaxArrivalRatePerSec(et.Segment), rarc.getDuration(),
+		rarc.File.String, rarc.getBaseInfo(maxVUsRange))
+}
+
+// Validate makes sure all options are configured and valid
+func (rarc *ReplayArrivalRateConfig) Validate() []error {
+	errors := rarc.BaseConfig.Validate()
+
+	if rarc.File.String == "" {
+		errors = append(errors, fmt.Errorf("the file with the traffic shape isn't specified"))
+	} else if rarc.shape == nil {
+		errors = append(errors, fmt.Errorf("the traffic shape wasn't loaded from %q", rarc.File.String))
+	} else if len(rarc.shape.execs) > 0 && rarc.Exec.Valid {
+		errors = append(errors, fmt.Errorf("the exec option can't be used with a weighted traffic shape"))
+	}
+
+	if rarc.TimeUnit.TimeDuration() <= 0 {
+		errors = append(errors, fmt.Errorf("the timeUnit must be more than 0"))
+	}
+
+	if rarc.TimeCompression.Float64 <= 0 {
+		errors = append(errors, fmt.Errorf("the timeCompression must be more than 0"))
+	}
+
+	if rarc.ArrivalDistribution != nil {
+		errors = append(errors, rarc.ArrivalDistribution.Validate()...)
+	}
+
+	if !rarc.PreAllocatedVUs.Valid {
+		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs isn't specified"))
+	} else if rarc.PreAllocatedVUs.Int64 < 0 {
+		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs can't be negative"))
+	}
+
+	if !rarc.MaxVUs.Valid {
+		// TODO: don't change the config while validating
+		rarc.MaxVUs.Int64 = rarc.PreAllocatedVUs.Int64
+	} else if rarc.MaxVUs.Int64 < rarc.PreAllocatedVUs.Int64 {
+		errors = append(errors, fmt.Errorf("maxVUs can't be less than preAllocatedVUs"))
+	}
+
+	return errors
+}
+
+// GetExecutionRequirements returns the number of required VUs to run the
+// executor for its whole duration (disregarding any startTime), including the
+// maximum waiting time for any iterations to gracefully stop. This is used by
+// the execution scheduler in its VU reservation calculations, so it knows how
+// many VUs to pre-initialize.
+func (rarc ReplayArrivalRateConfig) GetExecutionRequirements(et *lib.ExecutionTuple) []lib.ExecutionStep {
+	return []lib.ExecutionStep{
+		{
+			TimeOffset:      0,
+			PlannedVUs:      uint64(et.ScaleInt64(rarc.PreAllocatedVUs.Int64)),
+			MaxUnplannedVUs: uint64(et.ScaleInt64(rarc.MaxVUs.Int64) - et.ScaleInt64(rarc.PreAllocatedVUs.Int64)),
+		},
+		{
+			TimeOffset:      rarc.getDuration() + rarc.GracefulStop.TimeDuration(),
+			PlannedVUs:      0,
+			MaxUnplannedVUs: 0,
+		},
+	}
+}
+
+// NewExecutor creates a new ReplayArrivalRate executor
+func (rarc ReplayArrivalRateConfig) NewExecutor(
+	es *lib.ExecutionState, logger *logrus.Entry,
+) (lib.Executor, error) {
+	return &ReplayArrivalRate{
+		BaseExecutor: NewBaseExecutor(&rarc, es, logger),
+		config:       rarc,
+	}, nil
+}
+
+// HasWork reports whether there is any work to be done for the given execution segment.
+func (rarc ReplayArrivalRateConfig) HasWork(et *lib.ExecutionTuple) bool {
+	return rarc.GetMaxVUs(et) > 0
+}
+
+// replayArrival is the start time of an iteration and the index of the
+// function it runs.
+type replayArrival struct {
+	offset time.Duration
+	exec   int
+}
+
+// cal sends the start times of the iterations of the execution segment to ch,
+// and closes it at the end of the traffic shape.
+func (rarc ReplayArrivalRateConfig) cal(et *lib.ExecutionTuple, ch chan<- replayArrival) {
+	defer close(ch)
+	startRate, stages := rarc.shape.stages(rarc.TimeUnit.TimeDuration(), rarc.TimeCompression.Float64)
+	weighted := len(rarc.shape.execs) > 0
+	rampArrivals(et, rarc.ArrivalDistribution.newSequence(rarc.Name), startRate, stages,
+		func(stage int, gi int64, offset time.Duration) {
+			arrival := replayArrival{offset: offset}
+			if weighted {
+				arrival.exec = rarc.shape.pickExec(stage, gi)
+			}
+			ch <- arrival
+		})
+}
+
+// getPreAllocatedVUsPerExec splits the pre-allocated VUs between the
+// functions, by their share of the iterations. If there are enough of them,
+// every function with any iterations gets at least one VU, so it isn't left
+// without VUs when the rest of the functions have reached maxVUs.
+func (rarc ReplayArrivalRateConfig) getPreAllocatedVUsPerExec(preAllocatedVUs int64) []int64 {
+	shares := rarc.shape.execShares()
+	if shares == nil {
+		return []int64{preAllocatedVUs}
+	}
+
+	result := make([]int64, len(shares))
+	var withIterations int64
+	for _, share := range shares {
+		if share > 0 {
+			withIterations++
+		}
+	}
+	left := preAllocatedVUs
+	if preAllocatedVUs >= withIterations {
+		for i, share := range shares {
+			if share > 0 {
+				result[i] = 1
+			}
+		}
+		left -= withIterations
+	}
+
+	// the rest of them are split by the largest remainder method
+	remainders := make([]float64, len(shares))
+	indexes := make([]int, len(shares))
+	split := left
+	for i, share := range shares {
+		exact := share * float64(split)
+		result[i] += int64(exact)
+		left -= int64(exact)
+		remainders[i] = exact - math.Floor(exact)
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(a, b int) bool { return remainders[indexes[a]] > remainders[indexes[b]] })
+	for i := int64(0); i < left; i++ {
+		result[indexes[i]]++
+	}
+	return result
+}
+
+// ReplayArrivalRate starts the iterations with the rates of a recorded
+// traffic shape.
+type ReplayArrivalRate struct {
+	*BaseExecutor
+	config ReplayArrivalRateConfig
+	et     *lib.ExecutionTuple
+}
+
+// Make sure we implement the lib.Executor interface.
+var _ lib.Executor = &ReplayArrivalRate{}
+
+// Init values needed for the execution
+func (rar *ReplayArrivalRate) Init(_ context.Context) error {
+	// err should always be nil, because Init() won't be called for executors
+	// with no work, as determined by their config's HasWork() method.
+	et, err := rar.BaseExecutor.executionState.ExecutionTuple.GetNewExecutionTupleFromValue(rar.config.MaxVUs.Int64)
+	rar.et = et
+	rar.iterSegIndex = lib.NewSegmentedIndex(et)
+
+	return err //nolint:wrapcheck
+}
+
+// Run executes the iterations of the traffic shape. It works like the Run
+// of the ramping-arrival-rate executor, except that there's a pool of VUs for
+// every function of a weighted traffic shape, since a VU runs the same
+// function while it's active.
+//
+//nolint:funlen
+func (rar ReplayArrivalRate) Run(parentCtx context.Context, out chan<- metrics.SampleContainer) (err error) {
+	gracefulStop := rar.config.GetGracefulStop()
+	duration := rar.config.getDuration()
+	preAllocatedVUs := rar.config.GetPreAllocatedVUs(rar.executionState.ExecutionTuple)
+	maxVUs := rar.config.GetMaxVUs(rar.executionState.ExecutionTuple)
+	execs := rar.config.GetExecs()
+	maxArrivalRatePerSec := rar.config.getMaxArrivalRatePerSec(rar.executionState.ExecutionTuple.Segment)
+
+	// Make sure the log and the progress bar have accurate information
+	rar.logger.WithFields(logrus.Fields{
+		"maxVUs": maxVUs, "preAllocatedVUs": preAllocatedVUs, "duration": duration,
+		"file": rar.config.File.String, "execs": execs, "type": rar.config.GetType(),
+	}).Debug("Starting executor run...")
+
+	activeVUsWg := &sync.WaitGroup{}
+
+	returnedVUs := make(chan struct{})
+	waitOnProgressChannel := make(chan struct{})
+	startTime, maxDurationCtx, regDurationCtx, cancel := getDurationContexts(parentCtx, duration, gracefulStop)
+
+	vusPools := make([]*activeVUPool, len(execs))
+	for i := range vusPools {
+		vusPools[i] = newActiveVUPool(rar.executionState)
+	}
+
+	defer func() {
+		// Make sure all VUs aren't executing iterations anymore, for the cancel()
+		// below to deactivate them.
+		<-returnedVUs
+		// first close the vusPools so we wait for the gracefulShutdown
+		for _, vusPool := range vusPools {
+			vusPool.Close()
+		}
+		cancel()
+		activeVUsWg.Wait()
+		<-waitOnProgressChannel
+	}()
+
+	activeVUsCount := uint64(0)
+	tickerPeriod := int64(0)
+	vusFmt := pb.GetFixedLengthIntFormat(maxVUs)
+	itersFmt := pb.GetFixedLengthFloatFormat(maxArrivalRatePerSec, 2) + " iters/s"
+
+	progressFn := func() (float64, []string) {
+		currActiveVUs := atomic.LoadUint64(&activeVUsCount)
+		currentTickerPeriod := atomic.LoadInt64(&tickerPeriod)
+		var running uint64
+		for _, vusPool := range vusPools {
+			running += vusPool.Running()
+		}
+		progVUs := fmt.Sprintf(vusFmt+"/"+vusFmt+" VUs", running, currActiveVUs)
+
+		itersPerSec := 0.0
+		if currentTickerPeriod > 0 {
+			itersPerSec = float64(time.Second) / float64(currentTickerPeriod)
+		}
+		progIters := fmt.Sprintf(itersFmt, itersPerSec)
+
+		right := []string{progVUs, duration.String(), progIters}
+
+		spent := time.Since(startTime)
+		if spent > duration {
+			return 1, right
+		}
+
+		spentDuration := pb.GetFixedLengthDuration(spent, duration)
+		progDur := fmt.Sprintf("%s/%s", spentDuration, duration)
+		right[1] = progDur
+
+		return math.Min(1, float64(spent)/float64(duration)), right
+	}
+
+	rar.progress.Modify(pb.WithProgress(progressFn))
+	maxDurationCtx = lib.WithScenarioState(maxDurationCtx, &lib.ScenarioState{
+		Name:       rar.config.Name,
+		Executor:   rar.config.Type,
+		StartTime:  startTime,
+		ProgressFn: progressFn,
+	})
+	go func() {
+		trackProgress(parentCtx, maxDurationCtx, regDurationCtx, &rar, progressFn)
+		close(waitOnProgressChannel)
+	}()
+
+	returnVU := func(u lib.InitializedVU) {
+		// Return the VU without decreasing the global active VU counter, which
+		// is done in the goroutine started by activeVUPool.AddVU, whenever the
+		// VU finishes running an iteration.
+		rar.executionState.ReturnVU(u, false)
+		activeVUsWg.Done()
+	}
+
+	runIterationBasic := getIterationRunner(rar.executionState, rar.logger)
+
+	activateVU := func(initVU lib.InitializedVU, exec int) {
+		activeVUsWg.Add(1)
+		conf := rar.config.BaseConfig
+		if len(rar.config.shape.execs) > 0 {
+			conf.Exec = null.StringFrom(execs[exec])
+		}
+		activeVU := initVU.Activate(getVUActivationParams(maxDurationCtx, conf, returnVU, rar.nextIterationCounters))
+		atomic.AddUint64(&activeVUsCount, 1)
+
+		vusPools[exec].AddVU(maxDurationCtx, activeVU, runIterationBasic)
+	}
+
+	remainingUnplannedVUs := maxVUs - preAllocatedVUs
+	makeUnplannedVUCh := make(chan int)
+	defer close(makeUnplannedVUCh)
+	go func() {
+		defer close(returnedVUs)
+
+		for exec := range makeUnplannedVUCh {
+			rar.logger.Debug("Starting initialization of an unplanned VU...")
+			initVU, err := rar.executionState.GetUnplannedVU(maxDurationCtx, rar.logger)
+			if err != nil {
+				// TODO figure out how to return it to the Run goroutine
+				rar.logger.WithError(err).Error("Error while allocating unplanned VU")
+			} else {
+				rar.logger.Debug("The unplanned VU finished initializing successfully!")
+				activateVU(initVU, exec)
+			}
+		}
+	}()
+
+	// Get the pre-allocated VUs in the local buffer
+	for exec, vus := range rar.config.getPreAllocatedVUsPerExec(preAllocatedVUs) {
+		for i := int64(0); i < vus; i++ {
+			initVU, err := rar.executionState.GetPlannedVU(rar.logger, false)
+			if err != nil {
+				return err
+			}
+			activateVU(initVU, exec)
+		}
+	}
+
+	regDurationDone := regDurationCtx.Done()
+	timer := time.NewTimer(time.Hour)
+	start := time.Now()
+	ch := make(chan replayArrival, 10) // buffer 10 iteration times ahead
+	var prevTime time.Duration
+	shownWarning := false
+	metricTags := rar.getMetricTags(nil)
+	go rar.config.cal(rar.et, ch)
+	for next := range ch {
+		select {
+		case <-regDurationDone:
+			return nil
+		default:
+		}
+		atomic.StoreInt64(&tickerPeriod, int64(next.offset-prevTime))
+		prevTime = next.offset
+		b := time.Until(start.Add(next.offset))
+		if b > 0 {
+			timer.Reset(b)
+			select {
+			case <-timer.C:
+			case <-regDurationDone:
+				return nil
+			}
+		}
+
+		if vusPools[next.exec].TryRunIteration() {
+			continue
+		}
+
+		// Since there aren't any free VUs available for the function,
+		// consider this iteration dropped
+		metrics.PushIfNotDone(parentCtx, out, metrics.Sample{
+			TimeSeries: metrics.TimeSeries{
+				Metric: rar.executionState.Test.BuiltinMetrics.DroppedIterations,
+				Tags:   metricTags,
+			},
+			Time:  time.Now(),
+			Value: 1,
+		})
+
+		// We'll try to start allocating another VU in the background,
+		// non-blockingly, if we have remainingUnplannedVUs...
+		if remainingUnplannedVUs == 0 {
+			if !shownWarning {
+				rar.logger.Warningf("Insufficient VUs, reached %d active VUs and cannot initialize more", maxVUs)
+				shownWarning = true
+			}
+			continue
+		}
+
+		select {
+		case makeUnplannedVUCh <- next.exec: // great!
+			remainingUnplannedVUs--
+		default: // we're already allocating a new VU
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+func getTestReplayArrivalRateConfig(t *testing.T, file, data string) *ReplayArrivalRateConfig {
+	t.Helper()
+
+	config := NewReplayArrivalRateConfig("replay")
+	config.File = null.StringFrom(file)
+	config.PreAllocatedVUs = null.IntFrom(10)
+	config.MaxVUs = null.IntFrom(20)
+	config.GracefulStop = types.NullDurationFrom(0)
+	require.NoError(t, config.LoadFiles(func(path string) ([]byte, error) {
+		if path != file {
+			return nil, fmt.Errorf("unexpected file %q", path)
+		}
+		return []byte(data), nil
+	}))
+	require.Empty(t, config.Validate())
+	return config
+}
+
+func TestParseTrafficShape(t *testing.T) {
+	t.Parallel()
+
+	shape, err := parseTrafficShape("shape.csv", []byte("offset,rate\n10s,5\n1m10s,20.5\n70000,0\n"))
+	require.NoError(t, err)
+	assert.Equal(t, &trafficShape{
+		points: []shapePoint{{offset: 0, rate: 5}, {offset: time.Minute, rate: 20.5}, {offset: time.Minute, rate: 0}},
+		execs:  []string{},
+	}, shape)
+
+	shape, err = parseTrafficShape("shape.CSV", []byte(
+		"timestamp, rate, browse, checkout\n2024-01-01T10:00:00Z, 10, 3, 1\n2024-01-01T10:00:01Z, 20, 1,\n"))
+	require.NoError(t, err)
+	assert.Equal(t, &trafficShape{
+		points: []shapePoint{
+			{offset: 0, rate: 10, weights: []float64{3, 1}},
+			{offset: time.Second, rate: 20, weights: []float64{1, 0}},
+		},
+		execs: []string{"browse", "checkout"},
+	}, shape)
+
+	shape, err = parseTrafficShape("shape.json", []byte(`[
+		{"timestamp": 1700000000, "rate": 1, "weights": {"a": 1}},
+		{"timestamp": "1700000001.5", "rate": 2, "weights": {"b": 1}},
+		{"timestamp": "2023-11-14T22:13:22Z", "rate": 3, "weights": {"a": 1, "b": 2}}
+	]`))
+	require.NoError(t, err)
+	assert.Equal(t, &trafficShape{
+		points: []shapePoint{
+			{offset: 0, rate: 1, weights: []float64{1, 0}},
+			{offset: 1500 * time.Millisecond, rate: 2, weights: []float64{0, 1}},
+			{offset: 2 * time.Second, rate: 3, weights: []float64{1, 2}},
+		},
+		execs: []string{"a", "b"},
+	}, shape)
+
+	testCases := []struct {
+		file, data, err string
+	}{
+		{"shape.txt", "offset,rate\n0,1\n1s,1", `unsupported file extension ".txt"`},
+		{"shape.csv", "", "the file is empty"},
+		{"shape.csv", "time,rate\n0,1\n1s,1", "the header should have an offset or a timestamp column and a rate column"},
+		{"shape.csv", "offset,timestamp,rate\n0,0,1\n1s,1,1", "only one offset or timestamp column"},
+		{"shape.csv", "offset,rate\n0,1", "it needs at least 2 points"},
+		{"shape.csv", "offset,rate\n0,1\nsoon,1", "invalid offset on line 3"},
+		{"shape.csv", "offset,rate\n0,1\n1s,many", "invalid rate on line 3"},
+		{"shape.csv", "offset,rate,a\n0,1,x\n1s,1,1", "invalid weight of a on line 2"},
+		{"shape.csv", "offset,rate\n1s,1\n0s,1", "the point at index 1 is before the previous one"},
+		{"shape.csv", "offset,rate\n1s,1\n1s,1", "the last point should be after the first one"},
+		{"shape.csv", "offset,rate\n0,-1\n1s,1", "the rate of the point at index 0 should be a non-negative number"},
+		{"shape.csv", "offset,rate,a,b\n0,1,0,0\n1s,1,1,1", "the weights of the point at index 0 should have a sum more than 0"},
+		{"shape.csv", "offset,rate,a\n0,1,-1\n1s,1,1", "the weight of a in the point at index 0 should be a non-negative number"},
+		{"shape.json", `[{"offset": "0s", "rate": 1}, {"timestamp": 1, "rate": 1}]`, "should have a offset like the first one"},
+		{"shape.json", `[{"offset": "0s", "timestamp": 1, "rate": 1}, {"offset": "1s", "rate": 1}]`, "either an offset or a timestamp"},
+		{"shape.json", `[{"offset": "0s", "rate": 1}, {"offset": "1s"}]`, "the point at index 1 doesn't have a rate"},
+		{"shape.json", `[{"offset": "0s", "rate": 1, "weights": {"a": 1}}, {"offset": "1s", "rate": 1}]`, "doesn't have weights"},
+		{"shape.json", `[{"offset": "0s", "rate": 1, "weight": 1}, {"offset": "1s", "rate": 1}]`, `unknown field "weight"`},
+		{"shape.json", `[{"timestamp": "yesterday", "rate": 1}, {"timestamp": 1, "rate": 1}]`, "invalid timestamp of the point at index 0"},
+	}
+	for _, tc := range testCases {
+		_, err := parseTrafficShape(tc.file, []byte(tc.data))
+		assert.ErrorContains(t, err, tc.err, tc.data)
+	}
+}
+
+func TestReplayArrivalRateConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	config := NewReplayArrivalRateConfig("replay")
+	config.PreAllocatedVUs = null.IntFrom(1)
+	require.NoError(t, config.LoadFiles(nil))
+	errs := config.Validate()
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "the file with the traffic shape isn't specified")
+
+	config.File = null.StringFrom("shape.csv")
+	errs = config.Validate()
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], `the traffic shape wasn't loaded from "shape.csv"`)
+
+	config = getTestReplayArrivalRateConfig(t, "shape.csv", "offset,rate,a,b\n0,1,1,1\n1s,1,1,1")
+	config.Exec = null.StringFrom("a")
+	config.TimeCompression = null.FloatFrom(0)
+	errs = config.Validate()
+	require.Len(t, errs, 2)
+	assert.ErrorContains(t, errs[0], "the exec option can't be used with a weighted traffic shape")
+	assert.ErrorContains(t, errs[1], "the timeCompression must be more than 0")
+
+	err := config.LoadFiles(func(string) ([]byte, error) { return []byte("offset,rate\n0,1"), nil })
+	assert.ErrorContains(t, err, `invalid traffic shape in "shape.csv": it needs at least 2 points`)
+}
+
+func TestReplayArrivalRateConfigRequirements(t *testing.T) {
+	t.Parallel()
+
+	config := getTestReplayArrivalRateConfig(t, "shape.csv", "offset,rate\n0,10\n1m,30\n2m,20\n")
+	config.TimeCompression = null.FloatFrom(4)
+	config.GracefulStop = types.NullDurationFrom(10 * time.Second)
+
+	et := mustNewExecutionTuple(newExecutionSegmentFromString("0:1/2"), nil)
+	assert.Equal(t, []lib.ExecutionStep{
+		{TimeOffset: 0, PlannedVUs: 5, MaxUnplannedVUs: 5},
+		{TimeOffset: 40 * time.Second, PlannedVUs: 0, MaxUnplannedVUs: 0},
+	}, config.GetExecutionRequirements(et))
+	assert.Equal(t, "Up to 15.00 iterations/s for 30s replayed from shape.csv (maxVUs: 5-10, gracefulStop: 10s)",
+		config.GetDescription(et))
+	assert.Equal(t, []string{"default"}, config.GetExecs())
+}
+
+func TestReplayArrivalRateCal(t *testing.T) {
+	t.Parallel()
+
+	config := getTestReplayArrivalRateConfig(t, "shape.json", `[
+		{"offset": "0s", "rate": 20, "weights": {"a": 3, "b": 1}},
+		{"offset": "2s", "rate": 20, "weights": {"a": 0, "b": 1}},
+		{"offset": "4s", "rate": 60, "weights": {"a": 1, "b": 1}}
+	]`)
+	config.TimeCompression = null.FloatFrom(2)
+
+	getArrivals := func(et *lib.ExecutionTuple) []replayArrival {
+		ch := make(chan replayArrival, 20)
+		go config.cal(et, ch)
+		var arrivals []replayArrival
+		for arrival := range ch {
+			arrivals = append(arrivals, arrival)
+		}
+		return arrivals
+	}
+
+	all := getArrivals(mustNewExecutionTuple(nil, nil))
+	// 20 iterations in the first second, then 40 in the second one
+	require.Len(t, all, 60)
+	assert.Equal(t, replayArrival{offset: 50 * time.Millisecond, exec: 0}, all[0])
+	assert.Equal(t, time.Second, all[19].offset)
+	assert.Equal(t, 2*time.Second, all[59].offset)
+	execs := [2][2]int{}
+	for i, arrival := range all {
+		execs[min(i/20, 1)][arrival.exec]++
+	}
+	// a and b are about 3:1 in the first second, and then it's only b
+	assert.InDelta(t, 15, execs[0][0], 1)
+	assert.Equal(t, 20, execs[0][0]+execs[0][1])
+	assert.Equal(t, [2]int{0, 40}, execs[1])
+
+	sequence := newExecutionSegmentSequenceFromString("0,1/3,1/2,1")
+	var segmented []replayArrival
+	for _, segment := range []string{"0:1/3", "1/3:1/2", "1/2:1"} {
+		segmented = append(segmented,
+			getArrivals(mustNewExecutionTuple(newExecutionSegmentFromString(segment), sequence))...)
+	}
+	sort.SliceStable(segmented, func(i, j int) bool { return segmented[i].offset < segmented[j].offset })
+	assert.Equal(t, all, segmented)
+}
+
+func TestReplayArrivalRatePreAllocatedVUsPerExec(t *testing.T) {
+	t.Parallel()
+
+	config := getTestReplayArrivalRateConfig(t, "shape.csv", "offset,rate,a,b,c\n0,10,2,1,0\n1s,10,0,0,1\n2s,10,0,0,1\n")
+	assert.Equal(t, []int64{3, 2, 5}, config.getPreAllocatedVUsPerExec(10))
+	assert.Equal(t, []int64{1, 1, 1}, config.getPreAllocatedVUsPerExec(3))
+	assert.Equal(t, []int64{1, 0, 1}, config.getPreAllocatedVUsPerExec(2))
+
+	config = getTestReplayArrivalRateConfig(t, "shape.csv", "offset,rate\n0,10\n1s,10\n")
+	assert.Equal(t, []int64{7}, config.getPreAllocatedVUsPerExec(7))
+}
+
+func TestReplayArrivalRateRun(t *testing.T) {
+	t.Parallel()
+
+	var count int64
+	runner := simpleRunner(func(_ context.Context, _ *lib.State) error {
+		atomic.AddInt64(&count, 1)
+		return nil
+	})
+
+	config := getTestReplayArrivalRateConfig(t, "shape.csv", "offset,rate,a,b\n0,20,1,1\n4s,20,1,3\n8s,60,1,0\n")
+	config.TimeCompression = null.FloatFrom(4)
+
+	test := setupExecutorTest(t, "", "", lib.Options{}, runner, config)
+	defer test.cancel()
+
+	engineOut := make(chan metrics.SampleContainer, 1000)
+	start := time.Now()
+	require.NoError(t, test.executor.Run(test.ctx, engineOut))
+	assert.InDelta(t, 2*time.Second, time.Since(start), float64(500*time.Millisecond))
+	// the last iteration is right at the end of the shape
+	assert.InDelta(t, 60, atomic.LoadInt64(&count), 1)
+	assert.Empty(t, test.logHook.Drain())
+}
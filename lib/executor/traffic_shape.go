@@ -0,0 +1,320 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+)
+
+// goldenRatioConjugate spreads the iterations of the weighted functions evenly,
+// see trafficShape.pickExec
+const goldenRatioConjugate = 0.6180339887498949
+
+// trafficShape is a recorded series of arrival rates. The rate changes linearly
+// between its points, like between the stages of the ramping-arrival-rate
+// executor, and the shape ends at the last one.
+type trafficShape struct {
+	points []shapePoint
+	// execs are the functions, which the iterations are spread between by
+	// the weights of the points, it's empty if the points aren't weighted
+	execs []string
+}
+
+type shapePoint struct {
+	// offset is the time since the first point
+	offset time.Duration
+	// rate is the number of iterations per timeUnit
+	rate float64
+	// weights are the weights of the execs, from this point until the next
+	weights []float64
+}
+
+// rawShapePoint is a point of a traffic shape as it's read from a JSON file.
+type rawShapePoint struct {
+	Offset    types.NullDuration `json:"offset"`
+	Timestamp json.RawMessage    `json:"timestamp"`
+	Rate      *float64           `json:"rate"`
+	Weights   map[string]float64 `json:"weights"`
+}
+
+// parseTrafficShape parses a CSV or JSON file, depending on its extension.
+//
+// The CSV files have a header with an offset or a timestamp column, a rate
+// column and the names of the weighted functions as the rest of the columns.
+// The JSON files are an array of objects with an offset or a timestamp, a
+// rate and an optional object with the weights of the functions.
+//
+// The offsets are durations, like "1m30s" or a number of milliseconds, and
+// the timestamps are either in RFC 3339 format or seconds since the Unix
+// epoch. Either way, the shape starts at the first point.
+func parseTrafficShape(filename string, data []byte) (*trafficShape, error) {
+	var (
+		points []rawShapePoint
+		err    error
+	)
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".csv":
+		points, err = parseTrafficShapeCSV(data)
+	case ".json":
+		err = lib.StrictJSONUnmarshal(data, &points)
+	default:
+		return nil, fmt.Errorf("unsupported file extension %q, it should be .csv or .json", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newTrafficShape(points)
+}
+
+func parseTrafficShapeCSV(data []byte) ([]rawShapePoint, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("the file is empty")
+	}
+
+	timeColumn, rateColumn := -1, -1
+	for i, name := range records[0] {
+		switch name {
+		case "offset", "timestamp":
+			if timeColumn >= 0 {
+				return nil, fmt.Errorf("the header should have only one offset or timestamp column")
+			}
+			timeColumn = i
+		case "rate":
+			rateColumn = i
+		}
+	}
+	if timeColumn < 0 || rateColumn < 0 {
+		return nil, fmt.Errorf("the header should have an offset or a timestamp column and a rate column")
+	}
+
+	header := records[0]
+	points := make([]rawShapePoint, 0, len(records)-1)
+	for line, record := range records[1:] {
+		var point rawShapePoint
+		if header[timeColumn] == "offset" {
+			err = point.Offset.UnmarshalText([]byte(record[timeColumn]))
+		} else {
+			point.Timestamp, err = json.Marshal(record[timeColumn])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s on line %d: %w", header[timeColumn], line+2, err)
+		}
+
+		rate, err := strconv.ParseFloat(record[rateColumn], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate on line %d: %w", line+2, err)
+		}
+		point.Rate = &rate
+
+		for i, value := range record {
+			if i == timeColumn || i == rateColumn {
+				continue
+			}
+			if point.Weights == nil {
+				point.Weights = make(map[string]float64)
+			}
+			if value == "" {
+				point.Weights[header[i]] = 0
+				continue
+			}
+			if point.Weights[header[i]], err = strconv.ParseFloat(value, 64); err != nil {
+				return nil, fmt.Errorf("invalid weight of %s on line %d: %w", header[i], line+2, err)
+			}
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// parseTimestamp parses a timestamp in RFC 3339 format or in seconds since the
+// Unix epoch, either as a JSON string or a number.
+func parseTimestamp(data json.RawMessage) (time.Time, error) {
+	value := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &value); err != nil {
+			return time.Time{}, err
+		}
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Unix(0, int64(seconds*float64(time.Second))), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+//nolint:funlen,gocognit
+func newTrafficShape(raw []rawShapePoint) (*trafficShape, error) {
+	if len(raw) < 2 {
+		return nil, fmt.Errorf("it needs at least 2 points")
+	}
+
+	execs := make(map[string]struct{})
+	for _, point := range raw {
+		for exec := range point.Weights {
+			execs[exec] = struct{}{}
+		}
+	}
+	shape := &trafficShape{
+		points: make([]shapePoint, len(raw)),
+		execs:  make([]string, 0, len(execs)),
+	}
+	for exec := range execs {
+		shape.execs = append(shape.execs, exec)
+	}
+	sort.Strings(shape.execs)
+
+	useTimestamps, kind := raw[0].Timestamp != nil, "offset"
+	if useTimestamps {
+		kind = "timestamp"
+	}
+	var first time.Time
+	for i, point := range raw {
+		p := &shape.points[i]
+		switch {
+		case point.Offset.Valid == (point.Timestamp != nil):
+			return nil, fmt.Errorf("the point at index %d should have either an offset or a timestamp", i)
+		case useTimestamps != (point.Timestamp != nil):
+			return nil, fmt.Errorf("the point at index %d should have a %s like the first one", i, kind)
+		case useTimestamps:
+			timestamp, err := parseTimestamp(point.Timestamp)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timestamp of the point at index %d: %w", i, err)
+			}
+			if i == 0 {
+				first = timestamp
+			}
+			p.offset = timestamp.Sub(first)
+		default:
+			p.offset = point.Offset.TimeDuration() - raw[0].Offset.TimeDuration()
+		}
+		if i > 0 && p.offset < shape.points[i-1].offset {
+			return nil, fmt.Errorf("the point at index %d is before the previous one", i)
+		}
+
+		if point.Rate == nil {
+			return nil, fmt.Errorf("the point at index %d doesn't have a rate", i)
+		}
+		if *point.Rate < 0 || math.IsInf(*point.Rate, 0) || math.IsNaN(*point.Rate) {
+			return nil, fmt.Errorf("the rate of the point at index %d should be a non-negative number", i)
+		}
+		p.rate = *point.Rate
+
+		if len(shape.execs) == 0 {
+			continue
+		}
+		if point.Weights == nil {
+			return nil, fmt.Errorf("the point at index %d doesn't have weights, but other points do", i)
+		}
+		p.weights = make([]float64, len(shape.execs))
+		var sum float64
+		for ei, exec := range shape.execs {
+			weight := point.Weights[exec]
+			if weight < 0 || math.IsInf(weight, 0) || math.IsNaN(weight) {
+				return nil, fmt.Errorf("the weight of %s in the point at index %d should be a non-negative number", exec, i)
+			}
+			p.weights[ei] = weight
+			sum += weight
+		}
+		if sum == 0 && i < len(raw)-1 {
+			return nil, fmt.Errorf("the weights of the point at index %d should have a sum more than 0", i)
+		}
+	}
+
+	if shape.duration(1) == 0 {
+		return nil, fmt.Errorf("the last point should be after the first one")
+	}
+	return shape, nil
+}
+
+// duration returns how long the replay of the shape is, when it's time
+// compressed by the given factor.
+func (ts *trafficShape) duration(timeCompression float64) time.Duration {
+	return time.Duration(float64(ts.points[len(ts.points)-1].offset) / timeCompression)
+}
+
+// maxRate returns the highest rate of the shape, per timeUnit.
+func (ts *trafficShape) maxRate() float64 {
+	var result float64
+	for _, point := range ts.points {
+		result = math.Max(result, point.rate)
+	}
+	return result
+}
+
+// stages returns the start rate and the stages of the shape, as they're used
+// by rampArrivals.
+func (ts *trafficShape) stages(timeUnit time.Duration, timeCompression float64) (float64, []rateStage) {
+	stages := make([]rateStage, len(ts.points)-1)
+	for i, point := range ts.points[1:] {
+		stages[i] = rateStage{
+			duration: time.Duration(float64(point.offset-ts.points[i].offset) / timeCompression),
+			target:   point.rate / float64(timeUnit),
+		}
+	}
+	return ts.points[0].rate / float64(timeUnit), stages
+}
+
+// execShares returns the part of all the iterations of the shape which every
+// weighted function gets, or nil if the points aren't weighted.
+func (ts *trafficShape) execShares() []float64 {
+	if len(ts.execs) == 0 {
+		return nil
+	}
+	shares := make([]float64, len(ts.execs))
+	var total float64
+	for i, point := range ts.points[1:] {
+		prev := ts.points[i]
+		// the area of the stage is the number of its iterations
+		iterations := float64(point.offset-prev.offset) * (prev.rate + point.rate) / 2
+		var sum float64
+		for _, weight := range prev.weights {
+			sum += weight
+		}
+		for ei, weight := range prev.weights {
+			shares[ei] += iterations * weight / sum
+		}
+		total += iterations
+	}
+	for i := range shares {
+		shares[i] /= total
+	}
+	return shares
+}
+
+// pickExec returns the index of the weighted function which should run the
+// iteration gi, which is in the given stage. Instead of being random, the
+// iterations are spread by the fractional parts of the multiples of the golden
+// ratio, which cover the weights evenly for any consecutive iterations. This
+// way, every instance of a distributed test picks the same function for the
+// same iteration.
+func (ts *trafficShape) pickExec(stage int, gi int64) int {
+	weights := ts.points[stage].weights
+	var sum float64
+	for _, weight := range weights {
+		sum += weight
+	}
+	_, position := math.Modf(float64(gi) * goldenRatioConjugate)
+	position *= sum
+	for i, weight := range weights {
+		if position < weight {
+			return i
+		}
+		position -= weight
+	}
+	return len(weights) - 1
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
 	"go.k6.io/k6/ui/pb"
 )
@@ -63,6 +64,10 @@ type ExecutorConfig interface {
 	GetName() string
 	GetType() string
 	GetStartTime() time.Duration
+	// Returns the names of the scenarios which have to finish before this
+	// one can start. If there are any, the start time is counted from the end
+	// of the last one of them, instead of from the beginning of the test.
+	GetStartAfter() []string
 	GetGracefulStop() time.Duration
 
 	// This is used to validate whether a particular script can run in the cloud
@@ -93,10 +98,24 @@ type ExecutorConfig interface {
 	HasWork(*ExecutionTuple) bool
 }
 
+// ExecutorConfigWithFiles should be implemented by the executor configs which
+// read some of their options from files, e.g. a recorded traffic shape. The
+// files are read through the filesystem of the test, so they are packaged
+// in its archives, like the ones opened by the script.
+type ExecutorConfigWithFiles interface {
+	ExecutorConfig
+
+	// LoadFiles is called before the config is validated, with a function
+	// which reads the files relative to the directory of the script.
+	LoadFiles(readFile func(path string) ([]byte, error)) error
+}
+
 // ScenarioOptions are options specific to a scenario. These include k6 browser
 // options, which are validated by the browser module, and not by k6 core.
 type ScenarioOptions struct {
 	Browser map[string]any `json:"browser"`
+	// Network overrides the global network emulation for the VUs of the scenario.
+	Network *types.NetworkConfig `json:"network,omitempty"`
 }
 
 // ScenarioState holds runtime scenario information returned by the k6/execution
@@ -203,6 +222,58 @@ func (scs ScenarioConfigs) Validate() (errors []error) {
 				fmt.Errorf("scenario %s has configuration errors: %s", name, ConcatErrors(execErr, ", ")))
 		}
 	}
+	return append(errors, scs.validateStartAfter()...)
+}
+
+// validateStartAfter checks that the scenarios only start after other existing
+// scenarios and that there are no cycles between them.
+func (scs ScenarioConfigs) validateStartAfter() (errors []error) {
+	names := make([]string, 0, len(scs))
+	for name := range scs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, dep := range scs[name].GetStartAfter() {
+			if _, exists := scs[dep]; !exists {
+				errors = append(errors, fmt.Errorf("scenario %s should start after the unknown scenario %s", name, dep))
+			}
+		}
+	}
+
+	// a depth-first search, the path is the chain of scenarios from the
+	// currently visited one to its root, and a scenario is done when all of
+	// its dependencies were visited
+	done := make(map[string]bool, len(scs))
+	var path []string
+	var visit func(name string)
+	visit = func(name string) {
+		for i, prev := range path {
+			if prev == name {
+				cycle := append(append([]string{}, path[i:]...), name)
+				errors = append(errors, fmt.Errorf(
+					"scenario %s can't start after itself, its startAfter has a cycle %s",
+					name, strings.Join(cycle, " -> "),
+				))
+				return
+			}
+		}
+		if done[name] {
+			return
+		}
+		path = append(path, name)
+		for _, dep := range scs[name].GetStartAfter() {
+			if _, exists := scs[dep]; exists {
+				visit(dep)
+			}
+		}
+		path = path[:len(path)-1]
+		done[name] = true
+	}
+	for _, name := range names {
+		visit(name)
+	}
 	return errors
 }
 
@@ -214,7 +285,8 @@ func (scs ScenarioConfigs) Validate() (errors []error) {
 //
 // The configs in the returned slice will be sorted by their start times in an
 // ascending order, and alphabetically by their names (which are unique) if
-// there are ties.
+// there are ties. The only exception are the scenarios with startAfter, which
+// are always after the scenarios they depend on.
 func (scs ScenarioConfigs) GetSortedConfigs() []ExecutorConfig {
 	configs := make([]ExecutorConfig, len(scs))
 
@@ -235,15 +307,98 @@ func (scs ScenarioConfigs) GetSortedConfigs() []ExecutorConfig {
 		}
 	})
 
-	return configs
+	// Move the scenarios after their dependencies, while keeping the order
+	// of the rest. Unknown and cyclic dependencies are ignored, since they
+	// are reported by Validate().
+	sorted := make([]ExecutorConfig, 0, len(configs))
+	added := make(map[string]bool, len(configs))
+	var add func(config ExecutorConfig)
+	add = func(config ExecutorConfig) {
+		if added[config.GetName()] {
+			return
+		}
+		added[config.GetName()] = true
+		for _, dep := range config.GetStartAfter() {
+			if depConfig, exists := scs[dep]; exists {
+				add(depConfig)
+			}
+		}
+		sorted = append(sorted, config)
+	}
+	for _, config := range configs {
+		add(config)
+	}
+
+	return sorted
+}
+
+// startWindow is the time span in which a scenario can start, relative to the
+// beginning of the test. It's a single moment for the scenarios with a fixed
+// startTime, but the ones with startAfter start when their dependencies
+// finish, which can be any time until the planned end of the dependencies.
+type startWindow struct {
+	earliest, latest time.Duration
+}
+
+// getStartWindows returns the start windows of all the scenarios.
+func (scs ScenarioConfigs) getStartWindows(et *ExecutionTuple) map[string]startWindow {
+	windows := make(map[string]startWindow, len(scs))
+	visiting := make(map[string]bool, len(scs))
+	var getWindow func(config ExecutorConfig) startWindow
+	getWindow = func(config ExecutorConfig) startWindow {
+		name := config.GetName()
+		if window, ok := windows[name]; ok {
+			return window
+		}
+		if visiting[name] { // a cycle, which is reported by Validate()
+			return startWindow{}
+		}
+		visiting[name] = true
+
+		var window startWindow
+		for _, dep := range config.GetStartAfter() {
+			depConfig, exists := scs[dep]
+			if !exists {
+				continue
+			}
+			depWindow := getWindow(depConfig)
+			depDuration, _ := GetEndOffset(depConfig.GetExecutionRequirements(et))
+			// the dependency could finish right after it starts, e.g. if
+			// it's aborted or it has no work for this execution segment
+			if depWindow.earliest > window.earliest {
+				window.earliest = depWindow.earliest
+			}
+			if depWindow.latest+depDuration > window.latest {
+				window.latest = depWindow.latest + depDuration
+			}
+		}
+		window.earliest += config.GetStartTime()
+		window.latest += config.GetStartTime()
+
+		windows[name] = window
+		return window
+	}
+	for _, config := range scs {
+		getWindow(config)
+	}
+	return windows
 }
 
 // GetFullExecutionRequirements combines the execution requirements from all of
 // the configured executors. It takes into account their start times and their
 // individual VU requirements and calculates the total VU requirements for each
 // moment in the test execution.
+//
+// The scenarios with startAfter are planned to start at the latest moment
+// their dependencies could finish, so the plan covers the longest possible
+// test run. Since they can start sooner, their maximum VU requirements are
+// reserved for the whole span between the earliest moment they can start and
+// their latest possible end. They never run in parallel with the scenarios
+// they start after though, so only the maximum of their VUs is needed.
 func (scs ScenarioConfigs) GetFullExecutionRequirements(et *ExecutionTuple) []ExecutionStep {
 	sortedConfigs := scs.GetSortedConfigs()
+	startWindows := scs.getStartWindows(et)
+	chains := getSequentialChains(sortedConfigs)
 
 	// Combine the steps and requirements from all different executors, and
 	// sort them by their time offset, counting the executors' startTimes as
@@ -254,8 +409,13 @@ func (scs ScenarioConfigs) GetFullExecutionRequirements(et *ExecutionTuple) []Ex
 	}
 	trackedSteps := []trackedStep{}
 	for configID, config := range sortedConfigs { // orderly iteration over a slice
-		configStartTime := config.GetStartTime()
+		window := startWindows[config.GetName()]
+		configStartTime := window.latest
 		configSteps := config.GetExecutionRequirements(et)
+		if window.earliest < window.latest && len(configSteps) > 0 {
+			configSteps = getStepsEnvelope(configSteps, window.latest-window.earliest)
+			configStartTime = window.earliest
+		}
 		for _, cs := range configSteps {
 			cs.TimeOffset += configStartTime // add the executor start time to the step time offset
 			trackedSteps = append(trackedSteps, trackedStep{cs, configID})
@@ -281,7 +441,13 @@ func (scs ScenarioConfigs) GetFullExecutionRequirements(et *ExecutionTuple) []Ex
 	currentPlannedVUs := make([]uint64, len(scs))
 	currentMaxUnplannedVUs := make([]uint64, len(scs))
 	sum := func(data []uint64) (result uint64) { // sigh...
-		for _, val := range data {
+		chainMax := make([]uint64, len(data))
+		for configID, val := range data {
+			if val > chainMax[chains[configID]] {
+				chainMax[chains[configID]] = val
+			}
+		}
+		for _, val := range chainMax {
 			result += val
 		}
 		return result
@@ -313,6 +479,39 @@ func (scs ScenarioConfigs) GetFullExecutionRequirements(et *ExecutionTuple) []Ex
 	return consolidatedSteps
 }
 
+// getSequentialChains splits the sorted configs into chains of scenarios that
+// can't run in parallel, since each one starts after the previous one in its
+// chain. It returns the index of the first config in the chain of each config.
+func getSequentialChains(sortedConfigs []ExecutorConfig) []int {
+	chains := make([]int, len(sortedConfigs))
+	chainEnds := make(map[string]int, len(sortedConfigs)) // by the name of the last config
+	for configID, config := range sortedConfigs {
+		chains[configID] = configID
+		for _, dep := range config.GetStartAfter() {
+			if chain, ok := chainEnds[dep]; ok {
+				// only a single scenario can continue a chain, the other
+				// ones after dep could run in parallel with it
+				delete(chainEnds, dep)
+				chains[configID] = chain
+				break
+			}
+		}
+		chainEnds[config.GetName()] = chains[configID]
+	}
+	return chains
+}
+
+// getStepsEnvelope returns steps which need the maximum VUs of the given ones
+// from the beginning until the end of the given steps, extended by slack.
+func getStepsEnvelope(steps []ExecutionStep, slack time.Duration) []ExecutionStep {
+	endOffset, _ := GetEndOffset(steps)
+	maxPlannedVUs := GetMaxPlannedVUs(steps)
+	return []ExecutionStep{
+		{PlannedVUs: maxPlannedVUs, MaxUnplannedVUs: GetMaxPossibleVUs(steps) - maxPlannedVUs},
+		{TimeOffset: endOffset + slack},
+	}
+}
+
 // GetParsedExecutorConfig returns a struct instance corresponding to the supplied
 // config type. It will be fully initialized - with both the default values of
 // the type, as well as with whatever the user had specified in the JSON
@@ -29,6 +29,13 @@ type OnlyCachedEnabler interface {
 	AllowOnlyCached()
 }
 
+// OnlyCachedPathAllower allows a path to be opened even in the mode of FS
+// that allows to open only already opened files, e.g. for the files which
+// k6 reads for the options after the initialization
+type OnlyCachedPathAllower interface {
+	AllowPath(path string)
+}
+
 // CacheLayerGetter provide a direct access to a cache layer
 type CacheLayerGetter interface {
 	GetCachingFs() afero.Fs
@@ -58,6 +65,14 @@ func (c *CacheOnReadFs) AllowOnlyCached() {
 	c.lock.Unlock()
 }
 
+// AllowPath allows the path to be opened, even if CacheOnReadFs is in the
+// opened only mode and it wasn't opened before
+func (c *CacheOnReadFs) AllowPath(path string) {
+	c.lock.Lock()
+	c.cached[path] = true
+	c.lock.Unlock()
+}
+
 // Open opens file and track the history of opened files
 // if CacheOnReadFs is in the opened only mode it should return
 // an error if file wasn't open before
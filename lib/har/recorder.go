@@ -0,0 +1,98 @@
+package har
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+)
+
+// Recorder writes the recorded entries to a HAR file as they come, so they
+// don't have to be kept in memory until the end of the test run. It's safe
+// for concurrent use.
+type Recorder struct {
+	sampleRate float64
+	vus        map[uint64]struct{}
+
+	mu      sync.Mutex
+	w       io.Writer
+	entries int
+	closed  bool
+	err     error
+}
+
+// NewRecorder returns a new Recorder that writes to w. Only the given share
+// of the requests is recorded, and only for the given VUs, if there are any.
+func NewRecorder(w io.Writer, creator Creator, sampleRate float64, vus []uint64) (*Recorder, error) {
+	if sampleRate <= 0 || sampleRate > 1 {
+		return nil, fmt.Errorf("the HAR sample rate should be in the (0, 1] range, but it's %v", sampleRate)
+	}
+
+	r := &Recorder{sampleRate: sampleRate, w: w}
+	if len(vus) > 0 {
+		r.vus = make(map[uint64]struct{}, len(vus))
+		for _, vu := range vus {
+			r.vus[vu] = struct{}{}
+		}
+	}
+
+	creatorJSON, err := json.Marshal(creator)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = fmt.Fprintf(w, `{"log":{"version":%q,"creator":%s,"pages":[],"entries":[`, Version, creatorJSON); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ShouldRecord returns whether the next request of the given VU should be
+// recorded.
+func (r *Recorder) ShouldRecord(vuID uint64) bool {
+	if r.vus != nil {
+		if _, ok := r.vus[vuID]; !ok {
+			return false
+		}
+	}
+	return r.sampleRate >= 1 || rand.Float64() < r.sampleRate //nolint:gosec
+}
+
+// Record writes the given entry. Any error is returned by Close, since there
+// is nothing the request itself could do about it.
+func (r *Recorder) Record(entry *Entry) {
+	data, err := json.Marshal(entry)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed || r.err != nil {
+		return
+	}
+	if err != nil {
+		r.err = err
+		return
+	}
+	if r.entries > 0 {
+		data = append([]byte{','}, data...)
+	}
+	if _, r.err = r.w.Write(data); r.err == nil {
+		r.entries++
+	}
+}
+
+// Close finishes the HAR file and returns the first error that happened
+// while writing it. Any entries recorded after that are discarded.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return errors.New("the HAR recorder is already closed")
+	}
+	r.closed = true
+	if r.err != nil {
+		return r.err
+	}
+	_, err := io.WriteString(r.w, "]}}\n")
+	return err
+}
@@ -0,0 +1,73 @@
+package har
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	r, err := NewRecorder(buf, Creator{Name: "k6", Version: "1.2.3"}, 1, nil)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 10; i++ {
+		wg.Add(1)
+		go func(vu uint64) {
+			defer wg.Done()
+			require.True(t, r.ShouldRecord(vu))
+			r.Record(&Entry{VU: vu, Request: &Request{Method: "GET"}, Response: &Response{Status: 200}})
+		}(uint64(i))
+	}
+	wg.Wait()
+	require.NoError(t, r.Close())
+	r.Record(&Entry{VU: 11})
+	require.Error(t, r.Close())
+
+	var result struct {
+		Log struct {
+			Version string
+			Creator Creator
+			Entries []map[string]interface{}
+		}
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	assert.Equal(t, Version, result.Log.Version)
+	assert.Equal(t, Creator{Name: "k6", Version: "1.2.3"}, result.Log.Creator)
+	require.Len(t, result.Log.Entries, 10)
+	vus := map[float64]bool{}
+	for _, entry := range result.Log.Entries {
+		vus[entry["_vu"].(float64)] = true //nolint:forcetypeassert
+	}
+	assert.Len(t, vus, 10)
+}
+
+func TestRecorderSampling(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewRecorder(&bytes.Buffer{}, Creator{}, 0, nil)
+	require.ErrorContains(t, err, "the HAR sample rate should be in the (0, 1] range")
+
+	r, err := NewRecorder(&bytes.Buffer{}, Creator{}, 1, []uint64{2, 3})
+	require.NoError(t, err)
+	assert.False(t, r.ShouldRecord(1))
+	assert.True(t, r.ShouldRecord(2))
+	assert.True(t, r.ShouldRecord(3))
+
+	r, err = NewRecorder(&bytes.Buffer{}, Creator{}, 0.5, nil)
+	require.NoError(t, err)
+	var recorded int
+	for i := 0; i < 1000; i++ {
+		if r.ShouldRecord(1) {
+			recorded++
+		}
+	}
+	assert.InDelta(t, 500, recorded, 150)
+}
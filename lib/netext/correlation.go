@@ -0,0 +1,70 @@
+package netext
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// MaxCorrelatedMessages is the maximum number of sent messages that are kept
+// while waiting for a reply with the same correlation ID, so the unanswered
+// ones don't pile up on long-lived connections.
+const MaxCorrelatedMessages = 10000
+
+// CorrelatedMessages keeps the time the messages with a correlation ID were
+// sent, until a message with the same ID is received. If an ID is sent again
+// before its reply is received, the round trip is measured from the latest
+// send. Once there are MaxCorrelatedMessages unanswered messages, the oldest
+// one is forgotten and a late reply to it isn't measured. The zero value is
+// ready to use and it's safe for concurrent use.
+type CorrelatedMessages struct {
+	mu    sync.Mutex
+	sent  map[string]*list.Element
+	order *list.List // of *correlatedMessage, the oldest first
+}
+
+type correlatedMessage struct {
+	id   string
+	sent time.Time
+}
+
+// Add keeps the time the message with the ID was sent.
+func (cm *CorrelatedMessages) Add(id string, t time.Time) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if cm.sent == nil {
+		cm.sent = make(map[string]*list.Element)
+		cm.order = list.New()
+	}
+	if e, ok := cm.sent[id]; ok {
+		cm.order.Remove(e)
+	} else if len(cm.sent) >= MaxCorrelatedMessages {
+		oldest := cm.order.Front()
+		cm.order.Remove(oldest)
+		delete(cm.sent, oldest.Value.(*correlatedMessage).id) //nolint:forcetypeassert
+	}
+	cm.sent[id] = cm.order.PushBack(&correlatedMessage{id: id, sent: t})
+}
+
+// Take returns the time the message with the ID was sent, and forgets it.
+func (cm *CorrelatedMessages) Take(id string) (time.Time, bool) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	e, ok := cm.sent[id]
+	if !ok {
+		return time.Time{}, false
+	}
+	cm.order.Remove(e)
+	delete(cm.sent, id)
+	return e.Value.(*correlatedMessage).sent, true //nolint:forcetypeassert
+}
+
+// Len returns the number of messages that are waiting for a reply.
+func (cm *CorrelatedMessages) Len() int {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	return len(cm.sent)
+}
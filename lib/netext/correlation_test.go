@@ -0,0 +1,62 @@
+package netext
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorrelatedMessages(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(1000, 0)
+
+	t.Run("take", func(t *testing.T) {
+		t.Parallel()
+
+		var cm CorrelatedMessages
+		_, ok := cm.Take("a")
+		assert.False(t, ok)
+
+		cm.Add("a", start)
+		sent, ok := cm.Take("a")
+		require.True(t, ok)
+		assert.Equal(t, start, sent)
+
+		_, ok = cm.Take("a")
+		assert.False(t, ok, "the message should be forgotten after its reply")
+		assert.Zero(t, cm.Len())
+	})
+
+	t.Run("reused ID", func(t *testing.T) {
+		t.Parallel()
+
+		var cm CorrelatedMessages
+		cm.Add("a", start)
+		cm.Add("a", start.Add(time.Second))
+		assert.Equal(t, 1, cm.Len())
+
+		sent, ok := cm.Take("a")
+		require.True(t, ok)
+		assert.Equal(t, start.Add(time.Second), sent)
+	})
+
+	t.Run("capped", func(t *testing.T) {
+		t.Parallel()
+
+		var cm CorrelatedMessages
+		for i := 0; i < MaxCorrelatedMessages+10; i++ {
+			cm.Add(strconv.Itoa(i), start.Add(time.Duration(i)*time.Millisecond))
+		}
+		assert.Equal(t, MaxCorrelatedMessages, cm.Len())
+
+		_, ok := cm.Take("9")
+		assert.False(t, ok, "the oldest messages should be forgotten")
+		sent, ok := cm.Take("10")
+		require.True(t, ok)
+		assert.Equal(t, start.Add(10*time.Millisecond), sent)
+	})
+}
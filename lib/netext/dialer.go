@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http/httptrace"
 	"strconv"
 	"sync/atomic"
 	"time"
@@ -25,6 +26,8 @@ type Dialer struct {
 
 	BytesRead    int64
 	BytesWritten int64
+
+	network atomic.Pointer[types.NetworkProfile]
 }
 
 // NewDialer constructs a new Dialer with the given DNS resolver.
@@ -57,18 +60,34 @@ func (b BlockedHostError) Error() string {
 
 // DialContext wraps the net.Dialer.DialContext and handles the k6 specifics
 func (d *Dialer) DialContext(ctx context.Context, proto, addr string) (net.Conn, error) {
-	dialAddr, err := d.getDialAddr(addr)
+	dialAddr, err := d.getDialAddr(ctx, addr)
 	if err != nil {
 		return nil, err
 	}
+	network := d.network.Load()
+	if network != nil {
+		if err = dialDelay(ctx, network); err != nil {
+			return nil, err
+		}
+	}
 	conn, err := d.Dialer.DialContext(ctx, proto, dialAddr)
 	if err != nil {
 		return nil, err
 	}
+	if network != nil {
+		conn = NewEmulatedConn(conn, *network)
+	}
 	conn = &Conn{conn, &d.BytesRead, &d.BytesWritten}
 	return conn, err
 }
 
+// SetNetworkProfile sets the network conditions emulated for the new
+// connections, nil disables the emulation. It returns whether the profile
+// has changed, in which case the idle connections should be closed.
+func (d *Dialer) SetNetworkProfile(profile *types.NetworkProfile) bool {
+	return d.network.Swap(profile) != profile
+}
+
 // IOSamples returns samples for data send and received since it last call and zeros out.
 // It uses the provided time as the sample time and tags and builtinMetrics to build the samples.
 func (d *Dialer) IOSamples(
@@ -98,8 +117,8 @@ func (d *Dialer) IOSamples(
 	})
 }
 
-func (d *Dialer) getDialAddr(addr string) (string, error) {
-	remote, err := d.findRemote(addr)
+func (d *Dialer) getDialAddr(ctx context.Context, addr string) (string, error) {
+	remote, err := d.findRemote(ctx, addr)
 	if err != nil {
 		return "", err
 	}
@@ -113,7 +132,7 @@ func (d *Dialer) getDialAddr(addr string) (string, error) {
 	return remote.String(), nil
 }
 
-func (d *Dialer) findRemote(addr string) (*types.Host, error) {
+func (d *Dialer) findRemote(ctx context.Context, addr string) (*types.Host, error) {
 	host, port, err := net.SplitHostPort(addr)
 	if err != nil {
 		return nil, err
@@ -137,7 +156,7 @@ func (d *Dialer) findRemote(addr string) (*types.Host, error) {
 		return types.NewHost(ip, port)
 	}
 
-	ip, err = d.Resolver.LookupIP(host)
+	ip, err = d.lookupIP(ctx, host)
 	if err != nil {
 		return nil, err
 	}
@@ -149,6 +168,24 @@ func (d *Dialer) findRemote(addr string) (*types.Host, error) {
 	return types.NewHost(ip, port)
 }
 
+// lookupIP resolves the host, and reports the DNS lookup to the
+// httptrace.ClientTrace of the context, since it doesn't happen in net.Dialer.
+func (d *Dialer) lookupIP(ctx context.Context, host string) (net.IP, error) {
+	trace := httptrace.ContextClientTrace(ctx)
+	if trace != nil && trace.DNSStart != nil {
+		trace.DNSStart(httptrace.DNSStartInfo{Host: host})
+	}
+	ip, err := d.Resolver.LookupIP(host)
+	if trace != nil && trace.DNSDone != nil {
+		info := httptrace.DNSDoneInfo{Err: err}
+		if ip != nil {
+			info.Addrs = []net.IPAddr{{IP: ip}}
+		}
+		trace.DNSDone(info)
+	}
+	return ip, err
+}
+
 func (d *Dialer) getConfiguredHost(addr, host, port string) (*types.Host, error) {
 	if remote := d.Hosts.Match(addr); remote != nil {
 		return remote, nil
@@ -1,6 +1,7 @@
 package netext
 
 import (
+	"context"
 	"net"
 	"testing"
 
@@ -62,7 +63,7 @@ func TestDialerAddr(t *testing.T) {
 
 		t.Run(tc.address, func(t *testing.T) {
 			t.Parallel()
-			addr, err := dialer.getDialAddr(tc.address)
+			addr, err := dialer.getDialAddr(context.Background(), tc.address)
 
 			if tc.expErr != "" {
 				require.EqualError(t, err, tc.expErr)
@@ -101,7 +102,7 @@ func TestDialerAddrBlockHostnamesStar(t *testing.T) {
 
 		t.Run(tc.address, func(t *testing.T) {
 			t.Parallel()
-			addr, err := dialer.getDialAddr(tc.address)
+			addr, err := dialer.getDialAddr(context.Background(), tc.address)
 
 			if tc.expErr != "" {
 				require.EqualError(t, err, tc.expErr)
@@ -134,7 +135,7 @@ func BenchmarkDialerHosts(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		for _, tc := range tcs {
 			//nolint:gosec,errcheck
-			dialer.getDialAddr(tc)
+			dialer.getDialAddr(context.Background(), tc)
 		}
 	}
 }
@@ -0,0 +1,47 @@
+package grpcext
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+)
+
+// The client-side load balancing policies supported by the connections.
+const (
+	// PickFirst sends all the requests to the first backend it can connect to.
+	PickFirst = "pick_first"
+	// RoundRobin spreads the requests over all the backends it's connected to.
+	RoundRobin = "round_robin"
+)
+
+// staticScheme is the scheme of the targets with a list of addresses.
+const staticScheme = "k6-static"
+
+// WithLoadBalancingPolicy returns the dial option to balance the requests
+// between the backends of a target with the given policy.
+func WithLoadBalancingPolicy(policy string) (grpc.DialOption, error) {
+	if policy != PickFirst && policy != RoundRobin {
+		return nil, fmt.Errorf("unsupported load balancing policy %q, it should be %s or %s",
+			policy, PickFirst, RoundRobin)
+	}
+	return grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"loadBalancingConfig":[{%q:{}}]}`, policy)), nil
+}
+
+// StaticTarget returns the target and the dial options to connect to a list
+// of addresses as a single connection, balanced by its policy. The authority
+// of the connection is the first address, so all of them should be valid for
+// the same TLS certificate.
+func StaticTarget(addrs []string) (string, []grpc.DialOption) {
+	r := manual.NewBuilderWithScheme(staticScheme)
+	state := resolver.State{Addresses: make([]resolver.Address, len(addrs))}
+	for i, addr := range addrs {
+		state.Addresses[i] = resolver.Address{Addr: addr}
+	}
+	r.InitialState(state)
+
+	target := staticScheme + ":///" + strings.Join(addrs, ",")
+	return target, []grpc.DialOption{grpc.WithResolvers(r), grpc.WithAuthority(addrs[0])}
+}
@@ -38,6 +38,10 @@ type InvokeRequest struct {
 	DiscardResponseMessage bool
 	Message                []byte
 	Metadata               metadata.MD
+	// State is the state of the VU making the request. It's needed for the
+	// connections shared by the VUs, otherwise the state of the VU which
+	// opened the connection is used.
+	State *lib.State
 }
 
 // InvokeResponse represents a gRPC response.
@@ -57,6 +61,8 @@ type StreamRequest struct {
 	DiscardResponseMessage bool
 	TagsAndMeta            *metrics.TagsAndMeta
 	Metadata               metadata.MD
+	// State is the state of the VU opening the stream, see InvokeRequest.
+	State *lib.State
 }
 
 type clientConnCloser interface {
@@ -67,6 +73,9 @@ type clientConnCloser interface {
 // Conn is a gRPC client connection.
 type Conn struct {
 	raw clientConnCloser
+	// release is set for the connections of a Pool, and it's called instead
+	// of closing the underlying connection.
+	release func() error
 }
 
 // DefaultOptions generates an option set
@@ -134,7 +143,7 @@ func (c *Conn) Invoke(
 		return nil, fmt.Errorf("unable to serialise request object to protocol buffer: %w", err)
 	}
 
-	ctx = withRPCState(ctx, &rpcState{tagsAndMeta: req.TagsAndMeta})
+	ctx = withRPCState(ctx, &rpcState{tagsAndMeta: req.TagsAndMeta, state: req.State})
 
 	var resp *dynamicpb.Message
 	if req.DiscardResponseMessage {
@@ -193,7 +202,7 @@ func (c *Conn) NewStream(
 ) (*Stream, error) {
 	ctx = metadata.NewOutgoingContext(ctx, req.Metadata)
 
-	ctx = withRPCState(ctx, &rpcState{tagsAndMeta: req.TagsAndMeta})
+	ctx = withRPCState(ctx, &rpcState{tagsAndMeta: req.TagsAndMeta, state: req.State})
 
 	stream, err := c.raw.NewStream(ctx, &grpc.StreamDesc{
 		StreamName:    string(req.MethodDescriptor.Name()),
@@ -212,8 +221,11 @@ func (c *Conn) NewStream(
 	}, nil
 }
 
-// Close closes the underhood connection.
+// Close closes the underhood connection, or releases it if it's shared.
 func (c *Conn) Close() error {
+	if c.release != nil {
+		return c.release()
+	}
 	return c.raw.Close()
 }
 
@@ -249,6 +261,9 @@ func (h statsHandler) HandleRPC(ctx context.Context, stat grpcstats.RPCStats) {
 		ctm := state.Tags.GetCurrentValues()
 		stateRPC = &rpcState{tagsAndMeta: &ctm}
 	}
+	if stateRPC.state != nil {
+		state = stateRPC.state
+	}
 
 	switch s := stat.(type) {
 	case *grpcstats.OutHeader:
@@ -258,6 +273,10 @@ func (h statsHandler) HandleRPC(ctx context.Context, stat grpcstats.RPCStats) {
 				stateRPC.tagsAndMeta.SetSystemTagOrMeta(metrics.TagIP, ip)
 			}
 		}
+		// the backend chosen by the load balancing policy of the connection
+		if state.Options.SystemTags.Has(metrics.TagBackend) && s.RemoteAddr != nil {
+			stateRPC.tagsAndMeta.SetSystemTagOrMeta(metrics.TagBackend, s.RemoteAddr.String())
+		}
 	case *grpcstats.End:
 		if state.Options.SystemTags.Has(metrics.TagStatus) {
 			stateRPC.tagsAndMeta.SetSystemTagOrMeta(metrics.TagStatus, strconv.Itoa(int(status.Code(s.Error))))
@@ -353,6 +372,7 @@ var ctxKeyRPCState = contextKey("rpcState") //nolint:gochecknoglobals
 
 type rpcState struct {
 	tagsAndMeta *metrics.TagsAndMeta
+	state       *lib.State
 }
 
 func withRPCState(ctx context.Context, rpcState *rpcState) context.Context {
@@ -0,0 +1,82 @@
+package grpcext
+
+import (
+	"context"
+	"sync"
+)
+
+// Pool is a set of connections which are shared by the VUs. A connection
+// is dialed by the first VU which gets it, and it's closed when the last VU
+// which got it closes it.
+type Pool struct {
+	mu    sync.Mutex
+	conns map[string]*pooledConn
+}
+
+type pooledConn struct {
+	conn  *Conn
+	err   error
+	ready chan struct{}
+	refs  int
+}
+
+// NewPool returns an empty pool.
+func NewPool() *Pool {
+	return &Pool{conns: make(map[string]*pooledConn)}
+}
+
+// Get returns the connection with the given key, which is dialed with the
+// dial function if it isn't in the pool yet. The returned connection has to
+// be closed, when it isn't used anymore.
+func (p *Pool) Get(ctx context.Context, key string, dial func(context.Context) (*Conn, error)) (*Conn, error) {
+	p.mu.Lock()
+	pc, found := p.conns[key]
+	if !found {
+		pc = &pooledConn{ready: make(chan struct{})}
+		p.conns[key] = pc
+	}
+	pc.refs++
+	p.mu.Unlock()
+
+	if !found {
+		conn, err := dial(ctx)
+		p.mu.Lock()
+		pc.conn, pc.err = conn, err
+		if err != nil {
+			delete(p.conns, key)
+		}
+		p.mu.Unlock()
+		close(pc.ready)
+	}
+
+	select {
+	case <-pc.ready:
+	case <-ctx.Done():
+		_ = p.release(key, pc)
+		return nil, ctx.Err()
+	}
+	if pc.err != nil {
+		return nil, pc.err
+	}
+
+	return &Conn{
+		raw:     pc.conn.raw,
+		release: func() error { return p.release(key, pc) },
+	}, nil
+}
+
+// release releases a reference to the connection, and closes it if it was
+// the last one.
+func (p *Pool) release(key string, pc *pooledConn) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pc.refs--
+	if pc.refs > 0 || pc.conn == nil {
+		return nil
+	}
+	if p.conns[key] == pc {
+		delete(p.conns, key)
+	}
+	return pc.conn.raw.Close()
+}
@@ -0,0 +1,69 @@
+package grpcext
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+type closeCounter struct {
+	grpc.ClientConnInterface
+	closed *atomic.Int64
+}
+
+func (c closeCounter) Close() error {
+	c.closed.Add(1)
+	return nil
+}
+
+func TestPool(t *testing.T) {
+	t.Parallel()
+
+	var dialed, closed atomic.Int64
+	dial := func(context.Context) (*Conn, error) {
+		dialed.Add(1)
+		return &Conn{raw: closeCounter{closed: &closed}}, nil
+	}
+
+	pool := NewPool()
+	var wg sync.WaitGroup
+	conns := make([]*Conn, 10)
+	for i := range conns {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			conns[i], err = pool.Get(context.Background(), "key", dial)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int64(1), dialed.Load())
+
+	for _, conn := range conns[1:] {
+		require.NoError(t, conn.Close())
+	}
+	assert.Equal(t, int64(0), closed.Load())
+	require.NoError(t, conns[0].Close())
+	assert.Equal(t, int64(1), closed.Load())
+
+	// the connection is dialed again after it has been closed
+	conn, err := pool.Get(context.Background(), "key", dial)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), dialed.Load())
+	require.NoError(t, conn.Close())
+
+	// a failed dial isn't kept in the pool
+	dialErr := errors.New("unavailable")
+	_, err = pool.Get(context.Background(), "failing", func(context.Context) (*Conn, error) { return nil, dialErr })
+	require.ErrorIs(t, err, dialErr)
+	conn, err = pool.Get(context.Background(), "failing", dial)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+}
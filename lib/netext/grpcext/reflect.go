@@ -7,6 +7,8 @@ import (
 	"github.com/jhump/protoreflect/desc"
 	"github.com/jhump/protoreflect/grpcreflect"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/descriptorpb"
 )
 
@@ -21,7 +23,12 @@ func (rc *reflectionClient) Reflect(ctx context.Context) (*descriptorpb.FileDesc
 	client := grpcreflect.NewClientAuto(ctx, rc.Conn)
 	client.AllowMissingFileDescriptors()
 
+	// the client uses the reflection v1, and falls back to v1alpha if the
+	// server doesn't implement it
 	services, err := client.ListServices()
+	if status.Code(err) == codes.Unimplemented {
+		return nil, fmt.Errorf("the server doesn't support the reflection v1 nor v1alpha: %w", err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("can't list services: %w", err)
 	}
@@ -10,6 +10,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/dynamicpb"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -28,25 +29,27 @@ type Stream struct {
 // ErrCanceled canceled by client (k6)
 var ErrCanceled = errors.New("canceled by client (k6)")
 
-// ReceiveConverted receives a converted message from the stream
+// ReceiveConverted receives a converted message from the stream, and the
+// size of its protobuf encoding.
 // if the stream has been closed successfully, it returns io.EOF
 // if the stream has been cancelled, it returns ErrCanceled
-func (s *Stream) ReceiveConverted() (interface{}, error) {
+func (s *Stream) ReceiveConverted() (interface{}, int, error) {
 	raw, err := s.receive()
 	if err != nil && !errors.Is(err, io.EOF) {
-		return nil, err
+		return nil, 0, err
 	}
 
+	size := proto.Size(raw)
 	if s.discardResponseMessage {
-		return struct{}{}, err
+		return struct{}{}, size, err
 	}
 
 	msg, errConv := convert(s.marshaler, raw)
 	if errConv != nil {
-		return nil, errConv
+		return nil, 0, errConv
 	}
 
-	return msg, err
+	return msg, size, err
 }
 
 func (s *Stream) receive() (msg *dynamicpb.Message, err error) {
@@ -122,12 +125,13 @@ func (s *Stream) buildMessage(b []byte) (*dynamicpb.Message, error) {
 	return msg, nil
 }
 
-// Send sends the message to the stream
-func (s *Stream) Send(b []byte) error {
+// Send sends the message to the stream, and returns the size of its protobuf
+// encoding.
+func (s *Stream) Send(b []byte) (int, error) {
 	msg, err := s.buildMessage(b)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return s.raw.SendMsg(msg)
+	return proto.Size(msg), s.raw.SendMsg(msg)
 }
@@ -0,0 +1,231 @@
+package httpext
+
+import (
+	"context"
+	"encoding/base64"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/har"
+	"go.k6.io/k6/metrics"
+)
+
+// harExchange is a request and its response, as they are recorded in a HAR
+// entry.
+type harExchange struct {
+	url      *url.URL
+	request  *Request
+	response *Response
+	start    time.Time
+}
+
+// recordHAREntries records the request and its response with the HAR recorder
+// of the VU, if there is one and the request is sampled. Each of the previous
+// requests, e.g. the redirects, is recorded as a separate entry before the
+// last one, which then has the URL and headers of the final request.
+func recordHAREntries(
+	ctx context.Context, state *lib.State, tagsAndMeta *metrics.TagsAndMeta,
+	exchange harExchange, previous []*finishedRequest, last *finishedRequest,
+) {
+	recorder := state.HARRecorder
+	if recorder == nil || !recorder.ShouldRecord(state.VUID) {
+		return
+	}
+
+	for _, hop := range previous {
+		recordHAREntry(ctx, state, tagsAndMeta, newHARHop(hop, exchange.request, nil))
+	}
+	if len(previous) > 0 && last != nil {
+		exchange = newHARHop(last, exchange.request, exchange.response)
+	}
+	recordHAREntry(ctx, state, tagsAndMeta, exchange)
+}
+
+// newHARHop returns the exchange of a single request of a chain, e.g. of
+// redirects. The given response is used if it's the last request, since its
+// body has been read, and a new one is made for the previous requests.
+func newHARHop(hop *finishedRequest, origReq *Request, resp *Response) harExchange {
+	req := &Request{
+		Method:  hop.request.Method,
+		URL:     hop.request.URL.String(),
+		Headers: hop.request.Header,
+		Cookies: stdCookiesToHTTPRequestCookies(hop.request.Cookies()),
+	}
+	if hop.request.ContentLength != 0 {
+		req.Body = origReq.Body // only 307 and 308 redirects resend the body
+	}
+	if resp == nil {
+		resp = &Response{URL: req.URL}
+		updateK6Response(resp, hop)
+		if hop.response != nil {
+			setK6ResponseFields(resp, hop.response)
+		}
+	}
+	trail := hop.trail
+	start := trail.EndTime.Add(-(trail.Blocked + trail.ConnDuration + trail.Duration))
+	return harExchange{url: hop.request.URL, request: req, response: resp, start: start}
+}
+
+func recordHAREntry(ctx context.Context, state *lib.State, tagsAndMeta *metrics.TagsAndMeta, exchange harExchange) {
+	resp := exchange.response
+	entry := &har.Entry{
+		StartedDateTime: exchange.start,
+		Request:         newHARRequest(exchange.url, exchange.request, resp.Proto),
+		Response:        newHARResponse(resp),
+		Timings:         newHARTimings(resp.Timings),
+		ServerIPAddress: resp.RemoteIP,
+		VU:              state.VUID,
+		Iteration:       state.Iteration,
+		Error:           resp.Error,
+		ErrorCode:       resp.ErrorCode,
+	}
+	for _, t := range []float64{
+		entry.Timings.Blocked, entry.Timings.Connect, entry.Timings.Send, entry.Timings.Wait, entry.Timings.Receive,
+	} {
+		if t > 0 {
+			entry.Time += t
+		}
+	}
+	if scenarioState := lib.GetScenarioState(ctx); scenarioState != nil {
+		entry.Scenario = scenarioState.Name
+	}
+	if group, ok := tagsAndMeta.Tags.Get(metrics.TagGroup.String()); ok {
+		entry.Group = group
+	}
+
+	state.HARRecorder.Record(entry)
+}
+
+func newHARRequest(reqURL *url.URL, respReq *Request, proto string) *har.Request {
+	req := &har.Request{
+		Method:      respReq.Method,
+		URL:         respReq.URL,
+		HTTPVersion: proto,
+		Cookies:     []har.Cookie{},
+		Headers:     []har.NameValue{},
+		QueryString: []har.NameValue{},
+		HeadersSize: -1,
+		BodySize:    int64(len(respReq.Body)),
+	}
+
+	for _, name := range sortedKeys(respReq.Headers) {
+		for _, value := range respReq.Headers[name] {
+			req.Headers = append(req.Headers, har.NameValue{Name: name, Value: value})
+		}
+	}
+	for _, name := range sortedKeys(respReq.Cookies) {
+		for _, cookie := range respReq.Cookies[name] {
+			req.Cookies = append(req.Cookies, har.Cookie{Name: cookie.Name, Value: cookie.Value})
+		}
+	}
+	query := reqURL.Query()
+	for _, name := range sortedKeys(query) {
+		for _, value := range query[name] {
+			req.QueryString = append(req.QueryString, har.NameValue{Name: name, Value: value})
+		}
+	}
+	if respReq.Body != "" {
+		var mimeType string
+		if values := respReq.Headers["Content-Type"]; len(values) > 0 {
+			mimeType = values[0]
+		}
+		req.PostData = &har.PostData{MimeType: mimeType, Text: respReq.Body}
+	}
+
+	return req
+}
+
+func newHARResponse(resp *Response) *har.Response {
+	harResp := &har.Response{
+		Status:      resp.Status,
+		StatusText:  strings.TrimPrefix(resp.StatusText, strconv.Itoa(resp.Status)+" "),
+		HTTPVersion: resp.Proto,
+		Cookies:     []har.Cookie{},
+		Headers:     []har.NameValue{},
+		Content:     har.Content{MimeType: resp.Headers["Content-Type"]},
+		RedirectURL: resp.Headers["Location"],
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+
+	for _, name := range sortedKeys(resp.Headers) {
+		harResp.Headers = append(harResp.Headers, har.NameValue{Name: name, Value: resp.Headers[name]})
+	}
+	for _, name := range sortedKeys(resp.Cookies) {
+		for _, cookie := range resp.Cookies[name] {
+			harCookie := har.Cookie{
+				Name:     cookie.Name,
+				Value:    cookie.Value,
+				Path:     cookie.Path,
+				Domain:   cookie.Domain,
+				HTTPOnly: cookie.HTTPOnly,
+				Secure:   cookie.Secure,
+			}
+			if cookie.Expires > 0 {
+				expires := time.UnixMilli(cookie.Expires)
+				harCookie.Expires = &expires
+			}
+			harResp.Cookies = append(harResp.Cookies, harCookie)
+		}
+	}
+
+	// The body isn't available if it was discarded.
+	switch body := resp.Body.(type) {
+	case string:
+		harResp.Content.Text = body
+		harResp.Content.Size = int64(len(body))
+	case []byte:
+		if utf8.Valid(body) {
+			harResp.Content.Text = string(body)
+		} else {
+			harResp.Content.Text = base64.StdEncoding.EncodeToString(body)
+			harResp.Content.Encoding = "base64"
+		}
+		harResp.Content.Size = int64(len(body))
+	}
+	if harResp.Content.Size > 0 {
+		harResp.BodySize = harResp.Content.Size
+	}
+
+	return harResp
+}
+
+// newHARTimings maps the timings of a response onto the HAR timing phases.
+// The DNS lookup is a part of blocked in k6, so it isn't added to the time
+// of the entry, and as the HAR spec says, the TLS handshake is included both
+// in the connect and ssl phases.
+func newHARTimings(timings ResponseTimings) har.Timings {
+	harTimings := har.Timings{
+		Blocked: timings.Blocked,
+		DNS:     -1,
+		Connect: -1,
+		Send:    timings.Sending,
+		Wait:    timings.Waiting,
+		Receive: timings.Receiving,
+		SSL:     -1,
+	}
+	if timings.LookingUp > 0 {
+		harTimings.DNS = timings.LookingUp
+	}
+	if timings.Connecting > 0 || timings.TLSHandshaking > 0 {
+		harTimings.Connect = timings.Connecting + timings.TLSHandshaking
+	}
+	if timings.TLSHandshaking > 0 {
+		harTimings.SSL = timings.TLSHandshaking
+	}
+	return harTimings
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,190 @@
+package httpext
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/har"
+	"go.k6.io/k6/metrics"
+)
+
+func TestMakeRequestHAR(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("created"))
+	}))
+	t.Cleanup(srv.Close)
+
+	buf := &bytes.Buffer{}
+	recorder, err := har.NewRecorder(buf, har.Creator{Name: "k6"}, 1, nil)
+	require.NoError(t, err)
+
+	registry := metrics.NewRegistry()
+	state := &lib.State{
+		Options:        lib.Options{SystemTags: &metrics.DefaultSystemTagSet},
+		Transport:      srv.Client().Transport,
+		Samples:        make(chan metrics.SampleContainer, 10),
+		Logger:         logrus.New(),
+		BufferPool:     lib.NewBufferPool(),
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet().With("group", "::my group")),
+		HARRecorder:    recorder,
+		VUID:           3,
+		Iteration:      7,
+	}
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/path?b=2&a=1", nil)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	preq := &ParsedHTTPRequest{
+		Req:          req,
+		URL:          &URL{u: req.URL, URL: req.URL.String()},
+		Body:         bytes.NewBufferString(`{"key":"value"}`),
+		Timeout:      10 * time.Second,
+		ResponseType: ResponseTypeText,
+		TagsAndMeta:  state.Tags.GetCurrentValues(),
+	}
+	ctx := lib.WithScenarioState(context.Background(), &lib.ScenarioState{Name: "my_scenario"})
+
+	res, err := MakeRequest(ctx, state, preq)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, res.Status)
+	require.NoError(t, recorder.Close())
+
+	var result struct {
+		Log struct {
+			Entries []*har.Entry
+		}
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	require.Len(t, result.Log.Entries, 1)
+	entry := result.Log.Entries[0]
+
+	assert.Equal(t, uint64(3), entry.VU)
+	assert.Equal(t, int64(7), entry.Iteration)
+	assert.Equal(t, "my_scenario", entry.Scenario)
+	assert.Equal(t, "::my group", entry.Group)
+	assert.Equal(t, "127.0.0.1", entry.ServerIPAddress)
+	assert.WithinDuration(t, time.Now(), entry.StartedDateTime, 10*time.Second)
+	assert.Greater(t, entry.Time, 0.0)
+
+	assert.Equal(t, http.MethodPost, entry.Request.Method)
+	assert.Equal(t, "HTTP/1.1", entry.Request.HTTPVersion)
+	assert.Equal(t, []har.NameValue{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}}, entry.Request.QueryString)
+	assert.Contains(t, entry.Request.Headers, har.NameValue{Name: "Content-Type", Value: "application/json"})
+	assert.Equal(t, &har.PostData{MimeType: "application/json", Text: `{"key":"value"}`}, entry.Request.PostData)
+	assert.Equal(t, int64(15), entry.Request.BodySize)
+
+	assert.Equal(t, http.StatusCreated, entry.Response.Status)
+	assert.Equal(t, "Created", entry.Response.StatusText)
+	assert.Equal(t, har.Content{Size: 7, MimeType: "text/plain", Text: "created"}, entry.Response.Content)
+	require.Len(t, entry.Response.Cookies, 1)
+	assert.Equal(t, "session", entry.Response.Cookies[0].Name)
+
+	assert.Equal(t, -1.0, entry.Timings.DNS)
+	assert.Greater(t, entry.Timings.Connect, 0.0)
+	assert.Greater(t, entry.Timings.SSL, 0.0)
+	assert.GreaterOrEqual(t, entry.Timings.Connect, entry.Timings.SSL)
+}
+
+func TestMakeRequestHARRedirects(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/final?c=3", http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("done"))
+	}))
+	t.Cleanup(srv.Close)
+
+	buf := &bytes.Buffer{}
+	recorder, err := har.NewRecorder(buf, har.Creator{Name: "k6"}, 1, nil)
+	require.NoError(t, err)
+
+	registry := metrics.NewRegistry()
+	state := &lib.State{
+		Options:        lib.Options{SystemTags: &metrics.DefaultSystemTagSet},
+		Transport:      srv.Client().Transport,
+		Samples:        make(chan metrics.SampleContainer, 10),
+		Logger:         logrus.New(),
+		BufferPool:     lib.NewBufferPool(),
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+		HARRecorder:    recorder,
+	}
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/redirect?a=1", nil)
+	require.NoError(t, err)
+	preq := &ParsedHTTPRequest{
+		Req:          req,
+		URL:          &URL{u: req.URL, URL: req.URL.String()},
+		Body:         bytes.NewBufferString("data"),
+		Timeout:      10 * time.Second,
+		ResponseType: ResponseTypeText,
+		Redirects:    null.IntFrom(10),
+		TagsAndMeta:  state.Tags.GetCurrentValues(),
+	}
+
+	res, err := MakeRequest(context.Background(), state, preq)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.Status)
+	require.NoError(t, recorder.Close())
+
+	var result struct {
+		Log struct {
+			Entries []*har.Entry
+		}
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	require.Len(t, result.Log.Entries, 2)
+
+	redirect := result.Log.Entries[0]
+	assert.Equal(t, http.MethodPost, redirect.Request.Method)
+	assert.Equal(t, srv.URL+"/redirect?a=1", redirect.Request.URL)
+	assert.Equal(t, &har.PostData{Text: "data"}, redirect.Request.PostData)
+	assert.Equal(t, http.StatusFound, redirect.Response.Status)
+	assert.Equal(t, "/final?c=3", redirect.Response.RedirectURL)
+	assert.Greater(t, redirect.Time, 0.0)
+
+	final := result.Log.Entries[1]
+	assert.Equal(t, http.MethodGet, final.Request.Method)
+	assert.Equal(t, srv.URL+"/final?c=3", final.Request.URL)
+	assert.Equal(t, []har.NameValue{{Name: "c", Value: "3"}}, final.Request.QueryString)
+	assert.Nil(t, final.Request.PostData)
+	assert.Equal(t, http.StatusOK, final.Response.Status)
+	assert.Equal(t, "done", final.Response.Content.Text)
+	assert.False(t, final.StartedDateTime.Before(redirect.StartedDateTime))
+}
+
+func TestNewHARTimings(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t,
+		har.Timings{Blocked: 1, DNS: -1, Connect: 5, Send: 4, Wait: 5, Receive: 6, SSL: 3},
+		newHARTimings(ResponseTimings{Blocked: 1, Connecting: 2, TLSHandshaking: 3, Sending: 4, Waiting: 5, Receiving: 6}),
+	)
+	assert.Equal(t,
+		har.Timings{Blocked: 1, DNS: -1, Connect: -1, Send: 4, Wait: 5, Receive: 6, SSL: -1},
+		newHARTimings(ResponseTimings{Blocked: 1, Sending: 4, Waiting: 5, Receiving: 6}),
+	)
+	assert.Equal(t,
+		har.Timings{Blocked: 8, DNS: 7, Connect: -1, Send: 4, Wait: 5, Receive: 6, SSL: -1},
+		newHARTimings(ResponseTimings{Blocked: 8, LookingUp: 7, Sending: 4, Waiting: 5, Receiving: 6}),
+	)
+}
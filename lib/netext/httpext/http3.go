@@ -0,0 +1,186 @@
+package httpext
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+
+	"go.k6.io/k6/lib/netext"
+)
+
+// HTTP3Transport is an http.RoundTripper that sends the requests over HTTP/3.
+//
+// The HTTP/3 client doesn't support httptrace, so HTTP3Transport calls the
+// httptrace.ClientTrace hooks of the requests itself, which allows the Tracer
+// to measure them the same way as HTTP/1.1 and HTTP/2 requests. As the TLS
+// handshake is a part of the QUIC handshake, it's measured as connecting.
+type HTTP3Transport struct {
+	roundTripper *http3.RoundTripper
+	dialer       *netext.Dialer
+
+	connsLock sync.Mutex
+	conns     map[string]quic.Connection
+}
+
+var _ http.RoundTripper = &HTTP3Transport{}
+
+// NewHTTP3Transport returns a new HTTP3Transport, which dials the QUIC
+// connections with the given dialer.
+func NewHTTP3Transport(dialer *netext.Dialer, tlsConfig *tls.Config) *HTTP3Transport {
+	t := &HTTP3Transport{
+		dialer: dialer,
+		conns:  make(map[string]quic.Connection),
+	}
+	t.roundTripper = &http3.RoundTripper{
+		DisableCompression: true,
+		TLSClientConfig:    tlsConfig,
+		Dial:               t.dial,
+	}
+	return t
+}
+
+type http3RequestKey struct{}
+
+// http3Request keeps track of a single request made through HTTP3Transport.
+type http3Request struct {
+	dialed bool
+}
+
+func (t *HTTP3Transport) dial(
+	ctx context.Context, addr string, tlsConf *tls.Config, conf *quic.Config,
+) (quic.EarlyConnection, error) {
+	trace := httptrace.ContextClientTrace(ctx)
+	if req, ok := ctx.Value(http3RequestKey{}).(*http3Request); ok {
+		req.dialed = true
+	}
+	if trace != nil && trace.ConnectStart != nil {
+		trace.ConnectStart("udp", addr)
+	}
+
+	conn, err := t.dialer.DialQUIC(ctx, addr, tlsConf, conf)
+	if err == nil {
+		select {
+		case <-conn.HandshakeComplete():
+		case <-conn.Context().Done():
+			err = context.Cause(conn.Context())
+		case <-ctx.Done():
+			_ = conn.CloseWithError(0, "")
+			err = ctx.Err()
+		}
+	}
+	if trace != nil && trace.ConnectDone != nil {
+		trace.ConnectDone("udp", addr, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t.connsLock.Lock()
+	t.conns[addr] = conn
+	t.connsLock.Unlock()
+	go func() {
+		<-conn.Context().Done()
+		t.connsLock.Lock()
+		if t.conns[addr] == conn {
+			delete(t.conns, addr)
+		}
+		t.connsLock.Unlock()
+	}()
+
+	return conn, nil
+}
+
+// RoundTrip is the implementation of http.RoundTripper
+func (t *HTTP3Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	trace := httptrace.ContextClientTrace(req.Context())
+	if trace == nil {
+		return t.roundTripper.RoundTrip(req)
+	}
+
+	addr := http3AuthorityAddr(req)
+	if trace.GetConn != nil {
+		trace.GetConn(addr)
+	}
+
+	state := &http3Request{}
+	req = req.WithContext(context.WithValue(req.Context(), http3RequestKey{}, state))
+	hasBody := req.Body != nil && req.Body != http.NoBody
+	if hasBody && trace.WroteRequest != nil {
+		req.Body = &http3RequestBody{ReadCloser: req.Body, done: func() {
+			trace.WroteRequest(httptrace.WroteRequestInfo{})
+		}}
+	}
+
+	resp, err := t.roundTripper.RoundTripOpt(req, http3.RoundTripOpt{
+		// This is called once the connection is ready, right before the
+		// request is sent to the server.
+		CheckSettings: func(http3.Settings) error {
+			if trace.GotConn != nil {
+				t.connsLock.Lock()
+				conn := t.conns[addr]
+				t.connsLock.Unlock()
+				if conn != nil {
+					trace.GotConn(httptrace.GotConnInfo{Conn: http3Conn{conn: conn}, Reused: !state.dialed})
+				}
+			}
+			if !hasBody && trace.WroteRequest != nil {
+				trace.WroteRequest(httptrace.WroteRequestInfo{})
+			}
+			return nil
+		},
+	})
+	if err == nil && trace.GotFirstResponseByte != nil {
+		trace.GotFirstResponseByte()
+	}
+	return resp, err
+}
+
+// CloseIdleConnections closes all QUIC connections that aren't in use.
+func (t *HTTP3Transport) CloseIdleConnections() {
+	t.roundTripper.CloseIdleConnections()
+}
+
+// http3AuthorityAddr returns the host:port address the HTTP/3 client dials
+// for the given request.
+func http3AuthorityAddr(req *http.Request) string {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		return net.JoinHostPort(host, "443")
+	}
+	return host
+}
+
+// http3Conn exposes the addresses of a QUIC connection as a net.Conn, so it
+// can be passed to the httptrace.GotConn hook. It can't be used for I/O.
+type http3Conn struct {
+	net.Conn
+	conn quic.Connection
+}
+
+func (c http3Conn) LocalAddr() net.Addr  { return c.conn.LocalAddr() }
+func (c http3Conn) RemoteAddr() net.Addr { return c.conn.RemoteAddr() }
+
+// http3RequestBody calls done once the whole request body has been sent.
+type http3RequestBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *http3RequestBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF { //nolint:errorlint
+		b.once.Do(b.done)
+	}
+	return n, err
+}
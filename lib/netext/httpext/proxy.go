@@ -0,0 +1,25 @@
+package httpext
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+type proxyKey struct{}
+
+// withProxy returns a copy of the context with the proxy that should be used
+// for the requests made with it.
+func withProxy(ctx context.Context, proxy *url.URL) context.Context {
+	return context.WithValue(ctx, proxyKey{}, proxy)
+}
+
+// ProxyFromRequest returns the proxy that was set for the request by
+// MakeRequest or, if there is none, the one from the HTTP_PROXY-style
+// environment variables. It's meant to be used as http.Transport.Proxy.
+func ProxyFromRequest(req *http.Request) (*url.URL, error) {
+	if proxy, ok := req.Context().Value(proxyKey{}).(*url.URL); ok {
+		return proxy, nil
+	}
+	return http.ProxyFromEnvironment(req)
+}
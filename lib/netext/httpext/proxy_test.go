@@ -0,0 +1,253 @@
+package httpext
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+const testProxyDelay = 50 * time.Millisecond
+
+// newTestConnectProxy starts an HTTP proxy that only supports CONNECT
+// requests with the basic auth credentials user:pass.
+func newTestConnectProxy(t *testing.T, used *int64) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expectedAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:pass"))
+		if r.Method != http.MethodConnect || r.Header.Get("Proxy-Authorization") != expectedAuth {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		target, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		atomic.AddInt64(used, 1)
+		time.Sleep(testProxyDelay)
+		w.WriteHeader(http.StatusOK)
+
+		conn, _, err := w.(http.Hijacker).Hijack() //nolint:forcetypeassert
+		if err != nil {
+			_ = target.Close()
+			return
+		}
+		go pipeTestConns(conn, target)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newTestSOCKS5Proxy starts a SOCKS5 proxy that only supports the CONNECT
+// command with the username and password credentials user:pass.
+func newTestSOCKS5Proxy(t *testing.T, used *int64) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				target, err := handshakeTestSOCKS5(conn)
+				if err != nil {
+					_ = conn.Close()
+					return
+				}
+				atomic.AddInt64(used, 1)
+				go pipeTestConns(conn, target)
+			}()
+		}
+	}()
+	return l
+}
+
+func handshakeTestSOCKS5(conn net.Conn) (net.Conn, error) {
+	r := bufio.NewReader(conn)
+	// greeting: version, number of methods, methods
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(r, make([]byte, header[1])); err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write([]byte{5, 2}); err != nil { // username/password auth
+		return nil, err
+	}
+
+	// auth: version, username length, username, password length, password
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	user := make([]byte, header[1])
+	if _, err := io.ReadFull(r, user); err != nil {
+		return nil, err
+	}
+	passLen, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	pass := make([]byte, passLen)
+	if _, err = io.ReadFull(r, pass); err != nil {
+		return nil, err
+	}
+	if string(user) != "user" || string(pass) != "pass" {
+		_, _ = conn.Write([]byte{1, 1})
+		return nil, io.ErrUnexpectedEOF
+	}
+	if _, err = conn.Write([]byte{1, 0}); err != nil {
+		return nil, err
+	}
+
+	// request: version, command, reserved, address type, address, port
+	request := make([]byte, 4)
+	if _, err = io.ReadFull(r, request); err != nil {
+		return nil, err
+	}
+	var host string
+	switch request[3] {
+	case 1: // IPv4
+		ip := make([]byte, 4)
+		if _, err = io.ReadFull(r, ip); err != nil {
+			return nil, err
+		}
+		host = net.IP(ip).String()
+	case 3: // domain name
+		length, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		name := make([]byte, length)
+		if _, err = io.ReadFull(r, name); err != nil {
+			return nil, err
+		}
+		host = string(name)
+	default:
+		return nil, io.ErrUnexpectedEOF
+	}
+	port := make([]byte, 2)
+	if _, err = io.ReadFull(r, port); err != nil {
+		return nil, err
+	}
+
+	target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))))
+	if err != nil {
+		return nil, err
+	}
+	time.Sleep(testProxyDelay)
+	if _, err = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}); err != nil {
+		_ = target.Close()
+		return nil, err
+	}
+	return target, nil
+}
+
+func pipeTestConns(a, b net.Conn) {
+	defer func() { _ = a.Close() }()
+	defer func() { _ = b.Close() }()
+	go func() { _, _ = io.Copy(a, b) }()
+	_, _ = io.Copy(b, a)
+}
+
+func TestMakeRequestProxy(t *testing.T) {
+	t.Parallel()
+
+	target := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(target.Close)
+
+	var connectProxyUsed, socksProxyUsed int64
+	connectProxy := newTestConnectProxy(t, &connectProxyUsed)
+	socksProxy := newTestSOCKS5Proxy(t, &socksProxyUsed)
+	connectProxyURL, err := url.Parse(connectProxy.URL)
+	require.NoError(t, err)
+	connectProxyURL.User = url.UserPassword("user", "pass")
+
+	testCases := []struct {
+		name     string
+		proxy    *url.URL
+		used     *int64
+		errorMsg string
+	}{
+		{name: "connect", proxy: connectProxyURL, used: &connectProxyUsed},
+		{
+			name:  "socks5",
+			proxy: &url.URL{Scheme: "socks5", Host: socksProxy.Addr().String(), User: url.UserPassword("user", "pass")},
+			used:  &socksProxyUsed,
+		},
+		{
+			name:     "connect with wrong credentials",
+			proxy:    &url.URL{Scheme: "http", Host: connectProxyURL.Host, User: url.UserPassword("user", "wrong")},
+			errorMsg: "Proxy Authentication Required",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			transport := target.Client().Transport.(*http.Transport).Clone() //nolint:forcetypeassert
+			transport.Proxy = ProxyFromRequest
+			samples := make(chan metrics.SampleContainer, 10)
+			registry := metrics.NewRegistry()
+			state := &lib.State{
+				Options:        lib.Options{SystemTags: &metrics.DefaultSystemTagSet},
+				Transport:      transport,
+				Samples:        samples,
+				Logger:         logrus.New(),
+				BufferPool:     lib.NewBufferPool(),
+				BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+				Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+			}
+			req, err := http.NewRequest(http.MethodGet, target.URL, nil)
+			require.NoError(t, err)
+			preq := &ParsedHTTPRequest{
+				Req:         req,
+				URL:         &URL{u: req.URL, URL: target.URL},
+				Body:        new(bytes.Buffer),
+				Timeout:     10 * time.Second,
+				Proxy:       tc.proxy,
+				Redirects:   null.IntFrom(0),
+				TagsAndMeta: state.Tags.GetCurrentValues(),
+			}
+
+			res, err := MakeRequest(context.Background(), state, preq)
+			require.NoError(t, err)
+			if tc.errorMsg != "" {
+				assert.Contains(t, res.Error, tc.errorMsg)
+				return
+			}
+			assert.Equal(t, http.StatusOK, res.Status)
+			assert.Equal(t, int64(1), atomic.LoadInt64(tc.used))
+
+			trail, ok := (<-samples).(*Trail)
+			require.True(t, ok)
+			assert.GreaterOrEqual(t, trail.Connecting, testProxyDelay)
+			assert.Greater(t, trail.TLSHandshaking, time.Duration(0))
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/cookiejar"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -48,6 +49,9 @@ type ParsedHTTPRequest struct {
 	ResponseCallback func(int) bool
 	Compressions     []CompressionType
 	Redirects        null.Int
+	HTTP3            bool
+	Proxy            *url.URL
+	Retry            *RetryPolicy
 	ActiveJar        *cookiejar.Jar
 	Cookies          map[string]*HTTPRequestCookie
 	TagsAndMeta      metrics.TagsAndMeta
@@ -98,6 +102,7 @@ func updateK6Response(k6Response *Response, finishedReq *finishedRequest) {
 	k6Response.Timings = ResponseTimings{
 		Duration:       metrics.D(trail.Duration),
 		Blocked:        metrics.D(trail.Blocked),
+		LookingUp:      metrics.D(trail.DNSLookup),
 		Connecting:     metrics.D(trail.Connecting),
 		TLSHandshaking: metrics.D(trail.TLSHandshaking),
 		Sending:        metrics.D(trail.Sending),
@@ -174,25 +179,116 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 		preq.TagsAndMeta.SetSystemTagOrMeta(metrics.TagName, preq.URL.Name)
 	}
 
+	var (
+		resp   *Response
+		resErr error
+		retry  *pendingRetry
+	)
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			if !preq.Retry.wait(ctx, attempt-1) {
+				// The retry is never made, so the previous attempt is the final one.
+				retry.abort(state)
+				break
+			}
+			if preq.Req.GetBody != nil {
+				preq.Req.Body, _ = preq.Req.GetBody()
+			}
+		}
+		attemptResp, attemptRetry, attemptErr := makeRequestAttempt(ctx, state, preq, respReq, attempt)
+		if attemptResp == nil {
+			// The attempt couldn't be made, so the previous one is the final one.
+			retry.abort(state)
+			return nil, attemptErr
+		}
+		resp, retry, resErr = attemptResp, attemptRetry, attemptErr
+		resp.Attempts = attempt
+		if retry == nil {
+			break
+		}
+	}
+
+	if resErr != nil {
+		if preq.Throw { // if we are going to throw, we shouldn't log it
+			return nil, resErr
+		}
+
+		// Do *not* log errors about the context being cancelled.
+		select {
+		case <-ctx.Done():
+		default:
+			state.Logger.WithField("error", resErr).Warn("Request Failed")
+		}
+	}
+
+	return resp, nil
+}
+
+// pendingRetry is returned by makeRequestAttempt when the request should be
+// retried. It holds the http_req_failed sample of the attempt, which is only
+// emitted if the retry is aborted.
+type pendingRetry struct {
+	failedSample *metrics.Sample
+}
+
+// abort emits the held back http_req_failed sample of the attempt, since it
+// turned out to be the final one. The samples channel is still read while
+// the VU is running, even if its context is done. It's a no-op for a nil
+// pendingRetry, i.e. if there was no previous attempt.
+func (r *pendingRetry) abort(state *lib.State) {
+	if r != nil && r.failedSample != nil {
+		state.Samples <- *r.failedSample
+	}
+}
+
+// makeRequestAttempt makes a single attempt to send the request and read its
+// response. It returns the response, a non-nil pendingRetry if the request
+// should be retried, and the error of the request, if any. The response is
+// nil only if the request couldn't be made at all.
+//
+//nolint:funlen,gocognit,cyclop
+func makeRequestAttempt(
+	ctx context.Context, state *lib.State, preq *ParsedHTTPRequest, respReq *Request, attempt int,
+) (*Response, *pendingRetry, error) {
 	// Check rate limit *after* we've prepared a request; no need to wait with that part.
 	if rpsLimit := state.RPSLimit; rpsLimit != nil {
 		if err := rpsLimit.Wait(ctx); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
-	tracerTransport := newTransport(ctx, state, &preq.TagsAndMeta, preq.ResponseCallback)
+	tagsAndMeta := preq.TagsAndMeta
+	if preq.Retry != nil {
+		tagsAndMeta = preq.TagsAndMeta.Clone()
+		tagsAndMeta.SetSystemTagOrMetaIfEnabled(state.Options.SystemTags, metrics.TagAttempt, strconv.Itoa(attempt))
+	}
+
+	tracerTransport := newTransport(ctx, state, &tagsAndMeta, preq.ResponseCallback)
+	if preq.Retry != nil {
+		tracerTransport.willRetry = func(status int, code errCode) bool {
+			return ctx.Err() == nil && preq.Retry.shouldRetry(attempt, status, code)
+		}
+	}
+	if preq.HTTP3 {
+		if state.HTTP3Transport == nil {
+			return nil, nil, errors.New("HTTP/3 isn't supported in this context")
+		}
+		if preq.Proxy != nil {
+			return nil, nil, errors.New("proxies aren't supported for HTTP/3 requests")
+		}
+		tracerTransport.roundTripper = state.HTTP3Transport
+	}
 	var transport http.RoundTripper = tracerTransport
 
 	if state.Options.HTTPDebug.String != "" {
 		// Combine tags with common log fields
 		combinedLogFields := map[string]interface{}{"source": "http-debug", "vu": state.VUID, "iter": state.Iteration}
-		for k, v := range preq.TagsAndMeta.Metadata {
+		for k, v := range tagsAndMeta.Metadata {
 			if _, present := combinedLogFields[k]; !present {
 				combinedLogFields[k] = v
 			}
 		}
-		for k, v := range preq.TagsAndMeta.Tags.Map() {
+		for k, v := range tagsAndMeta.Tags.Map() {
 			if _, present := combinedLogFields[k]; !present {
 				combinedLogFields[k] = v
 			}
@@ -261,7 +357,11 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 
 	reqCtx, cancelFunc := context.WithTimeout(ctx, preq.Timeout)
 	defer cancelFunc()
+	if preq.Proxy != nil {
+		reqCtx = withProxy(reqCtx, preq.Proxy)
+	}
 	mreq := preq.Req.WithContext(reqCtx)
+	start := time.Now()
 	res, resErr := client.Do(mreq)
 
 	// TODO(imiric): It would be safer to check for a writeable
@@ -270,7 +370,7 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 	// unusable until https://github.com/golang/go/issues/31391 is fixed.
 	if res != nil && res.StatusCode == http.StatusSwitchingProtocols {
 		_ = res.Body.Close()
-		return nil, fmt.Errorf("unsupported response status: %s", res.Status)
+		return nil, nil, fmt.Errorf("unsupported response status: %s", res.Status)
 	}
 
 	if resErr == nil {
@@ -281,8 +381,12 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 		}
 	}
 	finishedReq := tracerTransport.processLastSavedRequest(wrapDecompressionError(resErr))
+	var retry *pendingRetry
 	if finishedReq != nil {
 		updateK6Response(resp, finishedReq)
+		if finishedReq.retried {
+			retry = &pendingRetry{failedSample: finishedReq.heldFailedSample}
+		}
 	}
 
 	if resErr == nil {
@@ -293,49 +397,46 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 		}
 
 		resp.URL = res.Request.URL.String()
-		resp.Status = res.StatusCode
-		resp.StatusText = res.Status
-		resp.Proto = res.Proto
+		setK6ResponseFields(resp, res)
+	}
 
-		if res.TLS != nil {
-			resp.setTLSInfo(res.TLS)
-		}
+	recordHAREntries(ctx, state, &tagsAndMeta, harExchange{
+		url: preq.Req.URL, request: respReq, response: resp, start: start,
+	}, tracerTransport.previousRequests, finishedReq)
 
-		resp.Headers = make(map[string]string, len(res.Header))
-		for k, vs := range res.Header {
-			resp.Headers[k] = strings.Join(vs, ", ")
-		}
+	return resp, retry, resErr
+}
 
-		resCookies := res.Cookies()
-		resp.Cookies = make(map[string][]*HTTPCookie, len(resCookies))
-		for _, c := range resCookies {
-			resp.Cookies[c.Name] = append(resp.Cookies[c.Name], &HTTPCookie{
-				Name:     c.Name,
-				Value:    c.Value,
-				Domain:   c.Domain,
-				Path:     c.Path,
-				HTTPOnly: c.HttpOnly,
-				Secure:   c.Secure,
-				MaxAge:   c.MaxAge,
-				Expires:  c.Expires.UnixNano() / 1000000,
-			})
-		}
-	}
+// setK6ResponseFields sets the status, headers and cookies of the k6 response
+// from the received HTTP response.
+func setK6ResponseFields(resp *Response, res *http.Response) {
+	resp.Status = res.StatusCode
+	resp.StatusText = res.Status
+	resp.Proto = res.Proto
 
-	if resErr != nil {
-		if preq.Throw { // if we are going to throw, we shouldn't log it
-			return nil, resErr
-		}
+	if res.TLS != nil {
+		resp.setTLSInfo(res.TLS)
+	}
 
-		// Do *not* log errors about the context being cancelled.
-		select {
-		case <-ctx.Done():
-		default:
-			state.Logger.WithField("error", resErr).Warn("Request Failed")
-		}
+	resp.Headers = make(map[string]string, len(res.Header))
+	for k, vs := range res.Header {
+		resp.Headers[k] = strings.Join(vs, ", ")
 	}
 
-	return resp, nil
+	resCookies := res.Cookies()
+	resp.Cookies = make(map[string][]*HTTPCookie, len(resCookies))
+	for _, c := range resCookies {
+		resp.Cookies[c.Name] = append(resp.Cookies[c.Name], &HTTPCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			HTTPOnly: c.HttpOnly,
+			Secure:   c.Secure,
+			MaxAge:   c.MaxAge,
+			Expires:  c.Expires.UnixNano() / 1000000,
+		})
+	}
 }
 
 // SetRequestCookies sets the cookies of the requests getting those cookies both from the jar and
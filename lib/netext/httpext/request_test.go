@@ -274,7 +274,7 @@ func TestMakeRequestTimeoutInTheMiddle(t *testing.T) {
 	assert.Len(t, samples, 1)
 	sampleCont := <-samples
 	allSamples := sampleCont.GetSamples()
-	require.Len(t, allSamples, 9)
+	require.Len(t, allSamples, 10)
 	expTags := map[string]string{
 		"error":             "request timeout",
 		"error_code":        "1050",
@@ -422,7 +422,7 @@ func TestMakeRequestDialTimeout(t *testing.T) {
 	assert.Len(t, samples, 1)
 	sampleCont := <-samples
 	allSamples := sampleCont.GetSamples()
-	require.Len(t, allSamples, 9)
+	require.Len(t, allSamples, 10)
 	expTags := map[string]string{
 		"error":             "dial: i/o timeout",
 		"error_code":        "1211",
@@ -477,7 +477,7 @@ func TestMakeRequestTimeoutInTheBegining(t *testing.T) {
 	assert.Len(t, samples, 1)
 	sampleCont := <-samples
 	allSamples := sampleCont.GetSamples()
-	require.Len(t, allSamples, 9)
+	require.Len(t, allSamples, 10)
 	expTags := map[string]string{
 		"error":             "request timeout",
 		"error_code":        "1050",
@@ -68,6 +68,7 @@ type Response struct {
 	OCSP           netext.OCSP              `json:"ocsp"`
 	Error          string                   `json:"error"`
 	ErrorCode      int                      `json:"error_code"`
+	Attempts       int                      `json:"attempts"`
 	Request        *Request                 `json:"request"`
 }
 
@@ -0,0 +1,86 @@
+package httpext
+
+import (
+	"context"
+	"math/rand"
+	"slices"
+	"time"
+)
+
+const (
+	defaultRetryBackoff    = 100 * time.Millisecond
+	defaultRetryMaxBackoff = 10 * time.Second
+)
+
+// RetryPolicy describes when and how a request is retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	MaxAttempts int
+	// Statuses are the response statuses that are retried. If there are none,
+	// all 5xx responses are retried.
+	Statuses []int
+	// ErrorCodes are the k6 error codes of the failed requests that are
+	// retried. If there are none, all failed requests are retried.
+	ErrorCodes []int
+	// Backoff is the delay before the second attempt, which is doubled for
+	// every next attempt, up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// NewRetryPolicy returns a new RetryPolicy with the given maximum number of
+// attempts and the default values for the rest.
+func NewRetryPolicy(maxAttempts int) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: maxAttempts,
+		Backoff:     defaultRetryBackoff,
+		MaxBackoff:  defaultRetryMaxBackoff,
+	}
+}
+
+// shouldRetry returns whether an attempt that ended with the given status, or
+// with the given error code if it failed, should be retried.
+func (p *RetryPolicy) shouldRetry(attempt int, status int, code errCode) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+	if status == 0 {
+		if code == 0 {
+			return false
+		}
+		return len(p.ErrorCodes) == 0 || slices.Contains(p.ErrorCodes, int(code))
+	}
+	if len(p.Statuses) == 0 {
+		return status >= 500
+	}
+	return slices.Contains(p.Statuses, status)
+}
+
+// backoff returns the delay before the attempt after the given one. It's
+// exponential with "equal jitter", i.e. a random value in the upper half.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1)) //nolint:gosec
+}
+
+// wait waits for the backoff delay after the given attempt. It returns false
+// if the context was done before that.
+func (p *RetryPolicy) wait(ctx context.Context, attempt int) bool {
+	timer := time.NewTimer(p.backoff(attempt))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,236 @@
+package httpext
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	t.Parallel()
+
+	policy := NewRetryPolicy(3)
+	assert.True(t, policy.shouldRetry(1, http.StatusServiceUnavailable, 0))
+	assert.True(t, policy.shouldRetry(2, 0, tcpDialErrorCode))
+	assert.False(t, policy.shouldRetry(3, http.StatusServiceUnavailable, 0))
+	assert.False(t, policy.shouldRetry(1, http.StatusNotFound, 0))
+	assert.False(t, policy.shouldRetry(1, http.StatusOK, 0))
+
+	policy.Statuses = []int{http.StatusTooManyRequests}
+	policy.ErrorCodes = []int{int(requestTimeoutErrorCode)}
+	assert.True(t, policy.shouldRetry(1, http.StatusTooManyRequests, 0))
+	assert.False(t, policy.shouldRetry(1, http.StatusServiceUnavailable, 0))
+	assert.True(t, policy.shouldRetry(1, 0, requestTimeoutErrorCode))
+	assert.False(t, policy.shouldRetry(1, 0, tcpDialErrorCode))
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	t.Parallel()
+
+	policy := &RetryPolicy{MaxAttempts: 10, Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt, max := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		5: time.Second,
+		9: time.Second,
+	} {
+		delay := policy.backoff(attempt)
+		assert.GreaterOrEqual(t, delay, max/2, attempt)
+		assert.LessOrEqual(t, delay, max, attempt)
+	}
+}
+
+func TestMakeRequestRetry(t *testing.T) {
+	t.Parallel()
+
+	var requests int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if atomic.AddInt64(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+
+	samples := make(chan metrics.SampleContainer, 10)
+	registry := metrics.NewRegistry()
+	state := &lib.State{
+		Options:        lib.Options{SystemTags: &metrics.DefaultSystemTagSet},
+		Transport:      srv.Client().Transport,
+		Samples:        samples,
+		Logger:         logrus.New(),
+		BufferPool:     lib.NewBufferPool(),
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+	}
+	req, err := http.NewRequest(http.MethodPost, srv.URL, nil)
+	require.NoError(t, err)
+	preq := &ParsedHTTPRequest{
+		Req:              req,
+		URL:              &URL{u: req.URL, URL: srv.URL},
+		Body:             bytes.NewBufferString("hello"),
+		Timeout:          10 * time.Second,
+		ResponseType:     ResponseTypeText,
+		ResponseCallback: func(status int) bool { return status == http.StatusOK },
+		Retry:            &RetryPolicy{MaxAttempts: 5, Backoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		TagsAndMeta:      state.Tags.GetCurrentValues(),
+	}
+
+	res, err := MakeRequest(context.Background(), state, preq)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Status)
+	assert.Equal(t, 3, res.Attempts)
+	assert.Equal(t, "hello", res.Body)
+
+	require.Len(t, samples, 3)
+	for attempt := 1; attempt <= 3; attempt++ {
+		trail, ok := (<-samples).(*Trail)
+		require.True(t, ok)
+		tag, _ := trail.Tags.Get(metrics.TagAttempt.String())
+		assert.Equal(t, strconv.Itoa(attempt), tag)
+
+		var failedSamples int
+		for _, s := range trail.GetSamples() {
+			if s.Metric.Name == metrics.HTTPReqFailedName {
+				failedSamples++
+				assert.Equal(t, 0.0, s.Value)
+			}
+		}
+		if attempt < 3 {
+			assert.Zero(t, failedSamples)
+			assert.False(t, trail.Failed.Valid)
+		} else {
+			assert.Equal(t, 1, failedSamples)
+		}
+	}
+}
+
+func TestMakeRequestRetryAbortedDuringBackoff(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+
+	samples := make(chan metrics.SampleContainer, 10)
+	registry := metrics.NewRegistry()
+	state := &lib.State{
+		Options:        lib.Options{SystemTags: &metrics.DefaultSystemTagSet},
+		Transport:      srv.Client().Transport,
+		Samples:        samples,
+		Logger:         logrus.New(),
+		BufferPool:     lib.NewBufferPool(),
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+	}
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	preq := &ParsedHTTPRequest{
+		Req:              req,
+		URL:              &URL{u: req.URL, URL: srv.URL},
+		Timeout:          10 * time.Second,
+		ResponseType:     ResponseTypeText,
+		ResponseCallback: func(status int) bool { return status == http.StatusOK },
+		Retry:            &RetryPolicy{MaxAttempts: 3, Backoff: time.Minute, MaxBackoff: time.Minute},
+		TagsAndMeta:      state.Tags.GetCurrentValues(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		// Cancel the context once the first attempt is done, i.e. during the backoff.
+		for len(samples) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+	}()
+
+	res, err := MakeRequest(ctx, state, preq)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, res.Status)
+	assert.Equal(t, 1, res.Attempts)
+
+	require.Len(t, samples, 2)
+	trail, ok := (<-samples).(*Trail)
+	require.True(t, ok)
+	for _, s := range trail.GetSamples() {
+		assert.NotEqual(t, metrics.HTTPReqFailedName, s.Metric.Name)
+	}
+
+	failed, ok := (<-samples).(metrics.Sample)
+	require.True(t, ok)
+	assert.Equal(t, metrics.HTTPReqFailedName, failed.Metric.Name)
+	assert.Equal(t, 1.0, failed.Value)
+	tag, _ := failed.Tags.Get(metrics.TagAttempt.String())
+	assert.Equal(t, "1", tag)
+}
+
+func TestMakeRequestRetryAttemptNotMade(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+
+	samples := make(chan metrics.SampleContainer, 10)
+	registry := metrics.NewRegistry()
+	state := &lib.State{
+		Options:        lib.Options{SystemTags: &metrics.DefaultSystemTagSet},
+		Transport:      srv.Client().Transport,
+		Samples:        samples,
+		Logger:         logrus.New(),
+		BufferPool:     lib.NewBufferPool(),
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+		// The second attempt would have to wait for longer than the context
+		// deadline, so it fails before it makes the request.
+		RPSLimit: rate.NewLimiter(rate.Every(time.Hour), 1),
+	}
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	preq := &ParsedHTTPRequest{
+		Req:              req,
+		URL:              &URL{u: req.URL, URL: srv.URL},
+		Timeout:          10 * time.Second,
+		ResponseType:     ResponseTypeText,
+		ResponseCallback: func(status int) bool { return status == http.StatusOK },
+		Retry:            &RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		TagsAndMeta:      state.Tags.GetCurrentValues(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	res, err := MakeRequest(ctx, state, preq)
+	require.Error(t, err)
+	assert.Nil(t, res)
+
+	require.Len(t, samples, 2)
+	_, ok := (<-samples).(*Trail)
+	require.True(t, ok)
+
+	failed, ok := (<-samples).(metrics.Sample)
+	require.True(t, ok)
+	assert.Equal(t, metrics.HTTPReqFailedName, failed.Metric.Name)
+	assert.Equal(t, 1.0, failed.Value)
+	tag, _ := failed.Tags.Get(metrics.TagAttempt.String())
+	assert.Equal(t, "1", tag)
+}
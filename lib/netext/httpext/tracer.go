@@ -23,6 +23,7 @@ type Trail struct {
 	Duration time.Duration
 
 	Blocked        time.Duration // Waiting to acquire a connection.
+	DNSLookup      time.Duration // Looking up the IP of the remote host, as a part of Blocked.
 	Connecting     time.Duration // Connecting to remote host.
 	TLSHandshaking time.Duration // Executing TLS handshake.
 	Sending        time.Duration // Writing request.
@@ -44,7 +45,7 @@ type Trail struct {
 func (tr *Trail) SaveSamples(builtinMetrics *metrics.BuiltinMetrics, ctm *metrics.TagsAndMeta) {
 	tr.Tags = ctm.Tags
 	tr.Metadata = ctm.Metadata
-	tr.Samples = make([]metrics.Sample, 0, 9) // this is with 1 more for a possible HTTPReqFailed
+	tr.Samples = make([]metrics.Sample, 0, 10) // this is with 1 more for a possible HTTPReqFailed
 	tr.Samples = append(tr.Samples, []metrics.Sample{
 		{
 			TimeSeries: metrics.TimeSeries{
@@ -73,6 +74,15 @@ func (tr *Trail) SaveSamples(builtinMetrics *metrics.BuiltinMetrics, ctm *metric
 			Metadata: ctm.Metadata,
 			Value:    metrics.D(tr.Blocked),
 		},
+		{
+			TimeSeries: metrics.TimeSeries{
+				Metric: builtinMetrics.HTTPReqDNSLookup,
+				Tags:   ctm.Tags,
+			},
+			Time:     tr.EndTime,
+			Metadata: ctm.Metadata,
+			Value:    metrics.D(tr.DNSLookup),
+		},
 		{
 			TimeSeries: metrics.TimeSeries{
 				Metric: builtinMetrics.HTTPReqConnecting,
@@ -146,6 +156,8 @@ var _ metrics.ConnectedSampleContainer = &Trail{}
 // Cheers, love, the cavalry's here.
 type Tracer struct {
 	getConn              int64
+	dnsStart             int64
+	dnsDone              int64
 	connectStart         int64
 	connectDone          int64
 	tlsHandshakeStart    int64
@@ -156,12 +168,18 @@ type Tracer struct {
 
 	connReused     bool
 	connRemoteAddr net.Addr
+
+	// Whether the connection is made through a proxy, in which case the
+	// handshake with the proxy is measured as connecting.
+	proxied bool
 }
 
 // Trace returns a premade ClientTrace that calls all of the Tracer's hooks.
 func (t *Tracer) Trace() *httptrace.ClientTrace {
 	return &httptrace.ClientTrace{
 		GetConn:              t.GetConn,
+		DNSStart:             t.DNSStart,
+		DNSDone:              t.DNSDone,
 		ConnectStart:         t.ConnectStart,
 		ConnectDone:          t.ConnectDone,
 		TLSHandshakeStart:    t.TLSHandshakeStart,
@@ -188,6 +206,21 @@ func (t *Tracer) GetConn(_ string) {
 	t.getConn = now()
 }
 
+// DNSStart is called when a DNS lookup begins. The lookups are done by the
+// k6 dialer, so they are reported by it instead of by the transport.
+//
+// If the connection is reused, or the host is an IP or is in the hosts
+// option, this won't be called. Otherwise, it will be called after GetConn()
+// and before DNSDone() and ConnectStart().
+func (t *Tracer) DNSStart(_ httptrace.DNSStartInfo) {
+	atomic.CompareAndSwapInt64(&t.dnsStart, 0, now())
+}
+
+// DNSDone is called when a DNS lookup ends.
+func (t *Tracer) DNSDone(_ httptrace.DNSDoneInfo) {
+	atomic.CompareAndSwapInt64(&t.dnsDone, 0, now())
+}
+
 // ConnectStart is called when a new connection's Dial begins.
 // If net.Dialer.DualStack (IPv6 "Happy Eyeballs") support is
 // enabled (default), this may be called multiple times.
@@ -269,6 +302,8 @@ func (t *Tracer) GotConn(info httptrace.GotConnInfo) {
 	// put incorrect values in them (they use CompareAndSwap)
 	_, isConnTLS := info.Conn.(*tls.Conn)
 	if info.Reused {
+		atomic.SwapInt64(&t.dnsStart, now)
+		atomic.SwapInt64(&t.dnsDone, now)
 		atomic.SwapInt64(&t.connectStart, now)
 		atomic.SwapInt64(&t.connectDone, now)
 		if isConnTLS {
@@ -329,6 +364,8 @@ func (t *Tracer) Done() *Trail {
 	// already returned our result and we've called Done(). This happens
 	// mostly for cancelled requests, but we have to use atomics here as
 	// well (or use global Tracer locking) so we can avoid data races.
+	dnsStart := atomic.LoadInt64(&t.dnsStart)
+	dnsDone := atomic.LoadInt64(&t.dnsDone)
 	connectStart := atomic.LoadInt64(&t.connectStart)
 	connectDone := atomic.LoadInt64(&t.connectDone)
 	tlsHandshakeStart := atomic.LoadInt64(&t.tlsHandshakeStart)
@@ -337,6 +374,22 @@ func (t *Tracer) Done() *Trail {
 	wroteRequest := atomic.LoadInt64(&t.wroteRequest)
 	gotFirstResponseByte := atomic.LoadInt64(&t.gotFirstResponseByte)
 
+	if t.proxied && connectDone != 0 {
+		// The CONNECT request or the SOCKS5 handshake with the proxy happen after
+		// the TCP connection to the proxy is established, and before the TLS
+		// handshake with the target or getting the connection, if it's not TLS.
+		proxyDone := tlsHandshakeStart
+		if proxyDone == 0 {
+			proxyDone = gotConn
+		}
+		if proxyDone > connectDone {
+			connectDone = proxyDone
+		}
+	}
+
+	if dnsDone != 0 && dnsStart != 0 {
+		trail.DNSLookup = time.Duration(dnsDone - dnsStart)
+	}
 	if connectDone != 0 && connectStart != 0 {
 		trail.Connecting = time.Duration(connectDone - connectStart)
 	}
@@ -145,7 +145,7 @@ func TestTracer(t *testing.T) { //nolint:tparallel
 
 			assert.Equal(t, strings.TrimPrefix(srv.URL, "https://"), trail.ConnRemoteAddr.String())
 
-			assert.Len(t, samples, 8)
+			assert.Len(t, samples, 9)
 			seenMetrics := map[*metrics.Metric]bool{}
 			for i, s := range samples {
 				assert.NotContains(t, seenMetrics, s.Metric)
@@ -158,6 +158,9 @@ func TestTracer(t *testing.T) { //nolint:tparallel
 				case builtinMetrics.HTTPReqs:
 					assert.Equal(t, 1.0, s.Value)
 					assert.Equal(t, 0, i, "`HTTPReqs` is reported before the other HTTP builtinMetrics")
+				case builtinMetrics.HTTPReqDNSLookup:
+					// The requests are made to an IP, which isn't looked up.
+					assert.Equal(t, 0.0, s.Value)
 				case builtinMetrics.HTTPReqConnecting, builtinMetrics.HTTPReqTLSHandshaking:
 					if isReuse {
 						assert.Equal(t, 0.0, s.Value)
@@ -22,9 +22,17 @@ type transport struct {
 	state            *lib.State
 	tagsAndMeta      *metrics.TagsAndMeta
 	responseCallback func(int) bool
+	roundTripper     http.RoundTripper
+	// Returns whether a request with the given status or error code will be
+	// retried, in which case its http_req_failed sample is held back until
+	// it's known whether the retry is actually made.
+	willRetry func(status int, code errCode) bool
 
 	lastRequest     *unfinishedRequest
 	lastRequestLock *sync.Mutex
+	// The finished requests before the last one, e.g. the redirects, which
+	// are recorded in the HAR file together with it.
+	previousRequests []*finishedRequest
 }
 
 // unfinishedRequest stores the request and the raw result returned from the
@@ -47,6 +55,10 @@ type finishedRequest struct {
 	tlsInfo   netext.TLSInfo
 	errorCode errCode
 	errorMsg  string
+	retried   bool
+	// The http_req_failed sample of a retried request, which should only be
+	// emitted if the retry is never made, e.g. because the context is done.
+	heldFailedSample *metrics.Sample
 }
 
 var _ http.RoundTripper = &transport{}
@@ -66,6 +78,7 @@ func newTransport(
 		state:            state,
 		tagsAndMeta:      tagsAndMeta,
 		responseCallback: responseCallback,
+		roundTripper:     state.Transport,
 		lastRequestLock:  new(sync.Mutex),
 	}
 }
@@ -129,12 +142,16 @@ func (t *transport) measureAndEmitMetrics(unfReq *unfinishedRequest) *finishedRe
 			tagsAndMeta.SetSystemTagOrMeta(metrics.TagIP, ip)
 		}
 	}
+	var statusCode int
+	if unfReq.err == nil {
+		statusCode = unfReq.response.StatusCode
+	}
+	if t.willRetry != nil {
+		result.retried = t.willRetry(statusCode, result.errorCode)
+	}
+
 	var failed float64
 	if t.responseCallback != nil {
-		var statusCode int
-		if unfReq.err == nil {
-			statusCode = unfReq.response.StatusCode
-		}
 		expected := t.responseCallback(statusCode)
 		if !expected {
 			failed = 1
@@ -145,21 +162,24 @@ func (t *transport) measureAndEmitMetrics(unfReq *unfinishedRequest) *finishedRe
 
 	trail.SaveSamples(t.state.BuiltinMetrics, &tagsAndMeta)
 	if t.responseCallback != nil {
-		trail.Failed.Valid = true
-		if failed == 1 {
-			trail.Failed.Bool = true
-		}
-		trail.Samples = append(trail.Samples,
-			metrics.Sample{
-				TimeSeries: metrics.TimeSeries{
-					Metric: t.state.BuiltinMetrics.HTTPReqFailed,
-					Tags:   tagsAndMeta.Tags,
-				},
-				Time:     trail.EndTime,
-				Metadata: tagsAndMeta.Metadata,
-				Value:    failed,
+		failedSample := metrics.Sample{
+			TimeSeries: metrics.TimeSeries{
+				Metric: t.state.BuiltinMetrics.HTTPReqFailed,
+				Tags:   tagsAndMeta.Tags,
 			},
-		)
+			Time:     trail.EndTime,
+			Metadata: tagsAndMeta.Metadata,
+			Value:    failed,
+		}
+		if result.retried {
+			result.heldFailedSample = &failedSample
+		} else {
+			trail.Failed.Valid = true
+			if failed == 1 {
+				trail.Failed.Bool = true
+			}
+			trail.Samples = append(trail.Samples, failedSample)
+		}
 	}
 	metrics.PushIfNotDone(t.ctx, t.state.Samples, trail)
 	return result
@@ -199,12 +219,17 @@ func (t *transport) processLastSavedRequest(lastErr error) *finishedRequest {
 
 // RoundTrip is the implementation of http.RoundTripper
 func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
-	t.processLastSavedRequest(nil)
+	if previous := t.processLastSavedRequest(nil); previous != nil {
+		t.previousRequests = append(t.previousRequests, previous)
+	}
 
 	ctx := req.Context()
 	tracer := &Tracer{}
+	if proxy, err := ProxyFromRequest(req); err == nil && proxy != nil {
+		tracer.proxied = true
+	}
 	reqWithTracer := req.WithContext(httptrace.WithClientTrace(ctx, tracer.Trace()))
-	resp, err := t.state.Transport.RoundTrip(reqWithTracer)
+	resp, err := t.roundTripper.RoundTrip(reqWithTracer)
 
 	var netError net.Error
 	if errors.As(err, &netError) && netError.Timeout() {
@@ -0,0 +1,283 @@
+package netext
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"go.k6.io/k6/lib/types"
+)
+
+const (
+	// dnsTimeout is the timeout of the queries sent to a nameserver.
+	dnsTimeout = 5 * time.Second
+	// maxDNSMessageSize is the maximum size of the DNS messages over TCP,
+	// TLS and HTTPS, the messages over UDP are up to 512 bytes without EDNS.
+	maxDNSMessageSize = 65535
+	// dnsMessageType is the media type of the DNS over HTTPS messages.
+	dnsMessageType = "application/dns-message"
+)
+
+type nameserversResolver struct {
+	nameservers []types.DNSNameserver
+	httpClient  *http.Client
+}
+
+// NewNameserversResolver returns a MultiResolver which looks up the IPs of
+// the hosts with the given nameservers instead of the system resolver. The
+// nameservers are queried in order, until one of them answers.
+func NewNameserversResolver(nameservers []types.DNSNameserver) MultiResolver {
+	r := &nameserversResolver{
+		nameservers: nameservers,
+		httpClient:  &http.Client{Timeout: dnsTimeout},
+	}
+	return r.lookupIP
+}
+
+func (r *nameserversResolver) lookupIP(host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	fqdn := host
+	if !strings.HasSuffix(fqdn, ".") {
+		fqdn += "."
+	}
+	name, err := dnsmessage.NewName(fqdn)
+	if err != nil {
+		return nil, &net.DNSError{Err: "invalid host name", Name: host}
+	}
+
+	var lastErr error
+	for _, ns := range r.nameservers {
+		ips, err := r.lookupIPFrom(ns, name)
+		if err == nil {
+			return ips, nil
+		}
+		// A host which doesn't exist doesn't exist for the other nameservers either.
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			dnsErr.Name = host
+			return nil, dnsErr
+		}
+		lastErr = err
+	}
+	var dnsErr *net.DNSError
+	if errors.As(lastErr, &dnsErr) {
+		dnsErr.Name = host
+		return nil, dnsErr
+	}
+	return nil, &net.DNSError{Err: lastErr.Error(), Name: host}
+}
+
+// lookupIPFrom queries a nameserver for the IPv4 and the IPv6 addresses of a
+// host concurrently, like the system resolver does.
+func (r *nameserversResolver) lookupIPFrom(ns types.DNSNameserver, name dnsmessage.Name) ([]net.IP, error) {
+	type result struct {
+		ips []net.IP
+		err error
+	}
+	results := make(chan result, 2)
+	for _, qtype := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		go func(qtype dnsmessage.Type) {
+			ips, err := r.query(ns, name, qtype)
+			results <- result{ips, err}
+		}(qtype)
+	}
+
+	var ips []net.IP
+	var notFound bool
+	for i := 0; i < 2; i++ {
+		res := <-results
+		var dnsErr *net.DNSError
+		switch {
+		case errors.As(res.err, &dnsErr) && dnsErr.IsNotFound:
+			notFound = true
+		case res.err != nil:
+			return nil, res.err
+		}
+		ips = append(ips, res.ips...)
+	}
+	if len(ips) == 0 || notFound {
+		return nil, &net.DNSError{Err: "no such host", Server: ns.String(), IsNotFound: true}
+	}
+	return ips, nil
+}
+
+// query sends a query to a nameserver and returns the addresses in the answer.
+func (r *nameserversResolver) query(
+	ns types.DNSNameserver, name dnsmessage.Name, qtype dnsmessage.Type,
+) ([]net.IP, error) {
+	// The ID of the DNS over HTTPS queries should be 0, to be cacheable.
+	var id uint16
+	if ns.Protocol != "https" {
+		id = uint16(rand.Uint32()) //nolint:gosec
+	}
+	msg := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	query, err := msg.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
+	defer cancel()
+
+	protocol := ns.Protocol
+	resp, err := r.exchange(ctx, protocol, ns.Address, query)
+	if err != nil {
+		return nil, &net.DNSError{Err: err.Error(), Server: ns.String(), IsTimeout: isTimeout(err)}
+	}
+	var answer dnsmessage.Message
+	if err = answer.Unpack(resp); err == nil && answer.Truncated && protocol == "udp" {
+		// The answer doesn't fit in a UDP message, so it's retried over TCP.
+		if resp, err = r.exchange(ctx, "tcp", ns.Address, query); err != nil {
+			return nil, &net.DNSError{Err: err.Error(), Server: ns.String(), IsTimeout: isTimeout(err)}
+		}
+		err = answer.Unpack(resp)
+	}
+	if err != nil {
+		return nil, &net.DNSError{Err: "invalid answer: " + err.Error(), Server: ns.String()}
+	}
+	if answer.ID != id {
+		return nil, &net.DNSError{Err: "the ID of the answer doesn't match the query", Server: ns.String()}
+	}
+
+	switch answer.RCode {
+	case dnsmessage.RCodeSuccess:
+	case dnsmessage.RCodeNameError:
+		return nil, &net.DNSError{Err: "no such host", Server: ns.String(), IsNotFound: true}
+	default:
+		return nil, &net.DNSError{Err: "server misbehaving: " + answer.RCode.String(), Server: ns.String()}
+	}
+
+	var ips []net.IP
+	for _, rr := range answer.Answers {
+		switch body := rr.Body.(type) {
+		case *dnsmessage.AResource:
+			ips = append(ips, net.IP(body.A[:]))
+		case *dnsmessage.AAAAResource:
+			ips = append(ips, net.IP(body.AAAA[:]))
+		}
+	}
+	return ips, nil
+}
+
+// exchange sends the query to the address with the given protocol, and
+// returns the answer.
+func (r *nameserversResolver) exchange(ctx context.Context, protocol, address string, query []byte) ([]byte, error) {
+	switch protocol {
+	case "udp":
+		return exchangeUDP(ctx, address, query)
+	case "tcp", "tls":
+		return exchangeStream(ctx, protocol, address, query)
+	case "https":
+		return r.exchangeHTTPS(ctx, address, query)
+	default:
+		return nil, fmt.Errorf("unsupported nameserver protocol '%s'", protocol)
+	}
+}
+
+func exchangeUDP(ctx context.Context, address string, query []byte) ([]byte, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err = conn.Write(query); err != nil {
+		return nil, err
+	}
+	// The answers to the other queries, which could have been sent to the
+	// same port before, are ignored.
+	buf := make([]byte, 512)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		if n >= 2 && bytes.Equal(buf[:2], query[:2]) {
+			return buf[:n], nil
+		}
+	}
+}
+
+// exchangeStream sends the query over TCP or TLS, where the messages are
+// prefixed with their length.
+func exchangeStream(ctx context.Context, protocol, address string, query []byte) ([]byte, error) {
+	var conn net.Conn
+	var err error
+	if protocol == "tls" {
+		host, _, _ := net.SplitHostPort(address)
+		d := tls.Dialer{Config: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}}
+		conn, err = d.DialContext(ctx, "tcp", address)
+	} else {
+		var d net.Dialer
+		conn, err = d.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	msg := make([]byte, 2+len(query))
+	binary.BigEndian.PutUint16(msg, uint16(len(query)))
+	copy(msg[2:], query)
+	if _, err = conn.Write(msg); err != nil {
+		return nil, err
+	}
+
+	var length uint16
+	if err = binary.Read(conn, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+	resp := make([]byte, length)
+	if _, err = io.ReadFull(conn, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// exchangeHTTPS sends the query with a POST request, as defined in RFC 8484.
+func (r *nameserversResolver) exchangeHTTPS(ctx context.Context, url string, query []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", dnsMessageType)
+	req.Header.Set("Accept", dnsMessageType)
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxDNSMessageSize))
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
@@ -0,0 +1,149 @@
+package netext
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"go.k6.io/k6/lib/types"
+)
+
+// dnsAnswer answers a query for example.com. with 127.0.0.1 and ::1, and
+// with NXDOMAIN for the other names. The answers over UDP are truncated if
+// truncateUDP is set.
+func dnsAnswer(t *testing.T, query []byte, udp, truncateUDP bool) []byte {
+	t.Helper()
+	var q dnsmessage.Message
+	require.NoError(t, q.Unpack(query))
+	require.Len(t, q.Questions, 1)
+
+	answer := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: q.ID, Response: true, RecursionAvailable: true},
+		Questions: q.Questions,
+	}
+	question := q.Questions[0]
+	rh := dnsmessage.ResourceHeader{Name: question.Name, Type: question.Type, Class: question.Class, TTL: 60}
+	switch {
+	case question.Name.String() != "example.com.":
+		answer.RCode = dnsmessage.RCodeNameError
+	case udp && truncateUDP:
+		answer.Truncated = true
+	case question.Type == dnsmessage.TypeA:
+		answer.Answers = []dnsmessage.Resource{{Header: rh, Body: &dnsmessage.AResource{A: [4]byte{127, 0, 0, 1}}}}
+	case question.Type == dnsmessage.TypeAAAA:
+		answer.Answers = []dnsmessage.Resource{{
+			Header: rh, Body: &dnsmessage.AAAAResource{AAAA: [16]byte{15: 1}},
+		}}
+	}
+	data, err := answer.Pack()
+	require.NoError(t, err)
+	return data
+}
+
+// dnsServer starts a DNS server listening on the same UDP and TCP port.
+func dnsServer(t *testing.T, truncateUDP bool) string {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = pc.Close() })
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = pc.WriteTo(dnsAnswer(t, buf[:n], true, truncateUDP), addr)
+		}
+	}()
+
+	l, err := net.Listen("tcp", pc.LocalAddr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				var length uint16
+				if binary.Read(conn, binary.BigEndian, &length) != nil {
+					return
+				}
+				query := make([]byte, length)
+				if _, err := io.ReadFull(conn, query); err != nil {
+					return
+				}
+				answer := dnsAnswer(t, query, false, truncateUDP)
+				_ = binary.Write(conn, binary.BigEndian, uint16(len(answer)))
+				_, _ = conn.Write(answer)
+			}()
+		}
+	}()
+	return pc.LocalAddr().String()
+}
+
+func TestNameserversResolver(t *testing.T) {
+	t.Parallel()
+
+	expected := []net.IP{net.ParseIP("127.0.0.1").To4(), net.ParseIP("::1")}
+
+	doh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != dnsMessageType {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		query, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", dnsMessageType)
+		_, _ = w.Write(dnsAnswer(t, query, false, false))
+	}))
+	t.Cleanup(doh.Close)
+
+	testCases := map[string][]types.DNSNameserver{
+		"udp":            {{Protocol: "udp", Address: dnsServer(t, false)}},
+		"udp truncated":  {{Protocol: "udp", Address: dnsServer(t, true)}},
+		"tcp":            {{Protocol: "tcp", Address: dnsServer(t, false)}},
+		"https":          {{Protocol: "https", Address: doh.URL}},
+		"unavailable ns": {{Protocol: "tcp", Address: "127.0.0.1:1"}, {Protocol: "https", Address: doh.URL}},
+	}
+
+	for name, nameservers := range testCases {
+		nameservers := nameservers
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			resolve := NewNameserversResolver(nameservers)
+			ips, err := resolve("example.com")
+			require.NoError(t, err)
+			assert.ElementsMatch(t, expected, ips)
+
+			_, err = resolve("missing.example.com")
+			var dnsErr *net.DNSError
+			require.ErrorAs(t, err, &dnsErr)
+			assert.True(t, dnsErr.IsNotFound)
+			assert.Equal(t, "missing.example.com", dnsErr.Name)
+		})
+	}
+
+	t.Run("all unavailable", func(t *testing.T) {
+		t.Parallel()
+
+		resolve := NewNameserversResolver([]types.DNSNameserver{{Protocol: "tcp", Address: "127.0.0.1:1"}})
+		_, err := resolve("example.com")
+		var dnsErr *net.DNSError
+		require.ErrorAs(t, err, &dnsErr)
+		assert.False(t, dnsErr.IsNotFound)
+		assert.Equal(t, "example.com", dnsErr.Name)
+		assert.Equal(t, "tcp://127.0.0.1:1", dnsErr.Server)
+	})
+}
@@ -0,0 +1,303 @@
+package netext
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"go.k6.io/k6/lib/types"
+)
+
+const (
+	// emulatedChunkSize is the maximum size of the chunks in which the data
+	// is delayed, the same as the usual size of the segments of TCP.
+	emulatedChunkSize = 1460
+	// emulatedReadSize is the size of the reads from the real connection.
+	emulatedReadSize = 16 * 1024
+	// emulatedReadBuffer is the number of chunks which are read ahead from
+	// the real connection, after which the backpressure is left to TCP.
+	emulatedReadBuffer = 256
+	// minRetransmissionDelay is the minimum delay of a lost chunk, which is
+	// the minimum retransmission timeout of TCP.
+	minRetransmissionDelay = 200 * time.Millisecond
+)
+
+// emulatedChunk is a part of the data read from the real connection, which
+// is delivered to the reader after the emulated delays.
+type emulatedChunk struct {
+	data      []byte
+	err       error
+	deliverAt time.Time
+}
+
+// EmulatedConn wraps a net.Conn and emulates the latency, the bandwidth and
+// the packet loss of a network profile. The received data is read ahead and
+// is delivered when it would have arrived over the emulated network, while
+// the sent data is only throttled by the upload bandwidth.
+type EmulatedConn struct {
+	net.Conn
+
+	profile types.NetworkProfile
+	rand    *rand.Rand
+
+	chunks  chan emulatedChunk
+	pending emulatedChunk
+	done    chan struct{}
+	closeMx sync.Once
+
+	deadlineMx      sync.Mutex
+	readDeadline    time.Time
+	deadlineChanged chan struct{}
+
+	writeMx   sync.Mutex
+	writeFree time.Time
+}
+
+// NewEmulatedConn wraps the connection and starts reading from it.
+func NewEmulatedConn(conn net.Conn, profile types.NetworkProfile) *EmulatedConn {
+	c := &EmulatedConn{
+		Conn:            conn,
+		profile:         profile,
+		rand:            rand.New(rand.NewSource(time.Now().UnixNano())), //nolint:gosec
+		chunks:          make(chan emulatedChunk, emulatedReadBuffer),
+		done:            make(chan struct{}),
+		deadlineChanged: make(chan struct{}),
+	}
+	go c.readLoop()
+	return c
+}
+
+// delay returns the latency with a random jitter, which is never negative.
+func (c *EmulatedConn) delay() time.Duration {
+	d := time.Duration(c.profile.Latency)
+	if jitter := int64(c.profile.Jitter); jitter > 0 {
+		d += time.Duration(c.rand.Int63n(2*jitter+1) - jitter)
+	}
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// transmissionTime returns how long it takes to transfer n bytes with the
+// given bandwidth.
+func transmissionTime(n int, kbps float64) time.Duration {
+	if kbps <= 0 {
+		return 0
+	}
+	return time.Duration(float64(n) * 8 / (kbps * 1000) * float64(time.Second))
+}
+
+// readLoop reads the data from the real connection and schedules its
+// delivery. The chunks are delivered in order, after the previous one has
+// been transferred with the download bandwidth and the latency has passed.
+func (c *EmulatedConn) readLoop() {
+	var linkFree, lastDelivery time.Time
+	for {
+		buf := make([]byte, emulatedReadSize)
+		n, err := c.Conn.Read(buf)
+		if n == 0 && err == nil {
+			continue
+		}
+		now := time.Now()
+		if linkFree.Before(now) {
+			linkFree = now
+		}
+
+		for {
+			size := n
+			if size > emulatedChunkSize {
+				size = emulatedChunkSize
+			}
+			chunk := emulatedChunk{data: buf[:size]}
+			buf, n = buf[size:], n-size
+			if n == 0 {
+				// The error is delivered after the last data.
+				chunk.err = err
+			}
+
+			linkFree = linkFree.Add(transmissionTime(size, c.profile.DownloadKbps))
+			chunk.deliverAt = linkFree.Add(c.delay())
+			if size > 0 && c.profile.Loss > 0 && c.rand.Float64()*100 < c.profile.Loss {
+				retransmission := 2 * time.Duration(c.profile.Latency)
+				if retransmission < minRetransmissionDelay {
+					retransmission = minRetransmissionDelay
+				}
+				chunk.deliverAt = chunk.deliverAt.Add(retransmission)
+			}
+			if chunk.deliverAt.Before(lastDelivery) {
+				chunk.deliverAt = lastDelivery
+			}
+			lastDelivery = chunk.deliverAt
+
+			select {
+			case c.chunks <- chunk:
+			case <-c.done:
+				return
+			}
+			if n == 0 {
+				break
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Read reads the data which has arrived over the emulated network.
+func (c *EmulatedConn) Read(b []byte) (int, error) {
+	if len(c.pending.data) == 0 && c.pending.err == nil {
+		if err := c.nextChunk(); err != nil {
+			return 0, err
+		}
+	}
+	// The chunk is kept to be delivered by the next Read if the deadline is
+	// exceeded before it arrives.
+	if err := c.wait(c.pending.deliverAt, c.getReadDeadline()); err != nil {
+		return 0, err
+	}
+	if len(c.pending.data) > 0 {
+		n := copy(b, c.pending.data)
+		c.pending.data = c.pending.data[n:]
+		return n, nil
+	}
+	return 0, c.pending.err
+}
+
+// nextChunk waits for the next chunk read from the real connection, until
+// the read deadline, which can be changed while waiting.
+func (c *EmulatedConn) nextChunk() error {
+	for {
+		c.deadlineMx.Lock()
+		deadline, deadlineChanged := c.readDeadline, c.deadlineChanged
+		c.deadlineMx.Unlock()
+
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if !deadline.IsZero() {
+			timer = time.NewTimer(time.Until(deadline))
+			timeout = timer.C
+		}
+		var err error
+		select {
+		case c.pending = <-c.chunks:
+		case <-timeout:
+			err = os.ErrDeadlineExceeded
+		case <-deadlineChanged:
+			continue
+		case <-c.done:
+			err = net.ErrClosed
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		return err
+	}
+}
+
+// wait sleeps until the given time, unless the deadline is exceeded or the
+// connection is closed before it.
+func (c *EmulatedConn) wait(until, deadline time.Time) error {
+	d := time.Until(until)
+	if d <= 0 {
+		return nil
+	}
+	var err error
+	if !deadline.IsZero() && deadline.Before(until) {
+		d = time.Until(deadline)
+		err = os.ErrDeadlineExceeded
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return err
+	case <-c.done:
+		return net.ErrClosed
+	}
+}
+
+// Write writes the data after it has been transferred with the upload
+// bandwidth.
+func (c *EmulatedConn) Write(b []byte) (int, error) {
+	c.writeMx.Lock()
+	defer c.writeMx.Unlock()
+
+	var written int
+	for len(b) > 0 {
+		n := len(b)
+		if n > emulatedReadSize {
+			n = emulatedReadSize
+		}
+		now := time.Now()
+		if c.writeFree.Before(now) {
+			c.writeFree = now
+		}
+		c.writeFree = c.writeFree.Add(transmissionTime(n, c.profile.UploadKbps))
+		if err := c.wait(c.writeFree, time.Time{}); err != nil {
+			return written, err
+		}
+		w, err := c.Conn.Write(b[:n])
+		written += w
+		if err != nil {
+			return written, err
+		}
+		b = b[n:]
+	}
+	return written, nil
+}
+
+// Close closes the connection and stops reading from it.
+func (c *EmulatedConn) Close() error {
+	c.closeMx.Do(func() { close(c.done) })
+	return c.Conn.Close()
+}
+
+func (c *EmulatedConn) getReadDeadline() time.Time {
+	c.deadlineMx.Lock()
+	defer c.deadlineMx.Unlock()
+	return c.readDeadline
+}
+
+// SetReadDeadline sets the deadline of the emulated reads, the real
+// connection is always read from in the background.
+func (c *EmulatedConn) SetReadDeadline(t time.Time) error {
+	c.deadlineMx.Lock()
+	defer c.deadlineMx.Unlock()
+	c.readDeadline = t
+	close(c.deadlineChanged)
+	c.deadlineChanged = make(chan struct{})
+	return nil
+}
+
+// SetDeadline sets the read and the write deadlines.
+func (c *EmulatedConn) SetDeadline(t time.Time) error {
+	if err := c.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.Conn.SetWriteDeadline(t)
+}
+
+// dialDelay waits for the latency of the connection establishment, or until
+// the context is done.
+func dialDelay(ctx context.Context, profile *types.NetworkProfile) error {
+	d := time.Duration(profile.Latency)
+	if jitter := int64(profile.Jitter); jitter > 0 {
+		d += time.Duration(rand.Int63n(2*jitter+1) - jitter) //nolint:gosec
+	}
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,129 @@
+package netext
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib/types"
+)
+
+// listen starts a TCP server, which handles each connection with the given
+// function.
+func listen(t *testing.T, handle func(net.Conn)) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				handle(conn)
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestDialerNetworkProfile(t *testing.T) {
+	t.Parallel()
+
+	dialer := NewDialer(net.Dialer{}, newResolver())
+	profile := &types.NetworkProfile{Latency: types.Duration(100 * time.Millisecond)}
+	assert.True(t, dialer.SetNetworkProfile(profile))
+	assert.False(t, dialer.SetNetworkProfile(profile))
+
+	t.Run("latency", func(t *testing.T) {
+		t.Parallel()
+		addr := listen(t, func(conn net.Conn) { _, _ = io.Copy(conn, conn) })
+
+		start := time.Now()
+		conn, err := dialer.DialContext(context.Background(), "tcp", addr)
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+		assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+		start = time.Now()
+		_, err = conn.Write([]byte("ping"))
+		require.NoError(t, err)
+		buf := make([]byte, 4)
+		_, err = io.ReadFull(conn, buf)
+		require.NoError(t, err)
+		assert.Equal(t, "ping", string(buf))
+		assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	})
+
+	t.Run("canceled dial", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := dialer.DialContext(ctx, "tcp", "127.0.0.1:1")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestEmulatedConnBandwidth(t *testing.T) {
+	t.Parallel()
+
+	data := bytes.Repeat([]byte("k6"), 10*1024)
+	addr := listen(t, func(conn net.Conn) {
+		_, _ = conn.Write(data)
+	})
+
+	// 20KB are 160 kilobits, which take 200ms with 800kbps.
+	dialer := NewDialer(net.Dialer{}, newResolver())
+	dialer.SetNetworkProfile(&types.NetworkProfile{DownloadKbps: 800})
+	conn, err := dialer.DialContext(context.Background(), "tcp", addr)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	start := time.Now()
+	received, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Equal(t, data, received)
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	assert.Equal(t, int64(len(data)), dialer.BytesRead)
+}
+
+func TestEmulatedConnReadDeadline(t *testing.T) {
+	t.Parallel()
+
+	server, client := net.Pipe()
+	conn := NewEmulatedConn(client, types.NetworkProfile{Latency: types.Duration(300 * time.Millisecond)})
+	defer func() { _ = conn.Close() }()
+
+	go func() {
+		_, _ = server.Write([]byte("late"))
+	}()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(50*time.Millisecond)))
+	buf := make([]byte, 4)
+	_, err := conn.Read(buf)
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	var netErr net.Error
+	require.True(t, errors.As(err, &netErr))
+	assert.True(t, netErr.Timeout())
+
+	// The data isn't lost when the deadline is exceeded.
+	require.NoError(t, conn.SetReadDeadline(time.Time{}))
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "late", string(buf[:n]))
+
+	require.NoError(t, conn.Close())
+	_, err = conn.Read(buf)
+	assert.ErrorIs(t, err, net.ErrClosed)
+}
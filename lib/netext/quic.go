@@ -0,0 +1,106 @@
+package netext
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/quic-go/quic-go"
+)
+
+// DialQUIC resolves the given address the same way as DialContext and
+// establishes a new QUIC connection to it. Every connection uses its own UDP
+// socket, which is closed together with the connection.
+func (d *Dialer) DialQUIC(
+	ctx context.Context, addr string, tlsConf *tls.Config, conf *quic.Config,
+) (quic.EarlyConnection, error) {
+	udpAddr, err := d.ResolveUDPAddr(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	pconn, err := d.ListenPacket()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := quic.DialEarly(ctx, pconn, udpAddr, tlsConf, conf)
+	if err != nil {
+		_ = pconn.Close()
+		return nil, err
+	}
+	go func() {
+		<-conn.Context().Done()
+		_ = pconn.Close()
+	}()
+	return conn, nil
+}
+
+// ResolveUDPAddr resolves the given address the same way as DialContext, so
+// the blocked hostnames, the blacklisted IPs and the hosts option apply.
+func (d *Dialer) ResolveUDPAddr(ctx context.Context, addr string) (*net.UDPAddr, error) {
+	dialAddr, err := d.getDialAddr(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	return net.ResolveUDPAddr("udp", dialAddr)
+}
+
+// ListenPacket opens a new UDP socket on the local address of the dialer,
+// whose sent and received data is counted by the dialer. The destinations
+// should be resolved with ResolveUDPAddr.
+func (d *Dialer) ListenPacket() (*PacketConn, error) {
+	var localAddr *net.UDPAddr
+	if tcpAddr, ok := d.Dialer.LocalAddr.(*net.TCPAddr); ok {
+		localAddr = &net.UDPAddr{IP: tcpAddr.IP, Zone: tcpAddr.Zone}
+	}
+	udpConn, err := net.ListenUDP("udp", localAddr)
+	if err != nil {
+		return nil, err
+	}
+	return &PacketConn{PacketConn: udpConn, udpConn: udpConn, BytesRead: &d.BytesRead, BytesWritten: &d.BytesWritten}, nil
+}
+
+// PacketConn wraps a UDP net.PacketConn and keeps track of sent and received
+// data size. It doesn't expose the batch and OOB methods of net.UDPConn, so
+// all data goes through ReadFrom and WriteTo.
+type PacketConn struct {
+	net.PacketConn
+
+	udpConn                 *net.UDPConn
+	BytesRead, BytesWritten *int64
+}
+
+// ReadFrom reads a packet from the connection and counts its size.
+func (c *PacketConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	n, addr, err := c.PacketConn.ReadFrom(b)
+	if n > 0 {
+		atomic.AddInt64(c.BytesRead, int64(n))
+	}
+	return n, addr, err
+}
+
+// WriteTo writes a packet to the connection and counts its size.
+func (c *PacketConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	n, err := c.PacketConn.WriteTo(b, addr)
+	if n > 0 {
+		atomic.AddInt64(c.BytesWritten, int64(n))
+	}
+	return n, err
+}
+
+// SetReadBuffer sets the size of the operating system's receive buffer.
+func (c *PacketConn) SetReadBuffer(bytes int) error {
+	return c.udpConn.SetReadBuffer(bytes)
+}
+
+// SetWriteBuffer sets the size of the operating system's transmit buffer.
+func (c *PacketConn) SetWriteBuffer(bytes int) error {
+	return c.udpConn.SetWriteBuffer(bytes)
+}
+
+// SyscallConn returns the raw network connection.
+func (c *PacketConn) SyscallConn() (syscall.RawConn, error) {
+	return c.udpConn.SyscallConn()
+}
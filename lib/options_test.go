@@ -411,6 +411,45 @@ func TestOptions(t *testing.T) {
 		assert.True(t, opts.NoVUConnectionReuse.Valid)
 		assert.True(t, opts.NoVUConnectionReuse.Bool)
 	})
+	t.Run("HTTP3", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{HTTP3: null.BoolFrom(true)})
+		assert.True(t, opts.HTTP3.Valid)
+		assert.True(t, opts.HTTP3.Bool)
+	})
+	t.Run("Proxy", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{Proxy: null.StringFrom("socks5://127.0.0.1:1080")})
+		assert.True(t, opts.Proxy.Valid)
+		assert.Equal(t, "socks5://127.0.0.1:1080", opts.Proxy.String)
+		assert.Empty(t, opts.Validate())
+
+		opts = Options{}.Apply(Options{Proxy: null.StringFrom("ftp://127.0.0.1")})
+		errs := opts.Validate()
+		require.Len(t, errs, 1)
+		assert.Contains(t, errs[0].Error(), "the scheme should be http, https, socks5 or socks5h")
+	})
+	t.Run("Network", func(t *testing.T) {
+		t.Parallel()
+		var network types.NetworkConfig
+		require.NoError(t, json.Unmarshal([]byte(`{"profile":"3g","loss":2}`), &network))
+		opts := Options{}.Apply(Options{Network: network})
+		assert.True(t, opts.Network.Valid)
+		assert.Equal(t, []types.NetworkProfile{{
+			Profile:      "3g",
+			Latency:      types.Duration(100 * time.Millisecond),
+			DownloadKbps: 750,
+			UploadKbps:   250,
+			Loss:         2,
+		}}, opts.Network.Profiles)
+		assert.Empty(t, opts.Validate())
+
+		require.NoError(t, json.Unmarshal([]byte(`[{"profile":"4g"},{"latency":"1s","loss":101}]`), &network))
+		opts = Options{}.Apply(Options{Network: network})
+		errs := opts.Validate()
+		require.Len(t, errs, 1)
+		assert.Contains(t, errs[0].Error(), "invalid network profile #1: the network loss should be a percentage")
+	})
 	t.Run("NoCookiesReset", func(t *testing.T) {
 		t.Parallel()
 		opts := Options{}.Apply(Options{NoCookiesReset: null.BoolFrom(true)})
@@ -639,6 +678,27 @@ func TestOptionsEnv(t *testing.T) {
 			"true":  null.BoolFrom(true),
 			"false": null.BoolFrom(false),
 		},
+		{"HTTP3", "K6_HTTP3"}: {
+			"":      null.Bool{},
+			"true":  null.BoolFrom(true),
+			"false": null.BoolFrom(false),
+		},
+		{"Proxy", "K6_PROXY"}: {
+			"":                      null.String{},
+			"http://127.0.0.1:3128": null.StringFrom("http://127.0.0.1:3128"),
+		},
+		{"Network", "K6_NETWORK"}: {
+			"": types.NetworkConfig{},
+			"wifi": types.NetworkConfig{Profiles: []types.NetworkProfile{{
+				Profile:      "wifi",
+				Latency:      types.Duration(2 * time.Millisecond),
+				DownloadKbps: 30000,
+				UploadKbps:   15000,
+			}}, Valid: true},
+			`{"latency":"50ms"}`: types.NetworkConfig{Profiles: []types.NetworkProfile{{
+				Latency: types.Duration(50 * time.Millisecond),
+			}}, Valid: true},
+		},
 		{"UserAgent", "K6_USER_AGENT"}: {
 			"":    null.String{},
 			"Hi!": null.StringFrom("Hi!"),
@@ -44,6 +44,11 @@ type RuntimeOptions struct {
 	SummaryExport null.String `json:"summaryExport"`
 	KeyWriter     null.String `json:"-"`
 	TracesOutput  null.String `json:"tracesOutput"`
+
+	// Baseline is the path of a file, saved by an earlier test run with
+	// BaselineExport, that the thresholds can be compared against.
+	Baseline       null.String `json:"baseline"`
+	BaselineExport null.String `json:"baselineExport"`
 }
 
 // ValidateCompatibilityMode checks if the provided val is a valid compatibility mode
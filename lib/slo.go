@@ -0,0 +1,38 @@
+package lib
+
+import (
+	"time"
+
+	"go.k6.io/k6/metrics"
+)
+
+// SLOEvaluationDelay is how long SLOWatch.Evaluate waits for the samples that
+// were emitted before it was called to be processed. It's longer than the
+// period in which the metrics engine processes the buffered samples.
+const SLOEvaluationDelay = 100 * time.Millisecond
+
+// SLOWatcher evaluates service level objectives, which are defined with the
+// same syntax as the thresholds, on the metric samples of the test run while
+// it's running. It's implemented by the metrics engine, and the executors use
+// it to adapt the load to how the system under test behaves.
+type SLOWatcher interface {
+	// WatchSLOs starts watching the given objectives, keyed by the name of
+	// the metric or sub-metric they apply to.
+	WatchSLOs(slos map[string]metrics.Thresholds) (SLOWatch, error)
+}
+
+// SLOWatch is a set of objectives which are evaluated over a window of the
+// test run, which starts when the watch is created or reset.
+type SLOWatch interface {
+	// Reset discards the samples of the current window and starts a new one.
+	// If the window was evaluated, the new one starts where it ended, so the
+	// samples emitted since then aren't lost.
+	Reset()
+	// Evaluate ends the current window and returns the names of the metrics
+	// whose objectives weren't met in it, if any. The metrics without
+	// samples in the window are considered as meeting their objectives. It
+	// blocks for SLOEvaluationDelay, so the late samples are counted.
+	Evaluate() (breached []string, err error)
+	// Stop stops watching the objectives.
+	Stop()
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"go.k6.io/k6/event"
+	"go.k6.io/k6/lib/har"
 	"go.k6.io/k6/lib/trace"
 	"go.k6.io/k6/metrics"
 )
@@ -19,6 +20,7 @@ type TestPreInitState struct {
 	BuiltinMetrics *metrics.BuiltinMetrics
 	Events         *event.System
 	KeyLogger      io.Writer
+	HARRecorder    *har.Recorder
 	LookupEnv      func(key string) (val string, ok bool)
 	Logger         logrus.FieldLogger
 	TracerProvider *trace.TracerProvider
@@ -35,6 +37,10 @@ type TestRunState struct {
 
 	GroupSummary *GroupSummary // TODO(@mstoykov): move and rename
 
+	// SLOWatcher is nil when the metric samples aren't processed by k6,
+	// i.e. when both the thresholds and the end-of-test summary are disabled.
+	SLOWatcher SLOWatcher
+
 	// TODO: add other properties that are computed or derived after init, e.g.
 	// thresholds?
 }
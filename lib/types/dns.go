@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/url"
 	"strings"
 
 	"gopkg.in/guregu/null.v3"
@@ -17,6 +19,10 @@ type DNSConfig struct {
 	Select NullDNSSelect `json:"select"`
 	// Policy specifies how to handle returning of IPv4 or IPv6 addresses.
 	Policy NullDNSPolicy `json:"policy"`
+	// Nameservers are the DNS servers which are queried instead of the system resolver.
+	Nameservers []DNSNameserver `json:"nameservers"`
+	// Cache specifies whether the cached DNS lookups are shared by all VUs or are per VU.
+	Cache NullDNSCache `json:"cache"`
 	// FIXME: Valid is unused and is only added to satisfy some logic in
 	// lib.Options.ForEachSpecified(), otherwise it would panic with
 	// `reflect: call of reflect.Value.Bool on zero Value`.
@@ -29,6 +35,7 @@ func DefaultDNSConfig() DNSConfig {
 		TTL:    null.NewString("5m", false),
 		Select: NullDNSSelect{DNSrandom, false},
 		Policy: NullDNSPolicy{DNSpreferIPv4, false},
+		Cache:  NullDNSCache{DNSshared, false},
 	}
 }
 
@@ -167,18 +174,171 @@ func (d NullDNSSelect) MarshalJSON() ([]byte, error) {
 	return json.Marshal(d.DNSSelect)
 }
 
+// DNSCache specifies which VUs the cached DNS lookups are shared by.
+//
+//go:generate enumer -type=DNSCache -trimprefix DNS -output dns_cache_gen.go
+type DNSCache uint8
+
+// These are lower camel cased since enumer doesn't support it as a transform option.
+// See https://github.com/alvaroloes/enumer/pull/60 .
+const (
+	// DNSshared caches the lookups for all the VUs of the instance.
+	DNSshared DNSCache = iota + 1
+	// DNSvu caches the lookups separately for each VU, so each VU looks up
+	// every host at least once, like separate clients would.
+	DNSvu
+)
+
+// UnmarshalJSON converts JSON data to a valid DNSCache
+func (d *DNSCache) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte(`null`)) {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := DNSCacheString(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// MarshalJSON returns the JSON representation of d.
+func (d DNSCache) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// NullDNSCache is a nullable wrapper around DNSCache, required for the
+// current configuration system.
+type NullDNSCache struct {
+	DNSCache
+	Valid bool
+}
+
+// UnmarshalJSON converts JSON data to a valid NullDNSCache.
+func (d *NullDNSCache) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte(`null`)) {
+		return nil
+	}
+	if err := json.Unmarshal(data, &d.DNSCache); err != nil {
+		return err
+	}
+	d.Valid = true
+	return nil
+}
+
+// MarshalJSON returns the JSON representation of d.
+func (d NullDNSCache) MarshalJSON() ([]byte, error) {
+	if !d.Valid {
+		return []byte(`null`), nil
+	}
+	return json.Marshal(d.DNSCache)
+}
+
+// DNSNameserver is a DNS server which is queried instead of the system
+// resolver, over UDP, TCP, TLS (DoT) or HTTPS (DoH).
+type DNSNameserver struct {
+	// Protocol is one of udp, tcp, tls or https.
+	Protocol string
+	// Address is the host:port of the server, or its URL for HTTPS.
+	Address string
+}
+
+// ParseDNSNameserver parses the address of a nameserver, which is an IP or
+// a host with an optional port for UDP, or a URL with the udp://, tcp://,
+// tls:// or https:// scheme.
+func ParseDNSNameserver(s string) (DNSNameserver, error) {
+	protocol, address := "udp", s
+	if i := strings.Index(s, "://"); i >= 0 {
+		protocol, address = s[:i], s[i+3:]
+	}
+
+	var defaultPort string
+	switch protocol {
+	case "udp", "tcp":
+		defaultPort = "53"
+	case "tls":
+		defaultPort = "853"
+	case "https":
+		u, err := url.Parse(s)
+		if err != nil || u.Host == "" {
+			return DNSNameserver{}, fmt.Errorf("invalid DNS over HTTPS URL '%s'", s)
+		}
+		return DNSNameserver{Protocol: protocol, Address: s}, nil
+	default:
+		return DNSNameserver{}, fmt.Errorf(
+			"unsupported nameserver protocol '%s', it should be udp, tcp, tls or https", protocol)
+	}
+
+	if address == "" {
+		return DNSNameserver{}, fmt.Errorf("invalid nameserver '%s', it doesn't contain a host", s)
+	}
+	// The IPv6 addresses without a port don't need the brackets.
+	if ip := net.ParseIP(address); ip != nil {
+		return DNSNameserver{Protocol: protocol, Address: net.JoinHostPort(address, defaultPort)}, nil
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		host, port = address, defaultPort
+	}
+	if host == "" || strings.ContainsAny(host, "/[]") {
+		return DNSNameserver{}, fmt.Errorf("invalid nameserver '%s'", s)
+	}
+	return DNSNameserver{Protocol: protocol, Address: net.JoinHostPort(host, port)}, nil
+}
+
+// String returns the address of the nameserver as a URL.
+func (n DNSNameserver) String() string {
+	if n.Protocol == "https" {
+		return n.Address
+	}
+	return n.Protocol + "://" + n.Address
+}
+
+// UnmarshalJSON converts the address of a nameserver to a DNSNameserver.
+func (n *DNSNameserver) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := ParseDNSNameserver(s)
+	if err != nil {
+		return err
+	}
+	*n = v
+	return nil
+}
+
+// MarshalJSON returns the JSON representation of n.
+func (n DNSNameserver) MarshalJSON() ([]byte, error) {
+	return json.Marshal(n.String())
+}
+
 // String implements fmt.Stringer.
 func (c DNSConfig) String() string {
-	return fmt.Sprintf("ttl=%s,select=%s,policy=%s",
-		c.TTL.String, c.Select.String(), c.Policy.String())
+	s := fmt.Sprintf("ttl=%s,select=%s,policy=%s,cache=%s",
+		c.TTL.String, c.Select.String(), c.Policy.String(), c.Cache.String())
+	if len(c.Nameservers) > 0 {
+		nameservers := make([]string, len(c.Nameservers))
+		for i, n := range c.Nameservers {
+			nameservers[i] = n.String()
+		}
+		s += ",nameservers=" + strings.Join(nameservers, "|")
+	}
+	return s
 }
 
 // UnmarshalJSON implements json.Unmarshaler.
 func (c *DNSConfig) UnmarshalJSON(data []byte) error {
 	var s struct {
-		TTL    null.String   `json:"ttl"`
-		Select NullDNSSelect `json:"select"`
-		Policy NullDNSPolicy `json:"policy"`
+		TTL         null.String     `json:"ttl"`
+		Select      NullDNSSelect   `json:"select"`
+		Policy      NullDNSPolicy   `json:"policy"`
+		Nameservers []DNSNameserver `json:"nameservers"`
+		Cache       NullDNSCache    `json:"cache"`
 	}
 	if err := json.Unmarshal(data, &s); err != nil {
 		return err
@@ -186,6 +346,8 @@ func (c *DNSConfig) UnmarshalJSON(data []byte) error {
 	c.TTL = s.TTL
 	c.Select = s.Select
 	c.Policy = s.Policy
+	c.Nameservers = s.Nameservers
+	c.Cache = s.Cache
 	return nil
 }
 
@@ -226,6 +388,22 @@ func (c *DNSConfig) unmarshal(params map[string]string) error {
 			c.Select.Valid = true
 		case "ttl":
 			c.TTL = null.StringFrom(v)
+		case "cache":
+			cache, err := DNSCacheString(v)
+			if err != nil {
+				return err
+			}
+			c.Cache.DNSCache = cache
+			c.Cache.Valid = true
+		case "nameservers":
+			c.Nameservers = nil
+			for _, address := range strings.Split(v, "|") {
+				n, err := ParseDNSNameserver(address)
+				if err != nil {
+					return err
+				}
+				c.Nameservers = append(c.Nameservers, n)
+			}
 		default:
 			return fmt.Errorf("unknown DNS configuration field: %s", k)
 		}
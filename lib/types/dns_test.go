@@ -0,0 +1,92 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+)
+
+func TestParseDNSNameserver(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		input    string
+		expected DNSNameserver
+		err      string
+	}{
+		{input: "1.1.1.1", expected: DNSNameserver{"udp", "1.1.1.1:53"}},
+		{input: "1.1.1.1:5353", expected: DNSNameserver{"udp", "1.1.1.1:5353"}},
+		{input: "2606:4700:4700::1111", expected: DNSNameserver{"udp", "[2606:4700:4700::1111]:53"}},
+		{input: "tcp://[::1]:5353", expected: DNSNameserver{"tcp", "[::1]:5353"}},
+		{input: "tls://dns.example.com", expected: DNSNameserver{"tls", "dns.example.com:853"}},
+		{
+			input:    "https://dns.example.com/dns-query",
+			expected: DNSNameserver{"https", "https://dns.example.com/dns-query"},
+		},
+		{input: "quic://1.1.1.1", err: "unsupported nameserver protocol 'quic'"},
+		{input: "tcp://", err: "it doesn't contain a host"},
+		{input: "https:///dns-query", err: "invalid DNS over HTTPS URL"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.input, func(t *testing.T) {
+			t.Parallel()
+
+			n, err := ParseDNSNameserver(tc.input)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, n)
+
+			// The string representation is parsed to the same nameserver.
+			n2, err := ParseDNSNameserver(n.String())
+			require.NoError(t, err)
+			assert.Equal(t, n, n2)
+		})
+	}
+}
+
+func TestDNSConfigUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	nameservers := []DNSNameserver{{"udp", "1.1.1.1:53"}, {"https", "https://dns.example.com/dns-query"}}
+
+	t.Run("text", func(t *testing.T) {
+		t.Parallel()
+
+		c := DefaultDNSConfig()
+		require.NoError(t, c.UnmarshalText([]byte("ttl=1m,cache=vu,nameservers=1.1.1.1|https://dns.example.com/dns-query")))
+		assert.Equal(t, null.StringFrom("1m"), c.TTL)
+		assert.Equal(t, NullDNSCache{DNSCache: DNSvu, Valid: true}, c.Cache)
+		assert.Equal(t, nameservers, c.Nameservers)
+		assert.Equal(t, "ttl=1m,select=random,policy=preferIPv4,cache=vu,"+
+			"nameservers=udp://1.1.1.1:53|https://dns.example.com/dns-query", c.String())
+
+		assert.ErrorContains(t, c.UnmarshalText([]byte("cache=global")), "does not belong to DNSCache values")
+		assert.ErrorContains(t, c.UnmarshalText([]byte("nameservers=1.1.1.1|ftp://1.0.0.1")),
+			"unsupported nameserver protocol 'ftp'")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		var c DNSConfig
+		require.NoError(t, json.Unmarshal(
+			[]byte(`{"cache":"shared","nameservers":["1.1.1.1","https://dns.example.com/dns-query"]}`), &c))
+		assert.Equal(t, NullDNSCache{DNSCache: DNSshared, Valid: true}, c.Cache)
+		assert.Equal(t, nameservers, c.Nameservers)
+
+		data, err := json.Marshal(c)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"ttl":null,"select":null,"policy":null,"cache":"shared",`+
+			`"nameservers":["udp://1.1.1.1:53","https://dns.example.com/dns-query"]}`, string(data))
+
+		assert.ErrorContains(t, json.Unmarshal([]byte(`{"cache":"none"}`), &c), "does not belong to DNSCache values")
+	})
+}
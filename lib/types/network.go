@@ -0,0 +1,223 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NetworkProfile describes the conditions of an emulated network.
+type NetworkProfile struct {
+	// Profile is the name of the preset the profile is based on, if any.
+	Profile string `json:"profile,omitempty"`
+	// Latency is the round-trip time added to the connections.
+	Latency Duration `json:"latency"`
+	// Jitter is the maximum random variation of the latency.
+	Jitter Duration `json:"jitter"`
+	// DownloadKbps and UploadKbps are the bandwidth limits in kilobits per
+	// second, zero means unlimited.
+	DownloadKbps float64 `json:"downloadKbps"`
+	UploadKbps   float64 `json:"uploadKbps"`
+	// Loss is the percentage of the packets which are lost and retransmitted.
+	Loss float64 `json:"loss"`
+	// Weight is the share of the VUs the profile is assigned to, when more
+	// than one profile is configured.
+	Weight float64 `json:"weight,omitempty"`
+}
+
+// networkPresets are the predefined network profiles, with the same values
+// as the ones in the browser developer tools.
+//
+//nolint:gochecknoglobals
+var networkPresets = map[string]NetworkProfile{
+	"gprs":    {Latency: Duration(500 * time.Millisecond), DownloadKbps: 50, UploadKbps: 20},
+	"2g":      {Latency: Duration(300 * time.Millisecond), DownloadKbps: 250, UploadKbps: 50},
+	"good-2g": {Latency: Duration(150 * time.Millisecond), DownloadKbps: 450, UploadKbps: 150},
+	"3g":      {Latency: Duration(100 * time.Millisecond), DownloadKbps: 750, UploadKbps: 250},
+	"good-3g": {Latency: Duration(40 * time.Millisecond), DownloadKbps: 1500, UploadKbps: 750},
+	"4g":      {Latency: Duration(20 * time.Millisecond), DownloadKbps: 4000, UploadKbps: 3000},
+	"dsl":     {Latency: Duration(5 * time.Millisecond), DownloadKbps: 2000, UploadKbps: 1000},
+	"wifi":    {Latency: Duration(2 * time.Millisecond), DownloadKbps: 30000, UploadKbps: 15000},
+}
+
+// NetworkPreset returns the predefined network profile with the given name.
+func NetworkPreset(name string) (NetworkProfile, error) {
+	p, ok := networkPresets[strings.ToLower(name)]
+	if !ok {
+		names := make([]string, 0, len(networkPresets))
+		for n := range networkPresets {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return NetworkProfile{}, fmt.Errorf(
+			"unknown network profile '%s', the available ones are %s", name, strings.Join(names, ", "))
+	}
+	p.Profile = strings.ToLower(name)
+	return p, nil
+}
+
+// UnmarshalJSON converts the name of a preset, or an object with custom
+// values optionally based on a preset, to a NetworkProfile.
+func (p *NetworkProfile) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var name string
+		if err := json.Unmarshal(data, &name); err != nil {
+			return err
+		}
+		preset, err := NetworkPreset(name)
+		if err != nil {
+			return err
+		}
+		*p = preset
+		return nil
+	}
+
+	var base struct {
+		Profile string `json:"profile"`
+	}
+	if err := json.Unmarshal(data, &base); err != nil {
+		return err
+	}
+	var profile NetworkProfile
+	if base.Profile != "" {
+		preset, err := NetworkPreset(base.Profile)
+		if err != nil {
+			return err
+		}
+		profile = preset
+	}
+	// The custom values override the ones of the preset.
+	type rawProfile NetworkProfile
+	if err := json.Unmarshal(data, (*rawProfile)(&profile)); err != nil {
+		return err
+	}
+	profile.Profile = strings.ToLower(profile.Profile)
+	*p = profile
+	return nil
+}
+
+// Validate checks that the values of the profile are in range.
+func (p NetworkProfile) Validate() error {
+	switch {
+	case p.Latency < 0:
+		return errors.New("the network latency can't be negative")
+	case p.Jitter < 0:
+		return errors.New("the network jitter can't be negative")
+	case p.DownloadKbps < 0 || p.UploadKbps < 0:
+		return errors.New("the network bandwidth can't be negative")
+	case p.Loss < 0 || p.Loss > 100:
+		return errors.New("the network loss should be a percentage between 0 and 100")
+	case p.Weight < 0:
+		return errors.New("the network profile weight can't be negative")
+	}
+	return nil
+}
+
+// NetworkConfig is the configuration of the network emulation, which is
+// either a single profile for all VUs or a list of profiles which are
+// spread across the VUs in proportion to their weights.
+type NetworkConfig struct {
+	Profiles []NetworkProfile
+	Valid    bool
+}
+
+// UnmarshalJSON converts JSON data to a valid NetworkConfig.
+func (c *NetworkConfig) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte(`null`)) {
+		*c = NetworkConfig{}
+		return nil
+	}
+	var profiles []NetworkProfile
+	if len(data) > 0 && data[0] == '[' {
+		if err := json.Unmarshal(data, &profiles); err != nil {
+			return err
+		}
+		if len(profiles) == 0 {
+			return errors.New("the list of network profiles is empty")
+		}
+	} else {
+		var p NetworkProfile
+		if err := json.Unmarshal(data, &p); err != nil {
+			return err
+		}
+		profiles = []NetworkProfile{p}
+	}
+	*c = NetworkConfig{Profiles: profiles, Valid: true}
+	return nil
+}
+
+// UnmarshalText converts the name of a preset, or the JSON configuration,
+// to a valid NetworkConfig.
+func (c *NetworkConfig) UnmarshalText(text []byte) error {
+	text = bytes.TrimSpace(text)
+	if len(text) == 0 {
+		*c = NetworkConfig{}
+		return nil
+	}
+	if text[0] != '{' && text[0] != '[' {
+		text = []byte(fmt.Sprintf("%q", text))
+	}
+	return c.UnmarshalJSON(text)
+}
+
+// MarshalJSON returns the JSON representation of c.
+func (c NetworkConfig) MarshalJSON() ([]byte, error) {
+	if !c.Valid {
+		return []byte(`null`), nil
+	}
+	if len(c.Profiles) == 1 && c.Profiles[0].Weight == 0 {
+		return json.Marshal(c.Profiles[0])
+	}
+	return json.Marshal(c.Profiles)
+}
+
+// Validate checks all the profiles of the configuration.
+func (c NetworkConfig) Validate() error {
+	for i, p := range c.Profiles {
+		if err := p.Validate(); err != nil {
+			if len(c.Profiles) == 1 {
+				return err
+			}
+			return fmt.Errorf("invalid network profile #%d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ForVU returns the profile of the VU with the given global ID, or nil if
+// the network isn't emulated. The VUs are spread evenly across the profiles
+// in proportion to their weights, which are equal when none is specified.
+func (c NetworkConfig) ForVU(idGlobal uint64) *NetworkProfile {
+	if !c.Valid || len(c.Profiles) == 0 {
+		return nil
+	}
+	// The global IDs of the VUs start from 1.
+	index := idGlobal
+	if index > 0 {
+		index--
+	}
+	var total float64
+	for _, p := range c.Profiles {
+		total += p.Weight
+	}
+	if total == 0 {
+		return &c.Profiles[index%uint64(len(c.Profiles))]
+	}
+
+	// The golden ratio sequence spreads any number of VUs proportionally.
+	_, pos := math.Modf(float64(index) * (math.Sqrt(5) - 1) / 2)
+	pos *= total
+	for i := range c.Profiles {
+		if pos < c.Profiles[i].Weight {
+			return &c.Profiles[i]
+		}
+		pos -= c.Profiles[i].Weight
+	}
+	return &c.Profiles[len(c.Profiles)-1]
+}
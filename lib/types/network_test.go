@@ -0,0 +1,104 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetworkConfigUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	threeG := NetworkProfile{
+		Profile:      "3g",
+		Latency:      Duration(100 * time.Millisecond),
+		DownloadKbps: 750,
+		UploadKbps:   250,
+	}
+
+	testCases := []struct {
+		name, data string
+		expected   []NetworkProfile
+		err        string
+	}{
+		{name: "preset", data: `"3G"`, expected: []NetworkProfile{threeG}},
+		{
+			name: "custom",
+			data: `{"latency":"250ms","jitter":50,"downloadKbps":1000,"loss":1.5}`,
+			expected: []NetworkProfile{{
+				Latency:      Duration(250 * time.Millisecond),
+				Jitter:       Duration(50 * time.Millisecond),
+				DownloadKbps: 1000,
+				Loss:         1.5,
+			}},
+		},
+		{
+			name: "overridden preset",
+			data: `{"profile":"3g","uploadKbps":100}`,
+			expected: []NetworkProfile{{
+				Profile:      "3g",
+				Latency:      Duration(100 * time.Millisecond),
+				DownloadKbps: 750,
+				UploadKbps:   100,
+			}},
+		},
+		{
+			name: "weighted",
+			data: `[{"profile":"3g","weight":3},{"latency":"1s","weight":1}]`,
+			expected: []NetworkProfile{
+				{Profile: "3g", Latency: Duration(100 * time.Millisecond), DownloadKbps: 750, UploadKbps: 250, Weight: 3},
+				{Latency: Duration(time.Second), Weight: 1},
+			},
+		},
+		{name: "unknown preset", data: `"5g"`, err: "unknown network profile '5g', the available ones are 2g, 3g,"},
+		{name: "empty list", data: `[]`, err: "the list of network profiles is empty"},
+		{name: "invalid latency", data: `{"latency":"fast"}`, err: `invalid duration "fast"`},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var c NetworkConfig
+			err := json.Unmarshal([]byte(tc.data), &c)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, c.Valid)
+			assert.Equal(t, tc.expected, c.Profiles)
+
+			// The marshaled config is unmarshaled to the same one.
+			data, err := json.Marshal(c)
+			require.NoError(t, err)
+			var c2 NetworkConfig
+			require.NoError(t, json.Unmarshal(data, &c2))
+			assert.Equal(t, c, c2)
+		})
+	}
+}
+
+func TestNetworkConfigForVU(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, NetworkConfig{}.ForVU(1))
+
+	c := NetworkConfig{Profiles: []NetworkProfile{{Profile: "a"}, {Profile: "b"}}, Valid: true}
+	assert.Equal(t, "a", c.ForVU(1).Profile)
+	assert.Equal(t, "b", c.ForVU(2).Profile)
+	assert.Equal(t, "a", c.ForVU(3).Profile)
+	assert.Same(t, c.ForVU(1), c.ForVU(3))
+
+	c = NetworkConfig{Profiles: []NetworkProfile{{Profile: "a", Weight: 3}, {Profile: "b", Weight: 1}}, Valid: true}
+	counts := map[string]int{}
+	for id := uint64(1); id <= 100; id++ {
+		counts[c.ForVU(id).Profile]++
+	}
+	assert.InDelta(t, 75, counts["a"], 2)
+	assert.InDelta(t, 25, counts["b"], 2)
+}
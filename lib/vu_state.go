@@ -6,12 +6,14 @@ import (
 	"net"
 	"net/http"
 	"net/http/cookiejar"
+	"net/url"
 	"sync"
 
 	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/time/rate"
 
+	"go.k6.io/k6/lib/har"
 	"go.k6.io/k6/metrics"
 )
 
@@ -48,8 +50,14 @@ type State struct {
 	// TODO: move a lot of the things below to the k6/http ModuleInstance, see
 	// https://github.com/grafana/k6/issues/2293.
 	Transport http.RoundTripper
-	CookieJar *cookiejar.Jar
-	TLSConfig *tls.Config
+	// Used instead of Transport for the requests that are sent over HTTP/3.
+	HTTP3Transport http.RoundTripper
+	CookieJar      *cookiejar.Jar
+	TLSConfig      *tls.Config
+	// Records the HTTP requests in a HAR file, if that's enabled.
+	HARRecorder *har.Recorder
+	// The parsed proxy option, used by the HTTP requests without their own proxy.
+	Proxy *url.URL
 
 	// Rate limits.
 	RPSLimit *rate.Limiter
@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.k6.io/k6/lib/types"
+)
+
+// defaultBaselineTrendStats are the Trend values that are always saved in a
+// baseline, in addition to the ones from the summary and the thresholds.
+var defaultBaselineTrendStats = []string{ //nolint:gochecknoglobals
+	"avg", "min", "med", "max", "count", "p(90)", "p(95)", "p(99)",
+}
+
+// Baseline holds the aggregated metric values of a test run, so that later
+// test runs can be compared against it.
+type Baseline struct {
+	TestRunDuration types.Duration            `json:"testRunDuration"`
+	Metrics         map[string]BaselineMetric `json:"metrics"`
+}
+
+// BaselineMetric holds the aggregated values of a single metric or sub-metric
+// in a Baseline, keyed by their threshold aggregation method, e.g. "p(95)".
+type BaselineMetric struct {
+	Type     MetricType         `json:"type"`
+	Contains ValueType          `json:"contains"`
+	Values   map[string]float64 `json:"values"`
+}
+
+// NewBaseline creates a new Baseline from the given observed metrics, the same
+// way as the end-of-test summary. Besides the given Trend stats, it contains
+// all of the values the metric thresholds are evaluated against.
+func NewBaseline(observed map[string]*Metric, duration time.Duration, trendStats []string) (*Baseline, error) {
+	b := &Baseline{
+		TestRunDuration: types.Duration(duration),
+		Metrics:         make(map[string]BaselineMetric, len(observed)),
+	}
+
+	for name, m := range observed {
+		stats := append(append([]string{}, defaultBaselineTrendStats...), trendStats...)
+		for _, t := range m.Thresholds.Thresholds {
+			if t.parsed != nil && t.parsed.AggregationMethod == tokenPercentile {
+				stats = append(stats, t.parsed.SinkKey())
+			}
+		}
+
+		values, err := sinkBaselineValues(m.Sink, duration, stats)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create a baseline for metric %s: %w", name, err)
+		}
+		b.Metrics[name] = BaselineMetric{Type: m.Type, Contains: m.Contains, Values: values}
+	}
+
+	return b, nil
+}
+
+func sinkBaselineValues(sink Sink, duration time.Duration, trendStats []string) (map[string]float64, error) {
+	values := make(map[string]float64)
+	switch sink := sink.(type) {
+	case *CounterSink:
+		values["count"] = sink.Value
+		if duration > 0 {
+			values["rate"] = sink.Value / duration.Seconds()
+		}
+	case *GaugeSink:
+		values["value"] = sink.Value
+		values["min"] = sink.Min
+		values["max"] = sink.Max
+	case *RateSink:
+		if sink.Total > 0 {
+			values["rate"] = float64(sink.Trues) / float64(sink.Total)
+		}
+		values["passes"] = float64(sink.Trues)
+		values["fails"] = float64(sink.Total - sink.Trues)
+	case *TrendSink:
+		resolvers, err := GetResolversForTrendColumns(trendStats)
+		if err != nil {
+			return nil, err
+		}
+		for stat, resolve := range resolvers {
+			values[normalizeTrendStat(stat)] = resolve(sink)
+		}
+	default:
+		return nil, fmt.Errorf("unknown sink type %T", sink)
+	}
+	return values, nil
+}
+
+// normalizeTrendStat makes sure percentile stats use the same keys as the
+// thresholds, e.g. "p(95.0)" becomes "p(95)".
+func normalizeTrendStat(stat string) string {
+	method, value, err := parseThresholdAggregationMethod(stat)
+	if err != nil || method != tokenPercentile {
+		return stat
+	}
+	return fmt.Sprintf("%s(%g)", tokenPercentile, value.Float64)
+}
+
+// ParseBaseline parses a Baseline from its JSON representation.
+func ParseBaseline(data []byte) (*Baseline, error) {
+	b := &Baseline{}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("invalid baseline: %w", err)
+	}
+	if b.Metrics == nil {
+		return nil, fmt.Errorf("invalid baseline: it doesn't contain any metrics")
+	}
+	return b, nil
+}
+
+// MetricNames returns the sorted names of all metrics in the baseline.
+func (b *Baseline) MetricNames() []string {
+	names := make([]string, 0, len(b.Metrics))
+	for name := range b.Metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Values returns the values of the metric with the given name, or nil if the
+// baseline doesn't contain it.
+func (b *Baseline) Values(metricName string) map[string]float64 {
+	if b == nil {
+		return nil
+	}
+	return b.Metrics[metricName].Values
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBaseline(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	trend, err := r.NewMetric("my_trend", Trend, Time)
+	require.NoError(t, err)
+	for i := 1; i <= 100; i++ {
+		trend.Sink.Add(Sample{Value: float64(i)})
+	}
+	trend.Thresholds = NewThresholds([]string{"p(99.9)<baseline"})
+	require.NoError(t, trend.Thresholds.Parse())
+
+	counter, err := r.NewMetric("my_counter", Counter)
+	require.NoError(t, err)
+	counter.Sink.Add(Sample{Value: 10, Time: time.Now()})
+
+	rate, err := r.NewMetric("my_rate", Rate)
+	require.NoError(t, err)
+	rate.Sink.Add(Sample{Value: 1})
+	rate.Sink.Add(Sample{Value: 0})
+
+	observed := map[string]*Metric{trend.Name: trend, counter.Name: counter, rate.Name: rate}
+	baseline, err := NewBaseline(observed, 5*time.Second, []string{"p(99.0)"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"my_counter", "my_rate", "my_trend"}, baseline.MetricNames())
+	assert.Equal(t, map[string]float64{"count": 10, "rate": 2}, baseline.Values("my_counter"))
+	assert.Equal(t, map[string]float64{"rate": 0.5, "passes": 1, "fails": 1}, baseline.Values("my_rate"))
+
+	trendValues := baseline.Values("my_trend")
+	assert.Equal(t, 50.5, trendValues["avg"])
+	assert.Equal(t, float64(100), trendValues["count"])
+	assert.Contains(t, trendValues, "p(95)")
+	assert.Contains(t, trendValues, "p(99)")
+	assert.Contains(t, trendValues, "p(99.9)")
+	assert.Equal(t, Time, baseline.Metrics["my_trend"].Contains)
+
+	data, err := json.Marshal(baseline)
+	require.NoError(t, err)
+	parsed, err := ParseBaseline(data)
+	require.NoError(t, err)
+	assert.Equal(t, baseline, parsed)
+
+	_, err = ParseBaseline([]byte(`{}`))
+	require.ErrorContains(t, err, "it doesn't contain any metrics")
+	assert.Nil(t, (*Baseline)(nil).Values("my_trend"))
+}
+
+func TestThresholdsBaseline(t *testing.T) {
+	t.Parallel()
+
+	thresholds := NewThresholds([]string{"p(95)<baseline*1.1", "avg<300"})
+	require.NoError(t, thresholds.Parse())
+	assert.True(t, thresholds.UsesBaseline())
+	assert.True(t, thresholds.Thresholds[0].UsesBaseline())
+	assert.False(t, thresholds.Thresholds[1].UsesBaseline())
+
+	_, err := thresholds.Run(getTrendSink(100, 200), 0)
+	require.ErrorContains(t, err, "no baseline value was provided")
+
+	require.ErrorContains(t, thresholds.SetBaseline(map[string]float64{"avg": 10}),
+		"the baseline doesn't contain the p(95) value")
+	require.NoError(t, thresholds.SetBaseline(map[string]float64{"p(95)": 200}))
+
+	value, factor := thresholds.Thresholds[0].BaselineValue()
+	assert.Equal(t, 200.0, value.Float64)
+	assert.Equal(t, 1.1, factor)
+
+	ok, err := thresholds.Run(getTrendSink(100, 200), 0)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = thresholds.Run(getTrendSink(100, 250), 0)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = thresholds.RunOnValues(map[string]float64{"p(95)": 210, "avg": 100})
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -16,11 +16,13 @@ const (
 	HTTPReqFailedName         = "http_req_failed"
 	HTTPReqDurationName       = "http_req_duration"
 	HTTPReqBlockedName        = "http_req_blocked"
+	HTTPReqDNSLookupName      = "http_req_dns_lookup"
 	HTTPReqConnectingName     = "http_req_connecting"
 	HTTPReqTLSHandshakingName = "http_req_tls_handshaking"
 	HTTPReqSendingName        = "http_req_sending"
 	HTTPReqWaitingName        = "http_req_waiting"
 	HTTPReqReceivingName      = "http_req_receiving"
+	HTTPReqSchemaValidName    = "http_req_schema_valid"
 
 	WSSessionsName         = "ws_sessions"
 	WSMessagesSentName     = "ws_msgs_sent"
@@ -52,11 +54,13 @@ type BuiltinMetrics struct {
 	HTTPReqFailed         *Metric
 	HTTPReqDuration       *Metric
 	HTTPReqBlocked        *Metric
+	HTTPReqDNSLookup      *Metric
 	HTTPReqConnecting     *Metric
 	HTTPReqTLSHandshaking *Metric
 	HTTPReqSending        *Metric
 	HTTPReqWaiting        *Metric
 	HTTPReqReceiving      *Metric
+	HTTPReqSchemaValid    *Metric
 
 	// Websocket-related
 	WSSessions         *Metric
@@ -90,11 +94,13 @@ func RegisterBuiltinMetrics(registry *Registry) *BuiltinMetrics {
 		HTTPReqFailed:         registry.MustNewMetric(HTTPReqFailedName, Rate),
 		HTTPReqDuration:       registry.MustNewMetric(HTTPReqDurationName, Trend, Time),
 		HTTPReqBlocked:        registry.MustNewMetric(HTTPReqBlockedName, Trend, Time),
+		HTTPReqDNSLookup:      registry.MustNewMetric(HTTPReqDNSLookupName, Trend, Time),
 		HTTPReqConnecting:     registry.MustNewMetric(HTTPReqConnectingName, Trend, Time),
 		HTTPReqTLSHandshaking: registry.MustNewMetric(HTTPReqTLSHandshakingName, Trend, Time),
 		HTTPReqSending:        registry.MustNewMetric(HTTPReqSendingName, Trend, Time),
 		HTTPReqWaiting:        registry.MustNewMetric(HTTPReqWaitingName, Trend, Time),
 		HTTPReqReceiving:      registry.MustNewMetric(HTTPReqReceivingName, Trend, Time),
+		HTTPReqSchemaValid:    registry.MustNewMetric(HTTPReqSchemaValidName, Rate),
 
 		WSSessions:         registry.MustNewMetric(WSSessionsName, Counter),
 		WSMessagesSent:     registry.MustNewMetric(WSMessagesSentName, Counter),
@@ -26,11 +26,15 @@ const thresholdsRate = 2 * time.Second
 type MetricsEngine struct {
 	registry *metrics.Registry
 	logger   logrus.FieldLogger
+	baseline *metrics.Baseline
 
 	// These can be both top-level metrics or sub-metrics
 	metricsWithThresholds   []*metrics.Metric
 	breachedThresholdsCount uint32
 
+	// the objectives watched by the executors, guarded by MetricsLock
+	sloObjectives map[*metrics.Metric][]*sloObjective
+
 	// TODO: completely refactor:
 	//   - make these private, add a method to export the raw data
 	//   - do not use an unnecessary map for the observed metrics
@@ -114,6 +118,13 @@ func (me *MetricsEngine) markObserved(metric *metrics.Metric) {
 	}
 }
 
+// SetBaseline sets the baseline test run that the thresholds which refer to a
+// baseline are compared against. It should be called before
+// InitSubMetricsAndThresholds().
+func (me *MetricsEngine) SetBaseline(baseline *metrics.Baseline) {
+	me.baseline = baseline
+}
+
 // InitSubMetricsAndThresholds parses the thresholds from the test Options and
 // initializes both the thresholds themselves, as well as any submetrics that
 // were referenced in them.
@@ -132,6 +143,15 @@ func (me *MetricsEngine) InitSubMetricsAndThresholds(options lib.Options, onlyLo
 			return fmt.Errorf("invalid metric '%s' in threshold definitions: %w", metricName, err)
 		}
 
+		if thresholds.UsesBaseline() {
+			if me.baseline == nil {
+				return fmt.Errorf("thresholds on metric '%s' refer to a baseline, but no baseline was provided", metricName)
+			}
+			if err = thresholds.SetBaseline(me.baseline.Values(metricName)); err != nil {
+				return fmt.Errorf("invalid thresholds on metric '%s': %w", metricName, err)
+			}
+		}
+
 		metric.Thresholds = thresholds
 		me.metricsWithThresholds = append(me.metricsWithThresholds, metric)
 
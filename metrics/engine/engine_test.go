@@ -153,6 +153,83 @@ func TestMetricsEngineEvaluateIgnoreEmptySink(t *testing.T) {
 	assert.Empty(t, breached)
 }
 
+func TestMetricsEngineEvaluateWindowedThreshold(t *testing.T) {
+	t.Parallel()
+
+	me := newTestMetricsEngine(t)
+	m1, err := me.registry.NewMetric("m1", metrics.Trend)
+	require.NoError(t, err)
+
+	ths := metrics.NewThresholds([]string{"max<100 over 10s"})
+	require.NoError(t, ths.Parse())
+	m1.Thresholds = ths
+	m1.Thresholds.Thresholds[0].AbortOnFail = true
+	me.metricsWithThresholds = []*metrics.Metric{m1}
+
+	ingester := me.CreateIngester()
+	require.NoError(t, ingester.Start())
+	start := time.Now()
+	addSample := func(offset time.Duration, value float64) {
+		ingester.AddMetricSamples([]metrics.SampleContainer{metrics.Sample{
+			TimeSeries: metrics.TimeSeries{Metric: m1, Tags: me.registry.RootTagSet()},
+			Time:       start.Add(offset),
+			Value:      value,
+		}})
+	}
+	for i := 0; i < 20; i++ {
+		addSample(time.Duration(i)*time.Second, 10)
+	}
+	addSample(20*time.Second, 500)
+	require.NoError(t, ingester.Stop())
+
+	breached, abort := me.evaluateThresholds(true, zeroTestRunDuration)
+	assert.True(t, abort)
+	assert.Equal(t, []string{"m1"}, breached)
+
+	worst := m1.Thresholds.Thresholds[0].WorstWindow()
+	require.NotNil(t, worst)
+	assert.Equal(t, float64(500), worst.Value)
+}
+
+func TestMetricsEngineBaselineThresholds(t *testing.T) {
+	t.Parallel()
+
+	options := func() lib.Options {
+		ths := metrics.NewThresholds([]string{"p(95)<baseline*1.1"})
+		require.NoError(t, ths.Parse())
+		return lib.Options{Thresholds: map[string]metrics.Thresholds{"m1": ths}}
+	}
+
+	me := newTestMetricsEngine(t)
+	_, err := me.registry.NewMetric("m1", metrics.Trend)
+	require.NoError(t, err)
+	require.ErrorContains(t, me.InitSubMetricsAndThresholds(options(), false), "no baseline was provided")
+
+	me = newTestMetricsEngine(t)
+	_, err = me.registry.NewMetric("m1", metrics.Trend)
+	require.NoError(t, err)
+	me.SetBaseline(&metrics.Baseline{Metrics: map[string]metrics.BaselineMetric{
+		"m1": {Type: metrics.Trend, Values: map[string]float64{"avg": 100}},
+	}})
+	require.ErrorContains(t, me.InitSubMetricsAndThresholds(options(), false), "doesn't contain the p(95) value")
+
+	me = newTestMetricsEngine(t)
+	m1, err := me.registry.NewMetric("m1", metrics.Trend)
+	require.NoError(t, err)
+	me.SetBaseline(&metrics.Baseline{Metrics: map[string]metrics.BaselineMetric{
+		"m1": {Type: metrics.Trend, Values: map[string]float64{"p(95)": 100}},
+	}})
+	require.NoError(t, me.InitSubMetricsAndThresholds(options(), false))
+
+	m1.Sink.Add(metrics.Sample{Value: 105})
+	breached, _ := me.evaluateThresholds(false, zeroTestRunDuration)
+	assert.Empty(t, breached)
+
+	m1.Sink.Add(metrics.Sample{Value: 150})
+	breached, _ = me.evaluateThresholds(false, zeroTestRunDuration)
+	assert.Equal(t, []string{"m1"}, breached)
+}
+
 func newTestMetricsEngine(t *testing.T) *MetricsEngine {
 	m, err := NewMetricsEngine(metrics.NewRegistry(), testutils.NewLogger(t))
 	require.NoError(t, err)
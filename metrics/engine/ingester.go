@@ -88,6 +88,8 @@ func (oi *OutputIngester) flushMetrics() {
 			m := sample.Metric               // this should have come from the Registry, no need to look it up
 			oi.metricsEngine.markObserved(m) // mark it as observed so it shows in the end-of-test summary
 			m.Sink.Add(sample)               // finally, add its value to its own sink
+			m.Thresholds.AddSample(sample, m.Sink)
+			oi.metricsEngine.addSLOSample(m, sample)
 
 			// and also to the same for any submetrics that match the metric sample
 			for _, sm := range m.Submetrics {
@@ -96,6 +98,8 @@ func (oi *OutputIngester) flushMetrics() {
 				}
 				oi.metricsEngine.markObserved(sm.Metric)
 				sm.Metric.Sink.Add(sample)
+				sm.Metric.Thresholds.AddSample(sample, sm.Metric.Sink)
+				oi.metricsEngine.addSLOSample(sm.Metric, sample)
 			}
 
 			oi.cardinality.Add(sample.TimeSeries)
@@ -0,0 +1,174 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+var _ lib.SLOWatcher = &MetricsEngine{}
+
+// sloObjective is a set of thresholds on a metric, which are evaluated only
+// on the samples of the current window of its watch.
+type sloObjective struct {
+	name       string
+	metric     *metrics.Metric
+	registry   *metrics.Registry
+	thresholds metrics.Thresholds
+	sink       metrics.Sink
+	start      time.Time
+
+	// While the window is evaluated, the samples emitted after its end are
+	// kept in next, which becomes the window after it.
+	end  time.Time
+	next metrics.Sink
+}
+
+func (o *sloObjective) reset(now time.Time) {
+	if o.next != nil {
+		o.sink, o.start = o.next, o.end
+	} else {
+		o.sink, o.start = o.registry.NewSink(o.metric.Type), now
+	}
+	o.next, o.end = nil, time.Time{}
+}
+
+// close sets the end of the window, the samples after which are added to
+// the next one. If the window was already closed, it's extended to the new
+// end, since it wasn't reset after it was evaluated.
+func (o *sloObjective) close(end time.Time) {
+	if o.next != nil {
+		_ = o.sink.(metrics.MergeableSink).Merge(o.next) //nolint:forcetypeassert // all the sinks are mergeable
+	}
+	o.end, o.next = end, o.registry.NewSink(o.metric.Type)
+}
+
+// add adds the sample to the window it was emitted in. It's discarded if it
+// was emitted before the window started, since the previous one has already
+// been evaluated.
+func (o *sloObjective) add(sample metrics.Sample) {
+	if sample.Time.Before(o.start) {
+		return
+	}
+	if o.next != nil && !sample.Time.Before(o.end) {
+		o.next.Add(sample)
+		return
+	}
+	o.sink.Add(sample)
+	o.thresholds.AddSample(sample, o.sink)
+}
+
+// sloWatch implements lib.SLOWatch.
+type sloWatch struct {
+	me         *MetricsEngine
+	objectives []*sloObjective
+}
+
+// WatchSLOs implements lib.SLOWatcher. The objectives are validated like the
+// thresholds, and the sub-metrics they refer to are created if needed.
+func (me *MetricsEngine) WatchSLOs(slos map[string]metrics.Thresholds) (lib.SLOWatch, error) {
+	me.MetricsLock.Lock()
+	defer me.MetricsLock.Unlock()
+
+	names := make([]string, 0, len(slos))
+	for name := range slos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := time.Now()
+	w := &sloWatch{me: me}
+	for _, name := range names {
+		metric, err := me.getThresholdMetricOrSubmetric(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric '%s' in SLO definitions: %w", name, err)
+		}
+		thresholds := slos[name]
+		if err = thresholds.Validate(name, me.registry); err != nil {
+			return nil, err
+		}
+		if thresholds.UsesBaseline() {
+			return nil, fmt.Errorf("the SLOs on metric '%s' can't refer to a baseline", name)
+		}
+
+		o := &sloObjective{name: name, metric: metric, registry: me.registry, thresholds: thresholds}
+		o.reset(now)
+		w.objectives = append(w.objectives, o)
+	}
+
+	if me.sloObjectives == nil {
+		me.sloObjectives = make(map[*metrics.Metric][]*sloObjective)
+	}
+	for _, o := range w.objectives {
+		me.sloObjectives[o.metric] = append(me.sloObjectives[o.metric], o)
+	}
+	return w, nil
+}
+
+// addSLOSample adds the sample to the windows of the objectives on the
+// metric, it has to be called with the MetricsLock held.
+func (me *MetricsEngine) addSLOSample(metric *metrics.Metric, sample metrics.Sample) {
+	for _, o := range me.sloObjectives[metric] {
+		o.add(sample)
+	}
+}
+
+func (w *sloWatch) Reset() {
+	w.me.MetricsLock.Lock()
+	defer w.me.MetricsLock.Unlock()
+
+	now := time.Now()
+	for _, o := range w.objectives {
+		o.reset(now)
+	}
+}
+
+// Evaluate closes the current windows and waits for lib.SLOEvaluationDelay,
+// so the samples emitted before that, which are buffered by the ingester,
+// are added to them before they're evaluated.
+func (w *sloWatch) Evaluate() ([]string, error) {
+	end := time.Now()
+	w.me.MetricsLock.Lock()
+	for _, o := range w.objectives {
+		o.close(end)
+	}
+	w.me.MetricsLock.Unlock()
+
+	time.Sleep(lib.SLOEvaluationDelay)
+
+	w.me.MetricsLock.Lock()
+	defer w.me.MetricsLock.Unlock()
+
+	var breached []string
+	for _, o := range w.objectives {
+		if o.sink.IsEmpty() {
+			continue
+		}
+		succ, err := o.thresholds.Run(o.sink, o.end.Sub(o.start))
+		if err != nil {
+			return nil, fmt.Errorf("couldn't evaluate the SLOs on metric '%s': %w", o.name, err)
+		}
+		if !succ {
+			breached = append(breached, o.name)
+		}
+	}
+	return breached, nil
+}
+
+func (w *sloWatch) Stop() {
+	w.me.MetricsLock.Lock()
+	defer w.me.MetricsLock.Unlock()
+
+	for _, o := range w.objectives {
+		objectives := w.me.sloObjectives[o.metric]
+		for i := range objectives {
+			if objectives[i] == o {
+				w.me.sloObjectives[o.metric] = append(objectives[:i], objectives[i+1:]...)
+				break
+			}
+		}
+	}
+}
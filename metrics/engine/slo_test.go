@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+func TestWatchSLOs(t *testing.T) {
+	t.Parallel()
+
+	piState := newTestPreInitState(t)
+	testMetric, err := piState.Registry.NewMetric("test_metric", metrics.Trend)
+	require.NoError(t, err)
+
+	me, err := NewMetricsEngine(piState.Registry, piState.Logger)
+	require.NoError(t, err)
+	ingester := me.CreateIngester()
+
+	watch, err := me.WatchSLOs(map[string]metrics.Thresholds{
+		"test_metric{a:1}": metrics.NewThresholds([]string{"max<100"}),
+	})
+	require.NoError(t, err)
+
+	addSample := func(tags map[string]string, value float64, at time.Time) {
+		ingester.AddMetricSamples([]metrics.SampleContainer{metrics.Sample{
+			TimeSeries: metrics.TimeSeries{
+				Metric: testMetric,
+				Tags:   piState.Registry.RootTagSet().WithTagsFromMap(tags),
+			},
+			Time:  at,
+			Value: value,
+		}})
+		ingester.flushMetrics()
+	}
+
+	breached, err := watch.Evaluate()
+	require.NoError(t, err)
+	assert.Empty(t, breached, "the SLOs without samples should be met")
+
+	addSample(map[string]string{"a": "1"}, 50, time.Now())
+	addSample(map[string]string{"a": "2"}, 500, time.Now())
+	breached, err = watch.Evaluate()
+	require.NoError(t, err)
+	assert.Empty(t, breached)
+
+	addSample(map[string]string{"a": "1"}, 500, time.Now())
+	breached, err = watch.Evaluate()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test_metric{a:1}"}, breached)
+
+	// the samples of a window that was already evaluated are discarded
+	before := time.Now()
+	watch.Reset()
+	addSample(map[string]string{"a": "1"}, 500, before.Add(-time.Second))
+	addSample(map[string]string{"a": "1"}, 50, time.Now())
+	breached, err = watch.Evaluate()
+	require.NoError(t, err)
+	assert.Empty(t, breached)
+
+	// the late samples that arrive while the window is evaluated are added to
+	// the window they were emitted in
+	watch.Reset()
+	end := time.Now()
+	evaluated := make(chan []string)
+	go func() {
+		breached, err := watch.Evaluate()
+		assert.NoError(t, err)
+		evaluated <- breached
+	}()
+	time.Sleep(lib.SLOEvaluationDelay / 4)
+	addSample(map[string]string{"a": "1"}, 500, end.Add(-time.Millisecond))
+	addSample(map[string]string{"a": "1"}, 700, time.Now().Add(time.Second))
+	assert.Equal(t, []string{"test_metric{a:1}"}, <-evaluated)
+
+	// and the samples after the end of the evaluated window are in the next one
+	watch.Reset()
+	breached, err = watch.Evaluate()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test_metric{a:1}"}, breached)
+	watch.Reset()
+	breached, err = watch.Evaluate()
+	require.NoError(t, err)
+	assert.Empty(t, breached)
+
+	watch.Stop()
+	addSample(map[string]string{"a": "1"}, 500, time.Now())
+	breached, err = watch.Evaluate()
+	require.NoError(t, err)
+	assert.Empty(t, breached)
+}
+
+func TestWatchSLOsInvalid(t *testing.T) {
+	t.Parallel()
+
+	piState := newTestPreInitState(t)
+	_, err := piState.Registry.NewMetric("test_metric", metrics.Trend)
+	require.NoError(t, err)
+
+	me, err := NewMetricsEngine(piState.Registry, piState.Logger)
+	require.NoError(t, err)
+
+	_, err = me.WatchSLOs(map[string]metrics.Thresholds{
+		"unknown_metric": metrics.NewThresholds([]string{"max<100"}),
+	})
+	assert.ErrorContains(t, err, "metric 'unknown_metric' does not exist in the script")
+
+	_, err = me.WatchSLOs(map[string]metrics.Thresholds{
+		"test_metric": metrics.NewThresholds([]string{"rate<0.1"}),
+	})
+	assert.ErrorContains(t, err, "unsupported aggregation method rate on metric of type trend")
+}
+
+func TestWatchSLOsHistogramTrendSink(t *testing.T) {
+	t.Parallel()
+
+	piState := newTestPreInitState(t)
+	piState.Registry.SetTrendSinkType(metrics.TrendSinkHistogram)
+	_, err := piState.Registry.NewMetric("test_metric", metrics.Trend)
+	require.NoError(t, err)
+
+	me, err := NewMetricsEngine(piState.Registry, piState.Logger)
+	require.NoError(t, err)
+
+	watch, err := me.WatchSLOs(map[string]metrics.Thresholds{
+		"test_metric": metrics.NewThresholds([]string{"p(95)<100"}),
+	})
+	require.NoError(t, err)
+	o := watch.(*sloWatch).objectives[0] //nolint:forcetypeassert
+
+	isHistogram := func(sink metrics.Sink) bool {
+		trendSink, ok := sink.(*metrics.TrendSink)
+		return ok && trendSink.IsHistogram()
+	}
+	assert.True(t, isHistogram(o.sink))
+	_, err = watch.Evaluate()
+	require.NoError(t, err)
+	assert.True(t, isHistogram(o.next))
+	watch.Reset()
+	assert.True(t, isHistogram(o.sink))
+}
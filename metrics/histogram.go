@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+)
+
+// histogramSubBucketBits determines the number of linear sub-buckets (2^7=128)
+// in each power-of-two range of the histogram. Every value is represented by
+// the middle of its sub-bucket, so the relative error of the returned values
+// is at most 2^-(histogramSubBucketBits+1), i.e. about 0.4%.
+const histogramSubBucketBits = 7
+
+// HistogramRelativeError is the maximum relative error of the percentiles
+// calculated by the histogram-backed Trend sinks.
+const HistogramRelativeError = 1.0 / (1 << (histogramSubBucketBits + 1))
+
+// histogram is a mergeable log-linear streaming histogram with bounded
+// relative error. Its memory usage depends only on the range of the observed
+// values and not on their number. For example, all values between 1µs and 1h
+// fit in less than 4000 buckets.
+type histogram struct {
+	// positive and negative hold the counts for the buckets of the positive
+	// values and of the absolute negative values, indexed by bucketIndex().
+	positive map[int32]uint64
+	negative map[int32]uint64
+	zeros    uint64
+}
+
+func newHistogram() *histogram {
+	return &histogram{
+		positive: make(map[int32]uint64),
+		negative: make(map[int32]uint64),
+	}
+}
+
+// bucketIndex returns the index of the bucket for the given positive value.
+// The value is split into a fraction in [0.5, 1) and an exponent, and the
+// index is made of the exponent and of the linear position of the fraction.
+func bucketIndex(v float64) int32 {
+	frac, exp := math.Frexp(v)
+	sub := int32((frac - 0.5) * (2 << histogramSubBucketBits))
+	return int32(exp)<<histogramSubBucketBits | sub //nolint:gosec
+}
+
+// bucketValue returns the value in the middle of the bucket with the given
+// index, which is what represents all of the values in the bucket.
+func bucketValue(index int32) float64 {
+	exp := index >> histogramSubBucketBits
+	sub := index & (1<<histogramSubBucketBits - 1)
+	frac := 0.5 + (float64(sub)+0.5)/(2<<histogramSubBucketBits)
+	return math.Ldexp(frac, int(exp))
+}
+
+func (h *histogram) add(v float64) {
+	switch {
+	case v > 0:
+		h.positive[bucketIndex(v)]++
+	case v < 0:
+		h.negative[bucketIndex(-v)]++
+	default:
+		h.zeros++
+	}
+}
+
+func (h *histogram) merge(other *histogram) {
+	for i, c := range other.positive {
+		h.positive[i] += c
+	}
+	for i, c := range other.negative {
+		h.negative[i] += c
+	}
+	h.zeros += other.zeros
+}
+
+// valueAt returns the approximate value of the element with the given
+// (zero-based) rank, as if all the observed values were sorted.
+func (h *histogram) valueAt(rank uint64) float64 {
+	// The negative values are sorted in the reverse order of their absolute
+	// values, so their biggest bucket indexes come first.
+	negIndexes := sortedIndexes(h.negative)
+	for i := len(negIndexes) - 1; i >= 0; i-- {
+		c := h.negative[negIndexes[i]]
+		if rank < c {
+			return -bucketValue(negIndexes[i])
+		}
+		rank -= c
+	}
+
+	if rank < h.zeros {
+		return 0
+	}
+	rank -= h.zeros
+
+	var index int32
+	for _, index = range sortedIndexes(h.positive) {
+		c := h.positive[index]
+		if rank < c {
+			break
+		}
+		rank -= c
+	}
+	return bucketValue(index)
+}
+
+func sortedIndexes(buckets map[int32]uint64) []int32 {
+	indexes := make([]int32, 0, len(buckets))
+	for i := range buckets {
+		indexes = append(indexes, i)
+	}
+	sort.Slice(indexes, func(a, b int) bool { return indexes[a] < indexes[b] })
+	return indexes
+}
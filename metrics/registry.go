@@ -14,6 +14,8 @@ type Registry struct {
 	l       sync.RWMutex
 
 	rootTagSet *atlas.Node
+
+	trendSinkType TrendSinkType
 }
 
 // NewRegistry returns a new registry
@@ -96,7 +98,7 @@ func (r *Registry) newMetric(name string, mt MetricType, vt ...ValueType) *Metri
 		valueType = vt[0]
 	}
 
-	sink := NewSink(mt)
+	sink := r.newSink(mt)
 	return &Metric{
 		registry: r,
 		Name:     name,
@@ -106,6 +108,45 @@ func (r *Registry) newMetric(name string, mt MetricType, vt ...ValueType) *Metri
 	}
 }
 
+// NewSink returns a new sink for a metric of the given type, which is a
+// histogram for the Trend metrics if the registry's trend sink type is set so.
+func (r *Registry) NewSink(mt MetricType) Sink {
+	r.l.RLock()
+	defer r.l.RUnlock()
+
+	return r.newSink(mt)
+}
+
+func (r *Registry) newSink(mt MetricType) Sink {
+	if mt == Trend && r.trendSinkType == TrendSinkHistogram {
+		return NewHistogramTrendSink()
+	}
+	return NewSink(mt)
+}
+
+// SetTrendSinkType changes the type of the sinks used by the Trend metrics.
+// The already registered Trend metrics and their submetrics are updated as
+// well, as long as they haven't received any samples yet.
+func (r *Registry) SetTrendSinkType(t TrendSinkType) {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	r.trendSinkType = t
+	for _, m := range r.metrics {
+		if m.Type != Trend {
+			continue
+		}
+		if m.Sink.IsEmpty() {
+			m.Sink = r.newSink(m.Type)
+		}
+		for _, sm := range m.Submetrics {
+			if sm.Metric.Sink.IsEmpty() {
+				sm.Metric.Sink = r.newSink(m.Type)
+			}
+		}
+	}
+}
+
 // Get returns the Metric with the given name. If that metric doesn't exist,
 // Get() will return a nil value.
 func (r *Registry) Get(name string) *Metric {
@@ -101,3 +101,21 @@ func TestRegistryAll(t *testing.T) {
 		assert.ElementsMatch(t, exp, names(metrics))
 	})
 }
+
+func TestRegistrySetTrendSinkType(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	trend := r.MustNewMetric("trend", Trend)
+	counter := r.MustNewMetric("counter", Counter)
+	sm, err := trend.AddSubmetric("a:1")
+	require.NoError(t, err)
+
+	r.SetTrendSinkType(TrendSinkHistogram)
+	assert.True(t, trend.Sink.(*TrendSink).IsHistogram())     //nolint:forcetypeassert
+	assert.True(t, sm.Metric.Sink.(*TrendSink).IsHistogram()) //nolint:forcetypeassert
+	assert.IsType(t, &CounterSink{}, counter.Sink)
+
+	newTrend := r.MustNewMetric("new_trend", Trend)
+	assert.True(t, newTrend.Sink.(*TrendSink).IsHistogram()) //nolint:forcetypeassert
+}
@@ -8,10 +8,10 @@ import (
 )
 
 var (
-	_ Sink = &CounterSink{}
-	_ Sink = &GaugeSink{}
-	_ Sink = NewTrendSink()
-	_ Sink = &RateSink{}
+	_ MergeableSink = &CounterSink{}
+	_ MergeableSink = &GaugeSink{}
+	_ MergeableSink = NewTrendSink()
+	_ MergeableSink = &RateSink{}
 )
 
 // Sink is a sample sink which will accumulate data in specific way
@@ -21,6 +21,17 @@ type Sink interface {
 	IsEmpty() bool                             // Check if the Sink is empty.
 }
 
+// MergeableSink is a Sink that can combine the data of another Sink of the
+// same type into itself, e.g. to aggregate the results of multiple instances.
+type MergeableSink interface {
+	Sink
+	Merge(other Sink) error // Merge the other sink's data into this one.
+}
+
+func errIncompatibleSinks(dst, src Sink) error {
+	return fmt.Errorf("can't merge a sink of type %T into a sink of type %T", src, dst)
+}
+
 // NewSink creates the related Sink for
 // the provided MetricType.
 func NewSink(mt MetricType) Sink {
@@ -43,6 +54,27 @@ func NewSink(mt MetricType) Sink {
 	return sink
 }
 
+// TrendSinkType determines how the sinks of Trend metrics store their values.
+type TrendSinkType uint8
+
+// Possible values for TrendSinkType.
+const (
+	TrendSinkExact     TrendSinkType = iota // Keep all values, see NewTrendSink()
+	TrendSinkHistogram                      // Use a histogram, see NewHistogramTrendSink()
+)
+
+// ParseTrendSinkType parses the string representation of a TrendSinkType.
+func ParseTrendSinkType(s string) (TrendSinkType, error) {
+	switch s {
+	case "", "exact":
+		return TrendSinkExact, nil
+	case "histogram":
+		return TrendSinkHistogram, nil
+	default:
+		return TrendSinkExact, fmt.Errorf("invalid trend sink type '%s', use 'exact' or 'histogram'", s)
+	}
+}
+
 // CounterSink is a sink that represents a Counter
 type CounterSink struct {
 	Value float64
@@ -60,6 +92,19 @@ func (c *CounterSink) Add(s Sample) {
 // IsEmpty indicates whether the CounterSink is empty.
 func (c *CounterSink) IsEmpty() bool { return c.First.IsZero() }
 
+// Merge adds the value of the other CounterSink to this one.
+func (c *CounterSink) Merge(other Sink) error {
+	o, ok := other.(*CounterSink)
+	if !ok {
+		return errIncompatibleSinks(c, other)
+	}
+	c.Value += o.Value
+	if c.First.IsZero() || (!o.First.IsZero() && o.First.Before(c.First)) {
+		c.First = o.First
+	}
+	return nil
+}
+
 // Format counter and return a map
 func (c *CounterSink) Format(t time.Duration) map[string]float64 {
 	return map[string]float64{
@@ -90,21 +135,56 @@ func (g *GaugeSink) Add(s Sample) {
 	}
 }
 
+// Merge combines the other GaugeSink into this one. The current value is
+// taken from the other sink, since it's assumed to be the more recent one.
+func (g *GaugeSink) Merge(other Sink) error {
+	o, ok := other.(*GaugeSink)
+	if !ok {
+		return errIncompatibleSinks(g, other)
+	}
+	if o.IsEmpty() {
+		return nil
+	}
+	if g.IsEmpty() {
+		*g = *o
+		return nil
+	}
+	g.Value = o.Value
+	if o.Max > g.Max {
+		g.Max = o.Max
+	}
+	if o.Min < g.Min {
+		g.Min = o.Min
+	}
+	return nil
+}
+
 // Format gauge and return a map
 func (g *GaugeSink) Format(_ time.Duration) map[string]float64 {
 	return map[string]float64{"value": g.Value}
 }
 
-// NewTrendSink makes a Trend sink with the OpenHistogram circllhist histogram.
+// NewTrendSink makes a Trend sink that keeps all of the values, so its
+// percentiles are exact, but its memory usage grows with every sample.
 func NewTrendSink() *TrendSink {
 	return &TrendSink{}
 }
 
+// NewHistogramTrendSink makes a Trend sink that stores the values in a
+// mergeable streaming histogram. Its memory usage is bounded, while the
+// relative error of its percentiles is at most HistogramRelativeError.
+func NewHistogramTrendSink() *TrendSink {
+	return &TrendSink{hist: newHistogram()}
+}
+
 // TrendSink is a sink for a Trend
 type TrendSink struct {
 	values []float64
 	sorted bool
 
+	// if hist is not nil, the values are stored in it instead of in values
+	hist *histogram
+
 	count    uint64
 	min, max float64
 	sum      float64
@@ -113,27 +193,76 @@ type TrendSink struct {
 // IsEmpty indicates whether the TrendSink is empty.
 func (t *TrendSink) IsEmpty() bool { return t.count == 0 }
 
+// IsHistogram returns true if the sink stores its values in a histogram.
+func (t *TrendSink) IsHistogram() bool { return t.hist != nil }
+
 // Add a single sample into the trend
 func (t *TrendSink) Add(s Sample) {
+	t.addStats(s.Value, s.Value, 1, s.Value)
+
+	if t.hist != nil {
+		t.hist.add(s.Value)
+		return
+	}
+	t.values = append(t.values, s.Value)
+	t.sorted = false
+}
+
+func (t *TrendSink) addStats(minValue, maxValue float64, count uint64, sum float64) {
 	if t.count == 0 {
-		t.max, t.min = s.Value, s.Value
+		t.max, t.min = maxValue, minValue
 	} else {
-		if s.Value > t.max {
-			t.max = s.Value
+		if maxValue > t.max {
+			t.max = maxValue
 		}
-		if s.Value < t.min {
-			t.min = s.Value
+		if minValue < t.min {
+			t.min = minValue
 		}
 	}
+	t.count += count
+	t.sum += sum
+}
 
-	t.values = append(t.values, s.Value)
-	t.sorted = false
-	t.count++
-	t.sum += s.Value
+// Merge adds the values of the other TrendSink to this one. If either of them
+// is backed by a histogram, the result is backed by a histogram as well.
+func (t *TrendSink) Merge(other Sink) error {
+	o, ok := other.(*TrendSink)
+	if !ok {
+		return errIncompatibleSinks(t, other)
+	}
+	if o.count == 0 {
+		return nil
+	}
+
+	if t.hist == nil && o.hist != nil {
+		t.hist = newHistogram()
+		for _, v := range t.values {
+			t.hist.add(v)
+		}
+		t.values, t.sorted = nil, false
+	}
+
+	switch {
+	case t.hist == nil:
+		t.values = append(t.values, o.values...)
+		t.sorted = false
+	case o.hist == nil:
+		for _, v := range o.values {
+			t.hist.add(v)
+		}
+	default:
+		t.hist.merge(o.hist)
+	}
+
+	t.addStats(o.min, o.max, o.count, o.sum)
+	return nil
 }
 
 // P calculates the given percentile from sink values.
 func (t *TrendSink) P(pct float64) float64 {
+	if t.hist != nil {
+		return t.histogramP(pct)
+	}
 	switch t.count {
 	case 0:
 		return 0
@@ -156,6 +285,28 @@ func (t *TrendSink) P(pct float64) float64 {
 	}
 }
 
+// histogramP calculates the percentile the same way P() does for the exact
+// values, but based on the approximate values from the histogram. The results
+// are always kept within the exact min and max values.
+func (t *TrendSink) histogramP(pct float64) float64 {
+	switch {
+	case t.count == 0:
+		return 0
+	case pct <= 0:
+		return t.min
+	case pct >= 1:
+		return t.max
+	}
+
+	i := pct * (float64(t.count) - 1.0)
+	result := t.hist.valueAt(uint64(math.Floor(i)))
+	if f := i - math.Floor(i); f > 0 {
+		k := t.hist.valueAt(uint64(math.Ceil(i)))
+		result += (k - result) * f
+	}
+	return math.Min(math.Max(result, t.min), t.max)
+}
+
 // Min returns the minimum value.
 func (t *TrendSink) Min() float64 {
 	return t.min
@@ -214,6 +365,17 @@ func (r *RateSink) Add(s Sample) {
 	}
 }
 
+// Merge adds the values of the other RateSink to this one.
+func (r *RateSink) Merge(other Sink) error {
+	o, ok := other.(*RateSink)
+	if !ok {
+		return errIncompatibleSinks(r, other)
+	}
+	r.Trues += o.Trues
+	r.Total += o.Total
+	return nil
+}
+
 // Format rate and return a map
 func (r RateSink) Format(_ time.Duration) map[string]float64 {
 	var rate float64
@@ -236,6 +236,143 @@ func TestTrendSink(t *testing.T) {
 	})
 }
 
+func TestHistogramTrendSink(t *testing.T) {
+	t.Parallel()
+
+	t.Run("percentiles", func(t *testing.T) {
+		t.Parallel()
+
+		exact, hist := NewTrendSink(), NewHistogramTrendSink()
+		for i := 0; i < 100000; i++ {
+			// an uneven distribution of values between -1000 and ~10^6
+			v := math.Pow(float64(i%997), 2) - 1000 + float64(i)/1000
+			exact.Add(Sample{TimeSeries: TimeSeries{Metric: &Metric{}}, Value: v})
+			hist.Add(Sample{TimeSeries: TimeSeries{Metric: &Metric{}}, Value: v})
+		}
+		assert.True(t, hist.IsHistogram())
+		assert.Nil(t, hist.values)
+		assert.Less(t, len(hist.hist.positive)+len(hist.hist.negative), 4000)
+
+		assert.Equal(t, exact.Count(), hist.Count())
+		assert.Equal(t, exact.Min(), hist.Min())
+		assert.Equal(t, exact.Max(), hist.Max())
+		assert.Equal(t, exact.Total(), hist.Total())
+		assert.Equal(t, exact.P(0), hist.P(0))
+		assert.Equal(t, exact.P(1), hist.P(1))
+		for _, pct := range []float64{0.01, 0.1, 0.25, 0.5, 0.75, 0.9, 0.95, 0.99, 0.999} {
+			expected := exact.P(pct)
+			assert.InDelta(t, expected, hist.P(pct), math.Abs(expected)*HistogramRelativeError, "p(%g)", pct*100)
+		}
+	})
+
+	t.Run("zeros and negative values", func(t *testing.T) {
+		t.Parallel()
+
+		sink := NewHistogramTrendSink()
+		for _, s := range []float64{-100, -10, 0, 0, 10, 100} {
+			sink.Add(Sample{TimeSeries: TimeSeries{Metric: &Metric{}}, Value: s})
+		}
+		assert.Equal(t, -100.0, sink.P(0))
+		assert.InDelta(t, -10.0, sink.P(0.2), 10*HistogramRelativeError)
+		assert.Equal(t, 0.0, sink.P(0.5))
+		assert.InDelta(t, 10.0, sink.P(0.8), 10*HistogramRelativeError)
+		assert.Equal(t, 100.0, sink.P(1))
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		t.Parallel()
+
+		sink := NewHistogramTrendSink()
+		assert.True(t, sink.IsEmpty())
+		assert.Equal(t, 0.0, sink.P(0.5))
+	})
+}
+
+func TestSinkMerge(t *testing.T) {
+	t.Parallel()
+
+	addValues := func(sink Sink, values ...float64) Sink {
+		for i, v := range values {
+			sink.Add(Sample{
+				TimeSeries: TimeSeries{Metric: &Metric{}},
+				Time:       time.Unix(int64(i), 0),
+				Value:      v,
+			})
+		}
+		return sink
+	}
+
+	t.Run("counter", func(t *testing.T) {
+		t.Parallel()
+		sink := addValues(&CounterSink{}, 1, 2).(*CounterSink) //nolint:forcetypeassert
+		require.NoError(t, sink.Merge(addValues(&CounterSink{}, 3)))
+		assert.Equal(t, 6.0, sink.Value)
+		assert.Equal(t, time.Unix(0, 0), sink.First)
+	})
+
+	t.Run("gauge", func(t *testing.T) {
+		t.Parallel()
+		sink := addValues(&GaugeSink{}, 5, 1).(*GaugeSink) //nolint:forcetypeassert
+		require.NoError(t, sink.Merge(addValues(&GaugeSink{}, 3, 2)))
+		assert.Equal(t, 2.0, sink.Value)
+		assert.Equal(t, 1.0, sink.Min)
+		assert.Equal(t, 5.0, sink.Max)
+
+		empty := &GaugeSink{}
+		require.NoError(t, empty.Merge(sink))
+		assert.Equal(t, sink, empty)
+	})
+
+	t.Run("rate", func(t *testing.T) {
+		t.Parallel()
+		sink := addValues(&RateSink{}, 1, 0).(*RateSink) //nolint:forcetypeassert
+		require.NoError(t, sink.Merge(addValues(&RateSink{}, 1, 1)))
+		assert.Equal(t, RateSink{Trues: 3, Total: 4}, *sink)
+	})
+
+	t.Run("trend", func(t *testing.T) {
+		t.Parallel()
+		sink := addValues(NewTrendSink(), 10, 0).(*TrendSink) //nolint:forcetypeassert
+		require.NoError(t, sink.Merge(addValues(NewTrendSink(), 30, 20)))
+		assert.False(t, sink.IsHistogram())
+		assert.Equal(t, uint64(4), sink.Count())
+		assert.Equal(t, 0.0, sink.Min())
+		assert.Equal(t, 30.0, sink.Max())
+		assert.Equal(t, 15.0, sink.P(0.5))
+
+		// merging a histogram into an exact sink converts it to a histogram
+		require.NoError(t, sink.Merge(addValues(NewHistogramTrendSink(), 40, 50, 60)))
+		assert.True(t, sink.IsHistogram())
+		assert.Equal(t, uint64(7), sink.Count())
+		assert.Equal(t, 60.0, sink.Max())
+		assert.InDelta(t, 30.0, sink.P(0.5), 30*HistogramRelativeError)
+		assert.Equal(t, 210.0, sink.Total())
+
+		require.NoError(t, sink.Merge(NewTrendSink()))
+		assert.Equal(t, uint64(7), sink.Count())
+	})
+
+	t.Run("incompatible", func(t *testing.T) {
+		t.Parallel()
+		require.ErrorContains(t, (&CounterSink{}).Merge(&RateSink{}), "can't merge")
+		require.ErrorContains(t, NewTrendSink().Merge(&GaugeSink{}), "can't merge")
+	})
+}
+
+func TestParseTrendSinkType(t *testing.T) {
+	t.Parallel()
+
+	for input, expected := range map[string]TrendSinkType{
+		"": TrendSinkExact, "exact": TrendSinkExact, "histogram": TrendSinkHistogram,
+	} {
+		tst, err := ParseTrendSinkType(input)
+		require.NoError(t, err)
+		assert.Equal(t, expected, tst)
+	}
+	_, err := ParseTrendSinkType("hdr")
+	require.ErrorContains(t, err, "invalid trend sink type 'hdr'")
+}
+
 func TestRateSink(t *testing.T) {
 	t.Parallel()
 	samples6 := []float64{1.0, 0.0, 1.0, 0.0, 0.0, 1.0}
@@ -38,15 +38,23 @@ const (
 	TagVU   // non-indexable
 	TagOCSPStatus
 	TagIP
+
+	// Enabled by default, but only set for the requests that are retried.
+	TagAttempt
+
+	// Not enabled by default, since it's mostly the same as the ip tag, and
+	// only set for the gRPC requests.
+	TagBackend
 )
 
 // DefaultSystemTagSet includes all of the system tags emitted with metrics by default.
-// Other tags that are not enabled by default include: iter, vu, ocsp_status, ip
+// Other tags that are not enabled by default include: iter, vu, ocsp_status, ip, backend
 //
 //nolint:gochecknoglobals
 var DefaultSystemTagSet = SystemTagSet(
 	TagProto | TagSubproto | TagStatus | TagMethod | TagURL | TagName | TagGroup |
-		TagCheck | TagError | TagErrorCode | TagTLSVersion | TagScenario | TagService | TagExpectedResponse)
+		TagCheck | TagError | TagErrorCode | TagTLSVersion | TagScenario | TagService | TagExpectedResponse |
+		TagAttempt)
 
 // NonIndexableSystemTags are high cardinality system tags (i.e. metadata).
 //
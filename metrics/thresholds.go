@@ -8,6 +8,8 @@ import (
 	"strings"
 	"time"
 
+	"gopkg.in/guregu/null.v3"
+
 	"go.k6.io/k6/errext"
 	"go.k6.io/k6/errext/exitcodes"
 	"go.k6.io/k6/lib/types"
@@ -26,6 +28,12 @@ type Threshold struct {
 	AbortGracePeriod types.NullDuration
 	// parsed is the threshold expression parsed from the Source
 	parsed *thresholdExpression
+	// window holds the data of the sliding time window the threshold is
+	// evaluated over, if it has one
+	window *thresholdWindow
+	// baseline is the value of a baseline test run that the threshold is
+	// compared against, if its expression refers to a baseline
+	baseline null.Float
 }
 
 func newThreshold(src string, abortOnFail bool, gracePeriod types.NullDuration) *Threshold {
@@ -47,24 +55,33 @@ func (t *Threshold) runNoTaint(sinks map[string]float64) (bool, error) {
 		return true, nil
 	}
 
+	rhs := t.parsed.Value
+	if t.parsed.BaselineFactor.Valid {
+		if !t.baseline.Valid {
+			return false, fmt.Errorf("unable to apply threshold %s over metrics; "+
+				"reason: no baseline value was provided", t.Source)
+		}
+		rhs = t.baseline.Float64 * t.parsed.BaselineFactor.Float64
+	}
+
 	// Apply the threshold expression operator to the left and
 	// right hand side values
 	var passes bool
 	switch t.parsed.Operator {
 	case ">":
-		passes = lhs > t.parsed.Value
+		passes = lhs > rhs
 	case ">=":
-		passes = lhs >= t.parsed.Value
+		passes = lhs >= rhs
 	case "<=":
-		passes = lhs <= t.parsed.Value
+		passes = lhs <= rhs
 	case "<":
-		passes = lhs < t.parsed.Value
+		passes = lhs < rhs
 	case "==", "===":
 		// Considering a sink always maps to float64 values,
 		// strictly equal is equivalent to loosely equal
-		passes = lhs == t.parsed.Value
+		passes = lhs == rhs
 	case "!=":
-		passes = lhs != t.parsed.Value
+		passes = lhs != rhs
 	default:
 		// The parseThresholdExpression function should ensure that no invalid
 		// operator gets through, but let's protect our future selves anyhow.
@@ -85,6 +102,92 @@ func (t *Threshold) run(sinks map[string]float64) (bool, error) {
 	return passes, err
 }
 
+// setParsed sets the parsed threshold expression and initializes the sliding
+// time window of the threshold, if the expression has one.
+func (t *Threshold) setParsed(parsed *thresholdExpression) {
+	t.parsed = parsed
+	t.window = nil
+	if parsed.Window > 0 {
+		t.window = newThresholdWindow(parsed.Window)
+	}
+}
+
+// runWindow evaluates the threshold over its current time window. A windowed
+// threshold is considered failed if it failed for any of the windows so far,
+// but the returned value, which determines if the test should be aborted, only
+// reflects the current window.
+func (t *Threshold) runWindow(
+	now time.Time, sinkValues func(Sink, time.Duration) (map[string]float64, error),
+) (bool, error) {
+	t.window.advance(now)
+	sink, start, end := t.window.current()
+	if sink == nil {
+		t.LastFailed = t.window.hasFailed()
+		return true, nil
+	}
+
+	sinks, err := sinkValues(sink, end.Sub(start))
+	if err != nil {
+		return false, err
+	}
+	passes, err := t.runNoTaint(sinks)
+	if err != nil {
+		return false, err
+	}
+
+	value, ok := sinks[t.parsed.SinkKey()]
+	if ok {
+		t.window.record(t.parsed.Operator, passes, ThresholdWindowResult{Start: start, End: end, Value: value})
+	}
+	t.LastFailed = t.window.hasFailed()
+
+	// Partial windows at the start of the test can't abort it, since they
+	// might be based on just a few samples.
+	return passes || !t.window.isFull(start, end), nil
+}
+
+// UsesBaseline returns whether the threshold is relative to a baseline test run.
+func (t *Threshold) UsesBaseline() bool {
+	return t.parsed != nil && t.parsed.BaselineFactor.Valid
+}
+
+// BaselineValue returns the value of the baseline test run that the threshold
+// is compared against and the factor it is multiplied by.
+func (t *Threshold) BaselineValue() (value null.Float, factor float64) {
+	if !t.UsesBaseline() {
+		return null.Float{}, 0
+	}
+	return t.baseline, t.parsed.BaselineFactor.Float64
+}
+
+// AggregationKey returns the aggregated value of the metric that the threshold
+// is evaluated against, e.g. "p(95)".
+func (t *Threshold) AggregationKey() string {
+	if t.parsed == nil {
+		return ""
+	}
+	return t.parsed.SinkKey()
+}
+
+// Window returns the size of the sliding time window the threshold is
+// evaluated over, or zero if it applies to the whole test run.
+func (t *Threshold) Window() time.Duration {
+	if t.parsed == nil {
+		return 0
+	}
+	return t.parsed.Window
+}
+
+// WorstWindow returns the worst result of a windowed threshold so far, or nil
+// if the threshold has no window or hasn't been evaluated yet.
+func (t *Threshold) WorstWindow() *ThresholdWindowResult {
+	if t.window == nil || t.window.worst == nil {
+		return nil
+	}
+	result := *t.window.worst
+	return &result
+}
+
 type thresholdConfig struct {
 	Threshold        string             `json:"threshold"`
 	AbortOnFail      bool               `json:"abortOnFail"`
@@ -118,6 +221,10 @@ type Thresholds struct {
 	Thresholds []*Threshold
 	Abort      bool
 	sinked     map[string]float64
+
+	// now returns the current time, which the sliding time windows are
+	// advanced to when the thresholds are run. It defaults to time.Now.
+	now func() time.Time
 }
 
 // NewThresholds returns Thresholds objects representing the provided source strings
@@ -139,37 +246,113 @@ func newThresholdsWithConfig(configs []thresholdConfig) Thresholds {
 		thresholds[i] = t
 	}
 
-	return Thresholds{thresholds, false, sinked}
+	return Thresholds{Thresholds: thresholds, sinked: sinked}
 }
 
 func (ts *Thresholds) runAll(timeSpentInTest time.Duration) (bool, error) {
+	now := time.Now
+	if ts.now != nil {
+		now = ts.now
+	}
+
 	succeeded := true
 	for i, threshold := range ts.Thresholds {
-		b, err := threshold.run(ts.sinked)
+		var b bool
+		var err error
+		if threshold.window != nil {
+			b, err = threshold.runWindow(now(), ts.sinkValues)
+		} else {
+			b, err = threshold.run(ts.sinked)
+		}
 		if err != nil {
 			return false, fmt.Errorf("threshold %d run error: %w", i, err)
 		}
 
-		if !b {
+		if threshold.LastFailed {
 			succeeded = false
+		}
 
-			if ts.Abort || !threshold.AbortOnFail {
-				continue
-			}
+		if b || ts.Abort || !threshold.AbortOnFail {
+			continue
+		}
+
+		ts.Abort = !threshold.AbortGracePeriod.Valid ||
+			threshold.AbortGracePeriod.Duration < types.Duration(timeSpentInTest)
+	}
+
+	return succeeded, nil
+}
 
-			ts.Abort = !threshold.AbortGracePeriod.Valid ||
-				threshold.AbortGracePeriod.Duration < types.Duration(timeSpentInTest)
+// SetBaseline sets the values of a baseline test run for the thresholds that
+// refer to one. The values are keyed by aggregation method, e.g. "p(95)", and
+// it returns an error if a value is missing.
+func (ts *Thresholds) SetBaseline(values map[string]float64) error {
+	for _, threshold := range ts.Thresholds {
+		if !threshold.UsesBaseline() {
+			continue
+		}
+		value, ok := values[threshold.parsed.SinkKey()]
+		if !ok {
+			return fmt.Errorf("the baseline doesn't contain the %s value for threshold %q",
+				threshold.parsed.SinkKey(), threshold.Source)
 		}
+		threshold.baseline = null.FloatFrom(value)
 	}
+	return nil
+}
+
+// UsesBaseline returns whether any of the thresholds refers to a baseline.
+func (ts *Thresholds) UsesBaseline() bool {
+	for _, threshold := range ts.Thresholds {
+		if threshold.UsesBaseline() {
+			return true
+		}
+	}
+	return false
+}
 
+// RunOnValues processes all the thresholds with the provided aggregated
+// values, e.g. the ones of a Baseline, and returns if any of them fails.
+func (ts *Thresholds) RunOnValues(values map[string]float64) (bool, error) {
+	ts.sinked = values
+	succeeded := true
+	for i, threshold := range ts.Thresholds {
+		b, err := threshold.run(values)
+		if err != nil {
+			return false, fmt.Errorf("threshold %d run error: %w", i, err)
+		}
+		succeeded = succeeded && b
+	}
 	return succeeded, nil
 }
 
+// AddSample records the sample in the sliding time windows of the thresholds
+// that have one. It should be called for every sample that is added to the
+// sink of the metric the thresholds apply to.
+func (ts *Thresholds) AddSample(s Sample, metricSink Sink) {
+	for _, threshold := range ts.Thresholds {
+		if threshold.window != nil {
+			threshold.window.add(s, metricSink)
+		}
+	}
+}
+
 // Run processes all the thresholds with the provided Sink at the provided time and returns if any
 // of them fails
 func (ts *Thresholds) Run(sink Sink, duration time.Duration) (bool, error) {
-	// Initialize the sinks store
-	ts.sinked = make(map[string]float64)
+	sinked, err := ts.sinkValues(sink, duration)
+	if err != nil {
+		return false, err
+	}
+	ts.sinked = sinked
+
+	return ts.runAll(duration)
+}
+
+// sinkValues extracts the values the thresholds are asserted against from the
+// provided Sink, which covers the provided duration.
+func (ts *Thresholds) sinkValues(sink Sink, duration time.Duration) (map[string]float64, error) {
+	sinked := make(map[string]float64)
 
 	// FIXME: Remove this comment as soon as the metrics.Sink does not expose Format anymore.
 	//
@@ -180,15 +363,15 @@ func (ts *Thresholds) Run(sink Sink, duration time.Duration) (bool, error) {
 	// For more details, see https://github.com/grafana/k6/issues/2320
 	switch sinkImpl := sink.(type) {
 	case *CounterSink:
-		ts.sinked["count"] = sinkImpl.Value
-		ts.sinked["rate"] = sinkImpl.Value / (float64(duration) / float64(time.Second))
+		sinked["count"] = sinkImpl.Value
+		sinked["rate"] = sinkImpl.Value / (float64(duration) / float64(time.Second))
 	case *GaugeSink:
-		ts.sinked["value"] = sinkImpl.Value
+		sinked["value"] = sinkImpl.Value
 	case *TrendSink:
-		ts.sinked["min"] = sinkImpl.Min()
-		ts.sinked["max"] = sinkImpl.Max()
-		ts.sinked["avg"] = sinkImpl.Avg()
-		ts.sinked["med"] = sinkImpl.P(0.5)
+		sinked["min"] = sinkImpl.Min()
+		sinked["max"] = sinkImpl.Max()
+		sinked["avg"] = sinkImpl.Avg()
+		sinked["med"] = sinkImpl.P(0.5)
 
 		// Parse the percentile thresholds and insert them in
 		// the sinks mapping.
@@ -198,19 +381,19 @@ func (ts *Thresholds) Run(sink Sink, duration time.Duration) (bool, error) {
 			}
 
 			key := fmt.Sprintf("p(%g)", threshold.parsed.AggregationValue.Float64)
-			ts.sinked[key] = sinkImpl.P(threshold.parsed.AggregationValue.Float64 / 100)
+			sinked[key] = sinkImpl.P(threshold.parsed.AggregationValue.Float64 / 100)
 		}
 	case *RateSink:
 		// We want to avoid division by zero, which
 		// would lead to [#2520](https://github.com/grafana/k6/issues/2520)
 		if sinkImpl.Total > 0 {
-			ts.sinked["rate"] = float64(sinkImpl.Trues) / float64(sinkImpl.Total)
+			sinked["rate"] = float64(sinkImpl.Trues) / float64(sinkImpl.Total)
 		}
 	default:
-		return false, fmt.Errorf("unable to run Thresholds; reason: unknown sink type")
+		return nil, fmt.Errorf("unable to run Thresholds; reason: unknown sink type")
 	}
 
-	return ts.runAll(duration)
+	return sinked, nil
 }
 
 // Parse parses the Thresholds and fills each Threshold.parsed field with the result.
@@ -222,7 +405,7 @@ func (ts *Thresholds) Parse() error {
 			return err
 		}
 
-		t.parsed = parsed
+		t.setParsed(parsed)
 	}
 
 	return nil
@@ -263,7 +446,7 @@ func (ts *Thresholds) Validate(metricName string, r *Registry) error {
 					"parsing threshold failed %w", threshold.Source, metricName, err)
 			}
 
-			threshold.parsed = thresholdExpression
+			threshold.setParsed(thresholdExpression)
 		}
 
 		// If the threshold's expression aggregation method is not
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib/types"
 )
 
 // thresholdExpression holds the parsed result of a threshold expression,
@@ -27,6 +30,16 @@ type thresholdExpression struct {
 
 	// Value holds the value parsed from the threshold expression.
 	Value float64
+
+	// BaselineFactor is set when the right hand side of the expression is
+	// relative to the same value of a baseline test run, e.g. 1.1 for
+	// `p(95) < baseline*1.1`. Value is unused in that case.
+	BaselineFactor null.Float
+
+	// Window holds the size of the sliding time window the threshold is
+	// evaluated over, e.g. 1m for `p(95)<300 over 1m`. It is zero when
+	// the threshold applies to the whole test run.
+	Window time.Duration
 }
 
 // SinkKey computes the key used to index a thresholdExpression in the engine's sinks.
@@ -53,10 +66,12 @@ func (te *thresholdExpression) SinkKey() string {
 // as defined in a JS script (for instance p(95)<1000), into a thresholdExpression
 // instance.
 //
-// It is expected to be of the form: `aggregation_method operator value`.
+// It is expected to be of the form: `aggregation_method operator value`,
+// optionally followed by a sliding time window: `over duration`.
 // As defined by the following BNF:
 // ```
-// assertion           -> aggregation_method whitespace* operator whitespace* float
+// expression          -> assertion (whitespace+ "over" whitespace+ duration)?
+// assertion           -> aggregation_method whitespace* operator whitespace* (float | baseline)
 // aggregation_method  -> trend | rate | gauge | counter
 // counter             -> "count" | "rate"
 // gauge               -> "value"
@@ -64,14 +79,21 @@ func (te *thresholdExpression) SinkKey() string {
 // trend               -> "avg" | "min" | "max" | "med" | percentile
 // percentile          -> "p(" float ")"
 // operator            -> ">" | ">=" | "<=" | "<" | "==" | "===" | "!="
+// baseline            -> "baseline" (whitespace* "*" whitespace* float)?
 // float               -> digit+ ("." digit+)?
 // digit               -> "0" | "1" | "2" | "3" | "4" | "5" | "6" | "7" | "8" | "9"
+// duration            -> a duration string, e.g. "30s", "1m" or "1h30m"
 // whitespace          -> " "
 // ```
 func parseThresholdExpression(input string) (*thresholdExpression, error) {
+	assertion, window, err := parseThresholdWindow(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing threshold expression %q; reason: %w", input, err)
+	}
+
 	// Scanning makes no assumption on the underlying values, and only
 	// checks that the expression has the right format.
-	method, operator, value, err := scanThresholdExpression(input)
+	method, operator, value, err := scanThresholdExpression(assertion)
 	if err != nil {
 		return nil, fmt.Errorf("failed parsing threshold expression %q; reason: %w", input, err)
 	}
@@ -84,7 +106,7 @@ func parseThresholdExpression(input string) (*thresholdExpression, error) {
 		return nil, err
 	}
 
-	parsedValue, err := strconv.ParseFloat(value, 64)
+	parsedValue, baselineFactor, err := parseThresholdValue(value)
 	if err != nil {
 		err = fmt.Errorf("failed parsing threshold expresion's %q right hand side; "+
 			"reason: %w", input, err,
@@ -97,11 +119,65 @@ func parseThresholdExpression(input string) (*thresholdExpression, error) {
 		AggregationValue:  parsedMethodValue,
 		Operator:          operator,
 		Value:             parsedValue,
+		BaselineFactor:    baselineFactor,
+		Window:            window,
 	}
 
 	return condition, nil
 }
 
+// tokenOver separates the assertion of a threshold expression from the size
+// of the sliding time window it should be evaluated over.
+const tokenOver = "over"
+
+// parseThresholdWindow splits the optional `over duration` suffix from a
+// threshold expression. It returns the remaining assertion and the parsed
+// window size, which is zero if the expression has no window.
+func parseThresholdWindow(input string) (string, time.Duration, error) {
+	fields := strings.Fields(input)
+	if len(fields) < 2 || fields[len(fields)-2] != tokenOver {
+		return input, 0, nil
+	}
+
+	window, err := types.ParseExtendedDuration(fields[len(fields)-1])
+	if err != nil {
+		return "", 0, fmt.Errorf("malformed time window; reason: %w", err)
+	}
+	if window <= 0 {
+		return "", 0, fmt.Errorf("the time window should be positive, got %s", window)
+	}
+
+	assertion := strings.TrimSpace(input[:strings.LastIndex(input, tokenOver)])
+	return assertion, window, nil
+}
+
+// tokenBaseline is used on the right hand side of threshold expressions
+// instead of a fixed value, to refer to the value of a baseline test run.
+const tokenBaseline = "baseline"
+
+// parseThresholdValue parses the right hand side of a threshold expression,
+// which is either a float or a baseline reference with an optional factor.
+func parseThresholdValue(input string) (float64, null.Float, error) {
+	if !strings.HasPrefix(input, tokenBaseline) {
+		value, err := strconv.ParseFloat(input, 64)
+		return value, null.Float{}, err
+	}
+
+	rest := strings.TrimSpace(strings.TrimPrefix(input, tokenBaseline))
+	if rest == "" {
+		return 0, null.FloatFrom(1), nil
+	}
+	if !strings.HasPrefix(rest, "*") {
+		return 0, null.Float{}, fmt.Errorf("malformed baseline reference, expected baseline*factor")
+	}
+
+	factor, err := strconv.ParseFloat(strings.TrimSpace(rest[1:]), 64)
+	if err != nil {
+		return 0, null.Float{}, fmt.Errorf("malformed baseline factor; reason: %w", err)
+	}
+	return 0, null.FloatFrom(factor), nil
+}
+
 // Define accepted threshold expression operators tokens
 const (
 	tokenLessEqual     = "<="
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/guregu/null.v3"
@@ -40,6 +41,70 @@ func TestParseThresholdExpression(t *testing.T) {
 			wantExpression: &thresholdExpression{AggregationMethod: "count", Operator: ">", Value: 20},
 			wantErr:        false,
 		},
+		{
+			name:  "valid threshold expression with a time window",
+			input: "p(95) < 300 over 1m",
+			wantExpression: &thresholdExpression{
+				AggregationMethod: "p",
+				AggregationValue:  null.FloatFrom(95),
+				Operator:          "<",
+				Value:             300,
+				Window:            time.Minute,
+			},
+			wantErr: false,
+		},
+		{
+			name:  "valid threshold expression relative to a baseline",
+			input: "p(95) < baseline * 1.1",
+			wantExpression: &thresholdExpression{
+				AggregationMethod: "p",
+				AggregationValue:  null.FloatFrom(95),
+				Operator:          "<",
+				BaselineFactor:    null.FloatFrom(1.1),
+			},
+			wantErr: false,
+		},
+		{
+			name:  "valid threshold expression with a baseline and a time window",
+			input: "avg<=baseline over 30s",
+			wantExpression: &thresholdExpression{
+				AggregationMethod: "avg",
+				Operator:          "<=",
+				BaselineFactor:    null.FloatFrom(1),
+				Window:            30 * time.Second,
+			},
+			wantErr: false,
+		},
+		{
+			name:           "malformed baseline factor fails",
+			input:          "avg<baseline*abc",
+			wantExpression: nil,
+			wantErr:        true,
+		},
+		{
+			name:           "malformed baseline reference fails",
+			input:          "avg<baseline+1",
+			wantExpression: nil,
+			wantErr:        true,
+		},
+		{
+			name:           "malformed time window fails",
+			input:          "p(95)<300 over forever",
+			wantExpression: nil,
+			wantErr:        true,
+		},
+		{
+			name:           "zero time window fails",
+			input:          "p(95)<300 over 0s",
+			wantExpression: nil,
+			wantErr:        true,
+		},
+		{
+			name:           "time window without a duration fails",
+			input:          "p(95)<300 over",
+			wantExpression: nil,
+			wantErr:        true,
+		},
 	}
 	for _, testCase := range tests {
 		testCase := testCase
@@ -42,7 +42,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 	}{
 		{
 			name:             "valid expression using the > operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01, null.Float{}, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 1},
 			wantOk:           true,
@@ -50,7 +50,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using the > operator over passing threshold and defined abort grace period",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01, null.Float{}, 0},
 			abortGracePeriod: types.NullDurationFrom(2 * time.Second),
 			sinks:            map[string]float64{"rate": 1},
 			wantOk:           true,
@@ -58,7 +58,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using the >= operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreaterEqual, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreaterEqual, 0.01, null.Float{}, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.01},
 			wantOk:           true,
@@ -66,7 +66,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using the <= operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenLessEqual, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenLessEqual, 0.01, null.Float{}, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.01},
 			wantOk:           true,
@@ -74,7 +74,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using the < operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenLess, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenLess, 0.01, null.Float{}, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.00001},
 			wantOk:           true,
@@ -82,7 +82,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using the == operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenLooselyEqual, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenLooselyEqual, 0.01, null.Float{}, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.01},
 			wantOk:           true,
@@ -90,7 +90,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using the === operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenStrictlyEqual, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenStrictlyEqual, 0.01, null.Float{}, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.01},
 			wantOk:           true,
@@ -98,7 +98,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression using != operator over passing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenBangEqual, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenBangEqual, 0.01, null.Float{}, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.02},
 			wantOk:           true,
@@ -106,7 +106,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression over failing threshold",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01, null.Float{}, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.00001},
 			wantOk:           false,
@@ -114,7 +114,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 		},
 		{
 			name:             "valid expression over non-existing sink",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01, null.Float{}, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"med": 27.2},
 			wantOk:           true,
@@ -124,7 +124,7 @@ func TestThreshold_runNoTaint(t *testing.T) {
 			// The ParseThresholdCondition constructor should ensure that no invalid
 			// operator gets through, but let's protect our future selves anyhow.
 			name:             "invalid expression operator",
-			parsed:           &thresholdExpression{tokenRate, null.Float{}, "&", 0.01},
+			parsed:           &thresholdExpression{tokenRate, null.Float{}, "&", 0.01, null.Float{}, 0},
 			abortGracePeriod: types.NullDurationFrom(0 * time.Second),
 			sinks:            map[string]float64{"rate": 0.00001},
 			wantOk:           false,
@@ -168,7 +168,7 @@ func BenchmarkRunNoTaint(b *testing.B) {
 		LastFailed:       false,
 		AbortOnFail:      false,
 		AbortGracePeriod: types.NullDurationFrom(2 * time.Second),
-		parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01},
+		parsed:           &thresholdExpression{tokenRate, null.Float{}, tokenGreater, 0.01, null.Float{}, 0},
 	}
 
 	sinks := map[string]float64{"rate": 1}
@@ -868,3 +868,177 @@ func TestThresholdsJSON(t *testing.T) {
 		assert.False(t, ts.Abort)
 	})
 }
+
+func TestThresholdsRunWindow(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(1000, 0)
+	addSamples := func(ts Thresholds, sink Sink, from time.Duration, values ...float64) {
+		for i, v := range values {
+			s := Sample{Time: start.Add(from + time.Duration(i)*time.Second), Value: v}
+			sink.Add(s)
+			ts.AddSample(s, sink)
+		}
+	}
+	// runAt runs the thresholds as if the given time has passed since the start.
+	runAt := func(ts *Thresholds, sink Sink, elapsed time.Duration) (bool, error) {
+		ts.now = func() time.Time { return start.Add(elapsed) }
+		return ts.Run(sink, elapsed)
+	}
+
+	t.Run("spike fails the threshold", func(t *testing.T) {
+		t.Parallel()
+
+		thresholds := NewThresholds([]string{"max<100 over 10s", "max<100"})
+		require.NoError(t, thresholds.Parse())
+		sink := NewTrendSink()
+
+		addSamples(thresholds, sink, 0, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10)
+		ok, err := runAt(&thresholds, sink, 12*time.Second)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		addSamples(thresholds, sink, 12*time.Second, 500)
+		ok, err = runAt(&thresholds, sink, 13*time.Second)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.True(t, thresholds.Thresholds[0].LastFailed)
+
+		// The spike is out of the window, but the threshold stays failed.
+		addSamples(thresholds, sink, 13*time.Second, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10)
+		ok, err = runAt(&thresholds, sink, 25*time.Second)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.True(t, thresholds.Thresholds[0].LastFailed)
+
+		worst := thresholds.Thresholds[0].WorstWindow()
+		require.NotNil(t, worst)
+		assert.Equal(t, float64(500), worst.Value)
+		assert.Equal(t, 10*time.Second, worst.End.Sub(worst.Start))
+		assert.Equal(t, 10*time.Second, thresholds.Thresholds[0].Window())
+		assert.Nil(t, thresholds.Thresholds[1].WorstWindow())
+	})
+
+	t.Run("spike averaged away only without a window", func(t *testing.T) {
+		t.Parallel()
+
+		thresholds := NewThresholds([]string{"avg<50 over 5s", "avg<50"})
+		require.NoError(t, thresholds.Parse())
+		sink := NewTrendSink()
+
+		values := make([]float64, 60)
+		for i := range values {
+			values[i] = 10
+			if i >= 30 && i < 35 {
+				values[i] = 200
+			}
+		}
+		addSamples(thresholds, sink, 0, values...)
+		ok, err := runAt(&thresholds, sink, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, ok, "only the current window is evaluated")
+
+		// Evaluating while the spike is in the window marks the threshold as failed
+		thresholds = NewThresholds([]string{"avg<50 over 5s", "avg<50"})
+		require.NoError(t, thresholds.Parse())
+		sink = NewTrendSink()
+		addSamples(thresholds, sink, 0, values[:35]...)
+		_, err = runAt(&thresholds, sink, 35*time.Second)
+		require.NoError(t, err)
+		addSamples(thresholds, sink, 35*time.Second, values[35:]...)
+		ok, err = runAt(&thresholds, sink, time.Minute)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.True(t, thresholds.Thresholds[0].LastFailed)
+		assert.False(t, thresholds.Thresholds[1].LastFailed)
+	})
+
+	t.Run("abort with grace period", func(t *testing.T) {
+		t.Parallel()
+
+		thresholds := NewThresholds([]string{"rate<0.5 over 4s"})
+		require.NoError(t, thresholds.Parse())
+		thresholds.Thresholds[0].AbortOnFail = true
+		thresholds.Thresholds[0].AbortGracePeriod = types.NullDurationFrom(10 * time.Second)
+		sink := &RateSink{}
+
+		// A partial window at the start of the test doesn't abort it.
+		addSamples(thresholds, sink, 0, 1)
+		ok, err := runAt(&thresholds, sink, time.Second)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.False(t, thresholds.Abort)
+
+		addSamples(thresholds, sink, time.Second, 1, 1, 1, 1)
+		ok, err = runAt(&thresholds, sink, 5*time.Second)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.False(t, thresholds.Abort, "the grace period has not passed yet")
+
+		addSamples(thresholds, sink, 5*time.Second, 1, 1, 1, 1, 1, 1, 1)
+		ok, err = runAt(&thresholds, sink, 12*time.Second)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.True(t, thresholds.Abort)
+	})
+
+	t.Run("shorter test than the window", func(t *testing.T) {
+		t.Parallel()
+
+		thresholds := NewThresholds([]string{"count<3 over 1m"})
+		require.NoError(t, thresholds.Parse())
+		sink := &CounterSink{}
+
+		addSamples(thresholds, sink, 0, 1, 1)
+		ok, err := runAt(&thresholds, sink, 2*time.Second)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		addSamples(thresholds, sink, 2*time.Second, 1)
+		ok, err = runAt(&thresholds, sink, 3*time.Second)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		require.NotNil(t, thresholds.Thresholds[0].WorstWindow())
+		assert.Equal(t, float64(3), thresholds.Thresholds[0].WorstWindow().Value)
+	})
+
+	t.Run("stale window after the traffic stops", func(t *testing.T) {
+		t.Parallel()
+
+		thresholds := NewThresholds([]string{"rate<0.5 over 4s"})
+		require.NoError(t, thresholds.Parse())
+		sink := &RateSink{}
+
+		addSamples(thresholds, sink, 0, 0, 0, 0, 0, 1, 1, 1, 1)
+		ok, err := runAt(&thresholds, sink, 8*time.Second)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.True(t, thresholds.Thresholds[0].LastFailed)
+
+		// No samples have been added since, so the window is empty once it
+		// moves past the last one, but the failure isn't forgotten.
+		b, err := thresholds.Thresholds[0].runWindow(start.Add(20*time.Second), thresholds.sinkValues)
+		require.NoError(t, err)
+		assert.True(t, b, "the empty window can't abort the test")
+		assert.True(t, thresholds.Thresholds[0].LastFailed)
+		sinkValue, _, _ := thresholds.Thresholds[0].window.current()
+		assert.Nil(t, sinkValue)
+	})
+
+	t.Run("gauge uses the latest value in the window", func(t *testing.T) {
+		t.Parallel()
+
+		thresholds := NewThresholds([]string{"value<5 over 10s"})
+		require.NoError(t, thresholds.Parse())
+		sink := &GaugeSink{}
+
+		// every value is in a different bucket
+		addSamples(thresholds, sink, 0, 9, 8, 7, 6, 5, 9, 8, 7, 6, 1)
+		ok, err := runAt(&thresholds, sink, 10*time.Second)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		sinkValue, _, _ := thresholds.Thresholds[0].window.current()
+		require.IsType(t, &GaugeSink{}, sinkValue)
+		assert.Equal(t, float64(1), sinkValue.(*GaugeSink).Value) //nolint:forcetypeassert
+	})
+}
@@ -0,0 +1,203 @@
+package metrics
+
+import (
+	"time"
+)
+
+// thresholdWindowBuckets is the number of buckets a threshold's sliding time
+// window is split into. The window slides by one bucket at a time, so a
+// window of 1m is evaluated in 6s steps.
+const thresholdWindowBuckets = 10
+
+// ThresholdWindowResult is the aggregated value of a threshold's metric over
+// a single time window.
+type ThresholdWindowResult struct {
+	Start time.Time
+	End   time.Time
+	Value float64
+}
+
+// thresholdWindow keeps the data needed to evaluate a threshold over a sliding
+// time window. The samples are aggregated in fixed-size time buckets, which
+// are merged together every time the threshold is evaluated.
+type thresholdWindow struct {
+	size       time.Duration
+	bucketSize time.Duration
+	buckets    map[int64]MergeableSink
+	first      time.Time
+	latest     int64
+
+	// full is set once a window that covers its whole size has been evaluated.
+	// Until then, the partial window since the first sample is what matters,
+	// which is equivalent to a threshold without a window.
+	full          bool
+	failed        bool
+	partialFailed bool
+	worst         *ThresholdWindowResult
+	worstFailed   bool
+}
+
+func newThresholdWindow(size time.Duration) *thresholdWindow {
+	bucketSize := size / thresholdWindowBuckets
+	if bucketSize <= 0 {
+		bucketSize = 1
+	}
+	return &thresholdWindow{
+		size:       size,
+		bucketSize: bucketSize,
+		buckets:    make(map[int64]MergeableSink),
+	}
+}
+
+func (w *thresholdWindow) bucketCount() int64 {
+	return int64(w.size / w.bucketSize)
+}
+
+// add records the sample in the bucket for its time. The bucket sinks are of
+// the same kind as the given sink of the metric.
+func (w *thresholdWindow) add(s Sample, metricSink Sink) {
+	index := s.Time.UnixNano() / int64(w.bucketSize)
+	seen := !w.first.IsZero()
+	if !seen || s.Time.Before(w.first) {
+		w.first = s.Time
+	}
+	if seen && index <= w.latest-w.bucketCount() {
+		return // too late, the window has already moved past this sample
+	}
+
+	bucket, ok := w.buckets[index]
+	if !ok {
+		bucket = newEmptySinkLike(metricSink)
+		if bucket == nil {
+			return
+		}
+		w.buckets[index] = bucket
+	}
+	bucket.Add(s)
+
+	if !seen || index > w.latest {
+		w.latest = index
+		w.prune()
+	}
+}
+
+// advance moves the window forward to the given time, even if no samples have
+// been added since, so that the window doesn't keep reporting stale results
+// after the traffic on the metric stops. It's a no-op before the first sample.
+func (w *thresholdWindow) advance(now time.Time) {
+	if w.first.IsZero() {
+		return
+	}
+	if index := now.UnixNano() / int64(w.bucketSize); index > w.latest {
+		w.latest = index
+		w.prune()
+	}
+}
+
+// prune removes the buckets that are outside of the current window.
+func (w *thresholdWindow) prune() {
+	for index := range w.buckets {
+		if index <= w.latest-w.bucketCount() {
+			delete(w.buckets, index)
+		}
+	}
+}
+
+// current merges the buckets of the current window into a single sink and
+// returns it together with the start and end time of the window. The returned
+// sink is nil if there is no data in the window. The buckets are merged in
+// time order, so e.g. a gauge ends up with the latest value.
+func (w *thresholdWindow) current() (Sink, time.Time, time.Time) {
+	var merged MergeableSink
+	for index := w.latest - w.bucketCount() + 1; index <= w.latest; index++ {
+		bucket, ok := w.buckets[index]
+		if !ok {
+			continue
+		}
+		if merged == nil {
+			merged = newEmptySinkLike(bucket)
+		}
+		_ = merged.Merge(bucket) // the buckets all have the same type
+	}
+	if merged == nil {
+		return nil, time.Time{}, time.Time{}
+	}
+
+	end := time.Unix(0, (w.latest+1)*int64(w.bucketSize))
+	start := end.Add(-w.size)
+	if start.Before(w.first) {
+		start = w.first
+	}
+	return merged, start, end
+}
+
+// isFull returns whether the current window covers its whole size.
+func (w *thresholdWindow) isFull(start, end time.Time) bool {
+	return end.Sub(start) >= w.size
+}
+
+// record saves the result of the evaluation of the current window and keeps
+// track of the worst one, based on the threshold operator.
+func (w *thresholdWindow) record(operator string, passes bool, result ThresholdWindowResult) {
+	full := w.isFull(result.Start, result.End)
+	if !full {
+		if !w.full {
+			w.partialFailed = !passes
+			w.worst, w.worstFailed = &result, !passes
+		}
+		return
+	}
+
+	if !w.full {
+		w.full = true
+		w.worst = nil
+	}
+	w.failed = w.failed || !passes
+
+	// A failing window is always worse than a passing one, otherwise the
+	// values are compared based on the operator.
+	if w.worst == nil || (!passes && !w.worstFailed) ||
+		(!passes == w.worstFailed && isWorse(operator, result.Value, w.worst.Value)) {
+		w.worst, w.worstFailed = &result, !passes
+	}
+}
+
+// hasFailed returns whether the threshold has failed for any of the windows.
+func (w *thresholdWindow) hasFailed() bool {
+	if w.full {
+		return w.failed
+	}
+	return w.partialFailed
+}
+
+// isWorse returns whether the value a is worse than b, i.e. closer to failing
+// or failing by more, for the given threshold operator.
+func isWorse(operator string, a, b float64) bool {
+	switch operator {
+	case tokenLess, tokenLessEqual:
+		return a > b
+	case tokenGreater, tokenGreaterEqual:
+		return a < b
+	default:
+		return false
+	}
+}
+
+// newEmptySinkLike returns a new empty sink of the same kind as the given one.
+func newEmptySinkLike(sink Sink) MergeableSink {
+	switch s := sink.(type) {
+	case *CounterSink:
+		return &CounterSink{}
+	case *GaugeSink:
+		return &GaugeSink{}
+	case *TrendSink:
+		if s.IsHistogram() {
+			return NewHistogramTrendSink()
+		}
+		return NewTrendSink()
+	case *RateSink:
+		return &RateSink{}
+	default:
+		return nil
+	}
+}